@@ -49,7 +49,50 @@ type Config struct {
 		AuthorityKey          string `yaml:"authority_key"`             // Admin 账户私钥（Base58）。链上 sponsor config 的 authority 唯一，仅此账户可审核赞助；用于自动初始化；环境变量 SOLANA_AUTHORITY_KEY
 		SponsorAdminWallet    string `yaml:"sponsor_admin_wallet"`     // 审核通过时收款地址，须与链上 config.admin_wallet 一致。可填 Admin 钱包或平台指定主办方收款地址（链上仅一个）；环境变量 SOLANA_SPONSOR_ADMIN_WALLET
 		SponsorReviewPeriodSecs int `yaml:"sponsor_review_period_secs"` // 赞助审核期限（秒），默认 10800（3 小时）；自动初始化时写入链上
+		TokenMints            map[string]string `yaml:"token_mints"` // 赏金奖励币种（如 USDC）对应的 SPL 代币 mint 地址，登记非 SOL 奖励发放时按此校验链上转账
 	} `yaml:"solana"`
+	Storage      StorageConfig      `yaml:"storage"`
+	Notification NotificationConfig `yaml:"notification"`
+}
+
+// StorageConfig 文件存储配置（作品附件、赞助商 Logo 等）
+type StorageConfig struct {
+	Driver           string `yaml:"driver"`             // 存储后端：local（本地磁盘，默认）| s3（S3 兼容，如 AWS S3、MinIO）
+	LocalDir         string `yaml:"local_dir"`          // local：文件存放目录，默认 uploads
+	PublicBaseURL    string `yaml:"public_base_url"`    // local：下载链接前缀（如 https://api.example.com），为空时返回相对路径
+	SigningSecret    string `yaml:"signing_secret"`     // local：下载链接签名密钥，为空时使用 JWT 密钥
+	URLExpireMinutes int    `yaml:"url_expire_minutes"` // 签名下载链接有效期（分钟），默认 60
+	MaxUploadMB      int    `yaml:"max_upload_mb"`      // 单个文件大小上限（MB），默认 200，各类文件另有各自上限
+	S3               struct {
+		Endpoint     string `yaml:"endpoint"` // 如 https://s3.amazonaws.com、http://127.0.0.1:9000（MinIO）
+		Region       string `yaml:"region"`   // 默认 us-east-1
+		Bucket       string `yaml:"bucket"`
+		AccessKey    string `yaml:"access_key"`     // 环境变量 STORAGE_S3_ACCESS_KEY
+		SecretKey    string `yaml:"secret_key"`     // 环境变量 STORAGE_S3_SECRET_KEY
+		UsePathStyle bool   `yaml:"use_path_style"` // 使用 endpoint/bucket/key 形式访问（MinIO 需开启）
+	} `yaml:"s3"`
+}
+
+// NotificationConfig 通知发送配置（邮件、短信）
+type NotificationConfig struct {
+	EmailDriver string `yaml:"email_driver"` // 邮件发送方式：log（仅写日志，默认）| file（写入本地文件）| smtp
+	SMSDriver   string `yaml:"sms_driver"`   // 短信发送方式：log（仅写日志，默认）| file（写入本地文件）| gateway（HTTP 短信网关）
+	FilePath    string `yaml:"file_path"`    // file：通知写入的文件，默认 notifications.log，用于本地调试与测试
+	MaxAttempts int    `yaml:"max_attempts"` // 发送失败后的最大尝试次数，默认 5，重试间隔按次数指数增长
+	PollSeconds int    `yaml:"poll_seconds"` // 发送队列轮询间隔（秒），默认 10
+	PasswordSetupURL string `yaml:"password_setup_url"` // 设置密码页面地址，赞助商账号开通短信中附带一次性链接 {password_setup_url}?token=...，默认 http://localhost:3001/set-password
+	SMTP        struct {
+		Host     string `yaml:"host"`
+		Port     int    `yaml:"port"` // 默认 587（STARTTLS）；465 时使用隐式 TLS
+		Username string `yaml:"username"`
+		Password string `yaml:"password"` // 环境变量 NOTIFY_SMTP_PASSWORD
+		From     string `yaml:"from"`     // 发件人，如 Hackathon <noreply@example.com>
+	} `yaml:"smtp"`
+	SMSGateway struct {
+		URL    string `yaml:"url"`     // 短信网关地址，以 JSON {"to","content","sign"} POST 发送
+		APIKey string `yaml:"api_key"` // 以 Authorization: Bearer 传递；环境变量 NOTIFY_SMS_API_KEY
+		Sign   string `yaml:"sign"`    // 短信签名
+	} `yaml:"sms_gateway"`
 }
 
 var AppConfig *Config
@@ -69,6 +112,20 @@ func LoadConfig() error {
 		ServerPort:     "8000",
 		ServerMode:     "debug",
 		CORSOrigins:    []string{"http://localhost:3000", "http://localhost:3001"},
+		Storage: StorageConfig{
+			Driver:           "local",
+			LocalDir:         "uploads",
+			URLExpireMinutes: 60,
+			MaxUploadMB:      200,
+		},
+		Notification: NotificationConfig{
+			EmailDriver: "log",
+			SMSDriver:   "log",
+			FilePath:    "notifications.log",
+			MaxAttempts: 5,
+			PollSeconds: 10,
+			PasswordSetupURL: "http://localhost:3001/set-password",
+		},
 		TestWallets: []string{
 			"0x1111111111111111111111111111111111111111",
 			"0x2222222222222222222222222222222222222222",
@@ -114,14 +171,57 @@ func LoadConfig() error {
 			AuthorityKey             string `yaml:"authority_key"`
 			SponsorAdminWallet       string `yaml:"sponsor_admin_wallet"`
 			SponsorReviewPeriodSecs  int    `yaml:"sponsor_review_period_secs"`
+			TokenMints               map[string]string `yaml:"token_mints"`
 		}{
 			ProgramID:               getEnv("SOLANA_PROGRAM_ID", defaultConfig.Solana.ProgramID),
 			RPCURL:                  getEnv("SOLANA_RPC_URL", defaultConfig.Solana.RPCURL),
 			AuthorityKey:            getEnv("SOLANA_AUTHORITY_KEY", defaultConfig.Solana.AuthorityKey),
 			SponsorAdminWallet:      getEnv("SOLANA_SPONSOR_ADMIN_WALLET", defaultConfig.Solana.SponsorAdminWallet),
 			SponsorReviewPeriodSecs: getEnvAsInt("SOLANA_SPONSOR_REVIEW_PERIOD_SECS", defaultConfig.Solana.SponsorReviewPeriodSecs),
+			TokenMints:              defaultConfig.Solana.TokenMints,
 		},
+		Storage:      defaultConfig.Storage,
+		Notification: defaultConfig.Notification,
+	}
+
+	storage := &AppConfig.Storage
+	storage.Driver = getEnv("STORAGE_DRIVER", storage.Driver)
+	storage.LocalDir = getEnv("STORAGE_LOCAL_DIR", storage.LocalDir)
+	storage.PublicBaseURL = getEnv("STORAGE_PUBLIC_BASE_URL", storage.PublicBaseURL)
+	storage.SigningSecret = getEnv("STORAGE_SIGNING_SECRET", storage.SigningSecret)
+	if storage.SigningSecret == "" {
+		storage.SigningSecret = AppConfig.JWTSecret
+	}
+	storage.URLExpireMinutes = getEnvAsInt("STORAGE_URL_EXPIRE_MINUTES", storage.URLExpireMinutes)
+	storage.MaxUploadMB = getEnvAsInt("STORAGE_MAX_UPLOAD_MB", storage.MaxUploadMB)
+	storage.S3.Endpoint = getEnv("STORAGE_S3_ENDPOINT", storage.S3.Endpoint)
+	storage.S3.Region = getEnv("STORAGE_S3_REGION", storage.S3.Region)
+	if storage.S3.Region == "" {
+		storage.S3.Region = "us-east-1"
+	}
+	storage.S3.Bucket = getEnv("STORAGE_S3_BUCKET", storage.S3.Bucket)
+	storage.S3.AccessKey = getEnv("STORAGE_S3_ACCESS_KEY", storage.S3.AccessKey)
+	storage.S3.SecretKey = getEnv("STORAGE_S3_SECRET_KEY", storage.S3.SecretKey)
+	storage.S3.UsePathStyle = getEnvAsBool("STORAGE_S3_USE_PATH_STYLE", storage.S3.UsePathStyle)
+
+	notification := &AppConfig.Notification
+	notification.EmailDriver = getEnv("NOTIFY_EMAIL_DRIVER", notification.EmailDriver)
+	notification.SMSDriver = getEnv("NOTIFY_SMS_DRIVER", notification.SMSDriver)
+	notification.FilePath = getEnv("NOTIFY_FILE_PATH", notification.FilePath)
+	notification.MaxAttempts = getEnvAsInt("NOTIFY_MAX_ATTEMPTS", notification.MaxAttempts)
+	notification.PollSeconds = getEnvAsInt("NOTIFY_POLL_SECONDS", notification.PollSeconds)
+	notification.PasswordSetupURL = getEnv("NOTIFY_PASSWORD_SETUP_URL", notification.PasswordSetupURL)
+	notification.SMTP.Host = getEnv("NOTIFY_SMTP_HOST", notification.SMTP.Host)
+	notification.SMTP.Port = getEnvAsInt("NOTIFY_SMTP_PORT", notification.SMTP.Port)
+	if notification.SMTP.Port == 0 {
+		notification.SMTP.Port = 587
 	}
+	notification.SMTP.Username = getEnv("NOTIFY_SMTP_USERNAME", notification.SMTP.Username)
+	notification.SMTP.Password = getEnv("NOTIFY_SMTP_PASSWORD", notification.SMTP.Password)
+	notification.SMTP.From = getEnv("NOTIFY_SMTP_FROM", notification.SMTP.From)
+	notification.SMSGateway.URL = getEnv("NOTIFY_SMS_URL", notification.SMSGateway.URL)
+	notification.SMSGateway.APIKey = getEnv("NOTIFY_SMS_API_KEY", notification.SMSGateway.APIKey)
+	notification.SMSGateway.Sign = getEnv("NOTIFY_SMS_SIGN", notification.SMSGateway.Sign)
 
 	return nil
 }
@@ -187,6 +287,54 @@ func loadFromYAML(filename string, defaultConfig *Config) error {
 	if yamlConfig.Solana.SponsorReviewPeriodSecs > 0 {
 		defaultConfig.Solana.SponsorReviewPeriodSecs = yamlConfig.Solana.SponsorReviewPeriodSecs
 	}
+	if len(yamlConfig.Solana.TokenMints) > 0 {
+		defaultConfig.Solana.TokenMints = yamlConfig.Solana.TokenMints
+	}
+	if yamlConfig.Storage.Driver != "" {
+		defaultConfig.Storage.Driver = yamlConfig.Storage.Driver
+	}
+	if yamlConfig.Storage.LocalDir != "" {
+		defaultConfig.Storage.LocalDir = yamlConfig.Storage.LocalDir
+	}
+	if yamlConfig.Storage.PublicBaseURL != "" {
+		defaultConfig.Storage.PublicBaseURL = yamlConfig.Storage.PublicBaseURL
+	}
+	if yamlConfig.Storage.SigningSecret != "" {
+		defaultConfig.Storage.SigningSecret = yamlConfig.Storage.SigningSecret
+	}
+	if yamlConfig.Storage.URLExpireMinutes > 0 {
+		defaultConfig.Storage.URLExpireMinutes = yamlConfig.Storage.URLExpireMinutes
+	}
+	if yamlConfig.Storage.MaxUploadMB > 0 {
+		defaultConfig.Storage.MaxUploadMB = yamlConfig.Storage.MaxUploadMB
+	}
+	if yamlConfig.Storage.S3.Endpoint != "" {
+		defaultConfig.Storage.S3 = yamlConfig.Storage.S3
+	}
+	if yamlConfig.Notification.EmailDriver != "" {
+		defaultConfig.Notification.EmailDriver = yamlConfig.Notification.EmailDriver
+	}
+	if yamlConfig.Notification.SMSDriver != "" {
+		defaultConfig.Notification.SMSDriver = yamlConfig.Notification.SMSDriver
+	}
+	if yamlConfig.Notification.FilePath != "" {
+		defaultConfig.Notification.FilePath = yamlConfig.Notification.FilePath
+	}
+	if yamlConfig.Notification.MaxAttempts > 0 {
+		defaultConfig.Notification.MaxAttempts = yamlConfig.Notification.MaxAttempts
+	}
+	if yamlConfig.Notification.PollSeconds > 0 {
+		defaultConfig.Notification.PollSeconds = yamlConfig.Notification.PollSeconds
+	}
+	if yamlConfig.Notification.PasswordSetupURL != "" {
+		defaultConfig.Notification.PasswordSetupURL = yamlConfig.Notification.PasswordSetupURL
+	}
+	if yamlConfig.Notification.SMTP.Host != "" {
+		defaultConfig.Notification.SMTP = yamlConfig.Notification.SMTP
+	}
+	if yamlConfig.Notification.SMSGateway.URL != "" {
+		defaultConfig.Notification.SMSGateway = yamlConfig.Notification.SMSGateway
+	}
 
 	return nil
 }
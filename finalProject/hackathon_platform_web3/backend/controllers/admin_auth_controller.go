@@ -163,6 +163,26 @@ func (c *AdminAuthController) ChangePassword(ctx *gin.Context) {
 	utils.Success(ctx, nil)
 }
 
+// SetupPassword 使用短信中的一次性链接设置登录密码（无需认证）
+func (c *AdminAuthController) SetupPassword(ctx *gin.Context) {
+	var req struct {
+		Token    string `json:"token" binding:"required"`
+		Password string `json:"password" binding:"required,min=8"`
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	if err := c.userService.SetPasswordWithToken(req.Token, req.Password); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
+
 // GetWallets 获取当前用户的钱包地址列表
 func (c *AdminAuthController) GetWallets(ctx *gin.Context) {
 	userID, _ := ctx.Get("user_id")
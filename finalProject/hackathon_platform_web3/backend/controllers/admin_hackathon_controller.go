@@ -12,12 +12,38 @@ import (
 )
 
 type AdminHackathonController struct {
-	hackathonService *services.HackathonService
+	hackathonService   *services.HackathonService
+	trackService       *services.TrackService
+	voteService        *services.VoteService
+	resultService      *services.ResultService
+	matchmakingService *services.MatchmakingService
+	snapshotService    *services.SnapshotService
+	similarityService  *services.SimilarityService
+	extensionService   *services.ExtensionService
+	waitlistService    *services.WaitlistService
+	formService        *services.RegistrationFormService
+	eligibilityService *services.EligibilityService
+	reminderService    *services.ReminderService
+	sponsorPortal      *services.SponsorPortalService
+	bountyService      *services.BountyService
 }
 
 func NewAdminHackathonController() *AdminHackathonController {
 	return &AdminHackathonController{
-		hackathonService: &services.HackathonService{},
+		hackathonService:   &services.HackathonService{},
+		trackService:       &services.TrackService{},
+		voteService:        &services.VoteService{},
+		resultService:      &services.ResultService{},
+		matchmakingService: &services.MatchmakingService{},
+		snapshotService:    &services.SnapshotService{},
+		similarityService:  &services.SimilarityService{},
+		extensionService:   &services.ExtensionService{},
+		waitlistService:    &services.WaitlistService{},
+		formService:        &services.RegistrationFormService{},
+		eligibilityService: &services.EligibilityService{},
+		reminderService:    &services.ReminderService{},
+		sponsorPortal:      &services.SponsorPortalService{},
+		bountyService:      &services.BountyService{},
 	}
 }
 
@@ -111,8 +137,10 @@ func (c *AdminHackathonController) UpdateHackathon(ctx *gin.Context) {
 
 	var req struct {
 		models.Hackathon
-		Stages []models.HackathonStage `json:"stages"`
-		Awards []models.HackathonAward  `json:"awards"`
+		Stages          []models.HackathonStage `json:"stages"`
+		Awards          []models.HackathonAward  `json:"awards"`
+		VoteLimit       *int                     `json:"vote_limit"`       // 未传时沿用原配置，传 0 表示不限制
+		MaxParticipants *int                     `json:"max_participants"` // 未传时沿用原配置，传 0 表示不限制
 	}
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -120,7 +148,10 @@ func (c *AdminHackathonController) UpdateHackathon(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.hackathonService.UpdateHackathon(id, &req.Hackathon, req.Stages, req.Awards, userID.(uint64), role.(string)); err != nil {
+	if err := c.hackathonService.UpdateHackathon(id, &req.Hackathon, req.Stages, req.Awards, services.HackathonLimitUpdates{
+		VoteLimit:       req.VoteLimit,
+		MaxParticipants: req.MaxParticipants,
+	}, userID.(uint64), role.(string)); err != nil {
 		utils.BadRequest(ctx, err.Error())
 		return
 	}
@@ -249,16 +280,18 @@ func (c *AdminHackathonController) SwitchStage(ctx *gin.Context) {
 		return
 	}
 
-	// 可选：切换为 registration/checkin 且活动已上链时，需传主办方已签名交易
+	// 可选：切换为 registration/checkin 且活动已上链时，需传主办方已签名交易；
+	// force 确认在队伍延期或迟交宽限期内提前结束提交阶段
 	var body struct {
 		SignedTransaction string `json:"signed_transaction"`
+		Force             bool   `json:"force"`
 	}
 	_ = ctx.ShouldBindJSON(&body)
 
 	userID, _ := ctx.Get("user_id")
 	role, _ := ctx.Get("role")
 
-	if err := c.hackathonService.SwitchStage(id, stage, userID.(uint64), role.(string), body.SignedTransaction); err != nil {
+	if err := c.hackathonService.SwitchStage(id, stage, userID.(uint64), role.(string), body.SignedTransaction, body.Force); err != nil {
 		utils.BadRequest(ctx, err.Error())
 		return
 	}
@@ -415,6 +448,23 @@ func (c *AdminHackathonController) GetStageTimes(ctx *gin.Context) {
 	utils.Success(ctx, stages)
 }
 
+// GetReminders 获取活动的阶段提醒任务（按阶段时间自动生成）
+func (c *AdminHackathonController) GetReminders(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	reminders, err := c.reminderService.GetReminders(id)
+	if err != nil {
+		utils.InternalServerError(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, reminders)
+}
+
 // GetHackathonStats 获取活动统计信息
 func (c *AdminHackathonController) GetHackathonStats(ctx *gin.Context) {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
@@ -479,3 +529,829 @@ func (c *AdminHackathonController) GetPosterQRCode(ctx *gin.Context) {
 	})
 }
 
+
+// GetTracks 获取活动赛道列表
+func (c *AdminHackathonController) GetTracks(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	tracks, err := c.trackService.GetTracks(id)
+	if err != nil {
+		utils.InternalServerError(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, tracks)
+}
+
+// CreateTrack 创建赛道（仅活动创建者，作品提交阶段开始前）
+func (c *AdminHackathonController) CreateTrack(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	var req struct {
+		Name        string `json:"name" binding:"required"`
+		Description string `json:"description"`
+		Order       int    `json:"order"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	track := models.HackathonTrack{
+		Name:        req.Name,
+		Description: req.Description,
+		Order:       req.Order,
+	}
+	if err := c.trackService.CreateTrack(id, &track, userID.(uint64), role.(string)); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, track)
+}
+
+// UpdateTrack 更新赛道（仅活动创建者，作品提交阶段开始前）
+func (c *AdminHackathonController) UpdateTrack(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+	trackID, err := strconv.ParseUint(ctx.Param("track_id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的赛道ID")
+		return
+	}
+
+	var req struct {
+		Name        *string `json:"name"`
+		Description *string `json:"description"`
+		Order       *int    `json:"order"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	updates := make(map[string]interface{})
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.Order != nil {
+		updates["order"] = *req.Order
+	}
+	if len(updates) == 0 {
+		utils.BadRequest(ctx, "没有需要更新的字段")
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	if err := c.trackService.UpdateTrack(id, trackID, updates, userID.(uint64), role.(string)); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
+
+// DeleteTrack 删除赛道（仅活动创建者，作品提交阶段开始前）
+func (c *AdminHackathonController) DeleteTrack(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+	trackID, err := strconv.ParseUint(ctx.Param("track_id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的赛道ID")
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	if err := c.trackService.DeleteTrack(id, trackID, userID.(uint64), role.(string)); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
+
+// GetSuspiciousVoteClusters 获取可疑投票团伙报告（仅Admin）
+func (c *AdminHackathonController) GetSuspiciousVoteClusters(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	clusters, err := c.voteService.GetSuspiciousVoteClusters(id)
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, clusters)
+}
+
+// ComputeResults 计算比赛结果（仅活动创建者，投票结束后、公布结果前）
+func (c *AdminHackathonController) ComputeResults(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	if err := c.resultService.ComputeResults(id, userID.(uint64), role.(string)); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	results, err := c.resultService.GetResultRecords(id, 0)
+	if err != nil {
+		utils.InternalServerError(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, results)
+}
+
+// GetResultRecords 获取比赛结果（含未公布的计算结果与调整记录）
+func (c *AdminHackathonController) GetResultRecords(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	// track_id 为空时返回全场排名，否则返回指定赛道排名
+	trackID, _ := strconv.ParseUint(ctx.DefaultQuery("track_id", "0"), 10, 64)
+
+	results, err := c.resultService.GetResultRecords(id, trackID)
+	if err != nil {
+		utils.InternalServerError(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, results)
+}
+
+// OverrideResult 调整作品获奖结果（仅活动创建者，公布结果前，需填写原因）
+func (c *AdminHackathonController) OverrideResult(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+	resultID, err := strconv.ParseUint(ctx.Param("result_id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的结果ID")
+		return
+	}
+
+	var req struct {
+		AwardID *uint64 `json:"award_id"` // 为空表示取消获奖
+		Reason  string  `json:"reason" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	if err := c.resultService.OverrideResult(id, resultID, req.AwardID, req.Reason, userID.(uint64), role.(string)); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
+
+// AutoGroupTeams 自动组队（仅活动创建者，组队阶段内），dry_run=true 时仅预览分组方案
+func (c *AdminHackathonController) AutoGroupTeams(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	dryRun := ctx.Query("dry_run") == "true"
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	assignments, err := c.matchmakingService.AutoGroup(id, userID.(uint64), role.(string), dryRun)
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, assignments)
+}
+
+// SetAutoGroupOnClose 设置组队阶段结束时是否自动组队（仅活动创建者，组队阶段结束前）
+func (c *AdminHackathonController) SetAutoGroupOnClose(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	var req struct {
+		Enabled bool `json:"enabled"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	if err := c.matchmakingService.SetAutoGroupOnClose(id, req.Enabled, userID.(uint64), role.(string)); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, gin.H{"auto_group_on_close": req.Enabled})
+}
+
+// GetSnapshots 获取活动作品仓库快照及默克尔根
+func (c *AdminHackathonController) GetSnapshots(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	snapshots, err := c.snapshotService.GetSnapshots(id)
+	if err != nil {
+		utils.InternalServerError(ctx, "获取仓库快照失败")
+		return
+	}
+
+	utils.Success(ctx, snapshots)
+}
+
+// RetrySnapshots 补全或重试作品仓库快照（仅主办方）
+func (c *AdminHackathonController) RetrySnapshots(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	if err := c.snapshotService.RetrySnapshots(id, userID.(uint64), role.(string)); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	snapshots, err := c.snapshotService.GetSnapshots(id)
+	if err != nil {
+		utils.InternalServerError(ctx, "获取仓库快照失败")
+		return
+	}
+
+	utils.Success(ctx, snapshots)
+}
+
+// GetSimilarityReport 获取作品相似度检测报告
+func (c *AdminHackathonController) GetSimilarityReport(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	report, err := c.similarityService.GetReport(id, userID.(uint64), role.(string))
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, report)
+}
+
+// StartSimilarityScan 发起作品相似度检测（后台执行，通过检测报告查看进度与结果）
+func (c *AdminHackathonController) StartSimilarityScan(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	scan, err := c.similarityService.StartScan(id, userID.(uint64), role.(string))
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, scan)
+}
+
+// SetLatePolicy 设置迟交规则（仅活动创建者）
+func (c *AdminHackathonController) SetLatePolicy(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	var req struct {
+		LateGraceMinutes *int     `json:"late_grace_minutes" binding:"required"`
+		LatePenalty      *float64 `json:"late_penalty" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	if err := c.extensionService.SetLatePolicy(id, *req.LateGraceMinutes, *req.LatePenalty, userID.(uint64), role.(string)); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
+
+// GetExtensions 获取活动的队伍提交延期列表
+func (c *AdminHackathonController) GetExtensions(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	extensions, err := c.extensionService.GetExtensions(id)
+	if err != nil {
+		utils.InternalServerError(ctx, "获取提交延期失败")
+		return
+	}
+
+	utils.Success(ctx, extensions)
+}
+
+// GrantExtension 为队伍延长提交截止时间（仅活动创建者，须填写原因）
+func (c *AdminHackathonController) GrantExtension(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	var req struct {
+		TeamID        uint64    `json:"team_id" binding:"required"`
+		ExtendedUntil time.Time `json:"extended_until" binding:"required"`
+		Reason        string    `json:"reason" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	extension, err := c.extensionService.GrantExtension(id, req.TeamID, req.ExtendedUntil, req.Reason, userID.(uint64), role.(string))
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, extension)
+}
+
+// RevokeExtension 取消队伍的提交延期（仅活动创建者）
+func (c *AdminHackathonController) RevokeExtension(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	teamID, err := strconv.ParseUint(ctx.Param("team_id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的队伍ID")
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	if err := c.extensionService.RevokeExtension(id, teamID, userID.(uint64), role.(string)); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
+
+// GetWaitlist 获取活动的报名候补名单
+func (c *AdminHackathonController) GetWaitlist(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	entries, err := c.waitlistService.GetWaitlist(id, userID.(uint64), role.(string))
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, entries)
+}
+
+// ReorderWaitlist 调整报名候补顺序（仅活动创建者）
+func (c *AdminHackathonController) ReorderWaitlist(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	var req struct {
+		EntryIDs []uint64 `json:"entry_ids" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	if err := c.waitlistService.ReorderWaitlist(id, req.EntryIDs, userID.(uint64), role.(string)); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
+
+// GetRegistrationForm 获取活动报名表
+func (c *AdminHackathonController) GetRegistrationForm(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	form, err := c.formService.GetForm(id)
+	if err != nil {
+		utils.NotFound(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, form)
+}
+
+// SetRegistrationForm 设置活动报名表与是否需要审核（仅活动创建者，签到开始前）
+func (c *AdminHackathonController) SetRegistrationForm(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	var req struct {
+		RequireApproval bool                       `json:"require_approval"`
+		Fields          []models.RegistrationField `json:"fields"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	fields, err := c.formService.SetForm(id, req.RequireApproval, req.Fields, userID.(uint64), role.(string))
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"require_approval": req.RequireApproval,
+		"fields":           fields,
+	})
+}
+
+// ReviewRegistration 审核报名申请（仅活动创建者）
+func (c *AdminHackathonController) ReviewRegistration(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	registrationID, err := strconv.ParseUint(ctx.Param("registration_id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的报名ID")
+		return
+	}
+
+	var req struct {
+		Status string `json:"status" binding:"required,oneof=approved rejected"`
+		Note   string `json:"note"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	if err := c.formService.ReviewRegistration(id, registrationID, req.Status, req.Note, userID.(uint64), role.(string)); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
+
+// GetSponsorRequests 获取活动收到的追加赞助申请
+func (c *AdminHackathonController) GetSponsorRequests(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	requests, err := c.sponsorPortal.GetHackathonEventRequests(id, userID.(uint64), role.(string), ctx.Query("status"))
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, requests)
+}
+
+// ReviewSponsorRequest 审核追加赞助申请（仅活动创建者）
+func (c *AdminHackathonController) ReviewSponsorRequest(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	requestID, err := strconv.ParseUint(ctx.Param("request_id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的申请ID")
+		return
+	}
+
+	var req struct {
+		Action       string `json:"action" binding:"required,oneof=approved rejected"`
+		RejectReason string `json:"reject_reason"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	if err := c.sponsorPortal.ReviewEventRequest(id, requestID, req.Action, req.RejectReason, userID.(uint64), role.(string)); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
+
+// GetBounties 获取活动的赞助商赏金及奖励发放情况
+func (c *AdminHackathonController) GetBounties(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	bounties, err := c.bountyService.GetHackathonBountyPayouts(id, userID.(uint64), role.(string))
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, bounties)
+}
+
+// GetEligibility 获取活动报名资格配置
+func (c *AdminHackathonController) GetEligibility(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	eligibility, err := c.eligibilityService.GetEligibility(id, userID.(uint64), role.(string))
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, eligibility)
+}
+
+// SetEligibility 设置活动报名资格规则（仅活动创建者，报名结束前）
+func (c *AdminHackathonController) SetEligibility(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	var req struct {
+		Match string                   `json:"match"`
+		Rules []models.EligibilityRule `json:"rules"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	if err := c.eligibilityService.SetEligibility(id, req.Match, req.Rules, userID.(uint64), role.(string)); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, req.Rules)
+}
+
+// GetAllowlist 分页获取活动钱包白名单
+func (c *AdminHackathonController) GetAllowlist(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "20"))
+	keyword := ctx.Query("keyword")
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	entries, total, err := c.eligibilityService.GetAllowlist(id, page, pageSize, keyword, userID.(uint64), role.(string))
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.SuccessWithPagination(ctx, entries, page, pageSize, total)
+}
+
+// ImportAllowlist 从 CSV 导入钱包白名单（表单字段 file：CSV 文件，replace=true 时覆盖原有白名单）
+func (c *AdminHackathonController) ImportAllowlist(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		utils.BadRequest(ctx, "请上传白名单 CSV 文件")
+		return
+	}
+	replace := ctx.PostForm("replace") == "true"
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	imported, err := c.eligibilityService.ImportAllowlist(id, file, replace, userID.(uint64), role.(string))
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, gin.H{"imported": imported})
+}
+
+// ClearAllowlist 清空活动钱包白名单
+func (c *AdminHackathonController) ClearAllowlist(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	if err := c.eligibilityService.ClearAllowlist(id, userID.(uint64), role.(string)); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
+
+// CreateInviteCodes 批量生成报名邀请码
+func (c *AdminHackathonController) CreateInviteCodes(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	var req struct {
+		Count     int        `json:"count" binding:"required"`
+		MaxUses   int        `json:"max_uses"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	codes, err := c.eligibilityService.CreateInviteCodes(id, req.Count, req.MaxUses, req.ExpiresAt, userID.(uint64), role.(string))
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, codes)
+}
+
+// DeleteInviteCode 删除报名邀请码
+func (c *AdminHackathonController) DeleteInviteCode(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	codeID, err := strconv.ParseUint(ctx.Param("code_id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的邀请码ID")
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	if err := c.eligibilityService.DeleteInviteCode(id, codeID, userID.(uint64), role.(string)); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
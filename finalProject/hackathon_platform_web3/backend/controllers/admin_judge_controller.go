@@ -0,0 +1,355 @@
+package controllers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"hackathon-backend/models"
+	"hackathon-backend/services"
+	"hackathon-backend/utils"
+)
+
+type AdminJudgeController struct {
+	judgeService      *services.JudgeService
+	submissionService *services.SubmissionService
+	snapshotService   *services.SnapshotService
+}
+
+func NewAdminJudgeController() *AdminJudgeController {
+	return &AdminJudgeController{
+		judgeService:      &services.JudgeService{},
+		submissionService: &services.SubmissionService{},
+		snapshotService:   &services.SnapshotService{},
+	}
+}
+
+// GetJudges 获取活动评委列表
+func (c *AdminJudgeController) GetJudges(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	judges, err := c.judgeService.GetJudges(id)
+	if err != nil {
+		utils.InternalServerError(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, judges)
+}
+
+// AssignJudge 指派评委（仅活动创建者）
+func (c *AdminJudgeController) AssignJudge(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	var req struct {
+		UserID uint64 `json:"user_id" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	judge, err := c.judgeService.AssignJudge(id, req.UserID, userID.(uint64), role.(string))
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, judge)
+}
+
+// RemoveJudge 取消指派评委（仅活动创建者）
+func (c *AdminJudgeController) RemoveJudge(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+	judgeUserID, err := strconv.ParseUint(ctx.Param("user_id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的评委ID")
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	if err := c.judgeService.RemoveJudge(id, judgeUserID, userID.(uint64), role.(string)); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
+
+// GetConflicts 获取评委回避列表
+func (c *AdminJudgeController) GetConflicts(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	conflicts, err := c.judgeService.GetConflicts(id)
+	if err != nil {
+		utils.InternalServerError(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, conflicts)
+}
+
+// AddConflict 登记评委利益冲突（仅活动创建者）
+func (c *AdminJudgeController) AddConflict(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	var req struct {
+		JudgeID uint64 `json:"judge_id" binding:"required"`
+		TeamID  uint64 `json:"team_id" binding:"required"`
+		Reason  string `json:"reason"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	conflict, err := c.judgeService.AddConflict(id, req.JudgeID, req.TeamID, req.Reason, userID.(uint64), role.(string))
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, conflict)
+}
+
+// RemoveConflict 删除评委利益冲突登记（仅活动创建者）
+func (c *AdminJudgeController) RemoveConflict(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+	conflictID, err := strconv.ParseUint(ctx.Param("conflict_id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的回避记录ID")
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	if err := c.judgeService.RemoveConflict(id, conflictID, userID.(uint64), role.(string)); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
+
+// GetRubric 获取活动评分标准
+func (c *AdminJudgeController) GetRubric(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	criteria, err := c.judgeService.GetRubric(id)
+	if err != nil {
+		utils.InternalServerError(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, criteria)
+}
+
+// SetRubric 设置活动评分标准（仅活动创建者，评审开始前）
+func (c *AdminJudgeController) SetRubric(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	var req struct {
+		Criteria []models.RubricCriterion `json:"criteria" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	if err := c.judgeService.SetRubric(id, req.Criteria, userID.(uint64), role.(string)); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, req.Criteria)
+}
+
+// SetJudgeWeight 设置评委评分权重（仅活动创建者）
+func (c *AdminJudgeController) SetJudgeWeight(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	var req struct {
+		JudgeWeight *float64 `json:"judge_weight" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	if err := c.judgeService.SetJudgeWeight(id, *req.JudgeWeight, userID.(uint64), role.(string)); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
+
+// GetSubmissionScores 获取作品的评委打分明细（主办方查看）
+func (c *AdminJudgeController) GetSubmissionScores(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+	submissionID, err := strconv.ParseUint(ctx.Param("submission_id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的作品ID")
+		return
+	}
+
+	submission, err := c.submissionService.GetSubmissionByID(submissionID)
+	if err != nil || submission.HackathonID != id {
+		utils.NotFound(ctx, "作品不存在")
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+	scores, err := c.judgeService.GetSubmissionScores(id, submissionID, userID.(uint64), role.(string))
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, scores)
+}
+
+// GetMyHackathons 获取评委被指派的活动列表（Judge权限）
+func (c *AdminJudgeController) GetMyHackathons(ctx *gin.Context) {
+	userID, _ := ctx.Get("user_id")
+
+	hackathons, err := c.judgeService.GetJudgeHackathons(userID.(uint64))
+	if err != nil {
+		utils.InternalServerError(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, hackathons)
+}
+
+// GetJudgeSubmissions 获取评委可评审的作品列表（Judge权限，已排除回避队伍）
+func (c *AdminJudgeController) GetJudgeSubmissions(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+
+	submissions, err := c.judgeService.GetJudgeSubmissions(id, userID.(uint64))
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, submissions)
+}
+
+// SubmitScores 评委为作品打分（Judge权限，投票阶段内）
+func (c *AdminJudgeController) SubmitScores(ctx *gin.Context) {
+	submissionID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的作品ID")
+		return
+	}
+
+	var req struct {
+		Scores []struct {
+			CriterionID uint64  `json:"criterion_id" binding:"required"`
+			Score       float64 `json:"score"`
+			Comment     string  `json:"comment"`
+		} `json:"scores" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	scores := make([]models.JudgeScore, 0, len(req.Scores))
+	for _, item := range req.Scores {
+		scores = append(scores, models.JudgeScore{
+			CriterionID: item.CriterionID,
+			Score:       item.Score,
+			Comment:     item.Comment,
+		})
+	}
+
+	userID, _ := ctx.Get("user_id")
+
+	if err := c.judgeService.SubmitScores(submissionID, userID.(uint64), scores); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
+
+// CheckSnapshot 评委查看作品锁定的仓库提交及仓库是否已变化
+func (c *AdminJudgeController) CheckSnapshot(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的作品ID")
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+
+	result, err := c.snapshotService.CheckSnapshot(id, userID.(uint64))
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, result)
+}
@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"hackathon-backend/services"
+	"hackathon-backend/utils"
+)
+
+type AdminNotificationController struct {
+	notificationService *services.NotificationService
+}
+
+func NewAdminNotificationController() *AdminNotificationController {
+	return &AdminNotificationController{
+		notificationService: &services.NotificationService{},
+	}
+}
+
+// GetNotificationJobs 获取通知发送记录
+func (c *AdminNotificationController) GetNotificationJobs(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	status := ctx.Query("status")
+	template := ctx.Query("template")
+
+	jobs, total, err := c.notificationService.GetNotificationJobs(page, pageSize, status, template)
+	if err != nil {
+		utils.InternalServerError(ctx, err.Error())
+		return
+	}
+
+	utils.SuccessWithPagination(ctx, jobs, page, pageSize, total)
+}
+
+// RetryNotificationJob 重新发送失败的通知
+func (c *AdminNotificationController) RetryNotificationJob(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的通知ID")
+		return
+	}
+
+	if err := c.notificationService.RetryNotificationJob(id); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
@@ -35,8 +35,8 @@ func (c *AdminUserController) CreateUser(ctx *gin.Context) {
 	}
 
 	// 验证角色
-	if req.Role != "organizer" && req.Role != "sponsor" {
-		utils.BadRequest(ctx, "角色只能是organizer或sponsor")
+	if req.Role != "organizer" && req.Role != "sponsor" && req.Role != "judge" {
+		utils.BadRequest(ctx, "角色只能是organizer、sponsor或judge")
 		return
 	}
 
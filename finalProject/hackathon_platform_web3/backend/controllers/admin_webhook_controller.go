@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"hackathon-backend/services"
+	"hackathon-backend/utils"
+)
+
+type AdminWebhookController struct {
+	webhookService *services.WebhookService
+}
+
+func NewAdminWebhookController() *AdminWebhookController {
+	return &AdminWebhookController{
+		webhookService: &services.WebhookService{},
+	}
+}
+
+// GetEventTypes 获取可订阅的事件类型
+func (c *AdminWebhookController) GetEventTypes(ctx *gin.Context) {
+	utils.Success(ctx, c.webhookService.GetEventTypes())
+}
+
+// GetWebhooks 获取 Webhook 列表
+func (c *AdminWebhookController) GetWebhooks(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	hackathonID, _ := strconv.ParseUint(ctx.Query("hackathon_id"), 10, 64)
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	webhooks, total, err := c.webhookService.GetWebhooks(hackathonID, userID.(uint64), role.(string), page, pageSize)
+	if err != nil {
+		utils.InternalServerError(ctx, err.Error())
+		return
+	}
+
+	utils.SuccessWithPagination(ctx, webhooks, page, pageSize, total)
+}
+
+// CreateWebhook 创建 Webhook，返回的签名密钥只显示这一次
+func (c *AdminWebhookController) CreateWebhook(ctx *gin.Context) {
+	var input services.WebhookInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(ctx, "参数错误")
+		return
+	}
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	webhook, err := c.webhookService.CreateWebhook(input, userID.(uint64), role.(string))
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"webhook": webhook,
+		"secret":  webhook.Secret,
+	})
+}
+
+// UpdateWebhook 修改 Webhook
+func (c *AdminWebhookController) UpdateWebhook(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的Webhook ID")
+		return
+	}
+	var input services.WebhookInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(ctx, "参数错误")
+		return
+	}
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	webhook, err := c.webhookService.UpdateWebhook(id, input, userID.(uint64), role.(string))
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, webhook)
+}
+
+// DeleteWebhook 删除 Webhook
+func (c *AdminWebhookController) DeleteWebhook(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的Webhook ID")
+		return
+	}
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	if err := c.webhookService.DeleteWebhook(id, userID.(uint64), role.(string)); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
+
+// RotateSecret 重置签名密钥，返回的新密钥只显示这一次
+func (c *AdminWebhookController) RotateSecret(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的Webhook ID")
+		return
+	}
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	secret, err := c.webhookService.RotateSecret(id, userID.(uint64), role.(string))
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, gin.H{"secret": secret})
+}
+
+// GetDeliveries 获取 Webhook 的投递记录
+func (c *AdminWebhookController) GetDeliveries(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的Webhook ID")
+		return
+	}
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	deliveries, total, err := c.webhookService.GetDeliveries(id, userID.(uint64), role.(string), ctx.Query("status"), page, pageSize)
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.SuccessWithPagination(ctx, deliveries, page, pageSize, total)
+}
+
+// ReplayDelivery 重放投递
+func (c *AdminWebhookController) ReplayDelivery(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的Webhook ID")
+		return
+	}
+	deliveryID, err := strconv.ParseUint(ctx.Param("delivery_id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的投递ID")
+		return
+	}
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	delivery, err := c.webhookService.ReplayDelivery(id, deliveryID, userID.(uint64), role.(string))
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, delivery)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/gin-gonic/gin"
+	"hackathon-backend/models"
 	"hackathon-backend/services"
 	"hackathon-backend/utils"
 )
@@ -156,7 +157,11 @@ func (c *ArenaAuthController) GetProfile(ctx *gin.Context) {
 		utils.NotFound(ctx, "参赛者不存在")
 		return
 	}
-	utils.Success(ctx, participant)
+	// 邮箱不随参赛者信息公开，仅本人可见
+	utils.Success(ctx, struct {
+		*models.Participant
+		Email string `json:"email"`
+	}{participant, participant.Email})
 }
 
 // UpdateProfile 更新当前参赛者信息
@@ -10,11 +10,15 @@ import (
 
 type ArenaHackathonController struct {
 	hackathonService *services.HackathonService
+	trackService     *services.TrackService
+	bountyService    *services.BountyService
 }
 
 func NewArenaHackathonController() *ArenaHackathonController {
 	return &ArenaHackathonController{
 		hackathonService: &services.HackathonService{},
+		trackService:     &services.TrackService{},
+		bountyService:    &services.BountyService{},
 	}
 }
 
@@ -82,8 +86,9 @@ func (c *ArenaHackathonController) GetArchiveList(ctx *gin.Context) {
 	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
 	keyword := ctx.Query("keyword")
 	timeRange := ctx.Query("time_range") // 最近一个月、最近三个月、最近半年、全部
+	techStack := ctx.Query("tech_stack")
 
-	hackathons, total, err := c.hackathonService.GetArchiveHackathons(page, pageSize, keyword, timeRange)
+	hackathons, total, err := c.hackathonService.GetArchiveHackathons(page, pageSize, keyword, timeRange, techStack)
 	if err != nil {
 		utils.InternalServerError(ctx, err.Error())
 		return
@@ -109,3 +114,37 @@ func (c *ArenaHackathonController) GetArchiveDetail(ctx *gin.Context) {
 	utils.Success(ctx, archive)
 }
 
+
+// GetTracks 获取活动赛道列表
+func (c *ArenaHackathonController) GetTracks(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	tracks, err := c.trackService.GetTracks(id)
+	if err != nil {
+		utils.InternalServerError(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, tracks)
+}
+
+// GetBounties 获取活动的赞助商赏金
+func (c *ArenaHackathonController) GetBounties(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	bounties, err := c.bountyService.GetHackathonBounties(id)
+	if err != nil {
+		utils.NotFound(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, bounties)
+}
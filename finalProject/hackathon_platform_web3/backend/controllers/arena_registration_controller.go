@@ -1,7 +1,11 @@
 package controllers
 
 import (
+	"errors"
+	"io"
+	"mime/multipart"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"hackathon-backend/services"
@@ -10,15 +14,68 @@ import (
 
 type ArenaRegistrationController struct {
 	registrationService *services.RegistrationService
+	waitlistService     *services.WaitlistService
+	formService         *services.RegistrationFormService
+	eligibilityService  *services.EligibilityService
 }
 
 func NewArenaRegistrationController() *ArenaRegistrationController {
 	return &ArenaRegistrationController{
 		registrationService: &services.RegistrationService{},
+		waitlistService:     &services.WaitlistService{},
+		formService:         &services.RegistrationFormService{},
+		eligibilityService:  &services.EligibilityService{},
 	}
 }
 
-// Register 报名
+// parseRegisterRequest 解析报名请求中的邀请码与报名表答案：JSON 请求体 {"invite_code": "邀请码", "answers": {"字段ID": "答案"}}，
+// 或 multipart 表单 invite_code=邀请码、answers[字段ID]=答案、files[字段ID]=文件（含 file 字段时使用）
+func parseRegisterRequest(ctx *gin.Context) (string, map[uint64]string, map[uint64]*multipart.FileHeader, error) {
+	answers := make(map[uint64]string)
+	files := make(map[uint64]*multipart.FileHeader)
+
+	var inviteCode string
+	raw := make(map[string]string)
+	if strings.HasPrefix(ctx.ContentType(), "multipart/") {
+		form, err := ctx.MultipartForm()
+		if err != nil {
+			return "", nil, nil, err
+		}
+		inviteCode = ctx.PostForm("invite_code")
+		raw = ctx.PostFormMap("answers")
+		for key, headers := range form.File {
+			if !strings.HasPrefix(key, "files[") || !strings.HasSuffix(key, "]") || len(headers) == 0 {
+				continue
+			}
+			fieldID, err := strconv.ParseUint(key[len("files["):len(key)-1], 10, 64)
+			if err != nil {
+				return "", nil, nil, errors.New("无效的报名表字段ID")
+			}
+			files[fieldID] = headers[0]
+		}
+	} else if ctx.Request.ContentLength != 0 {
+		var req struct {
+			InviteCode string            `json:"invite_code"`
+			Answers    map[string]string `json:"answers"`
+		}
+		if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			return "", nil, nil, err
+		}
+		inviteCode = req.InviteCode
+		raw = req.Answers
+	}
+
+	for key, value := range raw {
+		fieldID, err := strconv.ParseUint(key, 10, 64)
+		if err != nil {
+			return "", nil, nil, errors.New("无效的报名表字段ID")
+		}
+		answers[fieldID] = value
+	}
+	return inviteCode, answers, files, nil
+}
+
+// Register 报名（同时提交报名表答案），报名人数已满时加入候补名单
 func (c *ArenaRegistrationController) Register(ctx *gin.Context) {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
 	if err != nil {
@@ -26,14 +83,38 @@ func (c *ArenaRegistrationController) Register(ctx *gin.Context) {
 		return
 	}
 
+	inviteCode, answers, files, err := parseRegisterRequest(ctx)
+	if err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
 	participantID, _ := ctx.Get("participant_id")
 
-	if err := c.registrationService.Register(id, participantID.(uint64)); err != nil {
+	entry, err := c.registrationService.Register(id, participantID.(uint64), inviteCode, answers, files)
+	if err != nil {
 		utils.BadRequest(ctx, err.Error())
 		return
 	}
 
-	utils.Success(ctx, nil)
+	if entry != nil {
+		utils.Success(ctx, gin.H{
+			"status":   "waitlisted",
+			"position": entry.QueuePosition,
+		})
+		return
+	}
+
+	registration, err := c.registrationService.GetRegistrationStatus(id, participantID.(uint64))
+	if err != nil {
+		utils.InternalServerError(ctx, err.Error())
+		return
+	}
+	result := gin.H{"status": "registered"}
+	if registration != nil {
+		result["review_status"] = registration.Status
+	}
+	utils.Success(ctx, result)
 }
 
 // GetRegistrationStatus 获取报名状态
@@ -46,17 +127,30 @@ func (c *ArenaRegistrationController) GetRegistrationStatus(ctx *gin.Context) {
 
 	participantID, _ := ctx.Get("participant_id")
 
-	registered, registeredAt, err := c.registrationService.GetRegistrationStatus(id, participantID.(uint64))
+	registration, err := c.registrationService.GetRegistrationStatus(id, participantID.(uint64))
 	if err != nil {
 		utils.InternalServerError(ctx, err.Error())
 		return
 	}
 
 	result := gin.H{
-		"registered": registered,
+		"registered": registration != nil,
 	}
-	if registeredAt != nil {
-		result["registered_at"] = registeredAt
+	if registration != nil {
+		result["registered_at"] = registration.CreatedAt
+		result["review_status"] = registration.Status
+		result["review_note"] = registration.ReviewNote
+		result["answers"] = registration.Answers
+		result["sponsor_consent"] = registration.SponsorConsent
+	} else {
+		entry, err := c.waitlistService.GetMyEntry(id, participantID.(uint64))
+		if err != nil {
+			utils.InternalServerError(ctx, err.Error())
+			return
+		}
+		if entry != nil {
+			result["waitlist"] = entry
+		}
 	}
 
 	utils.Success(ctx, result)
@@ -124,3 +218,100 @@ func (c *ArenaRegistrationController) GetCheckinStatus(ctx *gin.Context) {
 	utils.Success(ctx, result)
 }
 
+// SetSponsorConsent 设置是否同意向活动赞助商提供个人信息
+func (c *ArenaRegistrationController) SetSponsorConsent(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	var req struct {
+		Consent *bool `json:"consent" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, "参数错误")
+		return
+	}
+
+	participantID, _ := ctx.Get("participant_id")
+
+	if err := c.registrationService.SetSponsorConsent(id, participantID.(uint64), *req.Consent); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, gin.H{"sponsor_consent": *req.Consent})
+}
+
+// ConfirmWaitlistOffer 确认候补递补的名额
+func (c *ArenaRegistrationController) ConfirmWaitlistOffer(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	participantID, _ := ctx.Get("participant_id")
+
+	if err := c.waitlistService.ConfirmOffer(id, participantID.(uint64)); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, gin.H{"status": "registered"})
+}
+
+// LeaveWaitlist 退出候补名单
+func (c *ArenaRegistrationController) LeaveWaitlist(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	participantID, _ := ctx.Get("participant_id")
+
+	if err := c.waitlistService.LeaveWaitlist(id, participantID.(uint64)); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
+
+// GetRegistrationForm 获取活动报名表
+func (c *ArenaRegistrationController) GetRegistrationForm(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	form, err := c.formService.GetForm(id)
+	if err != nil {
+		utils.NotFound(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, form)
+}
+
+// CheckEligibility 检查当前参赛者是否满足活动的报名资格（邀请码在报名时校验）
+func (c *ArenaRegistrationController) CheckEligibility(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	participantID, _ := ctx.Get("participant_id")
+
+	result, err := c.eligibilityService.CheckParticipantEligibility(id, participantID.(uint64))
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, result)
+}
@@ -1,9 +1,11 @@
 package controllers
 
 import (
+	"encoding/json"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"hackathon-backend/database"
 	"hackathon-backend/models"
 	"hackathon-backend/services"
@@ -13,12 +15,16 @@ import (
 type ArenaSubmissionController struct {
 	submissionService *services.SubmissionService
 	teamService       *services.TeamService
+	attachmentService *services.AttachmentService
+	bountyService     *services.BountyService
 }
 
 func NewArenaSubmissionController() *ArenaSubmissionController {
 	return &ArenaSubmissionController{
 		submissionService: &services.SubmissionService{},
 		teamService:       &services.TeamService{},
+		attachmentService: &services.AttachmentService{},
+		bountyService:     &services.BountyService{},
 	}
 }
 
@@ -31,7 +37,12 @@ func (c *ArenaSubmissionController) CreateSubmission(ctx *gin.Context) {
 	}
 
 	var submission models.Submission
-	if err := ctx.ShouldBindJSON(&submission); err != nil {
+	if err := ctx.ShouldBindBodyWith(&submission, binding.JSON); err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	supplied, err := suppliedJSONFields(ctx)
+	if err != nil {
 		utils.BadRequest(ctx, "参数错误: "+err.Error())
 		return
 	}
@@ -53,7 +64,7 @@ func (c *ArenaSubmissionController) CreateSubmission(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.submissionService.CreateSubmission(hackathonID, teamMember.TeamID, &submission); err != nil {
+	if err := c.submissionService.CreateSubmission(hackathonID, teamMember.TeamID, teamMember.ParticipantID, &submission, supplied); err != nil {
 		utils.BadRequest(ctx, err.Error())
 		return
 	}
@@ -61,6 +72,19 @@ func (c *ArenaSubmissionController) CreateSubmission(ctx *gin.Context) {
 	utils.Success(ctx, submission)
 }
 
+// suppliedJSONFields 请求 JSON 中出现的字段名，用于修改作品时区分未传与传空（需先以 ShouldBindBodyWith 读取请求体）
+func suppliedJSONFields(ctx *gin.Context) (map[string]bool, error) {
+	var raw map[string]json.RawMessage
+	if err := ctx.ShouldBindBodyWith(&raw, binding.JSON); err != nil {
+		return nil, err
+	}
+	supplied := make(map[string]bool, len(raw))
+	for key := range raw {
+		supplied[key] = true
+	}
+	return supplied, nil
+}
+
 // GetSubmissionList 获取作品列表
 func (c *ArenaSubmissionController) GetSubmissionList(ctx *gin.Context) {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
@@ -73,8 +97,10 @@ func (c *ArenaSubmissionController) GetSubmissionList(ctx *gin.Context) {
 	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
 	keyword := ctx.Query("keyword")
 	sort := ctx.DefaultQuery("sort", "created_at_desc")
+	trackID, _ := strconv.ParseUint(ctx.DefaultQuery("track_id", "0"), 10, 64)
+	tag := ctx.Query("tag")
 
-	submissions, total, err := c.submissionService.GetSubmissionList(id, page, pageSize, keyword, sort)
+	submissions, total, err := c.submissionService.GetSubmissionList(id, page, pageSize, keyword, sort, trackID, tag)
 	if err != nil {
 		utils.InternalServerError(ctx, err.Error())
 		return
@@ -83,6 +109,22 @@ func (c *ArenaSubmissionController) GetSubmissionList(ctx *gin.Context) {
 	utils.SuccessWithPagination(ctx, submissions, page, pageSize, total)
 }
 
+// SearchArchiveSubmissions 按技术栈搜索已结束活动的作品
+func (c *ArenaSubmissionController) SearchArchiveSubmissions(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
+	keyword := ctx.Query("keyword")
+	techStack := ctx.Query("tech_stack")
+
+	submissions, total, err := c.submissionService.SearchArchiveSubmissions(techStack, keyword, page, pageSize)
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.SuccessWithPagination(ctx, submissions, page, pageSize, total)
+}
+
 // GetSubmissionByID 获取作品详情
 func (c *ArenaSubmissionController) GetSubmissionByID(ctx *gin.Context) {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
@@ -109,7 +151,12 @@ func (c *ArenaSubmissionController) UpdateSubmission(ctx *gin.Context) {
 	}
 
 	var submission models.Submission
-	if err := ctx.ShouldBindJSON(&submission); err != nil {
+	if err := ctx.ShouldBindBodyWith(&submission, binding.JSON); err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	supplied, err := suppliedJSONFields(ctx)
+	if err != nil {
 		utils.BadRequest(ctx, "参数错误: "+err.Error())
 		return
 	}
@@ -128,7 +175,7 @@ func (c *ArenaSubmissionController) UpdateSubmission(ctx *gin.Context) {
 
 	// 队长和队员都可以修改作品
 	participantIDUint, _ := participantID.(uint64)
-	if err := c.submissionService.UpdateSubmission(id, teamMember.TeamID, participantIDUint, &submission); err != nil {
+	if err := c.submissionService.UpdateSubmission(id, teamMember.TeamID, participantIDUint, &submission, supplied); err != nil {
 		utils.BadRequest(ctx, err.Error())
 		return
 	}
@@ -165,3 +212,212 @@ func (c *ArenaSubmissionController) GetSubmissionHistory(ctx *gin.Context) {
 	utils.Success(ctx, histories)
 }
 
+// GetSubmissionDiff 比较作品的两个版本（队伍成员），参数 from、to 为修改记录ID
+func (c *ArenaSubmissionController) GetSubmissionDiff(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的作品ID")
+		return
+	}
+
+	fromID, err := strconv.ParseUint(ctx.Query("from"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的起始版本")
+		return
+	}
+	toID, err := strconv.ParseUint(ctx.Query("to"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的目标版本")
+		return
+	}
+
+	participantID, _ := ctx.Get("participant_id")
+
+	// 查找用户所在的队伍
+	var teamMember models.TeamMember
+	if err := database.DB.Joins("JOIN teams ON team_members.team_id = teams.id").
+		Joins("JOIN submissions ON submissions.team_id = teams.id").
+		Where("team_members.participant_id = ? AND submissions.id = ?", participantID, id).
+		First(&teamMember).Error; err != nil {
+		utils.BadRequest(ctx, "您没有权限查看此作品的修改记录")
+		return
+	}
+
+	diff, err := c.submissionService.GetSubmissionDiff(id, fromID, toID)
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, diff)
+}
+
+// RestoreSubmissionRevision 将作品恢复为历史版本（队伍成员，提交阶段内）
+func (c *ArenaSubmissionController) RestoreSubmissionRevision(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的作品ID")
+		return
+	}
+
+	historyID, err := strconv.ParseUint(ctx.Param("history_id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的修改记录ID")
+		return
+	}
+
+	participantID, _ := ctx.Get("participant_id")
+
+	// 查找用户所在的队伍
+	var teamMember models.TeamMember
+	if err := database.DB.Joins("JOIN teams ON team_members.team_id = teams.id").
+		Joins("JOIN submissions ON submissions.team_id = teams.id").
+		Where("team_members.participant_id = ? AND submissions.id = ?", participantID, id).
+		First(&teamMember).Error; err != nil {
+		utils.BadRequest(ctx, "您没有权限修改此作品")
+		return
+	}
+
+	if err := c.submissionService.RestoreSubmissionRevision(id, historyID, teamMember.TeamID, teamMember.ParticipantID); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	submission, err := c.submissionService.GetSubmissionByID(id)
+	if err != nil {
+		utils.InternalServerError(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, submission)
+}
+
+// UploadAttachment 上传作品附件（队伍成员），表单字段 kind：pitch_deck、screenshot、demo_video、other，file：文件
+func (c *ArenaSubmissionController) UploadAttachment(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的作品ID")
+		return
+	}
+
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		utils.BadRequest(ctx, "请选择要上传的文件")
+		return
+	}
+
+	participantID, _ := ctx.Get("participant_id")
+
+	attachment, err := c.attachmentService.AddAttachment(id, participantID.(uint64), ctx.PostForm("kind"), file)
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, attachment)
+}
+
+// GetAttachments 获取作品附件
+func (c *ArenaSubmissionController) GetAttachments(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的作品ID")
+		return
+	}
+
+	participantID, _ := ctx.Get("participant_id")
+
+	attachments, err := c.attachmentService.GetAttachments(id, participantID.(uint64))
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, attachments)
+}
+
+// DeleteAttachment 删除作品附件（队伍成员）
+func (c *ArenaSubmissionController) DeleteAttachment(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的作品ID")
+		return
+	}
+	attachmentID, err := strconv.ParseUint(ctx.Param("attachment_id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的附件ID")
+		return
+	}
+
+	participantID, _ := ctx.Get("participant_id")
+
+	if err := c.attachmentService.DeleteAttachment(id, attachmentID, participantID.(uint64)); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
+
+// GetSubmissionBounties 获取作品已报名的赏金
+func (c *ArenaSubmissionController) GetSubmissionBounties(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的作品ID")
+		return
+	}
+
+	bounties, err := c.bountyService.GetSubmissionBounties(id)
+	if err != nil {
+		utils.InternalServerError(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, bounties)
+}
+
+// JoinBounty 将作品报名参加赏金
+func (c *ArenaSubmissionController) JoinBounty(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的作品ID")
+		return
+	}
+	bountyID, err := strconv.ParseUint(ctx.Param("bounty_id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的赏金ID")
+		return
+	}
+
+	participantID, _ := ctx.Get("participant_id")
+
+	if err := c.bountyService.JoinBounty(id, bountyID, participantID.(uint64)); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
+
+// LeaveBounty 撤回作品的赏金报名
+func (c *ArenaSubmissionController) LeaveBounty(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的作品ID")
+		return
+	}
+	bountyID, err := strconv.ParseUint(ctx.Param("bounty_id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的赏金ID")
+		return
+	}
+
+	participantID, _ := ctx.Get("participant_id")
+
+	if err := c.bountyService.LeaveBounty(id, bountyID, participantID.(uint64)); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
@@ -1,7 +1,9 @@
 package controllers
 
 import (
+	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"hackathon-backend/services"
@@ -9,12 +11,16 @@ import (
 )
 
 type ArenaTeamController struct {
-	teamService *services.TeamService
+	teamService        *services.TeamService
+	teamJoinService    *services.TeamJoinService
+	matchmakingService *services.MatchmakingService
 }
 
 func NewArenaTeamController() *ArenaTeamController {
 	return &ArenaTeamController{
-		teamService: &services.TeamService{},
+		teamService:        &services.TeamService{},
+		teamJoinService:    &services.TeamJoinService{},
+		matchmakingService: &services.MatchmakingService{},
 	}
 }
 
@@ -27,8 +33,9 @@ func (c *ArenaTeamController) CreateTeam(ctx *gin.Context) {
 	}
 
 	var req struct {
-		Name    string `json:"name" binding:"required"`
-		MaxSize int    `json:"max_size"`
+		Name       string `json:"name" binding:"required"`
+		MaxSize    int    `json:"max_size"`
+		JoinPolicy string `json:"join_policy"` // open、request、invite_only，默认 open
 	}
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -42,7 +49,7 @@ func (c *ArenaTeamController) CreateTeam(ctx *gin.Context) {
 
 	participantID, _ := ctx.Get("participant_id")
 
-	team, err := c.teamService.CreateTeam(id, participantID.(uint64), req.Name, req.MaxSize)
+	team, err := c.teamService.CreateTeam(id, participantID.(uint64), req.Name, req.MaxSize, req.JoinPolicy)
 	if err != nil {
 		utils.BadRequest(ctx, err.Error())
 		return
@@ -210,3 +217,322 @@ func (c *ArenaTeamController) UpdateTeam(ctx *gin.Context) {
 	utils.Success(ctx, nil)
 }
 
+
+// TransferLeadership 转让队长（仅队长）
+func (c *ArenaTeamController) TransferLeadership(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的队伍ID")
+		return
+	}
+
+	var req struct {
+		NewLeaderID uint64 `json:"new_leader_id" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	leaderID, _ := ctx.Get("participant_id")
+
+	if err := c.teamService.TransferLeadership(id, leaderID.(uint64), req.NewLeaderID); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
+
+// LockTeam 锁定队伍（仅队长）
+func (c *ArenaTeamController) LockTeam(ctx *gin.Context) {
+	c.setTeamLocked(ctx, true)
+}
+
+// UnlockTeam 解锁队伍（仅队长）
+func (c *ArenaTeamController) UnlockTeam(ctx *gin.Context) {
+	c.setTeamLocked(ctx, false)
+}
+
+func (c *ArenaTeamController) setTeamLocked(ctx *gin.Context, locked bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的队伍ID")
+		return
+	}
+
+	leaderID, _ := ctx.Get("participant_id")
+
+	if err := c.teamService.SetTeamLocked(id, leaderID.(uint64), locked); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
+
+// CreateInvitation 发放队伍邀请（仅队长）
+func (c *ArenaTeamController) CreateInvitation(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的队伍ID")
+		return
+	}
+
+	var req struct {
+		InviteeID      *uint64 `json:"invitee_id"`       // 指定受邀参赛者，为空时生成公开邀请码/链接
+		MaxUses        int     `json:"max_uses"`         // 公开邀请可使用次数，默认 1
+		ExpiresInHours int     `json:"expires_in_hours"` // 有效期（小时），默认 72
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	leaderID, _ := ctx.Get("participant_id")
+
+	invitation, err := c.teamJoinService.CreateInvitation(id, leaderID.(uint64), req.InviteeID, req.MaxUses, time.Duration(req.ExpiresInHours)*time.Hour)
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"invitation":  invitation,
+		"invite_link": fmt.Sprintf("/invitations/%s", invitation.Code),
+	})
+}
+
+// GetTeamInvitations 获取队伍发放的邀请（仅队长）
+func (c *ArenaTeamController) GetTeamInvitations(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的队伍ID")
+		return
+	}
+
+	leaderID, _ := ctx.Get("participant_id")
+
+	invitations, err := c.teamJoinService.GetTeamInvitations(id, leaderID.(uint64))
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, invitations)
+}
+
+// RevokeInvitation 撤销队伍邀请（仅队长）
+func (c *ArenaTeamController) RevokeInvitation(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的队伍ID")
+		return
+	}
+	invitationID, err := strconv.ParseUint(ctx.Param("invitation_id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的邀请ID")
+		return
+	}
+
+	leaderID, _ := ctx.Get("participant_id")
+
+	if err := c.teamJoinService.RevokeInvitation(id, invitationID, leaderID.(uint64)); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
+
+// GetInvitation 根据邀请码查看邀请
+func (c *ArenaTeamController) GetInvitation(ctx *gin.Context) {
+	invitation, err := c.teamJoinService.GetInvitationByCode(ctx.Param("code"))
+	if err != nil {
+		utils.NotFound(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, invitation)
+}
+
+// AcceptInvitation 接受邀请加入队伍
+func (c *ArenaTeamController) AcceptInvitation(ctx *gin.Context) {
+	participantID, _ := ctx.Get("participant_id")
+
+	team, err := c.teamJoinService.AcceptInvitation(ctx.Param("code"), participantID.(uint64))
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, team)
+}
+
+// DeclineInvitation 拒绝邀请
+func (c *ArenaTeamController) DeclineInvitation(ctx *gin.Context) {
+	participantID, _ := ctx.Get("participant_id")
+
+	if err := c.teamJoinService.DeclineInvitation(ctx.Param("code"), participantID.(uint64)); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
+
+// CreateJoinRequest 申请加入队伍
+func (c *ArenaTeamController) CreateJoinRequest(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的队伍ID")
+		return
+	}
+
+	var req struct {
+		Message string `json:"message"`
+	}
+	_ = ctx.ShouldBindJSON(&req)
+
+	participantID, _ := ctx.Get("participant_id")
+
+	request, err := c.teamJoinService.CreateJoinRequest(id, participantID.(uint64), req.Message)
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, request)
+}
+
+// GetTeamJoinRequests 获取队伍的加入申请（仅队长）
+func (c *ArenaTeamController) GetTeamJoinRequests(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的队伍ID")
+		return
+	}
+
+	leaderID, _ := ctx.Get("participant_id")
+
+	requests, err := c.teamJoinService.GetTeamJoinRequests(id, leaderID.(uint64), ctx.Query("status"))
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, requests)
+}
+
+// ReviewJoinRequest 审批加入申请（仅队长），action 为 approve 或 decline
+func (c *ArenaTeamController) ReviewJoinRequest(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的队伍ID")
+		return
+	}
+	requestID, err := strconv.ParseUint(ctx.Param("request_id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的申请ID")
+		return
+	}
+
+	var req struct {
+		Action string `json:"action" binding:"required,oneof=approve decline"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	leaderID, _ := ctx.Get("participant_id")
+
+	if req.Action == "approve" {
+		err = c.teamJoinService.ApproveJoinRequest(id, requestID, leaderID.(uint64))
+	} else {
+		err = c.teamJoinService.DeclineJoinRequest(id, requestID, leaderID.(uint64))
+	}
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
+
+// CancelJoinRequest 撤回自己的加入申请
+func (c *ArenaTeamController) CancelJoinRequest(ctx *gin.Context) {
+	requestID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的申请ID")
+		return
+	}
+
+	participantID, _ := ctx.Get("participant_id")
+
+	if err := c.teamJoinService.CancelJoinRequest(requestID, participantID.(uint64)); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
+
+// GetMyJoinStatus 获取我在活动中待处理的加入申请与收到的邀请
+func (c *ArenaTeamController) GetMyJoinStatus(ctx *gin.Context) {
+	hackathonID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	participantID, _ := ctx.Get("participant_id")
+
+	status, err := c.teamJoinService.GetMyJoinStatus(hackathonID, participantID.(uint64))
+	if err != nil {
+		utils.InternalServerError(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, status)
+}
+
+// RecommendTeams 为当前参赛者推荐队伍（按技能、角色与时区匹配度排序）
+func (c *ArenaTeamController) RecommendTeams(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	participantID, _ := ctx.Get("participant_id")
+
+	matches, err := c.matchmakingService.RecommendTeams(id, participantID.(uint64), limit)
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, matches)
+}
+
+// RecommendParticipants 为队伍推荐尚未组队的参赛者（仅队长）
+func (c *ArenaTeamController) RecommendParticipants(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的队伍ID")
+		return
+	}
+
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	leaderID, _ := ctx.Get("participant_id")
+
+	matches, err := c.matchmakingService.RecommendParticipants(id, leaderID.(uint64), limit)
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, matches)
+}
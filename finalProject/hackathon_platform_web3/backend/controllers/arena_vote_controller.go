@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"errors"
+	"io"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -28,6 +30,17 @@ func (c *ArenaVoteController) Vote(ctx *gin.Context) {
 		return
 	}
 
+	// 活动设置了赛道时需指定投票的赛道；二次方投票可指定票数
+	var body struct {
+		TrackID uint64 `json:"track_id"`
+		Weight  int    `json:"weight"`
+	}
+	// 请求体可为空（未分赛道的普通投票），非空时须为合法 JSON
+	if err := ctx.ShouldBindJSON(&body); err != nil && !errors.Is(err, io.EOF) {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
 	participantID, _ := ctx.Get("participant_id")
 
 	// 获取作品信息以获取活动ID
@@ -37,7 +50,7 @@ func (c *ArenaVoteController) Vote(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.voteService.Vote(submission.HackathonID, participantID.(uint64), submissionID); err != nil {
+	if err := c.voteService.Vote(submission.HackathonID, participantID.(uint64), submissionID, body.TrackID, body.Weight); err != nil {
 		utils.BadRequest(ctx, err.Error())
 		return
 	}
@@ -53,9 +66,11 @@ func (c *ArenaVoteController) CancelVote(ctx *gin.Context) {
 		return
 	}
 
+	trackID, _ := strconv.ParseUint(ctx.DefaultQuery("track_id", "0"), 10, 64)
+
 	participantID, _ := ctx.Get("participant_id")
 
-	if err := c.voteService.CancelVote(participantID.(uint64), submissionID); err != nil {
+	if err := c.voteService.CancelVote(participantID.(uint64), submissionID, trackID); err != nil {
 		utils.BadRequest(ctx, err.Error())
 		return
 	}
@@ -63,6 +78,52 @@ func (c *ArenaVoteController) CancelVote(ctx *gin.Context) {
 	utils.Success(ctx, nil)
 }
 
+// SubmitBallot 提交排序选票（排序投票）
+func (c *ArenaVoteController) SubmitBallot(ctx *gin.Context) {
+	hackathonID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	var req struct {
+		TrackID       uint64   `json:"track_id"`
+		SubmissionIDs []uint64 `json:"submission_ids" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	participantID, _ := ctx.Get("participant_id")
+
+	if err := c.voteService.SubmitBallot(hackathonID, participantID.(uint64), req.TrackID, req.SubmissionIDs); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
+
+// GetVoteQuota 获取我的投票额度
+func (c *ArenaVoteController) GetVoteQuota(ctx *gin.Context) {
+	hackathonID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	participantID, _ := ctx.Get("participant_id")
+
+	quota, err := c.voteService.GetVoteQuota(hackathonID, participantID.(uint64))
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, quota)
+}
+
 // GetMyVotes 获取我的投票记录
 func (c *ArenaVoteController) GetMyVotes(ctx *gin.Context) {
 	hackathonID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
@@ -90,7 +151,16 @@ func (c *ArenaVoteController) GetResults(ctx *gin.Context) {
 		return
 	}
 
-	results, err := c.voteService.GetResults(id)
+	// track_id 为空时返回全场排名，否则返回指定赛道排名
+	trackID, _ := strconv.ParseUint(ctx.DefaultQuery("track_id", "0"), 10, 64)
+
+	results, err := c.voteService.GetResults(id, trackID)
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	trackResults, err := c.voteService.GetTrackResults(id)
 	if err != nil {
 		utils.BadRequest(ctx, err.Error())
 		return
@@ -104,6 +174,7 @@ func (c *ArenaVoteController) GetResults(ctx *gin.Context) {
 
 	utils.Success(ctx, gin.H{
 		"rankings": results,
+		"track_results": trackResults,
 		"statistics": gin.H{
 			"total_votes":      totalVotes,
 			"total_teams":       totalTeams,
@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"io"
+	"mime"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"hackathon-backend/storage"
+	"hackathon-backend/utils"
+)
+
+type FileController struct{}
+
+func NewFileController() *FileController {
+	return &FileController{}
+}
+
+// Download 本地存储的签名下载（S3 存储直接使用预签名链接，不经过此接口）
+func (c *FileController) Download(ctx *gin.Context) {
+	local, ok := storage.Default.(*storage.LocalStorage)
+	if !ok {
+		utils.NotFound(ctx, "文件不存在")
+		return
+	}
+
+	key := strings.TrimPrefix(ctx.Param("key"), "/")
+	if err := local.Verify(key, ctx.Query("expires"), ctx.Query("signature")); err != nil {
+		utils.Forbidden(ctx, err.Error())
+		return
+	}
+
+	serveFile(ctx, key, "private, max-age=300")
+}
+
+// GetLogo 获取赞助商 Logo（公开访问）
+func (c *FileController) GetLogo(ctx *gin.Context) {
+	serveFile(ctx, "logos/"+ctx.Param("name"), "public, max-age=86400")
+}
+
+// serveFile 从存储后端读取文件并返回
+func serveFile(ctx *gin.Context, key, cacheControl string) {
+	if storage.Default == nil {
+		utils.NotFound(ctx, "文件不存在")
+		return
+	}
+	reader, err := storage.Default.Get(key)
+	if err != nil {
+		utils.NotFound(ctx, "文件不存在")
+		return
+	}
+	defer reader.Close()
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	ctx.Header("Content-Type", contentType)
+	ctx.Header("Cache-Control", cacheControl)
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.Status(200)
+	_, _ = io.Copy(ctx.Writer, reader)
+}
@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"hackathon-backend/services"
+	"hackathon-backend/utils"
+)
+
+// InboxController 站内通知，Arena 参赛者与后台用户共用
+type InboxController struct {
+	inboxService *services.InboxService
+}
+
+func NewInboxController() *InboxController {
+	return &InboxController{
+		inboxService: &services.InboxService{},
+	}
+}
+
+// inboxRecipient 当前登录的通知接收方：Arena 为参赛者，后台为用户
+func inboxRecipient(ctx *gin.Context) (string, uint64) {
+	if participantID, ok := ctx.Get("participant_id"); ok {
+		return services.RecipientParticipant, participantID.(uint64)
+	}
+	userID, _ := ctx.Get("user_id")
+	return services.RecipientUser, userID.(uint64)
+}
+
+// GetNotifications 获取站内通知列表
+func (c *InboxController) GetNotifications(ctx *gin.Context) {
+	recipientType, recipientID := inboxRecipient(ctx)
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	unreadOnly := ctx.Query("unread") == "true"
+
+	notifications, total, err := c.inboxService.GetNotifications(recipientType, recipientID, page, pageSize, unreadOnly)
+	if err != nil {
+		utils.InternalServerError(ctx, err.Error())
+		return
+	}
+
+	utils.SuccessWithPagination(ctx, notifications, page, pageSize, total)
+}
+
+// GetUnreadCount 获取未读通知数量
+func (c *InboxController) GetUnreadCount(ctx *gin.Context) {
+	recipientType, recipientID := inboxRecipient(ctx)
+
+	count, err := c.inboxService.GetUnreadCount(recipientType, recipientID)
+	if err != nil {
+		utils.InternalServerError(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, gin.H{"unread": count})
+}
+
+// MarkRead 标记通知为已读
+func (c *InboxController) MarkRead(ctx *gin.Context) {
+	recipientType, recipientID := inboxRecipient(ctx)
+	notificationID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的通知ID")
+		return
+	}
+
+	if err := c.inboxService.MarkRead(recipientType, recipientID, notificationID); err != nil {
+		utils.NotFound(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
+
+// MarkAllRead 全部标记为已读
+func (c *InboxController) MarkAllRead(ctx *gin.Context) {
+	recipientType, recipientID := inboxRecipient(ctx)
+
+	if err := c.inboxService.MarkAllRead(recipientType, recipientID); err != nil {
+		utils.InternalServerError(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
+
+// GetPreferences 获取通知偏好
+func (c *InboxController) GetPreferences(ctx *gin.Context) {
+	recipientType, recipientID := inboxRecipient(ctx)
+
+	preferences, err := c.inboxService.GetPreferences(recipientType, recipientID)
+	if err != nil {
+		utils.InternalServerError(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, preferences)
+}
+
+// UpdatePreferences 修改通知偏好（开启或屏蔽某类通知）
+func (c *InboxController) UpdatePreferences(ctx *gin.Context) {
+	recipientType, recipientID := inboxRecipient(ctx)
+
+	var req struct {
+		Preferences []services.NotificationPreferenceUpdate `json:"preferences" binding:"required,dive"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	preferences, err := c.inboxService.UpdatePreferences(recipientType, recipientID, req.Preferences)
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, preferences)
+}
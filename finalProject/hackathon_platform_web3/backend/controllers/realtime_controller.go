@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"hackathon-backend/events"
+	"hackathon-backend/services"
+	"hackathon-backend/utils"
+)
+
+// streamHeartbeat SSE 心跳间隔，避免代理因连接空闲而断开
+const streamHeartbeat = 25 * time.Second
+
+// RealtimeController 实时推送（SSE）
+type RealtimeController struct {
+	realtimeService *services.RealtimeService
+}
+
+func NewRealtimeController() *RealtimeController {
+	return &RealtimeController{
+		realtimeService: &services.RealtimeService{},
+	}
+}
+
+// streamEvents 以 SSE 推送订阅的事件：先发送 snapshot（当前状态），之后推送 stage_changed、team_updated、vote_tally 事件
+func streamEvents(ctx *gin.Context, sub *events.Subscription, snapshot interface{}, staff bool) {
+	defer sub.Close()
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.SSEvent("snapshot", snapshot)
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Request.Context().Done():
+			return false
+		case event := <-sub.C:
+			if event.StaffOnly && !staff {
+				return true
+			}
+			ctx.SSEvent(event.Type, event)
+			return true
+		case <-heartbeat.C:
+			ctx.SSEvent("ping", time.Now().Unix())
+			return true
+		}
+	})
+}
+
+// ArenaStream 参赛者订阅活动实时推送（投票期间的实时计票按活动设置决定是否推送）
+func (c *RealtimeController) ArenaStream(ctx *gin.Context) {
+	hackathonID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+
+	sub, snapshot, err := c.realtimeService.SubscribeArena(hackathonID)
+	if err != nil {
+		utils.NotFound(ctx, err.Error())
+		return
+	}
+	streamEvents(ctx, sub, snapshot, false)
+}
+
+// AdminStream 主办方或 Admin 订阅活动实时推送（始终包含实时计票）
+func (c *RealtimeController) AdminStream(ctx *gin.Context) {
+	hackathonID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	sub, snapshot, err := c.realtimeService.SubscribeAdmin(hackathonID, userID.(uint64), role.(string))
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+	streamEvents(ctx, sub, snapshot, true)
+}
+
+// SetLiveTally 设置投票期间实时计票是否向参赛者公开
+func (c *RealtimeController) SetLiveTally(ctx *gin.Context) {
+	hackathonID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+	userID, _ := ctx.Get("user_id")
+	role, _ := ctx.Get("role")
+
+	var req struct {
+		LiveTally string `json:"live_tally" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	if err := c.realtimeService.SetLiveTally(hackathonID, req.LiveTally, userID.(uint64), role.(string)); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, gin.H{"live_tally": req.LiveTally})
+}
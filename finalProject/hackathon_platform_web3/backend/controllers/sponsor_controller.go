@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"strconv"
 	"strings"
+	"time"
 
 	"hackathon-backend/config"
 	"hackathon-backend/models"
@@ -17,16 +18,18 @@ import (
 type SponsorController struct {
 	sponsorService   *services.SponsorService
 	hackathonService *services.HackathonService
+	tierService      *services.SponsorTierService
 }
 
 func NewSponsorController() *SponsorController {
 	return &SponsorController{
 		sponsorService:   &services.SponsorService{},
 		hackathonService: &services.HackathonService{},
+		tierService:      &services.SponsorTierService{},
 	}
 }
 
-// PrepareSponsorApply 返回赞助商申请页构建链上 sponsor_apply 交易所需的 program_id、rpc_url（无需登录）。
+// PrepareSponsorApply 返回赞助商申请页构建链上 sponsor_apply 交易所需的 program_id、rpc_url，以及上传 Logo 的一次性凭证（无需登录）。
 // 若链上 sponsor config 未初始化且已配置 SOLANA_AUTHORITY_KEY，会先自动执行一次 initialize_sponsor_config。
 func (c *SponsorController) PrepareSponsorApply(ctx *gin.Context) {
 	if err := solana.EnsureSponsorConfigInitialized(); err != nil {
@@ -39,20 +42,22 @@ func (c *SponsorController) PrepareSponsorApply(ctx *gin.Context) {
 		return
 	}
 	utils.Success(ctx, gin.H{
-		"program_id": programID,
-		"rpc_url":    rpcURL,
+		"program_id":        programID,
+		"rpc_url":           rpcURL,
+		"logo_upload_token": c.sponsorService.IssueLogoUploadToken(),
 	})
 }
 
 // CreateApplication 提交赞助申请（无需登录）。申请创建后需由前端用钱包对链上 sponsor_apply 交易签名并发送，金额转入金库。
 func (c *SponsorController) CreateApplication(ctx *gin.Context) {
 	var req struct {
-		Phone         string   `json:"phone" binding:"required"`
-		LogoURL       *string  `json:"logo_url,omitempty"`
-		SponsorType   string   `json:"sponsor_type" binding:"required,oneof=long_term event_specific"`
-		EventIDs      []uint64 `json:"event_ids"`
-		AmountSol     float64  `json:"amount_sol" binding:"required"`
-		WalletAddress string   `json:"wallet_address" binding:"required"` // 赞助商链上钱包（申请时签名转入金库的地址），审核链上指令需要
+		Phone         string            `json:"phone" binding:"required"`
+		LogoURL       *string           `json:"logo_url,omitempty"`
+		SponsorType   string            `json:"sponsor_type" binding:"required,oneof=long_term event_specific"`
+		EventIDs      []uint64          `json:"event_ids"`
+		EventTracks   map[string]uint64 `json:"event_tracks"` // 可选：活动ID -> 赞助的赛道ID
+		AmountSol     float64           `json:"amount_sol" binding:"required"`
+		WalletAddress string            `json:"wallet_address" binding:"required"` // 赞助商链上钱包（申请时签名转入金库的地址），审核链上指令需要
 	}
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -88,6 +93,17 @@ func (c *SponsorController) CreateApplication(ctx *gin.Context) {
 		eventIDsJSON = string(eventIDsBytes)
 	}
 
+	// 赞助赛道映射（活动ID -> 赛道ID），提交申请与审核通过时校验赛道属于所选活动
+	var eventTracksJSON string
+	if len(req.EventTracks) > 0 {
+		eventTracksBytes, err := json.Marshal(req.EventTracks)
+		if err != nil {
+			utils.BadRequest(ctx, "赛道格式错误")
+			return
+		}
+		eventTracksJSON = string(eventTracksBytes)
+	}
+
 	// 处理LogoURL，如果为nil则使用空字符串
 	logoURL := ""
 	if req.LogoURL != nil {
@@ -99,6 +115,7 @@ func (c *SponsorController) CreateApplication(ctx *gin.Context) {
 		LogoURL:       logoURL,
 		SponsorType:   req.SponsorType,
 		EventIDs:      eventIDsJSON,
+		EventTracks:   eventTracksJSON,
 		AmountSol:     req.AmountSol,
 		WalletAddress: strings.TrimSpace(req.WalletAddress),
 		Status:        "pending",
@@ -115,6 +132,31 @@ func (c *SponsorController) CreateApplication(ctx *gin.Context) {
 	})
 }
 
+// UploadLogo 上传赞助商 Logo（无需登录，需携带 apply/prepare 返回的 logo_upload_token），返回的 logo_url 用于提交申请
+func (c *SponsorController) UploadLogo(ctx *gin.Context) {
+	token := ctx.GetHeader("X-Upload-Token")
+	if token == "" {
+		token = ctx.PostForm("upload_token")
+	}
+	if token == "" {
+		utils.Unauthorized(ctx, "缺少上传凭证")
+		return
+	}
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		utils.BadRequest(ctx, "请选择要上传的图片")
+		return
+	}
+
+	logoURL, err := c.sponsorService.UploadApplicationLogo(token, file)
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, gin.H{"logo_url": logoURL})
+}
+
 // SubmitSponsorApplyTransaction 接收前端已签名的 sponsor_apply 交易（base64），提交到链上并返回交易签名。
 func (c *SponsorController) SubmitSponsorApplyTransaction(ctx *gin.Context) {
 	var req struct {
@@ -318,7 +360,7 @@ func (c *SponsorController) ReviewApplication(ctx *gin.Context) {
 
 // GetLongTermSponsors 获取长期赞助商列表（Arena平台）
 func (c *SponsorController) GetLongTermSponsors(ctx *gin.Context) {
-	sponsors, err := c.sponsorService.GetLongTermSponsors()
+	sponsors, err := c.sponsorService.GetLongTermSponsors(ctx.Query("placement"))
 	if err != nil {
 		utils.InternalServerError(ctx, err.Error())
 		return
@@ -335,7 +377,9 @@ func (c *SponsorController) GetEventSponsors(ctx *gin.Context) {
 		return
 	}
 
-	sponsors, err := c.sponsorService.GetEventSponsors(hackathonID)
+	trackID, _ := strconv.ParseUint(ctx.DefaultQuery("track_id", "0"), 10, 64)
+
+	sponsors, err := c.sponsorService.GetEventSponsors(hackathonID, trackID, ctx.Query("placement"))
 	if err != nil {
 		utils.InternalServerError(ctx, err.Error())
 		return
@@ -357,3 +401,135 @@ func (c *SponsorController) GetPublishedHackathons(ctx *gin.Context) {
 
 	utils.SuccessWithPagination(ctx, hackathons, page, pageSize, total)
 }
+
+// GetTiers 获取赞助商等级（Admin权限）
+func (c *SponsorController) GetTiers(ctx *gin.Context) {
+	tiers, err := c.tierService.GetTiers()
+	if err != nil {
+		utils.InternalServerError(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, tiers)
+}
+
+// CreateTier 创建赞助商等级（Admin权限）
+func (c *SponsorController) CreateTier(ctx *gin.Context) {
+	var input services.SponsorTierInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	tier, err := c.tierService.CreateTier(input)
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, tier)
+}
+
+// UpdateTier 修改赞助商等级（Admin权限）
+func (c *SponsorController) UpdateTier(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的等级ID")
+		return
+	}
+	var input services.SponsorTierInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	tier, err := c.tierService.UpdateTier(id, input)
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, tier)
+}
+
+// DeleteTier 删除赞助商等级（Admin权限）
+func (c *SponsorController) DeleteTier(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的等级ID")
+		return
+	}
+
+	if err := c.tierService.DeleteTier(id); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
+
+// GetSponsors 获取赞助商列表（Admin权限）
+func (c *SponsorController) GetSponsors(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	sponsors, total, err := c.tierService.GetSponsors(page, pageSize, ctx.Query("sponsor_type"), ctx.Query("status"))
+	if err != nil {
+		utils.InternalServerError(ctx, err.Error())
+		return
+	}
+
+	utils.SuccessWithPagination(ctx, sponsors, page, pageSize, total)
+}
+
+// AssignTier 指定赞助商等级，tier_id 为空表示恢复按赞助金额自动匹配（Admin权限）
+func (c *SponsorController) AssignTier(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的赞助商ID")
+		return
+	}
+	var req struct {
+		TierID *uint64 `json:"tier_id"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	if err := c.tierService.AssignTier(id, req.TierID); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
+
+// SetSponsorTerm 设置长期赞助的到期时间，expires_at 为空表示不限期（Admin权限）
+func (c *SponsorController) SetSponsorTerm(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的赞助商ID")
+		return
+	}
+	var req struct {
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	if err := c.tierService.SetSponsorTerm(id, req.ExpiresAt); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
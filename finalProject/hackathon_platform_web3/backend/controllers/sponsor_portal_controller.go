@@ -0,0 +1,353 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"hackathon-backend/services"
+	"hackathon-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SponsorPortalController struct {
+	portalService *services.SponsorPortalService
+	bountyService *services.BountyService
+}
+
+func NewSponsorPortalController() *SponsorPortalController {
+	return &SponsorPortalController{
+		portalService: &services.SponsorPortalService{},
+		bountyService: &services.BountyService{},
+	}
+}
+
+// GetProfile 获取当前赞助商资料
+func (c *SponsorPortalController) GetProfile(ctx *gin.Context) {
+	userID, _ := ctx.Get("user_id")
+
+	sponsor, err := c.portalService.GetProfile(userID.(uint64))
+	if err != nil {
+		utils.NotFound(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, sponsor)
+}
+
+// UpdateProfile 修改当前赞助商资料
+func (c *SponsorPortalController) UpdateProfile(ctx *gin.Context) {
+	var input services.SponsorProfileInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(ctx, "参数错误")
+		return
+	}
+	userID, _ := ctx.Get("user_id")
+
+	sponsor, err := c.portalService.UpdateProfile(userID.(uint64), input)
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, sponsor)
+}
+
+// UpdateLogo 更换当前赞助商 Logo
+func (c *SponsorPortalController) UpdateLogo(ctx *gin.Context) {
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		utils.BadRequest(ctx, "请选择要上传的图片")
+		return
+	}
+	userID, _ := ctx.Get("user_id")
+
+	logoURL, err := c.portalService.UpdateLogo(userID.(uint64), file)
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, gin.H{"logo_url": logoURL})
+}
+
+// GetEvents 获取当前赞助商赞助的活动
+func (c *SponsorPortalController) GetEvents(ctx *gin.Context) {
+	userID, _ := ctx.Get("user_id")
+
+	events, err := c.portalService.GetSponsoredEvents(userID.(uint64))
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, events)
+}
+
+// GetEventMetrics 获取所赞助活动的实时数据
+func (c *SponsorPortalController) GetEventMetrics(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+	userID, _ := ctx.Get("user_id")
+
+	metrics, err := c.portalService.GetEventMetrics(userID.(uint64), id)
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, metrics)
+}
+
+// ExportParticipants 下载所赞助活动中同意提供信息的报名者名单（CSV）
+func (c *SponsorPortalController) ExportParticipants(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+	userID, _ := ctx.Get("user_id")
+
+	participants, err := c.portalService.GetConsentedParticipants(userID.(uint64), id)
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	var buf bytes.Buffer
+	// UTF-8 BOM，避免 Excel 打开中文昵称乱码
+	buf.WriteString("\xEF\xBB\xBF")
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write([]string{"participant_id", "nickname", "wallet_address", "email", "registered_at", "consented_at", "checked_in"}); err != nil {
+		utils.InternalServerError(ctx, err.Error())
+		return
+	}
+	for _, p := range participants {
+		consentedAt := ""
+		if p.ConsentedAt != nil {
+			consentedAt = p.ConsentedAt.Format(time.RFC3339)
+		}
+		// 昵称、邮箱等由参赛者填写，需防止 Excel 将其作为公式执行
+		if err := writer.Write([]string{
+			strconv.FormatUint(p.ParticipantID, 10),
+			csvSafeCell(p.Nickname),
+			csvSafeCell(p.WalletAddress),
+			csvSafeCell(p.Email),
+			p.RegisteredAt.Format(time.RFC3339),
+			consentedAt,
+			strconv.FormatBool(p.CheckedIn),
+		}); err != nil {
+			utils.InternalServerError(ctx, err.Error())
+			return
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		utils.InternalServerError(ctx, err.Error())
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=hackathon_%d_participants.csv", id))
+	ctx.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// csvSafeCell 以 =、+、-、@、制表符或回车开头的单元格加 ' 前缀，防止 CSV 公式注入
+func csvSafeCell(value string) string {
+	if value != "" && strings.ContainsAny(value[:1], "=+-@\t\r") {
+		return "'" + value
+	}
+	return value
+}
+
+// GetEventRequests 获取当前赞助商提交的追加赞助申请
+func (c *SponsorPortalController) GetEventRequests(ctx *gin.Context) {
+	userID, _ := ctx.Get("user_id")
+
+	requests, err := c.portalService.GetEventRequests(userID.(uint64))
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, requests)
+}
+
+// CreateEventRequest 申请赞助其他活动
+func (c *SponsorPortalController) CreateEventRequest(ctx *gin.Context) {
+	var input services.SponsorEventRequestInput
+	if err := ctx.ShouldBindJSON(&input); err != nil || input.HackathonID == 0 {
+		utils.BadRequest(ctx, "参数错误")
+		return
+	}
+	userID, _ := ctx.Get("user_id")
+
+	request, err := c.portalService.CreateEventRequest(userID.(uint64), input)
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, request)
+}
+
+// GetBounties 获取在所赞助活动中发布的赏金
+func (c *SponsorPortalController) GetBounties(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+	userID, _ := ctx.Get("user_id")
+
+	bounties, err := c.bountyService.GetSponsorBounties(userID.(uint64), id)
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, bounties)
+}
+
+// CreateBounty 在所赞助活动中发布赏金
+func (c *SponsorPortalController) CreateBounty(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的活动ID")
+		return
+	}
+	var input services.BountyInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(ctx, "参数错误")
+		return
+	}
+	userID, _ := ctx.Get("user_id")
+
+	bounty, err := c.bountyService.CreateBounty(userID.(uint64), id, input)
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, bounty)
+}
+
+// UpdateBounty 修改赏金
+func (c *SponsorPortalController) UpdateBounty(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的赏金ID")
+		return
+	}
+	var input services.BountyInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(ctx, "参数错误")
+		return
+	}
+	userID, _ := ctx.Get("user_id")
+
+	bounty, err := c.bountyService.UpdateBounty(userID.(uint64), id, input)
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, bounty)
+}
+
+// CancelBounty 取消赏金
+func (c *SponsorPortalController) CancelBounty(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的赏金ID")
+		return
+	}
+	userID, _ := ctx.Get("user_id")
+
+	if err := c.bountyService.CancelBounty(userID.(uint64), id); err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
+
+// GetBountyEntries 获取报名参加赏金的作品
+func (c *SponsorPortalController) GetBountyEntries(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的赏金ID")
+		return
+	}
+	userID, _ := ctx.Get("user_id")
+
+	entries, err := c.bountyService.GetBountyEntries(userID.(uint64), id)
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, entries)
+}
+
+// AwardBounty 评选赏金获奖作品
+func (c *SponsorPortalController) AwardBounty(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的赏金ID")
+		return
+	}
+	var req struct {
+		Winners []services.BountyWinnerInput `json:"winners" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, "参数错误")
+		return
+	}
+	userID, _ := ctx.Get("user_id")
+
+	winners, err := c.bountyService.AwardBounty(userID.(uint64), id, req.Winners)
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, winners)
+}
+
+// MarkPayoutPaid 登记赏金奖励已发放
+func (c *SponsorPortalController) MarkPayoutPaid(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的赏金ID")
+		return
+	}
+	winnerID, err := strconv.ParseUint(ctx.Param("winner_id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(ctx, "无效的获奖记录ID")
+		return
+	}
+	var req struct {
+		TxHash string `json:"tx_hash" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, "参数错误: 缺少 tx_hash")
+		return
+	}
+	userID, _ := ctx.Get("user_id")
+
+	winner, err := c.bountyService.MarkPayoutPaid(userID.(uint64), id, winnerID, req.TxHash)
+	if err != nil {
+		utils.BadRequest(ctx, err.Error())
+		return
+	}
+
+	utils.Success(ctx, winner)
+}
@@ -0,0 +1,29 @@
+package controllers
+
+import "testing"
+
+func TestCSVSafeCell(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "empty", value: "", want: ""},
+		{name: "plain text", value: "Alice", want: "Alice"},
+		{name: "formula", value: "=HYPERLINK(\"http://x\")", want: "'=HYPERLINK(\"http://x\")"},
+		{name: "plus", value: "+1+1", want: "'+1+1"},
+		{name: "minus", value: "-2", want: "'-2"},
+		{name: "at", value: "@SUM(A1)", want: "'@SUM(A1)"},
+		{name: "tab", value: "\t=1", want: "'\t=1"},
+		{name: "carriage return", value: "\r=1", want: "'\r=1"},
+		{name: "formula char not first", value: "a=b", want: "a=b"},
+		{name: "non-ascii", value: "团队", want: "团队"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := csvSafeCell(tt.value); got != tt.want {
+				t.Errorf("csvSafeCell(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"fmt"
 	"log"
+	"strings"
 
 	"hackathon-backend/config"
 	"hackathon-backend/models"
@@ -55,27 +56,189 @@ func InitDB() error {
 	return nil
 }
 
-// AutoMigrate 自动迁移数据库表
+// AutoMigrate 自动迁移数据库表，并执行 AutoMigrate 无法完成的结构变更
 func AutoMigrate() error {
-	return DB.AutoMigrate(
+	if err := prepareSchema(); err != nil {
+		return err
+	}
+	// 赞助商累计金额列首次添加时，迁移后按申请金额与已通过的追加赞助金额补齐（只执行一次）
+	sponsor := &models.Sponsor{}
+	backfillSponsorAmount := DB.Migrator().HasTable(sponsor) && !DB.Migrator().HasColumn(sponsor, "amount_sol")
+
+	if err := DB.AutoMigrate(
 		&models.User{},
 		&models.UserWallet{},
+		&models.PasswordSetupToken{},
 		&models.Participant{},
 		&models.Hackathon{},
 		&models.HackathonStage{},
 		&models.HackathonAward{},
 		&models.HackathonPrize{},
+		&models.HackathonTrack{},
 		&models.Registration{},
+		&models.RegistrationField{},
+		&models.RegistrationAnswer{},
+		&models.EligibilityRule{},
+		&models.AllowlistEntry{},
+		&models.RegistrationInviteCode{},
+		&models.WaitlistEntry{},
 		&models.Checkin{},
 		&models.Team{},
 		&models.TeamMember{},
 		&models.Submission{},
 		&models.SubmissionHistory{},
+		&models.SubmissionAttachment{},
+		&models.SubmissionContribution{},
+		&models.SubmissionExtension{},
+		&models.SubmissionSnapshot{},
+		&models.SubmissionTrack{},
+		&models.SimilarityScan{},
+		&models.SubmissionSimilarity{},
 		&models.Vote{},
 		&models.SponsorApplication{},
 		&models.Sponsor{},
 		&models.HackathonSponsorEvent{},
-	)
+		&models.SponsorTier{},
+		&models.SponsorEventRequest{},
+		&models.SponsorLogoUpload{},
+		&models.SponsorBounty{},
+		&models.BountyEntry{},
+		&models.BountyWinner{},
+		&models.HackathonJudge{},
+		&models.JudgeConflict{},
+		&models.RubricCriterion{},
+		&models.JudgeScore{},
+		&models.HackathonResult{},
+		&models.TeamInvitation{},
+		&models.TeamJoinRequest{},
+		&models.NotificationJob{},
+		&models.Notification{},
+		&models.NotificationPreference{},
+		&models.ScheduledReminder{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+	); err != nil {
+		return err
+	}
+	if err := migrateSchema(); err != nil {
+		return err
+	}
+	if backfillSponsorAmount {
+		if err := DB.Exec(`UPDATE sponsors SET amount_sol =
+			COALESCE((SELECT amount_sol FROM sponsor_applications WHERE sponsor_applications.id = sponsors.application_id), 0) +
+			COALESCE((SELECT SUM(amount_sol) FROM sponsor_event_requests WHERE sponsor_event_requests.sponsor_id = sponsors.id AND sponsor_event_requests.status = 'approved'), 0)`).Error; err != nil {
+			return fmt.Errorf("补齐赞助商累计金额失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// prepareSchema 在 AutoMigrate 之前清理会导致新唯一索引创建失败的历史数据，每一步都可重复执行
+func prepareSchema() error {
+	migrator := DB.Migrator()
+
+	// 作品修改记录新增 (submission_id, revision) 唯一索引：早期只记录修改前内容的记录版本号由 0 改为 NULL，
+	// 并发修改产生的重复版本号按记录顺序重新编号
+	history := &models.SubmissionHistory{}
+	if migrator.HasTable(history) && migrator.HasColumn(history, "revision") && !migrator.HasIndex(history, "uk_submission_revision") {
+		if err := DB.Exec("ALTER TABLE submission_histories MODIFY revision INT NULL").Error; err != nil {
+			return fmt.Errorf("修改作品版本号列失败: %w", err)
+		}
+		if err := DB.Exec("UPDATE submission_histories SET revision = NULL WHERE revision = 0").Error; err != nil {
+			return fmt.Errorf("清理早期作品修改记录失败: %w", err)
+		}
+		if err := DB.Exec(`UPDATE submission_histories h
+			JOIN (SELECT id, ROW_NUMBER() OVER (PARTITION BY submission_id ORDER BY id) AS rn
+				FROM submission_histories WHERE revision IS NOT NULL) r ON r.id = h.id
+			SET h.revision = r.rn`).Error; err != nil {
+			return fmt.Errorf("重新编号作品版本失败: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migrateSchema 执行 AutoMigrate 无法完成的结构变更（重建唯一索引等），每一步都可重复执行
+func migrateSchema() error {
+	migrator := DB.Migrator()
+
+	// 投票唯一约束加入赛道后改名为 uk_participant_submission_track，删除旧的（参赛者，作品）唯一索引
+	if migrator.HasIndex(&models.Vote{}, "uk_participant_submission") {
+		if err := migrator.DropIndex(&models.Vote{}, "uk_participant_submission"); err != nil {
+			return fmt.Errorf("删除旧投票唯一索引失败: %w", err)
+		}
+	}
+
+	// 队长唯一索引只约束未删除的队伍
+	if err := ensureTeamLeaderIndex(); err != nil {
+		return err
+	}
+
+	// 用户角色新增评委
+	if err := ensureEnumColumn("users", "role", []string{"admin", "organizer", "sponsor", "judge"}, "NOT NULL"); err != nil {
+		return err
+	}
+
+	// 赞助商状态新增已到期（长期赞助到期）
+	if err := ensureEnumColumn("sponsors", "status", []string{"active", "inactive", "expired"}, "DEFAULT 'active'"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ensureTeamLeaderIndex 确保队长唯一索引包含 active_leader 生成列（未删除为 1，已删除为 NULL）。
+// 唯一索引中含 NULL 的行互不冲突，已解散的队伍不再阻止队长在同一活动中重新建队或接任队长
+func ensureTeamLeaderIndex() error {
+	migrator := DB.Migrator()
+	team := &models.Team{}
+	if !migrator.HasColumn(team, "active_leader") {
+		if err := DB.Exec("ALTER TABLE teams ADD COLUMN active_leader TINYINT(1) GENERATED ALWAYS AS (IF(deleted_at IS NULL, 1, NULL)) VIRTUAL").Error; err != nil {
+			return fmt.Errorf("添加队伍 active_leader 列失败: %w", err)
+		}
+	}
+
+	var covered int64
+	if err := DB.Raw("SELECT COUNT(*) FROM information_schema.STATISTICS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'teams' AND INDEX_NAME = 'uk_hackathon_leader' AND COLUMN_NAME = 'active_leader'").
+		Scan(&covered).Error; err != nil {
+		return fmt.Errorf("查询队长唯一索引失败: %w", err)
+	}
+	if covered > 0 {
+		return nil
+	}
+	if migrator.HasIndex(team, "uk_hackathon_leader") {
+		if err := migrator.DropIndex(team, "uk_hackathon_leader"); err != nil {
+			return fmt.Errorf("删除旧队长唯一索引失败: %w", err)
+		}
+	}
+	if err := DB.Exec("CREATE UNIQUE INDEX uk_hackathon_leader ON teams (hackathon_id, leader_id, active_leader)").Error; err != nil {
+		return fmt.Errorf("创建队长唯一索引失败: %w", err)
+	}
+	log.Printf("Migrated index teams.uk_hackathon_leader to include active_leader")
+	return nil
+}
+
+// ensureEnumColumn 确保 enum 列包含全部取值。AutoMigrate 只比较列类型前缀 enum，不会为已有表扩充取值，需显式 ALTER
+func ensureEnumColumn(table, column string, values []string, options string) error {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = "'" + value + "'"
+	}
+	columnType := "enum(" + strings.Join(quoted, ",") + ")"
+
+	var current string
+	if err := DB.Raw("SELECT COLUMN_TYPE FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND COLUMN_NAME = ?", table, column).
+		Scan(&current).Error; err != nil {
+		return fmt.Errorf("查询 %s.%s 列类型失败: %w", table, column, err)
+	}
+	if current == "" || strings.EqualFold(current, columnType) {
+		return nil
+	}
+	if err := DB.Exec(fmt.Sprintf("ALTER TABLE `%s` MODIFY `%s` %s %s", table, column, columnType, options)).Error; err != nil {
+		return fmt.Errorf("扩充 %s.%s 取值失败: %w", table, column, err)
+	}
+	log.Printf("Migrated enum column %s.%s to %s", table, column, columnType)
+	return nil
 }
 
 // CloseDB 关闭数据库连接
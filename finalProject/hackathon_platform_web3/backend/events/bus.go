@@ -0,0 +1,110 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// 事件类型
+const (
+	TypeStageChanged = "stage_changed" // 活动阶段切换
+	TypeTeamUpdated  = "team_updated"  // 队伍成员变化（创建、加入、退出、移除、解散、转让队长）
+	TypeVoteTally    = "vote_tally"    // 实时计票
+)
+
+// Event 推送给订阅者的事件
+type Event struct {
+	Type        string      `json:"type"`
+	HackathonID uint64      `json:"hackathon_id"`
+	Data        interface{} `json:"data"`
+	Time        time.Time   `json:"time"`
+	StaffOnly   bool        `json:"-"` // 仅推送给后台订阅者（如投票期间未公开的实时计票）
+}
+
+// subscriptionBuffer 每个订阅者的事件缓冲，缓冲已满时丢弃新事件（客户端可重新拉取最新状态）
+const subscriptionBuffer = 64
+
+// Subscription 一个订阅者，从 C 读取事件，结束时调用 Close
+type Subscription struct {
+	HackathonID uint64
+	C           chan Event
+	bus         *Bus
+	once        sync.Once
+}
+
+// Close 取消订阅
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.bus.mu.Lock()
+		delete(s.bus.subs, s)
+		s.bus.mu.Unlock()
+	})
+}
+
+// Bus 进程内的发布订阅总线，按活动分发事件（多实例部署时每个实例只能推送本实例产生的事件）
+type Bus struct {
+	mu   sync.RWMutex
+	subs map[*Subscription]struct{}
+}
+
+// NewBus 创建事件总线
+func NewBus() *Bus {
+	return &Bus{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscribe 订阅某个活动的事件
+func (b *Bus) Subscribe(hackathonID uint64) *Subscription {
+	sub := &Subscription{HackathonID: hackathonID, C: make(chan Event, subscriptionBuffer), bus: b}
+	b.mu.Lock()
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+	return sub
+}
+
+// HasSubscribers 某个活动当前是否有订阅者（没有时发布方可跳过事件数据的计算）
+func (b *Bus) HasSubscribers(hackathonID uint64) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for sub := range b.subs {
+		if sub.HackathonID == hackathonID {
+			return true
+		}
+	}
+	return false
+}
+
+// Publish 发布事件，不会阻塞发布方
+func (b *Bus) Publish(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for sub := range b.subs {
+		if sub.HackathonID != event.HackathonID {
+			continue
+		}
+		select {
+		case sub.C <- event:
+		default:
+		}
+	}
+}
+
+// Default 服务内共用的事件总线
+var Default = NewBus()
+
+// Subscribe 在默认总线上订阅某个活动的事件
+func Subscribe(hackathonID uint64) *Subscription {
+	return Default.Subscribe(hackathonID)
+}
+
+// Publish 在默认总线上发布事件
+func Publish(event Event) {
+	Default.Publish(event)
+}
+
+// HasSubscribers 默认总线上某个活动当前是否有订阅者
+func HasSubscribers(hackathonID uint64) bool {
+	return Default.HasSubscribers(hackathonID)
+}
@@ -6,7 +6,10 @@ import (
 	"hackathon-backend/config"
 	"hackathon-backend/database"
 	"hackathon-backend/middleware"
+	"hackathon-backend/notify"
 	"hackathon-backend/routes"
+	"hackathon-backend/services"
+	"hackathon-backend/storage"
 
 	"github.com/gin-gonic/gin"
 )
@@ -23,11 +26,50 @@ func main() {
 	}
 	defer database.CloseDB()
 
+	// 初始化文件存储，并将历史 base64 Logo 迁移到文件存储
+	if err := storage.Init(); err != nil {
+		log.Fatal("Failed to initialize storage:", err)
+	}
+	if err := (&services.SponsorService{}).MigrateSponsorLogos(); err != nil {
+		log.Println("Failed to migrate sponsor logos:", err)
+	}
+	if err := (&services.SponsorTierService{}).InitSponsorTiers(); err != nil {
+		log.Println("Failed to initialize sponsor tiers:", err)
+	}
+	if err := (&services.ResultService{}).BackfillResults(); err != nil {
+		log.Println("Failed to backfill hackathon results:", err)
+	}
+
+	// 初始化通知发送并启动后台发送队列
+	if err := notify.Init(); err != nil {
+		log.Fatal("Failed to initialize notification:", err)
+	}
+	services.StartNotificationWorker()
+
+	// 启动 Webhook 后台投递
+	services.StartWebhookWorker()
+
+	// 启动活动阶段提醒
+	services.StartReminderWorker()
+
+	// 启动候补递补过期处理
+	services.StartWaitlistWorker()
+
+	// 启动提交截止时的作品仓库快照
+	services.StartSnapshotWorker()
+
+	// 启动长期赞助到期处理
+	services.StartSponsorExpiryWorker()
+
+	// 启动未引用赞助商 Logo 清理
+	services.StartLogoCleanupWorker()
+
 	// 设置Gin模式
 	gin.SetMode(config.AppConfig.ServerMode)
 
-	// 创建Gin引擎
-	router := gin.Default()
+	// 创建Gin引擎（访问日志隐藏 SSE 连接 token 查询参数中的 JWT）
+	router := gin.New()
+	router.Use(middleware.LoggerMiddleware(), gin.Recovery())
 
 	// 添加CORS中间件
 	router.Use(middleware.CORSMiddleware())
@@ -35,6 +77,7 @@ func main() {
 	// 设置路由
 	routes.SetupAdminRoutes(router)
 	routes.SetupArenaRoutes(router)
+	routes.SetupFileRoutes(router)
 
 	// 健康检查
 	router.GET("/health", func(c *gin.Context) {
@@ -110,3 +110,14 @@ func RoleMiddleware(allowedRoles ...string) gin.HandlerFunc {
 	}
 }
 
+// QueryTokenMiddleware 允许通过 token 查询参数传递 JWT（浏览器 EventSource 无法设置请求头），须放在认证中间件之前；访问日志由 LoggerMiddleware 隐藏该参数
+func QueryTokenMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if token := c.Query("token"); token != "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+		c.Next()
+	}
+}
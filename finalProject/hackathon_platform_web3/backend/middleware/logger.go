@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// redactedQueryParams 访问日志中隐藏取值的查询参数（SSE 连接以 token 查询参数传递 JWT）
+var redactedQueryParams = []string{"token"}
+
+// LoggerMiddleware 访问日志中间件，格式与 gin 默认日志一致，但隐藏 token 等敏感查询参数
+func LoggerMiddleware() gin.HandlerFunc {
+	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
+		var statusColor, methodColor, resetColor string
+		if param.IsOutputColor() {
+			statusColor = param.StatusCodeColor()
+			methodColor = param.MethodColor()
+			resetColor = param.ResetColor()
+		}
+		if param.Latency > time.Minute {
+			param.Latency = param.Latency.Truncate(time.Second)
+		}
+		return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+			param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+			statusColor, param.StatusCode, resetColor,
+			param.Latency,
+			param.ClientIP,
+			methodColor, param.Method, resetColor,
+			redactQuery(param.Path),
+			param.ErrorMessage,
+		)
+	})
+}
+
+// redactQuery 将请求路径中敏感查询参数的取值替换为 REDACTED，其余参数保持原样与顺序
+func redactQuery(path string) string {
+	idx := strings.IndexByte(path, '?')
+	if idx < 0 {
+		return path
+	}
+	pairs := strings.Split(path[idx+1:], "&")
+	for i, pair := range pairs {
+		key := pair
+		if eq := strings.IndexByte(pair, '='); eq >= 0 {
+			key = pair[:eq]
+		}
+		for _, name := range redactedQueryParams {
+			if strings.EqualFold(key, name) {
+				pairs[i] = key + "=REDACTED"
+				break
+			}
+		}
+	}
+	return path[:idx+1] + strings.Join(pairs, "&")
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SponsorBounty 赞助商在所赞助活动中发布的赏金挑战，独立于活动奖项评选与发放
+type SponsorBounty struct {
+	ID                 uint64         `gorm:"primaryKey;autoIncrement" json:"id"`
+	HackathonID        uint64         `gorm:"index;not null" json:"hackathon_id"`
+	SponsorID          uint64         `gorm:"index;not null" json:"sponsor_id"`
+	Title              string         `gorm:"type:varchar(200);not null" json:"title"`
+	Description        string         `gorm:"type:text" json:"description"`
+	AcceptanceCriteria string         `gorm:"type:text;not null" json:"acceptance_criteria"`                        // 验收标准
+	Reward             string         `gorm:"type:varchar(255);not null" json:"reward"`                             // 奖励说明，如 "500 USDC"
+	RewardAmount       float64        `gorm:"type:decimal(20,9);not null;default:0" json:"reward_amount"`           // 每个获奖作品的奖励金额
+	RewardToken        string         `gorm:"type:varchar(20);not null;default:'SOL'" json:"reward_token"`          // 奖励币种
+	MaxWinners         int            `gorm:"not null;default:1" json:"max_winners"`                                // 最多评选的获奖作品数
+	Status             string         `gorm:"type:enum('open','awarded','cancelled');default:'open'" json:"status"` // open-接受报名，awarded-已评选，cancelled-已取消
+	AwardedAt          *time.Time     `json:"awarded_at"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// 关联关系
+	Sponsor *Sponsor       `gorm:"foreignKey:SponsorID" json:"sponsor,omitempty"`
+	Winners []BountyWinner `gorm:"foreignKey:BountyID" json:"winners,omitempty"`
+
+	// EntryCount 参与的作品数，不落库
+	EntryCount int64 `gorm:"-" json:"entry_count"`
+}
+
+// TableName 指定表名
+func (SponsorBounty) TableName() string {
+	return "sponsor_bounties"
+}
+
+// BountyEntry 队伍将作品报名参加赏金挑战
+type BountyEntry struct {
+	ID           uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	BountyID     uint64    `gorm:"uniqueIndex:uk_bounty_submission;not null" json:"bounty_id"`
+	SubmissionID uint64    `gorm:"uniqueIndex:uk_bounty_submission;index;not null" json:"submission_id"`
+	TeamID       uint64    `gorm:"index;not null" json:"team_id"`
+	CreatedBy    uint64    `gorm:"not null" json:"created_by"` // 报名的参赛者
+	CreatedAt    time.Time `json:"created_at"`
+
+	// 关联关系
+	Submission *Submission `gorm:"foreignKey:SubmissionID" json:"submission,omitempty"`
+}
+
+// TableName 指定表名
+func (BountyEntry) TableName() string {
+	return "bounty_entries"
+}
+
+// BountyWinner 赏金挑战获奖作品及奖励发放记录
+type BountyWinner struct {
+	ID              uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	BountyID        uint64     `gorm:"uniqueIndex:uk_bounty_winner;not null" json:"bounty_id"`
+	SubmissionID    uint64     `gorm:"uniqueIndex:uk_bounty_winner;not null" json:"submission_id"`
+	TeamID          uint64     `gorm:"index;not null" json:"team_id"`
+	Rank            int        `gorm:"not null;default:1" json:"rank"`
+	RewardAmount    float64    `gorm:"type:decimal(20,9);not null;default:0" json:"reward_amount"`
+	RewardToken     string     `gorm:"type:varchar(20);not null" json:"reward_token"`
+	RecipientWallet string     `gorm:"type:varchar(255)" json:"recipient_wallet"` // 收款钱包，默认为评选时的队长钱包
+	PayoutStatus    string     `gorm:"type:enum('pending','paid');default:'pending';index" json:"payout_status"`
+	PayoutTxHash    string     `gorm:"type:varchar(128);index" json:"payout_tx_hash"` // 发放奖励的链上交易签名
+	PaidAt          *time.Time `json:"paid_at"`
+	Note            string     `gorm:"type:varchar(500)" json:"note"` // 评语
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+
+	// 关联关系
+	Submission *Submission `gorm:"foreignKey:SubmissionID" json:"submission,omitempty"`
+}
+
+// TableName 指定表名
+func (BountyWinner) TableName() string {
+	return "bounty_winners"
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+)
+
+// EligibilityRule 报名资格规则表（活动可配置多条，按活动的 EligibilityMatch 组合判断）
+type EligibilityRule struct {
+	ID               uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	HackathonID      uint64    `gorm:"index;not null" json:"hackathon_id"`
+	Type             string    `gorm:"type:enum('token_balance','nft_collection','allowlist','prior_attendance','invite_code');not null" json:"type"` // token_balance-持有SPL代币，nft_collection-持有NFT合集，allowlist-钱包白名单，prior_attendance-参加过往届活动，invite_code-邀请码
+	Address          string    `gorm:"type:varchar(64)" json:"address"`                                                                               // token_balance 为代币 mint 地址，nft_collection 为合集地址
+	MinAmount        float64   `gorm:"type:decimal(30,9);default:0" json:"min_amount"`                                                                // token_balance 要求的最低余额（按代币精度换算后的数量）
+	PriorHackathonID *uint64   `json:"prior_hackathon_id"`                                                                                            // prior_attendance 指定的往届活动，为空表示任意其他活动
+	Description      string    `gorm:"type:varchar(255)" json:"description"`                                                                          // 展示给参赛者的规则说明
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (EligibilityRule) TableName() string {
+	return "eligibility_rules"
+}
+
+// AllowlistEntry 报名钱包白名单表（CSV 导入）
+type AllowlistEntry struct {
+	ID            uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	HackathonID   uint64    `gorm:"uniqueIndex:uk_hackathon_wallet;not null" json:"hackathon_id"`
+	WalletAddress string    `gorm:"type:varchar(255);uniqueIndex:uk_hackathon_wallet;not null" json:"wallet_address"` // EVM 地址统一为小写
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (AllowlistEntry) TableName() string {
+	return "allowlist_entries"
+}
+
+// RegistrationInviteCode 报名邀请码表
+type RegistrationInviteCode struct {
+	ID          uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	HackathonID uint64     `gorm:"uniqueIndex:uk_hackathon_code;not null" json:"hackathon_id"`
+	Code        string     `gorm:"type:varchar(32);uniqueIndex:uk_hackathon_code;not null" json:"code"`
+	MaxUses     int        `gorm:"not null;default:1" json:"max_uses"` // 0 表示不限次数
+	UsedCount   int        `gorm:"not null;default:0" json:"used_count"`
+	ExpiresAt   *time.Time `json:"expires_at"` // 为空表示不过期
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// TableName 指定表名
+func (RegistrationInviteCode) TableName() string {
+	return "registration_invite_codes"
+}
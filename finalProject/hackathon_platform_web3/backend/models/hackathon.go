@@ -19,7 +19,19 @@ type Hackathon struct {
 	Status       string         `gorm:"type:enum('preparation','published','registration','checkin','team_formation','submission','voting','results');default:'preparation'" json:"status"`
 	OrganizerID  uint64         `gorm:"index;not null" json:"organizer_id"`
 	MaxTeamSize  int            `gorm:"default:3" json:"max_team_size"`
+	AutoGroupOnClose bool       `gorm:"default:false" json:"auto_group_on_close"` // 组队阶段结束时自动将已签到但未组队的参赛者分组
 	MaxParticipants int         `gorm:"default:0" json:"max_participants"` // 最大参与人数，0表示不限制
+	RequireApproval bool        `gorm:"default:false" json:"require_approval"` // 申请制：报名需主办方审核通过后才能签到
+	EligibilityMatch string     `gorm:"type:enum('all','any');default:'all'" json:"eligibility_match"` // 报名资格规则的组合方式：all-须满足全部规则，any-满足任一规则即可
+	JudgeWeight  float64        `gorm:"type:decimal(5,4);default:0" json:"judge_weight"` // 评委评分在最终结果中的权重（0-1），0 表示仅按社区投票
+	VotingScheme string         `gorm:"type:enum('simple','limited','quadratic','ranked_irv','ranked_borda');default:'simple'" json:"voting_scheme"` // 投票方式：simple-每个作品一票，limited-限制总票数，quadratic-二次方投票，ranked_irv/ranked_borda-排序投票（即时决选/波达计数）
+	VoteLimit    int            `gorm:"default:0" json:"vote_limit"`    // limited：每人最多投票数；排序投票：选票最多排序作品数，0表示不限制
+	CreditBudget int            `gorm:"default:0" json:"credit_budget"` // quadratic：每人投票积分预算，对一个作品投 n 票消耗 n² 积分
+	VoteWeighters string        `gorm:"type:varchar(255)" json:"vote_weighters"` // 启用的防女巫投票加权规则（逗号分隔）：wallet_type、attendance、wallet_age，为空表示不加权
+	TieBreakRules string        `gorm:"type:varchar(255)" json:"tie_break_rules"` // 同分决胜规则（逗号分隔，按顺序比较）：judge_score、earliest_submission、organizer_decision，为空时为 judge_score,earliest_submission
+	LateGraceMinutes int         `gorm:"default:0" json:"late_grace_minutes"` // 提交截止后的宽限时间（分钟），宽限期内仍可提交或修改作品，但会标记为迟交，0表示不允许迟交
+	LatePenalty  float64        `gorm:"type:decimal(5,4);default:0" json:"late_penalty"` // 迟交作品综合得分的扣减比例（0-1），0 表示不扣分
+	LiveTally    string         `gorm:"type:enum('hidden','public');default:'hidden'" json:"live_tally"` // 投票期间的实时计票：hidden-仅主办方可见，public-向参赛者实时推送
 	ChainActivityAddress string `gorm:"type:varchar(64);index" json:"chain_activity_address"` // Solana 活动账户 PDA，上链后可查
 	// ChainCheckInsAddress 签到信息上链地址（check_ins PDA），由后端根据 program_id + chain_activity_address 推导，不落库
 	ChainCheckInsAddress string `gorm:"-" json:"chain_check_ins_address,omitempty"`
@@ -33,6 +45,7 @@ type Hackathon struct {
 	Organizer    User            `gorm:"foreignKey:OrganizerID" json:"organizer,omitempty"`
 	Stages       []HackathonStage `gorm:"foreignKey:HackathonID" json:"stages,omitempty"`
 	Awards       []HackathonAward `gorm:"foreignKey:HackathonID" json:"awards,omitempty"`
+	Tracks       []HackathonTrack `gorm:"foreignKey:HackathonID" json:"tracks,omitempty"`
 }
 
 // TableName 指定表名
@@ -60,6 +73,7 @@ func (HackathonStage) TableName() string {
 type HackathonAward struct {
 	ID          uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
 	HackathonID uint64    `gorm:"index;not null" json:"hackathon_id"`
+	TrackID     *uint64   `gorm:"index" json:"track_id"` // 所属赛道，为空表示全场奖项
 	Name        string    `gorm:"type:varchar(100);not null" json:"name"`
 	Prize       string    `gorm:"type:varchar(255);not null" json:"prize"` // 奖金金额
 	Quantity    int       `gorm:"default:1" json:"quantity"`
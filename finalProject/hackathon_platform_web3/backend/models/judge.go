@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+)
+
+// HackathonJudge 活动评委表（评委为 role=judge 的用户，按活动指派）
+type HackathonJudge struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	HackathonID uint64    `gorm:"uniqueIndex:uk_hackathon_judge;not null" json:"hackathon_id"`
+	UserID      uint64    `gorm:"uniqueIndex:uk_hackathon_judge;index;not null" json:"user_id"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	// 关联关系
+	User User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// TableName 指定表名
+func (HackathonJudge) TableName() string {
+	return "hackathon_judges"
+}
+
+// JudgeConflict 评委利益冲突回避表（评委不能为回避队伍的作品打分）
+type JudgeConflict struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	HackathonID uint64    `gorm:"uniqueIndex:uk_judge_conflict;not null" json:"hackathon_id"`
+	JudgeID     uint64    `gorm:"uniqueIndex:uk_judge_conflict;not null" json:"judge_id"` // 评委用户ID
+	TeamID      uint64    `gorm:"uniqueIndex:uk_judge_conflict;not null" json:"team_id"`
+	Reason      string    `gorm:"type:varchar(255)" json:"reason"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	// 关联关系
+	Team Team `gorm:"foreignKey:TeamID" json:"team,omitempty"`
+}
+
+// TableName 指定表名
+func (JudgeConflict) TableName() string {
+	return "judge_conflicts"
+}
+
+// RubricCriterion 评分标准表（如创新性、技术深度、设计），按权重加权
+type RubricCriterion struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	HackathonID uint64    `gorm:"index;not null" json:"hackathon_id"`
+	Name        string    `gorm:"type:varchar(100);not null" json:"name"`
+	Description string    `gorm:"type:text" json:"description"`
+	Weight      float64   `gorm:"type:decimal(6,2);not null;default:1" json:"weight"`
+	MaxScore    int       `gorm:"not null;default:10" json:"max_score"`
+	Order       int       `gorm:"default:0" json:"order"` // 排序
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (RubricCriterion) TableName() string {
+	return "rubric_criteria"
+}
+
+// JudgeScore 评委打分表（每个评委对每个作品的每项标准一条记录）
+type JudgeScore struct {
+	ID           uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	HackathonID  uint64    `gorm:"index;not null" json:"hackathon_id"`
+	SubmissionID uint64    `gorm:"uniqueIndex:uk_judge_submission_criterion;not null" json:"submission_id"`
+	JudgeID      uint64    `gorm:"uniqueIndex:uk_judge_submission_criterion;not null" json:"judge_id"` // 评委用户ID
+	CriterionID  uint64    `gorm:"uniqueIndex:uk_judge_submission_criterion;not null" json:"criterion_id"`
+	Score        float64   `gorm:"type:decimal(6,2);not null" json:"score"`
+	Comment      string    `gorm:"type:text" json:"comment"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	// 关联关系
+	Criterion RubricCriterion `gorm:"foreignKey:CriterionID" json:"criterion,omitempty"`
+}
+
+// TableName 指定表名
+func (JudgeScore) TableName() string {
+	return "judge_scores"
+}
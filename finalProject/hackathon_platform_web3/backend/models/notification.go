@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+)
+
+// NotificationJob 通知发送队列表，失败后按指数退避重试
+type NotificationJob struct {
+	ID            uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	Channel       string     `gorm:"type:enum('email','sms');not null" json:"channel"`
+	Recipient     string     `gorm:"type:varchar(255);not null" json:"recipient"`                                                // 邮箱地址或手机号
+	Template      string     `gorm:"type:varchar(50);index;not null" json:"template"`                                            // 通知模板，如 sponsor_approved、stage_changed
+	Subject       string     `gorm:"type:varchar(255)" json:"subject"`                                                           // 邮件标题
+	Body          string     `gorm:"type:text" json:"body"`                                                                      // 含设置密码链接等敏感内容的通知在发送结束后清空
+	Sensitive     bool       `gorm:"default:false" json:"sensitive"`                                                             // 是否含敏感内容
+	Status        string     `gorm:"type:enum('pending','sent','failed');default:'pending';index:idx_status_next" json:"status"` // pending-待发送（含等待重试），sent-已发送，failed-重试次数用尽
+	Attempts      int        `gorm:"default:0" json:"attempts"`
+	NextAttemptAt time.Time  `gorm:"index:idx_status_next" json:"next_attempt_at"` // 下次尝试时间，发送中的任务会被临时推后，避免被重复领取
+	LastError     string     `gorm:"type:varchar(1000)" json:"last_error"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (NotificationJob) TableName() string {
+	return "notification_jobs"
+}
+
+// Notification 站内通知表（参赛者与后台用户共用，按接收方类型区分）
+type Notification struct {
+	ID            uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	RecipientType string     `gorm:"type:enum('participant','user');index:idx_recipient;not null" json:"recipient_type"` // participant-参赛者，user-后台用户（主办方、赞助商等）
+	RecipientID   uint64     `gorm:"index:idx_recipient;not null" json:"recipient_id"`
+	Type          string     `gorm:"type:varchar(50);not null" json:"type"` // 事件类型，如 stage_changed、team_member_removed
+	Title         string     `gorm:"type:varchar(255);not null" json:"title"`
+	Content       string     `gorm:"type:text" json:"content"`
+	HackathonID   *uint64    `gorm:"index" json:"hackathon_id"` // 关联的活动，便于前端跳转
+	ReadAt        *time.Time `json:"read_at"`                   // 为空表示未读
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// TableName 指定表名
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// NotificationPreference 通知偏好表（事件类型 × 渠道），无记录时默认开启
+type NotificationPreference struct {
+	ID            uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	RecipientType string    `gorm:"type:enum('participant','user');uniqueIndex:uk_recipient_event_channel;not null" json:"recipient_type"`
+	RecipientID   uint64    `gorm:"uniqueIndex:uk_recipient_event_channel;not null" json:"recipient_id"`
+	EventType     string    `gorm:"type:varchar(50);uniqueIndex:uk_recipient_event_channel;not null" json:"event_type"`
+	Channel       string    `gorm:"type:enum('in_app','email');uniqueIndex:uk_recipient_event_channel;not null" json:"channel"` // in_app-站内通知，email-邮件
+	Enabled       bool      `gorm:"not null" json:"enabled"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
+
+// ScheduledReminder 活动阶段提醒任务，按阶段时间生成，阶段时间修改后重新计算，到期后由后台任务发送
+type ScheduledReminder struct {
+	ID          uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	HackathonID uint64     `gorm:"uniqueIndex:uk_hackathon_kind;not null" json:"hackathon_id"`
+	Kind        string     `gorm:"type:varchar(50);uniqueIndex:uk_hackathon_kind;not null" json:"kind"`                          // 提醒类型（同通知模板名），如 registration_closing、checkin_open、submission_closing
+	RunAt       time.Time  `gorm:"index:idx_status_run;not null" json:"run_at"`                                                  // 计划发送时间，发送中的提醒会被临时推后，避免被重复领取
+	Status      string     `gorm:"type:enum('pending','sent','cancelled');default:'pending';index:idx_status_run" json:"status"` // pending-待发送，sent-已发送，cancelled-已取消（阶段已删除或已错过）
+	Recipients  int        `gorm:"default:0" json:"recipients"`                                                                  // 发送时的接收人数
+	Note        string     `gorm:"type:varchar(255)" json:"note"`                                                                // 取消原因或发送失败原因
+	SentAt      *time.Time `json:"sent_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (ScheduledReminder) TableName() string {
+	return "scheduled_reminders"
+}
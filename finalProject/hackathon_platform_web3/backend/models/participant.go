@@ -12,6 +12,10 @@ type Participant struct {
 	WalletAddress string         `gorm:"type:varchar(255);uniqueIndex;not null" json:"wallet_address"`
 	WalletType    string         `gorm:"type:varchar(20);default:metamask" json:"wallet_type"` // 钱包类型：metamask | phantom
 	Nickname      string         `gorm:"type:varchar(50)" json:"nickname"`                      // 用户昵称
+	Skills        string         `gorm:"type:varchar(500)" json:"skills"`                       // 技能标签（逗号分隔），如 rust,react,solidity
+	SeekingRoles  string         `gorm:"type:varchar(255)" json:"seeking_roles"`                // 希望在队伍中担任的角色（逗号分隔），如 frontend,designer
+	TimeZone      string         `gorm:"type:varchar(64)" json:"time_zone"`                     // 时区，IANA 名称（Asia/Shanghai）或 UTC 偏移（UTC+8）
+	Email         string         `gorm:"type:varchar(255)" json:"-"`                            // 接收报名、组队、结果等通知的邮箱，可为空；仅在个人中心返回
 	Nonce         string         `gorm:"type:varchar(255)" json:"-"`
 	LastLoginAt   *time.Time     `json:"last_login_at"`
 	CreatedAt     time.Time      `json:"created_at"`
@@ -6,14 +6,23 @@ import (
 
 // Registration 报名记录表
 type Registration struct {
-	ID            uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
-	HackathonID   uint64    `gorm:"uniqueIndex:uk_hackathon_participant;not null" json:"hackathon_id"`
-	ParticipantID uint64    `gorm:"uniqueIndex:uk_hackathon_participant;not null" json:"participant_id"`
-	CreatedAt     time.Time `json:"created_at"`
+	ID               uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	HackathonID      uint64     `gorm:"uniqueIndex:uk_hackathon_participant;not null" json:"hackathon_id"`
+	ParticipantID    uint64     `gorm:"uniqueIndex:uk_hackathon_participant;not null" json:"participant_id"`
+	Status           string     `gorm:"type:enum('pending','approved','rejected');default:'approved';not null" json:"status"` // 申请制活动报名后为 pending，审核通过后才能签到
+	ReviewNote       string     `gorm:"type:varchar(500)" json:"review_note"`
+	ReviewedBy       *uint64    `json:"reviewed_by"`
+	ReviewedAt       *time.Time `json:"reviewed_at"`
+	SponsorConsent   bool       `gorm:"not null;default:false" json:"sponsor_consent"` // 参赛者是否同意向活动赞助商提供报名信息
+	SponsorConsentAt *time.Time `json:"sponsor_consent_at"`
+	SybilWeight      *float64   `gorm:"type:decimal(5,4)" json:"-"` // 投票防女巫加权系数，首次投票时计算并缓存，未投票时为 NULL
+	InviteCodeID     *uint64    `json:"-"`                          // 报名时核销的邀请码，取消报名或审核未通过时归还
+	CreatedAt        time.Time  `json:"created_at"`
 
 	// 关联关系
-	Hackathon  Hackathon  `gorm:"foreignKey:HackathonID" json:"hackathon,omitempty"`
-	Participant Participant `gorm:"foreignKey:ParticipantID" json:"participant,omitempty"`
+	Hackathon   Hackathon            `gorm:"foreignKey:HackathonID" json:"hackathon,omitempty"`
+	Participant Participant          `gorm:"foreignKey:ParticipantID" json:"participant,omitempty"`
+	Answers     []RegistrationAnswer `gorm:"foreignKey:RegistrationID" json:"answers,omitempty"`
 }
 
 // TableName 指定表名
@@ -21,6 +30,70 @@ func (Registration) TableName() string {
 	return "registrations"
 }
 
+// RegistrationField 活动报名表字段（主办方自定义）
+type RegistrationField struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	HackathonID uint64    `gorm:"index;not null" json:"hackathon_id"`
+	Label       string    `gorm:"type:varchar(100);not null" json:"label"`
+	Type        string    `gorm:"type:enum('text','select','url','checkbox','file');not null" json:"type"`
+	Options     string    `gorm:"type:text" json:"-"` // JSON数组字符串，select 字段的可选项
+	OptionList  []string  `gorm:"-" json:"options"`   // 由 Options 解析，不落库
+	Required    bool      `gorm:"default:false" json:"required"`
+	Order       int       `gorm:"default:0" json:"order"` // 排序
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (RegistrationField) TableName() string {
+	return "registration_fields"
+}
+
+// RegistrationAnswer 报名表答案（候补期间 RegistrationID 为空，递补确认后关联到报名记录）
+type RegistrationAnswer struct {
+	ID             uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	HackathonID    uint64    `gorm:"index;not null" json:"hackathon_id"`
+	ParticipantID  uint64    `gorm:"uniqueIndex:uk_participant_field;not null" json:"participant_id"`
+	FieldID        uint64    `gorm:"uniqueIndex:uk_participant_field;not null" json:"field_id"`
+	RegistrationID *uint64   `gorm:"index" json:"registration_id"`
+	Value          string    `gorm:"type:text" json:"value"`             // checkbox 为 true/false，file 为文件存储 key
+	FileName       string    `gorm:"type:varchar(255)" json:"file_name"` // file 字段的原始文件名
+	URL            string    `gorm:"-" json:"url,omitempty"`             // file 字段的签名下载链接，不落库
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	// 关联关系
+	Field RegistrationField `gorm:"foreignKey:FieldID" json:"field,omitempty"`
+}
+
+// TableName 指定表名
+func (RegistrationAnswer) TableName() string {
+	return "registration_answers"
+}
+
+// WaitlistEntry 报名候补表（活动报名人数已满时按先后排队，有名额空出时依次递补）
+type WaitlistEntry struct {
+	ID             uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	HackathonID    uint64     `gorm:"uniqueIndex:uk_hackathon_participant;not null" json:"hackathon_id"`
+	ParticipantID  uint64     `gorm:"uniqueIndex:uk_hackathon_participant;not null" json:"participant_id"`
+	Position       int        `gorm:"index;not null" json:"position"` // 排队顺序（越小越靠前），主办方可调整
+	Status         string     `gorm:"type:enum('waiting','offered','expired');default:'waiting';not null" json:"status"` // waiting-候补中，offered-已递补待确认（占用名额），expired-未在确认期限内确认
+	OfferedAt      *time.Time `json:"offered_at"`
+	OfferExpiresAt *time.Time `json:"offer_expires_at"` // 递补确认截止时间
+	InviteCodeID   *uint64    `json:"-"`                // 加入候补时核销的邀请码，递补过期或退出候补时归还
+	QueuePosition  int        `gorm:"-" json:"queue_position,omitempty"` // 候补中时当前排第几位（不存储）
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	// 关联关系
+	Participant Participant `gorm:"foreignKey:ParticipantID" json:"participant,omitempty"`
+}
+
+// TableName 指定表名
+func (WaitlistEntry) TableName() string {
+	return "waitlist_entries"
+}
+
 // Checkin 签到记录表
 type Checkin struct {
 	ID            uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// HackathonResult 比赛结果表（由结果引擎计算并落库，所有结果展示均读取此表）
+type HackathonResult struct {
+	ID             uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	HackathonID    uint64    `gorm:"uniqueIndex:uk_hackathon_track_submission;not null" json:"hackathon_id"`
+	TrackID        uint64    `gorm:"uniqueIndex:uk_hackathon_track_submission;not null;default:0" json:"track_id"` // 0 表示全场排名
+	SubmissionID   uint64    `gorm:"uniqueIndex:uk_hackathon_track_submission;not null" json:"submission_id"`
+	TeamID         uint64    `gorm:"index;not null" json:"team_id"`
+	Rank           int       `gorm:"not null" json:"rank"`
+	VoteCount      int64     `gorm:"not null;default:0" json:"vote_count"`
+	JudgeScore     float64   `gorm:"type:decimal(8,4);not null;default:0" json:"judge_score"`
+	Score          float64   `gorm:"type:decimal(8,4);not null;default:0" json:"score"`
+	LatePenalized  bool      `gorm:"not null;default:false" json:"late_penalized"` // 综合得分是否已按迟交规则扣减
+	TieBreak       string    `gorm:"type:varchar(50)" json:"tie_break"`            // 与上一名同分时决定先后的规则，非同分为空
+	TiePending     bool      `gorm:"not null;default:false" json:"tie_pending"`    // 同分且需主办方裁定
+	AwardID        *uint64   `gorm:"index" json:"award_id"`
+	Overridden     bool      `gorm:"not null;default:false" json:"overridden"` // 获奖是否经主办方调整
+	OverrideReason string    `gorm:"type:text" json:"override_reason"`
+	OverriddenBy   *uint64   `json:"overridden_by"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	// 关联关系
+	Submission Submission      `gorm:"foreignKey:SubmissionID" json:"submission,omitempty"`
+	Team       Team            `gorm:"foreignKey:TeamID" json:"team,omitempty"`
+	Award      *HackathonAward `gorm:"foreignKey:AwardID" json:"award,omitempty"`
+}
+
+// TableName 指定表名
+func (HackathonResult) TableName() string {
+	return "hackathon_results"
+}
@@ -10,9 +10,10 @@ import (
 type SponsorApplication struct {
 	ID          uint64         `gorm:"primaryKey;autoIncrement" json:"id"`
 	Phone       string         `gorm:"type:varchar(20);uniqueIndex;not null" json:"phone"`
-	LogoURL     string         `gorm:"type:longtext;not null" json:"logo_url"` // 存储base64编码的图片
+	LogoURL     string         `gorm:"type:longtext;not null" json:"logo_url"` // Logo 地址（文件存储中的公开地址），历史 base64 数据在启动时迁移到文件存储
 	SponsorType string         `gorm:"type:enum('long_term','event_specific');not null" json:"sponsor_type"`
 	EventIDs    string         `gorm:"type:text" json:"event_ids"` // JSON数组字符串，存储活动ID列表
+	EventTracks string         `gorm:"type:text" json:"event_tracks"` // JSON对象字符串，活动ID -> 赞助的赛道ID（可选）
 	AmountSol     float64        `gorm:"type:decimal(20,9);not null;default:0" json:"amount_sol"`       // 赞助金额（SOL），提交时转入金库
 	WalletAddress string         `gorm:"type:varchar(64);index" json:"wallet_address"`               // 赞助商链上钱包地址（申请时签名 sponsor_apply 的地址），审核链上指令需要
 	Status        string         `gorm:"type:enum('pending','approved','rejected');default:'pending'" json:"status"`
@@ -34,11 +35,17 @@ func (SponsorApplication) TableName() string {
 // Sponsor 赞助商表（审核通过后自动创建）
 type Sponsor struct {
 	ID            uint64         `gorm:"primaryKey;autoIncrement" json:"id"`
-	UserID        uint64         `gorm:"uniqueIndex;not null" json:"user_id"` // 关联到User表
-	LogoURL       string         `gorm:"type:longtext;not null" json:"logo_url"` // 存储base64编码的图片
+	UserID        uint64         `gorm:"uniqueIndex;not null" json:"user_id"`    // 关联到User表
+	LogoURL       string         `gorm:"type:longtext;not null" json:"logo_url"` // Logo 地址（文件存储中的公开地址），历史 base64 数据在启动时迁移到文件存储
 	SponsorType   string         `gorm:"type:enum('long_term','event_specific');not null" json:"sponsor_type"`
-	Status        string         `gorm:"type:enum('active','inactive');default:'active'" json:"status"`
-	ApplicationID uint64         `gorm:"index;not null" json:"application_id"` // 关联申请记录
+	Status        string         `gorm:"type:enum('active','inactive','expired');default:'active'" json:"status"` // expired-长期赞助已到期（仍展示在其赞助的活动中）
+	ApplicationID uint64         `gorm:"index;not null" json:"application_id"`                                    // 关联申请记录
+	Website       string         `gorm:"type:varchar(255)" json:"website"`                                        // 官网地址，赞助商在门户中维护
+	Description   string         `gorm:"type:text" json:"description"`                                            // 赞助商简介，赞助商在门户中维护
+	AmountSol     float64        `gorm:"type:decimal(20,9);not null;default:0" json:"amount_sol"`                 // 累计赞助金额（SOL）：申请金额 + 已通过的追加赞助金额
+	TierID        *uint64        `gorm:"index" json:"tier_id"`                                                    // 赞助商等级，为空表示未达到任何等级
+	TierManual    bool           `gorm:"not null;default:false" json:"tier_manual"`                               // 等级由 Admin 指定，不随赞助金额自动调整
+	ExpiresAt     *time.Time     `gorm:"index" json:"expires_at"`                                                 // 长期赞助到期时间，为空表示不限期
 	CreatedAt     time.Time      `json:"created_at"`
 	UpdatedAt     time.Time      `json:"updated_at"`
 	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
@@ -47,6 +54,11 @@ type Sponsor struct {
 	User        User                    `gorm:"foreignKey:UserID" json:"user,omitempty"`
 	Application SponsorApplication      `gorm:"foreignKey:ApplicationID" json:"application,omitempty"`
 	Events      []HackathonSponsorEvent `gorm:"foreignKey:SponsorID" json:"events,omitempty"`
+	Tier        *SponsorTier            `gorm:"foreignKey:TierID" json:"tier,omitempty"`
+
+	// LogoSize、Placements 按等级生成的 Logo 尺寸与展示位置，不落库
+	LogoSize   string   `gorm:"-" json:"logo_size,omitempty"`
+	Placements []string `gorm:"-" json:"placements,omitempty"`
 }
 
 // TableName 指定表名
@@ -59,12 +71,14 @@ type HackathonSponsorEvent struct {
 	ID          uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
 	HackathonID uint64    `gorm:"uniqueIndex:uk_hackathon_sponsor;not null" json:"hackathon_id"`
 	SponsorID   uint64    `gorm:"uniqueIndex:uk_hackathon_sponsor;not null" json:"sponsor_id"`
+	TrackID     *uint64   `gorm:"index" json:"track_id"` // 赞助的赛道，为空表示赞助整个活动
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 
 	// 关联关系
 	Hackathon Hackathon `gorm:"foreignKey:HackathonID" json:"hackathon,omitempty"`
 	Sponsor   Sponsor   `gorm:"foreignKey:SponsorID" json:"sponsor,omitempty"`
+	Track     *HackathonTrack `gorm:"foreignKey:TrackID" json:"track,omitempty"`
 }
 
 // TableName 指定表名
@@ -72,3 +86,59 @@ func (HackathonSponsorEvent) TableName() string {
 	return "hackathon_sponsor_events"
 }
 
+// SponsorEventRequest 已开通账号的赞助商追加赞助活动的申请，由活动主办方审核，通过后建立活动赞助关联
+type SponsorEventRequest struct {
+	ID           uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	SponsorID    uint64     `gorm:"index;not null" json:"sponsor_id"`
+	HackathonID  uint64     `gorm:"index;not null" json:"hackathon_id"`
+	TrackID      *uint64    `json:"track_id"`                                                // 赞助的赛道，为空表示赞助整个活动
+	AmountSol    float64    `gorm:"type:decimal(20,9);not null;default:0" json:"amount_sol"` // 承诺赞助金额（SOL），由双方线下结算
+	Message      string     `gorm:"type:varchar(1000)" json:"message"`                       // 给主办方的留言
+	Status       string     `gorm:"type:enum('pending','approved','rejected');default:'pending'" json:"status"`
+	ReviewerID   *uint64    `json:"reviewer_id"`
+	ReviewedAt   *time.Time `json:"reviewed_at"`
+	RejectReason string     `gorm:"type:varchar(500)" json:"reject_reason"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+
+	// 关联关系
+	Sponsor   *Sponsor        `gorm:"foreignKey:SponsorID" json:"sponsor,omitempty"`
+	Hackathon *Hackathon      `gorm:"foreignKey:HackathonID" json:"hackathon,omitempty"`
+	Track     *HackathonTrack `gorm:"foreignKey:TrackID" json:"track,omitempty"`
+}
+
+// TableName 指定表名
+func (SponsorEventRequest) TableName() string {
+	return "sponsor_event_requests"
+}
+
+// SponsorTier 赞助商等级（如冠名、金牌、银牌），决定 Logo 在活动页与海报上的排序、尺寸与展示位置
+type SponsorTier struct {
+	ID           uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	Code         string    `gorm:"type:varchar(20);uniqueIndex;not null" json:"code"` // 等级标识，如 title、gold、silver
+	Name         string    `gorm:"type:varchar(50);not null" json:"name"`
+	MinAmountSol float64   `gorm:"type:decimal(20,9);not null;default:0" json:"min_amount_sol"`  // 累计赞助金额达到该值自动获得此等级，为 0 表示仅由 Admin 指定
+	Priority     int       `gorm:"not null;default:0" json:"priority"`                           // 展示顺序，越小越靠前
+	LogoSize     string    `gorm:"type:enum('xl','lg','md','sm');default:'md'" json:"logo_size"` // Logo 尺寸提示
+	Placements   string    `gorm:"type:varchar(255);not null" json:"placements"`                 // 展示位置，逗号分隔：hero、hackathon_page、poster、footer
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (SponsorTier) TableName() string {
+	return "sponsor_tiers"
+}
+
+// SponsorLogoUpload 申请页匿名上传、尚未被申请引用的 Logo，超时仍未引用的由后台清理
+type SponsorLogoUpload struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	FileKey   string    `gorm:"type:varchar(255);uniqueIndex;not null" json:"file_key"`
+	TokenID   string    `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"` // 上传凭证标识，每个凭证只能上传一次
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// TableName 指定表名
+func (SponsorLogoUpload) TableName() string {
+	return "sponsor_logo_uploads"
+}
@@ -12,13 +12,27 @@ type Submission struct {
 	Name        string    `gorm:"type:varchar(100);not null" json:"name"`
 	Description string    `gorm:"type:text;not null" json:"description"`
 	Link        string    `gorm:"type:varchar(500);not null" json:"link"`
+	TechStack   string    `gorm:"type:varchar(500)" json:"tech_stack"` // 技术栈标签，逗号分隔（小写），如 "solana,anchor,react"
+	DemoURL     string    `gorm:"type:varchar(500)" json:"demo_url"`
+	VideoURL    string    `gorm:"type:varchar(500)" json:"video_url"`
+	RepoURL     string    `gorm:"type:varchar(500)" json:"repo_url"` // 代码仓库地址，为空时仓库快照使用 Link
 	Draft       int       `gorm:"type:tinyint(1);default:0" json:"draft"` // 1-草稿，0-已提交
+	Late        bool       `gorm:"not null;default:false" json:"late"` // 是否在截止后的宽限期内提交或修改过（由系统标记）
+	LateAt      *time.Time `json:"late_at"`                           // 首次迟交时间
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 
+	// TrackIDs 作品参加的赛道ID列表，由 submission_tracks 关联表维护，不落库
+	TrackIDs []uint64 `gorm:"-" json:"track_ids,omitempty"`
+	// DescriptionHTML Description（Markdown）渲染并净化后的 HTML，读取时生成，不落库
+	DescriptionHTML string `gorm:"-" json:"description_html,omitempty"`
+
 	// 关联关系
-	Hackathon Hackathon `gorm:"foreignKey:HackathonID" json:"hackathon,omitempty"`
-	Team      Team      `gorm:"foreignKey:TeamID" json:"team,omitempty"`
+	Hackathon Hackathon         `gorm:"foreignKey:HackathonID" json:"hackathon,omitempty"`
+	Team      Team              `gorm:"foreignKey:TeamID" json:"team,omitempty"`
+	Tracks    []SubmissionTrack `gorm:"foreignKey:SubmissionID" json:"tracks,omitempty"`
+	Attachments []SubmissionAttachment `gorm:"foreignKey:SubmissionID" json:"attachments,omitempty"`
+	Contributions []SubmissionContribution `gorm:"foreignKey:SubmissionID" json:"contributions,omitempty"`
 }
 
 // TableName 指定表名
@@ -26,12 +40,138 @@ func (Submission) TableName() string {
 	return "submissions"
 }
 
+// SubmissionExtension 队伍提交截止时间延期表（主办方为遇到平台问题等情况的队伍单独延长截止时间）
+type SubmissionExtension struct {
+	ID            uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	HackathonID   uint64    `gorm:"uniqueIndex:uk_hackathon_team;not null" json:"hackathon_id"`
+	TeamID        uint64    `gorm:"uniqueIndex:uk_hackathon_team;not null" json:"team_id"`
+	ExtendedUntil time.Time `gorm:"not null" json:"extended_until"` // 该队伍的提交截止时间（延期内提交不算迟交）
+	Reason        string    `gorm:"type:varchar(500);not null" json:"reason"`
+	GrantedBy     uint64    `gorm:"not null" json:"granted_by"` // 批准延期的主办方
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	// 关联关系
+	Team Team `gorm:"foreignKey:TeamID" json:"team,omitempty"`
+}
+
+// TableName 指定表名
+func (SubmissionExtension) TableName() string {
+	return "submission_extensions"
+}
+
+// SubmissionContribution 作品成员贡献说明表（每位队员在作品中的分工）
+type SubmissionContribution struct {
+	ID            uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	SubmissionID  uint64    `gorm:"uniqueIndex:uk_submission_participant;not null" json:"submission_id"`
+	ParticipantID uint64    `gorm:"uniqueIndex:uk_submission_participant;not null" json:"participant_id"`
+	Note          string    `gorm:"type:varchar(500);not null" json:"note"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	// 关联关系
+	Participant Participant `gorm:"foreignKey:ParticipantID" json:"participant,omitempty"`
+}
+
+// TableName 指定表名
+func (SubmissionContribution) TableName() string {
+	return "submission_contributions"
+}
+
+// SubmissionAttachment 作品附件表（路演材料、截图、演示视频等），文件保存在文件存储中
+type SubmissionAttachment struct {
+	ID           uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	SubmissionID uint64    `gorm:"index;not null" json:"submission_id"`
+	UploaderID   uint64    `gorm:"not null" json:"uploader_id"` // 上传的参赛者
+	Kind         string    `gorm:"type:enum('pitch_deck','screenshot','demo_video','other');not null" json:"kind"`
+	FileName     string    `gorm:"type:varchar(255);not null" json:"file_name"` // 原始文件名
+	StorageKey   string    `gorm:"type:varchar(255);not null" json:"-"`
+	ThumbnailKey string    `gorm:"type:varchar(255)" json:"-"` // 图片缩略图
+	ContentType  string    `gorm:"type:varchar(100);not null" json:"content_type"`
+	Size         int64     `gorm:"not null" json:"size"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	// URL、ThumbnailURL 限时有效的签名下载链接，读取时生成，不落库
+	URL          string `gorm:"-" json:"url"`
+	ThumbnailURL string `gorm:"-" json:"thumbnail_url,omitempty"`
+}
+
+// TableName 指定表名
+func (SubmissionAttachment) TableName() string {
+	return "submission_attachments"
+}
+
+// SubmissionSnapshot 作品仓库快照表（提交阶段结束时锁定代码仓库的提交，防止截止后继续推送）
+type SubmissionSnapshot struct {
+	ID           uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	HackathonID  uint64    `gorm:"index;not null" json:"hackathon_id"`
+	SubmissionID uint64    `gorm:"uniqueIndex;not null" json:"submission_id"`
+	Link         string    `gorm:"type:varchar(500)" json:"link"`     // 快照时作品的链接
+	RepoURL      string    `gorm:"type:varchar(500)" json:"repo_url"` // 解析出的 git 仓库地址，非 git 链接为空
+	Ref          string    `gorm:"type:varchar(255)" json:"ref"`      // 链接指定的分支或标签，为空表示默认分支（HEAD）
+	CommitHash   string    `gorm:"type:varchar(64)" json:"commit_hash"`
+	Status       string    `gorm:"type:enum('pinned','not_git','failed');not null" json:"status"` // pinned-已锁定提交，not_git-非 git 仓库链接，failed-解析失败（可重试）
+	Error        string    `gorm:"type:varchar(500)" json:"error"`
+	LeafHash     string    `gorm:"type:varchar(64)" json:"leaf_hash"` // 默克尔树叶子哈希，sha256(作品ID:仓库地址:提交)
+	SnapshotAt   time.Time `json:"snapshot_at"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (SubmissionSnapshot) TableName() string {
+	return "submission_snapshots"
+}
+
+// SimilarityScan 作品相似度检测任务表（每个活动保留最近一次检测）
+type SimilarityScan struct {
+	ID          uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	HackathonID uint64     `gorm:"uniqueIndex;not null" json:"hackathon_id"`
+	Status      string     `gorm:"type:enum('running','done','failed');not null" json:"status"`
+	Error       string     `gorm:"type:varchar(500)" json:"error"`
+	Compared    int        `gorm:"not null;default:0" json:"compared"` // 参与比较的作品数（含往届活动）
+	Flagged     int        `gorm:"not null;default:0" json:"flagged"`  // 标记的相似作品对数
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+}
+
+// TableName 指定表名
+func (SimilarityScan) TableName() string {
+	return "similarity_scans"
+}
+
+// SubmissionSimilarity 相似作品对表（检测活动中的作品与本活动其他作品或往届活动作品相似）
+type SubmissionSimilarity struct {
+	ID                  uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	HackathonID         uint64    `gorm:"index;not null" json:"hackathon_id"` // 检测的活动
+	SubmissionID        uint64    `gorm:"not null" json:"submission_id"`
+	MatchedHackathonID  uint64    `gorm:"not null" json:"matched_hackathon_id"`
+	MatchedSubmissionID uint64    `gorm:"not null" json:"matched_submission_id"`
+	DescriptionScore    float64   `gorm:"type:decimal(5,4);not null" json:"description_score"` // 描述文本相似度（Jaccard，0~1）
+	SameRepo            bool      `gorm:"not null" json:"same_repo"`                          // 规范化后的仓库链接相同
+	Score               float64   `gorm:"type:decimal(5,4);not null" json:"score"`             // 综合相似度，仓库相同时为 1
+	CreatedAt           time.Time `json:"created_at"`
+
+	// 关联关系
+	Submission        Submission `gorm:"foreignKey:SubmissionID" json:"submission,omitempty"`
+	MatchedSubmission Submission `gorm:"foreignKey:MatchedSubmissionID" json:"matched_submission,omitempty"`
+}
+
+// TableName 指定表名
+func (SubmissionSimilarity) TableName() string {
+	return "submission_similarities"
+}
+
 // Vote 投票记录表
 type Vote struct {
 	ID            uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
 	HackathonID  uint64    `gorm:"index;not null" json:"hackathon_id"`
-	ParticipantID uint64    `gorm:"uniqueIndex:uk_participant_submission;not null" json:"participant_id"`
-	SubmissionID  uint64    `gorm:"uniqueIndex:uk_participant_submission;not null" json:"submission_id"`
+	ParticipantID uint64    `gorm:"uniqueIndex:uk_participant_submission_track;not null" json:"participant_id"`
+	SubmissionID  uint64    `gorm:"uniqueIndex:uk_participant_submission_track;not null" json:"submission_id"`
+	TrackID       uint64    `gorm:"uniqueIndex:uk_participant_submission_track;not null;default:0" json:"track_id"` // 投票所属赛道，0 表示未分赛道（旧索引 uk_participant_submission 在迁移时删除）
+	Weight        int       `gorm:"not null;default:1" json:"weight"` // 票数，仅二次方投票可大于 1
+	Rank          int       `gorm:"not null;default:0" json:"rank"`   // 排序选票中的名次（1 为首选），非排序投票为 0
+	SybilWeight   float64   `gorm:"type:decimal(5,4);not null;default:1" json:"sybil_weight"` // 防女巫加权系数（投票时按活动加权规则计算）
 	CreatedAt     time.Time `json:"created_at"`
 
 	// 关联关系
@@ -45,14 +185,22 @@ func (Vote) TableName() string {
 	return "votes"
 }
 
-// SubmissionHistory 作品修改记录表
+// SubmissionHistory 作品修改记录表，每次创建、修改或恢复后记录作品当时的完整内容（含草稿）
 type SubmissionHistory struct {
 	ID            uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
-	SubmissionID uint64    `gorm:"index;not null" json:"submission_id"`
-	ParticipantID uint64    `gorm:"index;not null" json:"participant_id"`
+	SubmissionID uint64    `gorm:"index;uniqueIndex:uk_submission_revision;not null" json:"submission_id"`
+	ParticipantID *uint64   `gorm:"index" json:"participant_id"`                  // 本次修改的参赛者，补记的早期版本为空
+	Revision      *int      `gorm:"uniqueIndex:uk_submission_revision" json:"revision"` // 版本号（从 1 递增），早期只记录修改前内容的记录为空
+	Action        string    `gorm:"type:varchar(20);not null;default:'update'" json:"action"` // create-创建，update-修改，restore-恢复历史版本
+	RestoredFrom  *uint64   `json:"restored_from,omitempty"`                  // 恢复时来源的修改记录ID
 	Name          string    `gorm:"type:varchar(100)" json:"name"`
 	Description   string    `gorm:"type:text" json:"description"`
 	Link          string    `gorm:"type:varchar(500)" json:"link"`
+	TechStack     string    `gorm:"type:varchar(500)" json:"tech_stack"`
+	DemoURL       string    `gorm:"type:varchar(500)" json:"demo_url"`
+	VideoURL      string    `gorm:"type:varchar(500)" json:"video_url"`
+	RepoURL       string    `gorm:"type:varchar(500)" json:"repo_url"`
+	Draft         int       `gorm:"type:tinyint(1);default:0" json:"draft"`
 	CreatedAt     time.Time `json:"created_at"`
 
 	// 关联关系
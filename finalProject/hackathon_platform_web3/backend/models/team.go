@@ -7,16 +7,20 @@ import (
 )
 
 // Team 队伍表
-// 唯一索引 uk_hackathon_leader (hackathon_id, leader_id) 确保：
+// 唯一索引 uk_hackathon_leader (hackathon_id, leader_id, active_leader) 确保：
 // - 一个队长在一个活动中只能创建一个队伍
 // - 同一个队长可以在不同活动中创建不同的队伍
+// - 已解散（软删除）的队伍不占用索引：active_leader 为生成列，未删除时为 1、删除后为 NULL，
+//   由 database.migrateSchema 创建（AutoMigrate 不支持生成列）
 type Team struct {
 	ID          uint64         `gorm:"primaryKey;autoIncrement" json:"id"`
-	HackathonID uint64         `gorm:"uniqueIndex:uk_hackathon_leader;not null" json:"hackathon_id"`
+	HackathonID uint64         `gorm:"not null" json:"hackathon_id"`
 	Name        string         `gorm:"type:varchar(50);not null" json:"name"`
-	LeaderID    uint64         `gorm:"uniqueIndex:uk_hackathon_leader;not null" json:"leader_id"`
+	LeaderID    uint64         `gorm:"not null" json:"leader_id"`
 	MaxSize     int            `gorm:"default:3" json:"max_size"`
 	Status      string         `gorm:"type:enum('recruiting','locked');default:'recruiting'" json:"status"`
+	JoinPolicy  string         `gorm:"type:enum('open','request','invite_only');default:'open'" json:"join_policy"` // 加入方式：open-直接加入，request-申请后队长审批，invite_only-仅限邀请
+	LookingForRoles string     `gorm:"type:varchar(255)" json:"looking_for_roles"` // 队伍正在招募的角色（逗号分隔），用于队友匹配
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
@@ -25,6 +29,9 @@ type Team struct {
 	Hackathon  Hackathon      `gorm:"foreignKey:HackathonID" json:"hackathon,omitempty"`
 	Leader     Participant    `gorm:"foreignKey:LeaderID" json:"leader,omitempty"`
 	Members    []TeamMember   `gorm:"foreignKey:TeamID" json:"members,omitempty"`
+
+	// JoinRequests 待审批的加入申请（仅队长查看自己队伍时填充），不落库
+	JoinRequests []TeamJoinRequest `gorm:"-" json:"join_requests,omitempty"`
 }
 
 // TableName 指定表名
@@ -50,3 +57,49 @@ func (TeamMember) TableName() string {
 	return "team_members"
 }
 
+
+// TeamInvitation 队伍邀请表（队长发放的邀请码/邀请链接，可指定受邀人，过期失效）
+type TeamInvitation struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	TeamID    uint64    `gorm:"index;not null" json:"team_id"`
+	InviterID uint64    `gorm:"not null" json:"inviter_id"`
+	InviteeID *uint64   `gorm:"index" json:"invitee_id"` // 受邀参赛者，为空表示持有邀请码/链接的任何人均可使用
+	Code      string    `gorm:"type:varchar(32);uniqueIndex;not null" json:"code"`
+	MaxUses   int       `gorm:"not null;default:1" json:"max_uses"`
+	UsedCount int       `gorm:"not null;default:0" json:"used_count"`
+	Status    string    `gorm:"type:enum('active','used','declined','revoked');default:'active'" json:"status"`
+	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// 关联关系
+	Team    Team         `gorm:"foreignKey:TeamID" json:"team,omitempty"`
+	Invitee *Participant `gorm:"foreignKey:InviteeID" json:"invitee,omitempty"`
+}
+
+// TableName 指定表名
+func (TeamInvitation) TableName() string {
+	return "team_invitations"
+}
+
+// TeamJoinRequest 加入队伍申请表（队伍加入方式为 request 时，由队长审批）
+type TeamJoinRequest struct {
+	ID            uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	TeamID        uint64     `gorm:"index;not null" json:"team_id"`
+	HackathonID   uint64     `gorm:"index;not null" json:"hackathon_id"`
+	ParticipantID uint64     `gorm:"index;not null" json:"participant_id"`
+	Message       string     `gorm:"type:varchar(500)" json:"message"`
+	Status        string     `gorm:"type:enum('pending','approved','declined','cancelled');default:'pending'" json:"status"`
+	ReviewedAt    *time.Time `json:"reviewed_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	// 关联关系
+	Team        Team        `gorm:"foreignKey:TeamID" json:"team,omitempty"`
+	Participant Participant `gorm:"foreignKey:ParticipantID" json:"participant,omitempty"`
+}
+
+// TableName 指定表名
+func (TeamJoinRequest) TableName() string {
+	return "team_join_requests"
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// HackathonTrack 活动赛道表（如 DeFi、Gaming、Infra），奖项、赞助与投票结果均可按赛道划分
+type HackathonTrack struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	HackathonID uint64    `gorm:"uniqueIndex:uk_hackathon_track_name;not null" json:"hackathon_id"`
+	Name        string    `gorm:"uniqueIndex:uk_hackathon_track_name;type:varchar(100);not null" json:"name"`
+	Description string    `gorm:"type:text" json:"description"`
+	Order       int       `gorm:"default:0" json:"order"` // 排序
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (HackathonTrack) TableName() string {
+	return "hackathon_tracks"
+}
+
+// SubmissionTrack 作品参赛赛道关联表（一个作品可以参加多个赛道）
+type SubmissionTrack struct {
+	ID           uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	SubmissionID uint64    `gorm:"uniqueIndex:uk_submission_track;not null" json:"submission_id"`
+	TrackID      uint64    `gorm:"uniqueIndex:uk_submission_track;index;not null" json:"track_id"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	// 关联关系
+	Track HackathonTrack `gorm:"foreignKey:TrackID" json:"track,omitempty"`
+}
+
+// TableName 指定表名
+func (SubmissionTrack) TableName() string {
+	return "submission_tracks"
+}
@@ -6,13 +6,13 @@ import (
 	"gorm.io/gorm"
 )
 
-// User 用户表（管理员、主办方、赞助商）
+// User 用户表（管理员、主办方、赞助商、评委）
 type User struct {
 	ID        uint64         `gorm:"primaryKey;autoIncrement" json:"id"`
 	Name      string         `gorm:"type:varchar(100);not null" json:"name"`
 	Phone     string         `gorm:"type:varchar(20);uniqueIndex" json:"phone"` // 手机号，唯一但不强制（web3登录可能没有）
 	Password  string         `gorm:"type:varchar(255)" json:"-"` // 不返回密码，可为空（web3登录不需要密码）
-	Role      string         `gorm:"type:enum('admin','organizer','sponsor');not null" json:"role"`
+	Role      string         `gorm:"type:enum('admin','organizer','sponsor','judge');not null" json:"role"`
 	SponsorID *uint64        `gorm:"index" json:"sponsor_id"`
 	Status    int            `gorm:"type:tinyint(1);default:1" json:"status"` // 1-启用，0-禁用
 	CreatedAt time.Time      `json:"created_at"`
@@ -40,3 +40,17 @@ func (UserWallet) TableName() string {
 	return "user_wallets"
 }
 
+// PasswordSetupToken 一次性设置密码凭证（赞助商账号开通时通过短信发送设置密码链接），只保存凭证哈希
+type PasswordSetupToken struct {
+	ID        uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint64     `gorm:"index;not null" json:"user_id"`
+	TokenHash string     `gorm:"type:char(64);uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName 指定表名
+func (PasswordSetupToken) TableName() string {
+	return "password_setup_tokens"
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Webhook 外部系统的 Webhook 订阅（主办方订阅自己的活动，Admin 可订阅全平台事件）
+type Webhook struct {
+	ID          uint64         `gorm:"primaryKey;autoIncrement" json:"id"`
+	HackathonID *uint64        `gorm:"index" json:"hackathon_id"` // 订阅的活动，为空表示订阅全平台事件（仅 Admin）
+	CreatedBy   uint64         `gorm:"index;not null" json:"created_by"`
+	URL         string         `gorm:"type:varchar(500);not null" json:"url"`
+	Secret      string         `gorm:"type:varchar(64);not null" json:"-"`       // HMAC 签名密钥，仅在创建与重置时返回
+	Events      string         `gorm:"type:varchar(500);not null" json:"events"` // 订阅的事件类型，逗号分隔，如 "team.created,submission.created"
+	Description string         `gorm:"type:varchar(255)" json:"description"`
+	Enabled     bool           `gorm:"not null;default:true" json:"enabled"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName 指定表名
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// WebhookDelivery Webhook 投递记录，失败后按指数退避重试，并保留最近一次投递的响应状态码
+type WebhookDelivery struct {
+	ID             uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	WebhookID      uint64     `gorm:"index;not null" json:"webhook_id"`
+	EventID        string     `gorm:"type:varchar(36);index;not null" json:"event_id"` // 事件ID，重放时保持不变，便于接收方去重
+	Event          string     `gorm:"type:varchar(50);not null" json:"event"`
+	Payload        string     `gorm:"type:mediumtext;not null" json:"payload"`                                                       // 发送的 JSON 请求体
+	Status         string     `gorm:"type:enum('pending','success','failed');default:'pending';index:idx_status_next" json:"status"` // pending-待投递（含等待重试），success-已送达，failed-重试次数用尽
+	Attempts       int        `gorm:"default:0" json:"attempts"`
+	NextAttemptAt  time.Time  `gorm:"index:idx_status_next" json:"next_attempt_at"`
+	ResponseStatus int        `json:"response_status"`                // 最近一次投递的 HTTP 状态码，请求未完成时为 0
+	ResponseBody   string     `gorm:"type:text" json:"response_body"` // 最近一次投递的响应内容，仅保留开头一小段
+	LastError      string     `gorm:"type:varchar(1000)" json:"last_error"`
+	ReplayOf       *uint64    `gorm:"index" json:"replay_of"` // 手动重放时指向原投递记录
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
@@ -0,0 +1,87 @@
+package notify
+
+import (
+	"fmt"
+
+	"hackathon-backend/config"
+)
+
+// 通知渠道
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+)
+
+// Message 一条待发送的通知
+type Message struct {
+	Channel string // email | sms
+	To      string // 邮箱地址或手机号
+	Subject string // 邮件标题，短信忽略
+	Body    string
+	// Sensitive 正文含设置密码链接等敏感内容，log、file 后端不记录正文
+	Sensitive bool
+}
+
+// Provider 通知发送后端，Send 返回错误时由发送队列按配置重试
+type Provider interface {
+	Send(msg Message) error
+}
+
+// Email、SMS 当前使用的邮件与短信发送后端，由 Init 根据配置初始化
+var (
+	Email Provider
+	SMS   Provider
+)
+
+// Init 根据配置初始化邮件与短信发送后端
+func Init() error {
+	cfg := config.AppConfig.Notification
+
+	switch cfg.EmailDriver {
+	case "", "log":
+		Email = &LogSink{}
+	case "file":
+		Email = NewFileSink(cfg.FilePath)
+	case "smtp":
+		smtpProvider, err := NewSMTPProvider(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Username, cfg.SMTP.Password, cfg.SMTP.From)
+		if err != nil {
+			return err
+		}
+		Email = smtpProvider
+	default:
+		return fmt.Errorf("不支持的邮件发送方式: %s", cfg.EmailDriver)
+	}
+
+	switch cfg.SMSDriver {
+	case "", "log":
+		SMS = &LogSink{}
+	case "file":
+		SMS = NewFileSink(cfg.FilePath)
+	case "gateway":
+		gateway, err := NewSMSGateway(cfg.SMSGateway.URL, cfg.SMSGateway.APIKey, cfg.SMSGateway.Sign)
+		if err != nil {
+			return err
+		}
+		SMS = gateway
+	default:
+		return fmt.Errorf("不支持的短信发送方式: %s", cfg.SMSDriver)
+	}
+	return nil
+}
+
+// Send 按渠道选择发送后端发送通知
+func Send(msg Message) error {
+	var provider Provider
+	switch msg.Channel {
+	case ChannelEmail:
+		provider = Email
+	case ChannelSMS:
+		provider = SMS
+	default:
+		return fmt.Errorf("不支持的通知渠道: %s", msg.Channel)
+	}
+	if provider == nil {
+		return fmt.Errorf("通知渠道 %s 未初始化", msg.Channel)
+	}
+	return provider.Send(msg)
+}
@@ -0,0 +1,63 @@
+package notify
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// redactedBody 敏感通知在日志与文件中显示的正文
+const redactedBody = "[敏感内容已隐藏]"
+
+// loggableBody 返回可写入日志或文件的正文，敏感通知不记录原文
+func loggableBody(msg Message) string {
+	if msg.Sensitive {
+		return redactedBody
+	}
+	return msg.Body
+}
+
+// LogSink 仅将通知写入服务日志，不实际发送（默认，用于本地开发）
+type LogSink struct{}
+
+func (s *LogSink) Send(msg Message) error {
+	log.Printf("[notify] %s to=%s subject=%q\n%s", msg.Channel, msg.To, msg.Subject, loggableBody(msg))
+	return nil
+}
+
+// FileSink 将通知追加写入本地文件，便于测试时检查发送内容
+type FileSink struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileSink 创建文件通知后端
+func NewFileSink(path string) *FileSink {
+	if path == "" {
+		path = "notifications.log"
+	}
+	return &FileSink{path: path}
+}
+
+func (s *FileSink) Send(msg Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if dir := filepath.Dir(s.path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "=== %s %s to=%s\nSubject: %s\n\n%s\n\n",
+		time.Now().Format(time.RFC3339), msg.Channel, msg.To, msg.Subject, loggableBody(msg))
+	return err
+}
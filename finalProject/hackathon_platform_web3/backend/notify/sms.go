@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// SMSGateway 通过 HTTP 短信网关发送短信：POST JSON {"to","content","sign"}，2xx 视为成功
+type SMSGateway struct {
+	url    string
+	apiKey string
+	sign   string
+	client *http.Client
+}
+
+// NewSMSGateway 创建 HTTP 短信网关发送后端
+func NewSMSGateway(url, apiKey, sign string) (*SMSGateway, error) {
+	if url == "" {
+		return nil, errors.New("短信网关发送需配置网关地址")
+	}
+	return &SMSGateway{url: url, apiKey: apiKey, sign: sign, client: &http.Client{Timeout: 15 * time.Second}}, nil
+}
+
+func (g *SMSGateway) Send(msg Message) error {
+	payload, err := json.Marshal(map[string]string{
+		"to":      msg.To,
+		"content": msg.Body,
+		"sign":    g.sign,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, g.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if g.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+g.apiKey)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("短信网关返回 %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return nil
+}
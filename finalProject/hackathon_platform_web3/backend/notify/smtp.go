@@ -0,0 +1,120 @@
+package notify
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPProvider 通过 SMTP 发送邮件：465 端口使用隐式 TLS，其余端口在服务端支持时使用 STARTTLS
+type SMTPProvider struct {
+	host     string
+	port     int
+	username string
+	password string
+	from     *mail.Address
+}
+
+// NewSMTPProvider 创建 SMTP 邮件发送后端
+func NewSMTPProvider(host string, port int, username, password, from string) (*SMTPProvider, error) {
+	if host == "" {
+		return nil, errors.New("SMTP 邮件发送需配置服务器地址")
+	}
+	if port == 0 {
+		port = 587
+	}
+	if from == "" {
+		from = username
+	}
+	fromAddr, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("无效的发件人地址: %w", err)
+	}
+	return &SMTPProvider{host: host, port: port, username: username, password: password, from: fromAddr}, nil
+}
+
+func (p *SMTPProvider) Send(msg Message) error {
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("无效的收件人地址: %w", err)
+	}
+
+	addr := net.JoinHostPort(p.host, strconv.Itoa(p.port))
+	var conn net.Conn
+	dialer := &net.Dialer{Timeout: 15 * time.Second}
+	if p.port == 465 {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: p.host})
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	_ = conn.SetDeadline(time.Now().Add(time.Minute))
+
+	client, err := smtp.NewClient(conn, p.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if p.port != 465 {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: p.host}); err != nil {
+				return err
+			}
+		}
+	}
+	if p.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", p.username, p.password, p.host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(p.from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(buildMail(p.from, to, msg.Subject, msg.Body)); err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// buildMail 生成纯文本邮件（UTF-8，正文 base64 编码）
+func buildMail(from, to *mail.Address, subject, body string) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from.String() + "\r\n")
+	b.WriteString("To: " + to.String() + "\r\n")
+	b.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(body))
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded + "\r\n")
+	return []byte(b.String())
+}
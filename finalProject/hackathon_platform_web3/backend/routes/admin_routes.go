@@ -13,6 +13,12 @@ func SetupAdminRoutes(router *gin.Engine) {
 	adminHackathonController := controllers.NewAdminHackathonController()
 	adminDashboardController := controllers.NewAdminDashboardController()
 	sponsorController := controllers.NewSponsorController()
+	sponsorPortalController := controllers.NewSponsorPortalController()
+	adminJudgeController := controllers.NewAdminJudgeController()
+	adminNotificationController := controllers.NewAdminNotificationController()
+	inboxController := controllers.NewInboxController()
+	realtimeController := controllers.NewRealtimeController()
+	adminWebhookController := controllers.NewAdminWebhookController()
 
 	api := router.Group("/api/v1/admin")
 	{
@@ -21,6 +27,7 @@ func SetupAdminRoutes(router *gin.Engine) {
 		{
 			auth.POST("/login", adminAuthController.Login)
 			auth.POST("/login/wallet", adminAuthController.LoginWithWallet)
+			auth.POST("/password/setup", adminAuthController.SetupPassword) // 赞助商账号开通短信中的一次性设置密码链接
 			auth.POST("/logout", middleware.AuthMiddleware(), adminAuthController.Logout)
 		}
 
@@ -28,12 +35,17 @@ func SetupAdminRoutes(router *gin.Engine) {
 		sponsor := api.Group("/sponsor")
 		{
 			sponsor.GET("/apply/prepare", sponsorController.PrepareSponsorApply)
+			sponsor.POST("/logo", sponsorController.UploadLogo) // 需携带 apply/prepare 签发的一次性上传凭证
 			sponsor.POST("/applications", sponsorController.CreateApplication)
 			sponsor.POST("/applications/submit-transaction", sponsorController.SubmitSponsorApplyTransaction)
 			sponsor.GET("/applications/query", sponsorController.QueryApplication)
 			sponsor.GET("/published-hackathons", sponsorController.GetPublishedHackathons)
 		}
 
+		// 实时推送（SSE，EventSource 无法设置请求头，支持以 token 查询参数认证）
+		api.GET("/hackathons/:id/events", middleware.QueryTokenMiddleware(), middleware.AuthMiddleware(),
+			middleware.RoleMiddleware("organizer", "admin"), realtimeController.AdminStream)
+
 		// 需要认证的路由
 		api.Use(middleware.AuthMiddleware())
 		{
@@ -61,9 +73,41 @@ func SetupAdminRoutes(router *gin.Engine) {
 				profile.DELETE("/wallets/:id", adminAuthController.DeleteWallet)
 			}
 
+			// 站内通知（所有角色）
+			inbox := api.Group("/inbox")
+			{
+				inbox.GET("", inboxController.GetNotifications)
+				inbox.GET("/unread-count", inboxController.GetUnreadCount)
+				inbox.POST("/:id/read", inboxController.MarkRead)
+				inbox.POST("/read-all", inboxController.MarkAllRead)
+				inbox.GET("/preferences", inboxController.GetPreferences)
+				inbox.PUT("/preferences", inboxController.UpdatePreferences)
+			}
+
 			// 活动概览（Organizer、Admin和Sponsor都可以）
 			api.GET("/dashboard", middleware.RoleMiddleware("organizer", "admin", "sponsor"), adminDashboardController.GetDashboard)
 
+			// 赞助商门户（仅Sponsor）
+			portal := api.Group("/sponsor/portal")
+			portal.Use(middleware.RoleMiddleware("sponsor"))
+			{
+				portal.GET("/profile", sponsorPortalController.GetProfile)
+				portal.PUT("/profile", sponsorPortalController.UpdateProfile)
+				portal.POST("/logo", sponsorPortalController.UpdateLogo)
+				portal.GET("/events", sponsorPortalController.GetEvents)
+				portal.GET("/events/:id/metrics", sponsorPortalController.GetEventMetrics)
+				portal.GET("/events/:id/participants", sponsorPortalController.ExportParticipants)
+				portal.GET("/event-requests", sponsorPortalController.GetEventRequests)
+				portal.POST("/event-requests", sponsorPortalController.CreateEventRequest)
+				portal.GET("/events/:id/bounties", sponsorPortalController.GetBounties)
+				portal.POST("/events/:id/bounties", sponsorPortalController.CreateBounty)
+				portal.PUT("/bounties/:id", sponsorPortalController.UpdateBounty)
+				portal.DELETE("/bounties/:id", sponsorPortalController.CancelBounty)
+				portal.GET("/bounties/:id/entries", sponsorPortalController.GetBountyEntries)
+				portal.POST("/bounties/:id/winners", sponsorPortalController.AwardBounty)
+				portal.PUT("/bounties/:id/winners/:winner_id/payout", sponsorPortalController.MarkPayoutPaid)
+			}
+
 			// 活动管理
 			hackathons := api.Group("/hackathons")
 			{
@@ -89,12 +133,79 @@ func SetupAdminRoutes(router *gin.Engine) {
 				hackathons.POST("/:id/stages/:stage/switch", middleware.RoleMiddleware("organizer"), adminHackathonController.SwitchStage)
 				hackathons.GET("/:id/stages", middleware.RoleMiddleware("organizer", "admin"), adminHackathonController.GetStageTimes)
 				hackathons.PUT("/:id/stages", middleware.RoleMiddleware("organizer"), adminHackathonController.UpdateStageTimes)
+				hackathons.GET("/:id/reminders", middleware.RoleMiddleware("organizer", "admin"), adminHackathonController.GetReminders)
+
+				// 赛道管理（仅Organizer，且仅活动创建者）
+				hackathons.GET("/:id/tracks", middleware.RoleMiddleware("organizer", "admin"), adminHackathonController.GetTracks)
+				hackathons.POST("/:id/tracks", middleware.RoleMiddleware("organizer"), adminHackathonController.CreateTrack)
+				hackathons.PUT("/:id/tracks/:track_id", middleware.RoleMiddleware("organizer"), adminHackathonController.UpdateTrack)
+				hackathons.DELETE("/:id/tracks/:track_id", middleware.RoleMiddleware("organizer"), adminHackathonController.DeleteTrack)
+
+				// 自动组队
+				hackathons.POST("/:id/teams/auto-group", middleware.RoleMiddleware("organizer"), adminHackathonController.AutoGroupTeams)
+				hackathons.PUT("/:id/teams/auto-group", middleware.RoleMiddleware("organizer"), adminHackathonController.SetAutoGroupOnClose)
+				hackathons.GET("/:id/snapshots", middleware.RoleMiddleware("organizer", "admin"), adminHackathonController.GetSnapshots)
+				hackathons.POST("/:id/snapshots", middleware.RoleMiddleware("organizer"), adminHackathonController.RetrySnapshots)
+				hackathons.GET("/:id/similarity", middleware.RoleMiddleware("organizer", "admin"), adminHackathonController.GetSimilarityReport)
+				hackathons.POST("/:id/similarity/scan", middleware.RoleMiddleware("organizer"), adminHackathonController.StartSimilarityScan)
+				hackathons.PUT("/:id/late-policy", middleware.RoleMiddleware("organizer"), adminHackathonController.SetLatePolicy)
+				hackathons.PUT("/:id/live-tally", middleware.RoleMiddleware("organizer"), realtimeController.SetLiveTally)
+				hackathons.GET("/:id/extensions", middleware.RoleMiddleware("organizer", "admin"), adminHackathonController.GetExtensions)
+				hackathons.POST("/:id/extensions", middleware.RoleMiddleware("organizer"), adminHackathonController.GrantExtension)
+				hackathons.DELETE("/:id/extensions/:team_id", middleware.RoleMiddleware("organizer"), adminHackathonController.RevokeExtension)
+				hackathons.GET("/:id/waitlist", middleware.RoleMiddleware("organizer", "admin"), adminHackathonController.GetWaitlist)
+				hackathons.PUT("/:id/waitlist/order", middleware.RoleMiddleware("organizer"), adminHackathonController.ReorderWaitlist)
+				hackathons.GET("/:id/registration-form", middleware.RoleMiddleware("organizer", "admin"), adminHackathonController.GetRegistrationForm)
+				hackathons.PUT("/:id/registration-form", middleware.RoleMiddleware("organizer"), adminHackathonController.SetRegistrationForm)
+				hackathons.PUT("/:id/registrations/:registration_id/review", middleware.RoleMiddleware("organizer"), adminHackathonController.ReviewRegistration)
+				hackathons.GET("/:id/sponsor-requests", middleware.RoleMiddleware("organizer", "admin"), adminHackathonController.GetSponsorRequests)
+				hackathons.PUT("/:id/sponsor-requests/:request_id/review", middleware.RoleMiddleware("organizer"), adminHackathonController.ReviewSponsorRequest)
+				hackathons.GET("/:id/bounties", middleware.RoleMiddleware("organizer", "admin"), adminHackathonController.GetBounties)
+				hackathons.GET("/:id/eligibility", middleware.RoleMiddleware("organizer", "admin"), adminHackathonController.GetEligibility)
+				hackathons.PUT("/:id/eligibility", middleware.RoleMiddleware("organizer"), adminHackathonController.SetEligibility)
+				hackathons.GET("/:id/eligibility/allowlist", middleware.RoleMiddleware("organizer", "admin"), adminHackathonController.GetAllowlist)
+				hackathons.POST("/:id/eligibility/allowlist", middleware.RoleMiddleware("organizer"), adminHackathonController.ImportAllowlist)
+				hackathons.DELETE("/:id/eligibility/allowlist", middleware.RoleMiddleware("organizer"), adminHackathonController.ClearAllowlist)
+				hackathons.POST("/:id/eligibility/invite-codes", middleware.RoleMiddleware("organizer"), adminHackathonController.CreateInviteCodes)
+				hackathons.DELETE("/:id/eligibility/invite-codes/:code_id", middleware.RoleMiddleware("organizer"), adminHackathonController.DeleteInviteCode)
+
+				// 评审管理（仅Organizer，且仅活动创建者）
+				hackathons.GET("/:id/judges", middleware.RoleMiddleware("organizer", "admin"), adminJudgeController.GetJudges)
+				hackathons.POST("/:id/judges", middleware.RoleMiddleware("organizer"), adminJudgeController.AssignJudge)
+				hackathons.DELETE("/:id/judges/:user_id", middleware.RoleMiddleware("organizer"), adminJudgeController.RemoveJudge)
+				hackathons.GET("/:id/judge-conflicts", middleware.RoleMiddleware("organizer", "admin"), adminJudgeController.GetConflicts)
+				hackathons.POST("/:id/judge-conflicts", middleware.RoleMiddleware("organizer"), adminJudgeController.AddConflict)
+				hackathons.DELETE("/:id/judge-conflicts/:conflict_id", middleware.RoleMiddleware("organizer"), adminJudgeController.RemoveConflict)
+				hackathons.GET("/:id/rubric", middleware.RoleMiddleware("organizer", "admin"), adminJudgeController.GetRubric)
+				hackathons.PUT("/:id/rubric", middleware.RoleMiddleware("organizer"), adminJudgeController.SetRubric)
+				hackathons.PUT("/:id/judge-weight", middleware.RoleMiddleware("organizer"), adminJudgeController.SetJudgeWeight)
+				hackathons.GET("/:id/submissions/:submission_id/scores", middleware.RoleMiddleware("organizer", "admin"), adminJudgeController.GetSubmissionScores)
+
+				// 比赛结果（仅Organizer，且仅活动创建者可计算与调整）
+				hackathons.GET("/:id/results", middleware.RoleMiddleware("organizer", "admin"), adminHackathonController.GetResultRecords)
+				hackathons.POST("/:id/results/compute", middleware.RoleMiddleware("organizer"), adminHackathonController.ComputeResults)
+				hackathons.PUT("/:id/results/:result_id", middleware.RoleMiddleware("organizer"), adminHackathonController.OverrideResult)
+
+				// 投票风控（仅Admin）
+				hackathons.GET("/:id/vote-clusters", middleware.RoleMiddleware("admin"), adminHackathonController.GetSuspiciousVoteClusters)
+
 				// 归档活动（Organizer和Admin都可以，但需检查权限）
 				hackathons.POST("/:id/archive", middleware.RoleMiddleware("organizer", "admin"), adminHackathonController.ArchiveHackathon)
 				hackathons.POST("/:id/unarchive", middleware.RoleMiddleware("organizer", "admin"), adminHackathonController.UnarchiveHackathon)
 				hackathons.POST("/batch-archive", middleware.RoleMiddleware("organizer", "admin"), adminHackathonController.BatchArchiveHackathons)
 			}
 
+			// 评委评审（Judge权限）
+			judging := api.Group("/judging")
+			judging.Use(middleware.RoleMiddleware("judge"))
+			{
+				judging.GET("/hackathons", adminJudgeController.GetMyHackathons)
+				judging.GET("/hackathons/:id/rubric", adminJudgeController.GetRubric)
+				judging.GET("/hackathons/:id/submissions", adminJudgeController.GetJudgeSubmissions)
+				judging.POST("/submissions/:id/scores", adminJudgeController.SubmitScores)
+				judging.GET("/submissions/:id/snapshot", adminJudgeController.CheckSnapshot)
+			}
+
 			// 赞助商审核（Admin权限）
 			sponsorAdmin := api.Group("/sponsor")
 			sponsorAdmin.Use(middleware.RoleMiddleware("admin"))
@@ -103,6 +214,35 @@ func SetupAdminRoutes(router *gin.Engine) {
 				sponsorAdmin.GET("/applications/pending", sponsorController.GetPendingApplications)
 				sponsorAdmin.GET("/applications/reviewed", sponsorController.GetReviewedApplications)
 				sponsorAdmin.POST("/applications/:id/review", sponsorController.ReviewApplication)
+				sponsorAdmin.GET("/tiers", sponsorController.GetTiers)
+				sponsorAdmin.POST("/tiers", sponsorController.CreateTier)
+				sponsorAdmin.PUT("/tiers/:id", sponsorController.UpdateTier)
+				sponsorAdmin.DELETE("/tiers/:id", sponsorController.DeleteTier)
+				sponsorAdmin.GET("/sponsors", sponsorController.GetSponsors)
+				sponsorAdmin.PUT("/sponsors/:id/tier", sponsorController.AssignTier)
+				sponsorAdmin.PUT("/sponsors/:id/term", sponsorController.SetSponsorTerm)
+			}
+
+			// 通知发送记录（Admin权限）
+			notifications := api.Group("/notifications")
+			notifications.Use(middleware.RoleMiddleware("admin"))
+			{
+				notifications.GET("", adminNotificationController.GetNotificationJobs)
+				notifications.POST("/:id/retry", adminNotificationController.RetryNotificationJob)
+			}
+
+			// Webhook 订阅（主办方管理自己活动的订阅，Admin 可管理全部并订阅全平台事件）
+			webhooks := api.Group("/webhooks")
+			webhooks.Use(middleware.RoleMiddleware("organizer", "admin"))
+			{
+				webhooks.GET("/events", adminWebhookController.GetEventTypes)
+				webhooks.GET("", adminWebhookController.GetWebhooks)
+				webhooks.POST("", adminWebhookController.CreateWebhook)
+				webhooks.PUT("/:id", adminWebhookController.UpdateWebhook)
+				webhooks.DELETE("/:id", adminWebhookController.DeleteWebhook)
+				webhooks.POST("/:id/rotate-secret", adminWebhookController.RotateSecret)
+				webhooks.GET("/:id/deliveries", adminWebhookController.GetDeliveries)
+				webhooks.POST("/:id/deliveries/:delivery_id/replay", adminWebhookController.ReplayDelivery)
 			}
 		}
 	}
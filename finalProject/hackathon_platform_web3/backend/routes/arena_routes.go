@@ -14,6 +14,8 @@ func SetupArenaRoutes(router *gin.Engine) {
 	arenaTeamController := controllers.NewArenaTeamController()
 	arenaSubmissionController := controllers.NewArenaSubmissionController()
 	arenaVoteController := controllers.NewArenaVoteController()
+	inboxController := controllers.NewInboxController()
+	realtimeController := controllers.NewRealtimeController()
 
 	api := router.Group("/api/v1/arena")
 	{
@@ -29,8 +31,15 @@ func SetupArenaRoutes(router *gin.Engine) {
 		{
 			hackathons.GET("", arenaHackathonController.GetHackathonList)
 			hackathons.GET("/:id", arenaHackathonController.GetHackathonByID)
+			hackathons.GET("/:id/tracks", arenaHackathonController.GetTracks)
+			hackathons.GET("/:id/bounties", arenaHackathonController.GetBounties)
+			hackathons.GET("/:id/registration-form", arenaRegistrationController.GetRegistrationForm)
 			hackathons.GET("/archive", arenaHackathonController.GetArchiveList)
 			hackathons.GET("/archive/:id", arenaHackathonController.GetArchiveDetail)
+			hackathons.GET("/archive/submissions", arenaSubmissionController.SearchArchiveSubmissions)
+
+			// 实时推送（SSE，EventSource 无法设置请求头，支持以 token 查询参数认证）
+			hackathons.GET("/:id/events", middleware.QueryTokenMiddleware(), middleware.ParticipantAuthMiddleware(), realtimeController.ArenaStream)
 		}
 
 		// 赞助商相关（无需认证）
@@ -54,14 +63,29 @@ func SetupArenaRoutes(router *gin.Engine) {
 			// 我的活动
 			api.GET("/my-hackathons", arenaHackathonController.GetMyHackathons)
 
+			// 站内通知
+			inbox := api.Group("/inbox")
+			{
+				inbox.GET("", inboxController.GetNotifications)
+				inbox.GET("/unread-count", inboxController.GetUnreadCount)
+				inbox.POST("/:id/read", inboxController.MarkRead)
+				inbox.POST("/read-all", inboxController.MarkAllRead)
+				inbox.GET("/preferences", inboxController.GetPreferences)
+				inbox.PUT("/preferences", inboxController.UpdatePreferences)
+			}
+
 			// 报名相关
 			registration := api.Group("/hackathons/:id")
 			{
 				registration.POST("/register", arenaRegistrationController.Register)
 				registration.DELETE("/register", arenaRegistrationController.CancelRegistration)
 				registration.GET("/registration-status", arenaRegistrationController.GetRegistrationStatus)
+				registration.GET("/eligibility", arenaRegistrationController.CheckEligibility)
+				registration.POST("/waitlist/confirm", arenaRegistrationController.ConfirmWaitlistOffer)
+				registration.DELETE("/waitlist", arenaRegistrationController.LeaveWaitlist)
 				registration.POST("/checkin", arenaRegistrationController.Checkin)
 				registration.GET("/checkin-status", arenaRegistrationController.GetCheckinStatus)
+				registration.PUT("/sponsor-consent", arenaRegistrationController.SetSponsorConsent)
 			}
 
 			// 组队相关
@@ -70,6 +94,7 @@ func SetupArenaRoutes(router *gin.Engine) {
 				teams.POST("", arenaTeamController.CreateTeam)
 				teams.GET("", arenaTeamController.GetTeamList)
 				teams.GET("/my-team", arenaTeamController.GetUserTeam)
+				teams.GET("/my-requests", arenaTeamController.GetMyJoinStatus)
 			}
 
 			api.GET("/teams/:id", arenaTeamController.GetTeamByID)
@@ -78,6 +103,25 @@ func SetupArenaRoutes(router *gin.Engine) {
 			api.DELETE("/teams/:id", arenaTeamController.DissolveTeam)
 			api.DELETE("/teams/:id/members/:member_id", arenaTeamController.RemoveMember)
 			api.PATCH("/teams/:id", arenaTeamController.UpdateTeam)
+			api.POST("/teams/:id/transfer-leadership", arenaTeamController.TransferLeadership)
+			api.POST("/teams/:id/lock", arenaTeamController.LockTeam)
+			api.POST("/teams/:id/unlock", arenaTeamController.UnlockTeam)
+
+			// 队伍邀请与加入申请
+			api.POST("/teams/:id/invitations", arenaTeamController.CreateInvitation)
+			api.GET("/teams/:id/invitations", arenaTeamController.GetTeamInvitations)
+			api.DELETE("/teams/:id/invitations/:invitation_id", arenaTeamController.RevokeInvitation)
+			api.GET("/invitations/:code", arenaTeamController.GetInvitation)
+			api.POST("/invitations/:code/accept", arenaTeamController.AcceptInvitation)
+			api.POST("/invitations/:code/decline", arenaTeamController.DeclineInvitation)
+			api.POST("/teams/:id/join-requests", arenaTeamController.CreateJoinRequest)
+			api.GET("/teams/:id/join-requests", arenaTeamController.GetTeamJoinRequests)
+			api.POST("/teams/:id/join-requests/:request_id/review", arenaTeamController.ReviewJoinRequest)
+			api.DELETE("/join-requests/:id", arenaTeamController.CancelJoinRequest)
+
+			// 队友匹配
+			api.GET("/hackathons/:id/matchmaking/teams", arenaTeamController.RecommendTeams)
+			api.GET("/teams/:id/matchmaking/participants", arenaTeamController.RecommendParticipants)
 
 			// 作品提交相关
 			submissions := api.Group("/hackathons/:id/submissions")
@@ -89,11 +133,21 @@ func SetupArenaRoutes(router *gin.Engine) {
 			api.GET("/submissions/:id", arenaSubmissionController.GetSubmissionByID)
 			api.PUT("/submissions/:id", arenaSubmissionController.UpdateSubmission)
 			api.GET("/submissions/:id/history", arenaSubmissionController.GetSubmissionHistory)
+			api.GET("/submissions/:id/history/diff", arenaSubmissionController.GetSubmissionDiff)
+			api.POST("/submissions/:id/history/:history_id/restore", arenaSubmissionController.RestoreSubmissionRevision)
+			api.POST("/submissions/:id/attachments", arenaSubmissionController.UploadAttachment)
+			api.GET("/submissions/:id/attachments", arenaSubmissionController.GetAttachments)
+			api.DELETE("/submissions/:id/attachments/:attachment_id", arenaSubmissionController.DeleteAttachment)
+			api.GET("/submissions/:id/bounties", arenaSubmissionController.GetSubmissionBounties)
+			api.POST("/submissions/:id/bounties/:bounty_id", arenaSubmissionController.JoinBounty)
+			api.DELETE("/submissions/:id/bounties/:bounty_id", arenaSubmissionController.LeaveBounty)
 
 			// 投票相关
 			api.POST("/submissions/:id/vote", arenaVoteController.Vote)
 			api.DELETE("/submissions/:id/vote", arenaVoteController.CancelVote)
 			api.GET("/hackathons/:id/votes", arenaVoteController.GetMyVotes)
+			api.GET("/hackathons/:id/votes/quota", arenaVoteController.GetVoteQuota)
+			api.PUT("/hackathons/:id/ballot", arenaVoteController.SubmitBallot)
 
 			// 结果查看
 			api.GET("/hackathons/:id/results", arenaVoteController.GetResults)
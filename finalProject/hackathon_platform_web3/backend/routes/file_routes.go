@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"hackathon-backend/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupFileRoutes 文件下载路由（无需认证，私有文件通过签名链接校验）
+func SetupFileRoutes(router *gin.Engine) {
+	fileController := controllers.NewFileController()
+
+	files := router.Group("/api/v1/files")
+	{
+		files.GET("/download/*key", fileController.Download)
+		files.GET("/logos/:name", fileController.GetLogo)
+	}
+}
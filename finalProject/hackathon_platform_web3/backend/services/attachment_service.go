@@ -0,0 +1,183 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+
+	"hackathon-backend/database"
+	"hackathon-backend/models"
+	"hackathon-backend/storage"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AttachmentService 作品附件（路演材料、截图、演示视频等）
+type AttachmentService struct{}
+
+// attachmentKind 作品附件类别对应的文件类别与数量上限
+type attachmentKind struct {
+	fileKind string
+	maxCount int64
+}
+
+var attachmentKinds = map[string]attachmentKind{
+	"pitch_deck": {fileKind: storage.KindPitchDeck, maxCount: 1},
+	"screenshot": {fileKind: storage.KindImage, maxCount: 10},
+	"demo_video": {fileKind: storage.KindVideo, maxCount: 1},
+	"other":      {fileKind: storage.KindDocument, maxCount: 5},
+}
+
+// fillAttachmentURLs 为附件生成限时有效的签名下载链接
+func fillAttachmentURLs(attachments []models.SubmissionAttachment) {
+	if storage.Default == nil {
+		return
+	}
+	expiry := storage.URLExpiry()
+	for i := range attachments {
+		attachments[i].URL, _ = storage.Default.SignedURL(attachments[i].StorageKey, expiry)
+		if attachments[i].ThumbnailKey != "" {
+			attachments[i].ThumbnailURL, _ = storage.Default.SignedURL(attachments[i].ThumbnailKey, expiry)
+		}
+	}
+}
+
+// getEditableSubmission 获取参赛者所在队伍的作品，并检查当前可修改；late 表示此时修改属于迟交，
+// 由调用方在附件修改成功的同一事务中标记
+func (s *AttachmentService) getEditableSubmission(submissionID, participantID uint64) (*models.Submission, bool, error) {
+	var submission models.Submission
+	if err := database.DB.Where("id = ?", submissionID).First(&submission).Error; err != nil {
+		return nil, false, errors.New("作品不存在")
+	}
+
+	var member models.TeamMember
+	if err := database.DB.Where("team_id = ? AND participant_id = ?", submission.TeamID, participantID).First(&member).Error; err != nil {
+		return nil, false, errors.New("您没有权限修改此作品")
+	}
+
+	submissionService := &SubmissionService{}
+	late, err := submissionService.checkSubmissionEditable(submission.HackathonID, submission.TeamID)
+	if err != nil {
+		return nil, false, err
+	}
+	return &submission, late, nil
+}
+
+// AddAttachment 上传作品附件（队伍成员，提交阶段内），路演材料与演示视频各限一个
+func (s *AttachmentService) AddAttachment(submissionID, participantID uint64, kind string, header *multipart.FileHeader) (*models.SubmissionAttachment, error) {
+	rule, ok := attachmentKinds[kind]
+	if !ok {
+		return nil, errors.New("无效的附件类型")
+	}
+
+	submission, late, err := s.getEditableSubmission(submissionID, participantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 上传前先检查一次数量上限，避免无效上传；写入前在事务中锁定作品行后再次检查
+	if err := checkAttachmentCount(database.DB, submissionID, kind, rule.maxCount); err != nil {
+		return nil, err
+	}
+
+	uploaded, err := storage.Upload(rule.fileKind, fmt.Sprintf("submissions/%d", submission.ID), header)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment := models.SubmissionAttachment{
+		SubmissionID: submission.ID,
+		UploaderID:   participantID,
+		Kind:         kind,
+		FileName:     uploaded.FileName,
+		StorageKey:   uploaded.Key,
+		ThumbnailKey: uploaded.ThumbnailKey,
+		ContentType:  uploaded.ContentType,
+		Size:         uploaded.Size,
+	}
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// 锁定作品行，同一作品的并发上传串行执行，数量检查与写入之间不会插入其他附件
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+			Where("id = ?", submission.ID).First(&models.Submission{}).Error; err != nil {
+			return err
+		}
+		if err := checkAttachmentCount(tx, submission.ID, kind, rule.maxCount); err != nil {
+			return err
+		}
+		if err := tx.Create(&attachment).Error; err != nil {
+			return err
+		}
+		if late {
+			return markSubmissionLate(tx, submission.ID)
+		}
+		return nil
+	}); err != nil {
+		storage.DeleteFiles(uploaded.Key, uploaded.ThumbnailKey)
+		return nil, err
+	}
+
+	attachments := []models.SubmissionAttachment{attachment}
+	fillAttachmentURLs(attachments)
+	return &attachments[0], nil
+}
+
+// checkAttachmentCount 检查作品该类别的附件数量是否已达上限
+func checkAttachmentCount(db *gorm.DB, submissionID uint64, kind string, maxCount int64) error {
+	var count int64
+	if err := db.Model(&models.SubmissionAttachment{}).Where("submission_id = ? AND kind = ?", submissionID, kind).Count(&count).Error; err != nil {
+		return err
+	}
+	if count >= maxCount {
+		return fmt.Errorf("该类型附件最多上传 %d 个", maxCount)
+	}
+	return nil
+}
+
+// GetAttachments 获取作品附件（含签名下载链接）。草稿作品的附件仅队伍成员可查看
+func (s *AttachmentService) GetAttachments(submissionID, participantID uint64) ([]models.SubmissionAttachment, error) {
+	var submission models.Submission
+	if err := database.DB.Select("id", "team_id", "draft").Where("id = ?", submissionID).First(&submission).Error; err != nil {
+		return nil, errors.New("作品不存在")
+	}
+	if submission.Draft != 0 {
+		var member models.TeamMember
+		if err := database.DB.Where("team_id = ? AND participant_id = ?", submission.TeamID, participantID).First(&member).Error; err != nil {
+			return nil, errors.New("您没有权限查看此作品的附件")
+		}
+	}
+
+	var attachments []models.SubmissionAttachment
+	if err := database.DB.Where("submission_id = ?", submissionID).Order("kind ASC, id ASC").Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+	fillAttachmentURLs(attachments)
+	return attachments, nil
+}
+
+// DeleteAttachment 删除作品附件（队伍成员，提交阶段内）
+func (s *AttachmentService) DeleteAttachment(submissionID, attachmentID, participantID uint64) error {
+	_, late, err := s.getEditableSubmission(submissionID, participantID)
+	if err != nil {
+		return err
+	}
+
+	var attachment models.SubmissionAttachment
+	if err := database.DB.Where("id = ? AND submission_id = ?", attachmentID, submissionID).First(&attachment).Error; err != nil {
+		return errors.New("附件不存在")
+	}
+
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&attachment).Error; err != nil {
+			return err
+		}
+		if late {
+			return markSubmissionLate(tx, submissionID)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	storage.DeleteFiles(attachment.StorageKey, attachment.ThumbnailKey)
+	return nil
+}
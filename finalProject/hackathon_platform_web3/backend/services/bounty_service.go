@@ -0,0 +1,558 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"hackathon-backend/config"
+	"hackathon-backend/database"
+	"hackathon-backend/models"
+	"hackathon-backend/solana"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BountyService 赞助商赏金挑战：赞助商发布与评选，队伍报名参加，奖励发放单独记录
+type BountyService struct{}
+
+// BountyInput 创建或修改赏金的参数
+type BountyInput struct {
+	Title              string  `json:"title"`
+	Description        string  `json:"description"`
+	AcceptanceCriteria string  `json:"acceptance_criteria"`
+	Reward             string  `json:"reward"`
+	RewardAmount       float64 `json:"reward_amount"`
+	RewardToken        string  `json:"reward_token"`
+	MaxWinners         int     `json:"max_winners"`
+}
+
+// BountyWinnerInput 评选的获奖作品
+type BountyWinnerInput struct {
+	SubmissionID uint64 `json:"submission_id"`
+	Note         string `json:"note"`
+}
+
+// bountyOpenStatuses 可以发布、修改赏金以及报名参加赏金的活动阶段（提交阶段结束前）
+var bountyOpenStatuses = map[string]bool{
+	"published":      true,
+	"registration":   true,
+	"checkin":        true,
+	"team_formation": true,
+	"submission":     true,
+}
+
+// validateBountyInput 校验并规范化赏金参数
+func validateBountyInput(input *BountyInput) error {
+	input.Title = strings.TrimSpace(input.Title)
+	input.AcceptanceCriteria = strings.TrimSpace(input.AcceptanceCriteria)
+	input.Reward = strings.TrimSpace(input.Reward)
+	input.RewardToken = strings.ToUpper(strings.TrimSpace(input.RewardToken))
+	if input.Title == "" || len([]rune(input.Title)) > 200 {
+		return errors.New("赏金标题不能为空且不能超过200个字符")
+	}
+	if input.AcceptanceCriteria == "" {
+		return errors.New("验收标准不能为空")
+	}
+	if input.RewardAmount < 0 {
+		return errors.New("奖励金额不能为负数")
+	}
+	if input.RewardToken == "" {
+		input.RewardToken = "SOL"
+	}
+	if len(input.RewardToken) > 20 {
+		return errors.New("奖励币种不能超过20个字符")
+	}
+	if input.Reward == "" {
+		input.Reward = strconv.FormatFloat(input.RewardAmount, 'f', -1, 64) + " " + input.RewardToken
+	}
+	if len([]rune(input.Reward)) > 255 {
+		return errors.New("奖励说明不能超过255个字符")
+	}
+	if input.MaxWinners == 0 {
+		input.MaxWinners = 1
+	}
+	if input.MaxWinners < 1 || input.MaxWinners > 100 {
+		return errors.New("获奖作品数须在1到100之间")
+	}
+	return nil
+}
+
+// sponsorBounty 获取赞助商自己发布的赏金及所属活动
+func (s *BountyService) sponsorBounty(tx *gorm.DB, userID, bountyID uint64, lock bool) (*models.Sponsor, *models.SponsorBounty, *models.Hackathon, error) {
+	sponsor, err := activeSponsor(userID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	query := tx
+	if lock {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	var bounty models.SponsorBounty
+	if err := query.Where("id = ?", bountyID).First(&bounty).Error; err != nil {
+		return nil, nil, nil, errors.New("赏金不存在")
+	}
+	if bounty.SponsorID != sponsor.ID {
+		return nil, nil, nil, errors.New("只能管理自己发布的赏金")
+	}
+	var hackathon models.Hackathon
+	if err := tx.Where("id = ? AND deleted_at IS NULL", bounty.HackathonID).First(&hackathon).Error; err != nil {
+		return nil, nil, nil, errors.New("活动不存在")
+	}
+	return sponsor, &bounty, &hackathon, nil
+}
+
+// fillBountyEntryCounts 填充各赏金的参与作品数
+func fillBountyEntryCounts(bounties []models.SponsorBounty) error {
+	if len(bounties) == 0 {
+		return nil
+	}
+	ids := make([]uint64, len(bounties))
+	for i := range bounties {
+		ids[i] = bounties[i].ID
+	}
+	var rows []struct {
+		BountyID uint64
+		Count    int64
+	}
+	if err := database.DB.Model(&models.BountyEntry{}).
+		Select("bounty_id, COUNT(*) AS count").
+		Where("bounty_id IN ?", ids).
+		Group("bounty_id").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+	counts := make(map[uint64]int64, len(rows))
+	for _, row := range rows {
+		counts[row.BountyID] = row.Count
+	}
+	for i := range bounties {
+		bounties[i].EntryCount = counts[bounties[i].ID]
+	}
+	return nil
+}
+
+// listBounties 查询活动的赏金（含获奖作品与参与作品数）
+func listBounties(query *gorm.DB) ([]models.SponsorBounty, error) {
+	var bounties []models.SponsorBounty
+	if err := query.
+		Preload("Sponsor").Preload("Sponsor.User").
+		Preload("Winners", func(db *gorm.DB) *gorm.DB { return db.Order("`rank` ASC") }).
+		Preload("Winners.Submission").
+		Order("id ASC").
+		Find(&bounties).Error; err != nil {
+		return nil, err
+	}
+	if err := fillBountyEntryCounts(bounties); err != nil {
+		return nil, err
+	}
+	return bounties, nil
+}
+
+// CreateBounty 赞助商在所赞助的活动中发布赏金（提交阶段结束前）
+func (s *BountyService) CreateBounty(userID, hackathonID uint64, input BountyInput) (*models.SponsorBounty, error) {
+	sponsor, err := activeSponsor(userID)
+	if err != nil {
+		return nil, err
+	}
+	event, err := sponsoredEvent(sponsor.ID, hackathonID)
+	if err != nil {
+		return nil, err
+	}
+	if !bountyOpenStatuses[event.Hackathon.Status] {
+		return nil, errors.New("只能在提交阶段结束前发布赏金")
+	}
+	if err := validateBountyInput(&input); err != nil {
+		return nil, err
+	}
+
+	bounty := models.SponsorBounty{
+		HackathonID:        hackathonID,
+		SponsorID:          sponsor.ID,
+		Title:              input.Title,
+		Description:        input.Description,
+		AcceptanceCriteria: input.AcceptanceCriteria,
+		Reward:             input.Reward,
+		RewardAmount:       input.RewardAmount,
+		RewardToken:        input.RewardToken,
+		MaxWinners:         input.MaxWinners,
+		Status:             "open",
+	}
+	if err := database.DB.Create(&bounty).Error; err != nil {
+		return nil, err
+	}
+	return &bounty, nil
+}
+
+// GetSponsorBounties 获取赞助商在活动中发布的赏金
+func (s *BountyService) GetSponsorBounties(userID, hackathonID uint64) ([]models.SponsorBounty, error) {
+	sponsor, err := activeSponsor(userID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := sponsoredEvent(sponsor.ID, hackathonID); err != nil {
+		return nil, err
+	}
+	return listBounties(database.DB.Where("hackathon_id = ? AND sponsor_id = ?", hackathonID, sponsor.ID))
+}
+
+// UpdateBounty 修改赏金（评选前、提交阶段结束前）
+func (s *BountyService) UpdateBounty(userID, bountyID uint64, input BountyInput) (*models.SponsorBounty, error) {
+	_, bounty, hackathon, err := s.sponsorBounty(database.DB, userID, bountyID, false)
+	if err != nil {
+		return nil, err
+	}
+	if bounty.Status != "open" {
+		return nil, errors.New("赏金已评选或已取消，不能修改")
+	}
+	if !bountyOpenStatuses[hackathon.Status] {
+		return nil, errors.New("提交阶段已结束，不能修改赏金")
+	}
+	if err := validateBountyInput(&input); err != nil {
+		return nil, err
+	}
+
+	if err := database.DB.Model(bounty).Updates(map[string]interface{}{
+		"title":               input.Title,
+		"description":         input.Description,
+		"acceptance_criteria": input.AcceptanceCriteria,
+		"reward":              input.Reward,
+		"reward_amount":       input.RewardAmount,
+		"reward_token":        input.RewardToken,
+		"max_winners":         input.MaxWinners,
+	}).Error; err != nil {
+		return nil, err
+	}
+	return bounty, nil
+}
+
+// CancelBounty 取消赏金（评选前）
+func (s *BountyService) CancelBounty(userID, bountyID uint64) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		_, bounty, _, err := s.sponsorBounty(tx, userID, bountyID, true)
+		if err != nil {
+			return err
+		}
+		if bounty.Status != "open" {
+			return errors.New("赏金已评选或已取消")
+		}
+		return tx.Model(bounty).Update("status", "cancelled").Error
+	})
+}
+
+// GetBountyEntries 获取报名参加赏金的作品
+func (s *BountyService) GetBountyEntries(userID, bountyID uint64) ([]models.BountyEntry, error) {
+	if _, _, _, err := s.sponsorBounty(database.DB, userID, bountyID, false); err != nil {
+		return nil, err
+	}
+	var entries []models.BountyEntry
+	if err := database.DB.Preload("Submission").
+		Where("bounty_id = ?", bountyID).
+		Order("id ASC").
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// AwardBounty 赞助商从报名的作品中评选获奖作品（提交阶段结束后），按提交顺序确定名次，奖励待发放
+func (s *BountyService) AwardBounty(userID, bountyID uint64, inputs []BountyWinnerInput) ([]models.BountyWinner, error) {
+	var winners []models.BountyWinner
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		sponsor, bounty, hackathon, err := s.sponsorBounty(tx, userID, bountyID, true)
+		if err != nil {
+			return err
+		}
+		if bounty.Status != "open" {
+			return errors.New("赏金已评选或已取消")
+		}
+		if hackathon.Status != "voting" && hackathon.Status != "results" {
+			return errors.New("提交阶段结束后才能评选赏金")
+		}
+		if len(inputs) == 0 {
+			return errors.New("请选择获奖作品")
+		}
+		if len(inputs) > bounty.MaxWinners {
+			return fmt.Errorf("最多评选%d个获奖作品", bounty.MaxWinners)
+		}
+
+		seen := make(map[uint64]bool, len(inputs))
+		for i, input := range inputs {
+			if seen[input.SubmissionID] {
+				return errors.New("获奖作品不能重复")
+			}
+			seen[input.SubmissionID] = true
+			if len([]rune(input.Note)) > 500 {
+				return errors.New("评语不能超过500个字符")
+			}
+
+			var entry models.BountyEntry
+			if err := tx.Where("bounty_id = ? AND submission_id = ?", bountyID, input.SubmissionID).First(&entry).Error; err != nil {
+				return fmt.Errorf("作品 %d 未报名该赏金", input.SubmissionID)
+			}
+			var submission models.Submission
+			if err := tx.Where("id = ?", input.SubmissionID).First(&submission).Error; err != nil {
+				return fmt.Errorf("作品 %d 不存在", input.SubmissionID)
+			}
+			if submission.Draft == 1 {
+				return fmt.Errorf("作品「%s」尚未正式提交，不能获奖", submission.Name)
+			}
+			var team models.Team
+			if err := tx.Preload("Leader").Where("id = ?", submission.TeamID).First(&team).Error; err != nil {
+				return fmt.Errorf("作品「%s」的队伍不存在", submission.Name)
+			}
+
+			winner := models.BountyWinner{
+				BountyID:        bountyID,
+				SubmissionID:    submission.ID,
+				TeamID:          team.ID,
+				Rank:            i + 1,
+				RewardAmount:    bounty.RewardAmount,
+				RewardToken:     bounty.RewardToken,
+				RecipientWallet: team.Leader.WalletAddress,
+				PayoutStatus:    "pending",
+				Note:            strings.TrimSpace(input.Note),
+			}
+			if err := tx.Create(&winner).Error; err != nil {
+				return err
+			}
+			winners = append(winners, winner)
+
+			var memberIDs []uint64
+			if err := tx.Model(&models.TeamMember{}).Where("team_id = ?", team.ID).Pluck("participant_id", &memberIDs).Error; err != nil {
+				return err
+			}
+			if err := notifyParticipants(tx, memberIDs, hackathon.ID, NotifyBountyAwarded, map[string]interface{}{
+				"HackathonName":  hackathon.Name,
+				"TeamName":       team.Name,
+				"SubmissionName": submission.Name,
+				"SponsorName":    sponsor.User.Name,
+				"BountyTitle":    bounty.Title,
+				"Reward":         bounty.Reward,
+				"Wallet":         winner.RecipientWallet,
+			}); err != nil {
+				return fmt.Errorf("写入通知失败: %w", err)
+			}
+		}
+
+		return tx.Model(bounty).Updates(map[string]interface{}{
+			"status":     "awarded",
+			"awarded_at": time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return winners, nil
+}
+
+// checkPayoutTxReuse 检查同一笔交易是否已登记为同一收款钱包的其他奖励
+func checkPayoutTxReuse(db *gorm.DB, winner *models.BountyWinner, txHash string) error {
+	var reused []uint64
+	if err := db.Model(&models.BountyWinner{}).
+		Where("payout_tx_hash = ? AND recipient_wallet = ? AND id <> ?", txHash, winner.RecipientWallet, winner.ID).
+		Pluck("id", &reused).Error; err != nil {
+		return err
+	}
+	if len(reused) > 0 {
+		return errors.New("该交易已登记为其他奖励的发放记录")
+	}
+	return nil
+}
+
+// MarkPayoutPaid 赞助商登记赏金奖励已发放（链上交易签名），通过 RPC 校验交易已成功执行且向收款钱包转入了奖励金额
+func (s *BountyService) MarkPayoutPaid(userID, bountyID, winnerID uint64, txHash string) (*models.BountyWinner, error) {
+	txHash = strings.TrimSpace(txHash)
+	if !solana.ValidSignature(txHash) {
+		return nil, errors.New("无效的交易签名")
+	}
+
+	// 链上校验较慢，在事务外进行；事务中再确认收款钱包与金额未变化
+	var pending models.BountyWinner
+	if _, _, _, err := s.sponsorBounty(database.DB, userID, bountyID, false); err != nil {
+		return nil, err
+	}
+	if err := database.DB.Where("id = ? AND bounty_id = ?", winnerID, bountyID).First(&pending).Error; err != nil {
+		return nil, errors.New("获奖记录不存在")
+	}
+	if pending.PayoutStatus == "paid" {
+		return nil, errors.New("奖励已发放")
+	}
+	if err := checkPayoutTxReuse(database.DB, &pending, txHash); err != nil {
+		return nil, err
+	}
+	if err := verifyPayoutTransfer(&pending, txHash); err != nil {
+		return nil, err
+	}
+
+	var winner models.BountyWinner
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		sponsor, bounty, hackathon, err := s.sponsorBounty(tx, userID, bountyID, false)
+		if err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND bounty_id = ?", winnerID, bountyID).First(&winner).Error; err != nil {
+			return errors.New("获奖记录不存在")
+		}
+		if winner.PayoutStatus == "paid" {
+			return errors.New("奖励已发放")
+		}
+		if winner.RecipientWallet != pending.RecipientWallet || winner.RewardAmount != pending.RewardAmount || winner.RewardToken != pending.RewardToken {
+			return errors.New("获奖记录已变更，请重新登记")
+		}
+		// 加锁重新检查，避免并发登记同一笔交易
+		if err := checkPayoutTxReuse(tx.Clauses(clause.Locking{Strength: "UPDATE"}), &winner, txHash); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(&winner).Updates(map[string]interface{}{
+			"payout_status":  "paid",
+			"payout_tx_hash": txHash,
+			"paid_at":        now,
+		}).Error; err != nil {
+			return err
+		}
+
+		var memberIDs []uint64
+		if err := tx.Model(&models.TeamMember{}).Where("team_id = ?", winner.TeamID).Pluck("participant_id", &memberIDs).Error; err != nil {
+			return err
+		}
+		if err := notifyParticipants(tx, memberIDs, hackathon.ID, NotifyBountyPaid, map[string]interface{}{
+			"SponsorName": sponsor.User.Name,
+			"BountyTitle": bounty.Title,
+			"Reward":      bounty.Reward,
+			"Wallet":      winner.RecipientWallet,
+			"TxHash":      txHash,
+		}); err != nil {
+			return fmt.Errorf("写入通知失败: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &winner, nil
+}
+
+// verifyPayoutTransfer 校验发放交易：SOL 奖励按收款钱包余额变化校验，其他币种按配置的 mint 校验代币转账
+func verifyPayoutTransfer(winner *models.BountyWinner, txHash string) error {
+	rpcURL := strings.TrimSpace(config.AppConfig.Solana.RPCURL)
+	if rpcURL == "" {
+		return errors.New("未配置 Solana RPC，无法校验发放交易")
+	}
+	mint := ""
+	if !strings.EqualFold(winner.RewardToken, "SOL") {
+		mint = config.AppConfig.Solana.TokenMints[strings.ToUpper(winner.RewardToken)]
+		if mint == "" {
+			return fmt.Errorf("未配置 %s 的代币地址，无法校验发放交易", winner.RewardToken)
+		}
+	}
+	return solana.VerifyTransfer(rpcURL, txHash, winner.RecipientWallet, mint, winner.RewardAmount)
+}
+
+// GetHackathonBounties 获取活动的赏金列表（不含已取消的）
+func (s *BountyService) GetHackathonBounties(hackathonID uint64) ([]models.SponsorBounty, error) {
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
+		return nil, errors.New("活动不存在")
+	}
+	return listBounties(database.DB.Where("hackathon_id = ? AND status <> ?", hackathonID, "cancelled"))
+}
+
+// GetHackathonBountyPayouts 获取活动全部赏金及奖励发放情况（活动创建者或Admin）
+func (s *BountyService) GetHackathonBountyPayouts(hackathonID, userID uint64, userRole string) ([]models.SponsorBounty, error) {
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
+		return nil, errors.New("活动不存在")
+	}
+	if userRole != "admin" && hackathon.OrganizerID != userID {
+		return nil, errors.New("只能查看自己创建活动的赏金")
+	}
+	return listBounties(database.DB.Where("hackathon_id = ?", hackathonID))
+}
+
+// teamSubmission 获取参赛者所在队伍的作品
+func (s *BountyService) teamSubmission(submissionID, participantID uint64) (*models.Submission, error) {
+	var submission models.Submission
+	if err := database.DB.Where("id = ?", submissionID).First(&submission).Error; err != nil {
+		return nil, errors.New("作品不存在")
+	}
+	var member models.TeamMember
+	if err := database.DB.Where("team_id = ? AND participant_id = ?", submission.TeamID, participantID).First(&member).Error; err != nil {
+		return nil, errors.New("您没有权限修改此作品")
+	}
+	return &submission, nil
+}
+
+// GetSubmissionBounties 获取作品已报名的赏金
+func (s *BountyService) GetSubmissionBounties(submissionID uint64) ([]models.SponsorBounty, error) {
+	return listBounties(database.DB.
+		Where("id IN (?)", database.DB.Model(&models.BountyEntry{}).Select("bounty_id").Where("submission_id = ?", submissionID)))
+}
+
+// JoinBounty 队伍成员将作品报名参加赏金（提交阶段内）
+func (s *BountyService) JoinBounty(submissionID, bountyID, participantID uint64) error {
+	submission, err := s.teamSubmission(submissionID, participantID)
+	if err != nil {
+		return err
+	}
+	var bounty models.SponsorBounty
+	if err := database.DB.Where("id = ? AND hackathon_id = ?", bountyID, submission.HackathonID).First(&bounty).Error; err != nil {
+		return errors.New("赏金不存在")
+	}
+	if bounty.Status != "open" {
+		return errors.New("赏金已评选或已取消")
+	}
+	if _, err := (&SubmissionService{}).checkSubmissionEditable(submission.HackathonID, submission.TeamID); err != nil {
+		return err
+	}
+
+	var count int64
+	if err := database.DB.Model(&models.BountyEntry{}).
+		Where("bounty_id = ? AND submission_id = ?", bountyID, submissionID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("已报名该赏金")
+	}
+
+	entry := models.BountyEntry{
+		BountyID:     bountyID,
+		SubmissionID: submissionID,
+		TeamID:       submission.TeamID,
+		CreatedBy:    participantID,
+	}
+	return database.DB.Create(&entry).Error
+}
+
+// LeaveBounty 队伍成员撤回作品的赏金报名（提交阶段内）
+func (s *BountyService) LeaveBounty(submissionID, bountyID, participantID uint64) error {
+	submission, err := s.teamSubmission(submissionID, participantID)
+	if err != nil {
+		return err
+	}
+	var bounty models.SponsorBounty
+	if err := database.DB.Where("id = ? AND hackathon_id = ?", bountyID, submission.HackathonID).First(&bounty).Error; err != nil {
+		return errors.New("赏金不存在")
+	}
+	if bounty.Status != "open" {
+		return errors.New("赏金已评选或已取消")
+	}
+	if _, err := (&SubmissionService{}).checkSubmissionEditable(submission.HackathonID, submission.TeamID); err != nil {
+		return err
+	}
+
+	result := database.DB.Where("bounty_id = ? AND submission_id = ?", bountyID, submissionID).Delete(&models.BountyEntry{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("未报名该赏金")
+	}
+	return nil
+}
@@ -0,0 +1,537 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"hackathon-backend/database"
+	"hackathon-backend/models"
+	"hackathon-backend/solana"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EligibilityService 报名资格规则：代币/NFT 持有、钱包白名单、往届参赛、邀请码
+type EligibilityService struct{}
+
+const (
+	eligibilityMaxRules  = 20      // 每个活动最多资格规则数
+	allowlistMaxFileSize = 5 << 20 // 白名单 CSV 文件大小上限
+	allowlistMaxRows     = 100000  // 白名单 CSV 最多行数
+	inviteCodeMaxBatch   = 500     // 每次最多生成邀请码数
+)
+
+var evmAddressPattern = regexp.MustCompile(`^0x[0-9a-f]{40}$`)
+
+// eligibilityRuleCost 规则校验顺序：先查库，最后查链上 RPC
+var eligibilityRuleCost = map[string]int{
+	"allowlist":        0,
+	"prior_attendance": 1,
+	"invite_code":      2,
+	"token_balance":    3,
+	"nft_collection":   4,
+}
+
+// normalizeWalletAddress 统一钱包地址格式（EVM 地址转为小写）
+func normalizeWalletAddress(address string) string {
+	address = strings.TrimSpace(address)
+	if strings.HasPrefix(strings.ToLower(address), "0x") {
+		return strings.ToLower(address)
+	}
+	return address
+}
+
+// validWalletAddress 检查是否为有效的 EVM 或 Solana 钱包地址（已统一格式）
+func validWalletAddress(address string) bool {
+	return evmAddressPattern.MatchString(address) || solana.ValidAddress(address)
+}
+
+// checkEligibilityViewer 检查用户是否可以查看活动报名资格配置（活动创建者或Admin）
+func (s *EligibilityService) checkEligibilityViewer(hackathonID, userID uint64, userRole string) error {
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
+		return errors.New("活动不存在")
+	}
+	if userRole != "admin" && hackathon.OrganizerID != userID {
+		return errors.New("只能查看自己创建活动的报名资格")
+	}
+	return nil
+}
+
+// checkEligibilityManager 检查用户是否可以修改活动报名资格配置（仅活动创建者，报名结束前）
+func (s *EligibilityService) checkEligibilityManager(hackathonID, userID uint64, userRole string) (*models.Hackathon, error) {
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
+		return nil, errors.New("活动不存在")
+	}
+	if userRole == "admin" {
+		return nil, errors.New("Admin不能修改报名资格")
+	}
+	if hackathon.OrganizerID != userID {
+		return nil, errors.New("只能修改自己创建活动的报名资格")
+	}
+	if hackathon.Status != "preparation" && hackathon.Status != "published" && hackathon.Status != "registration" {
+		return nil, errors.New("报名已结束，不能修改报名资格")
+	}
+	return &hackathon, nil
+}
+
+// getEligibilityRules 获取活动的报名资格规则
+func getEligibilityRules(hackathonID uint64) ([]models.EligibilityRule, error) {
+	var rules []models.EligibilityRule
+	if err := database.DB.Where("hackathon_id = ?", hackathonID).Order("id ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// GetEligibility 获取活动报名资格配置（规则、白名单人数、邀请码）
+func (s *EligibilityService) GetEligibility(hackathonID, userID uint64, userRole string) (map[string]interface{}, error) {
+	if err := s.checkEligibilityViewer(hackathonID, userID, userRole); err != nil {
+		return nil, err
+	}
+
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ?", hackathonID).First(&hackathon).Error; err != nil {
+		return nil, errors.New("活动不存在")
+	}
+	rules, err := getEligibilityRules(hackathonID)
+	if err != nil {
+		return nil, err
+	}
+	var allowlistCount int64
+	if err := database.DB.Model(&models.AllowlistEntry{}).Where("hackathon_id = ?", hackathonID).Count(&allowlistCount).Error; err != nil {
+		return nil, err
+	}
+	var codes []models.RegistrationInviteCode
+	if err := database.DB.Where("hackathon_id = ?", hackathonID).Order("id DESC").Find(&codes).Error; err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"match":           hackathon.EligibilityMatch,
+		"rules":           rules,
+		"allowlist_count": allowlistCount,
+		"invite_codes":    codes,
+	}, nil
+}
+
+// SetEligibility 设置活动报名资格规则（覆盖原有规则），match 为 all（须满足全部）或 any（满足任一）
+func (s *EligibilityService) SetEligibility(hackathonID uint64, match string, rules []models.EligibilityRule, userID uint64, userRole string) error {
+	if _, err := s.checkEligibilityManager(hackathonID, userID, userRole); err != nil {
+		return err
+	}
+	if match == "" {
+		match = "all"
+	}
+	if match != "all" && match != "any" {
+		return errors.New("规则组合方式必须是 all 或 any")
+	}
+	if len(rules) > eligibilityMaxRules {
+		return fmt.Errorf("报名资格规则最多 %d 条", eligibilityMaxRules)
+	}
+
+	for i := range rules {
+		rule := &rules[i]
+		rule.Address = strings.TrimSpace(rule.Address)
+		rule.Description = strings.TrimSpace(rule.Description)
+		if utf8.RuneCountInString(rule.Description) > 255 {
+			return errors.New("规则说明不能超过255个字符")
+		}
+		switch rule.Type {
+		case "token_balance":
+			if !solana.ValidAddress(rule.Address) {
+				return errors.New("代币 mint 地址无效")
+			}
+			if rule.MinAmount <= 0 {
+				return errors.New("代币最低持有数量必须大于 0")
+			}
+		case "nft_collection":
+			if !solana.ValidAddress(rule.Address) {
+				return errors.New("NFT 合集地址无效")
+			}
+			rule.MinAmount = 0
+		case "prior_attendance":
+			if rule.PriorHackathonID != nil {
+				if *rule.PriorHackathonID == hackathonID {
+					return errors.New("往届活动不能是当前活动")
+				}
+				var count int64
+				database.DB.Model(&models.Hackathon{}).Where("id = ? AND deleted_at IS NULL", *rule.PriorHackathonID).Count(&count)
+				if count == 0 {
+					return errors.New("指定的往届活动不存在")
+				}
+			}
+			rule.Address, rule.MinAmount = "", 0
+		case "allowlist", "invite_code":
+			rule.Address, rule.MinAmount = "", 0
+		default:
+			return errors.New("无效的报名资格规则类型")
+		}
+		if rule.Type != "prior_attendance" {
+			rule.PriorHackathonID = nil
+		}
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("hackathon_id = ?", hackathonID).Delete(&models.EligibilityRule{}).Error; err != nil {
+			return err
+		}
+		for i := range rules {
+			rules[i].ID = 0
+			rules[i].HackathonID = hackathonID
+			if err := tx.Create(&rules[i]).Error; err != nil {
+				return fmt.Errorf("创建报名资格规则失败: %w", err)
+			}
+		}
+		return tx.Model(&models.Hackathon{}).Where("id = ?", hackathonID).Update("eligibility_match", match).Error
+	})
+}
+
+// ImportAllowlist 从 CSV 导入钱包白名单（每行第一列为钱包地址，可带表头），replace 为 true 时覆盖原有白名单，返回新增数量
+func (s *EligibilityService) ImportAllowlist(hackathonID uint64, header *multipart.FileHeader, replace bool, userID uint64, userRole string) (int64, error) {
+	if _, err := s.checkEligibilityManager(hackathonID, userID, userRole); err != nil {
+		return 0, err
+	}
+	if header.Size > allowlistMaxFileSize {
+		return 0, errors.New("白名单文件不能超过 5MB")
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	wallets := make([]string, 0)
+	seen := make(map[string]bool)
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("第 %d 行格式错误", line)
+		}
+		if len(record) == 0 {
+			continue
+		}
+		address := normalizeWalletAddress(strings.TrimPrefix(record[0], "\ufeff"))
+		if address == "" {
+			continue
+		}
+		if !validWalletAddress(address) {
+			// 第一行可以是表头
+			if line == 1 {
+				continue
+			}
+			return 0, fmt.Errorf("第 %d 行钱包地址无效：%s", line, address)
+		}
+		if seen[address] {
+			continue
+		}
+		seen[address] = true
+		wallets = append(wallets, address)
+		if len(wallets) > allowlistMaxRows {
+			return 0, fmt.Errorf("白名单最多 %d 个钱包", allowlistMaxRows)
+		}
+	}
+	if len(wallets) == 0 {
+		return 0, errors.New("文件中没有有效的钱包地址")
+	}
+
+	var imported int64
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if replace {
+			if err := tx.Where("hackathon_id = ?", hackathonID).Delete(&models.AllowlistEntry{}).Error; err != nil {
+				return err
+			}
+		}
+		entries := make([]models.AllowlistEntry, len(wallets))
+		for i, wallet := range wallets {
+			entries[i] = models.AllowlistEntry{HackathonID: hackathonID, WalletAddress: wallet}
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(entries, 500)
+		imported = result.RowsAffected
+		return result.Error
+	})
+	return imported, err
+}
+
+// GetAllowlist 分页获取活动钱包白名单
+func (s *EligibilityService) GetAllowlist(hackathonID uint64, page, pageSize int, keyword string, userID uint64, userRole string) ([]models.AllowlistEntry, int64, error) {
+	if err := s.checkEligibilityViewer(hackathonID, userID, userRole); err != nil {
+		return nil, 0, err
+	}
+
+	query := database.DB.Model(&models.AllowlistEntry{}).Where("hackathon_id = ?", hackathonID)
+	if keyword != "" {
+		query = query.Where("wallet_address LIKE ?", "%"+normalizeWalletAddress(keyword)+"%")
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var entries []models.AllowlistEntry
+	offset := (page - 1) * pageSize
+	if err := query.Order("id ASC").Offset(offset).Limit(pageSize).Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// ClearAllowlist 清空活动钱包白名单
+func (s *EligibilityService) ClearAllowlist(hackathonID, userID uint64, userRole string) error {
+	if _, err := s.checkEligibilityManager(hackathonID, userID, userRole); err != nil {
+		return err
+	}
+	return database.DB.Where("hackathon_id = ?", hackathonID).Delete(&models.AllowlistEntry{}).Error
+}
+
+// CreateInviteCodes 批量生成报名邀请码，maxUses 为 0 表示不限次数，expiresAt 为空表示不过期
+func (s *EligibilityService) CreateInviteCodes(hackathonID uint64, count, maxUses int, expiresAt *time.Time, userID uint64, userRole string) ([]models.RegistrationInviteCode, error) {
+	if _, err := s.checkEligibilityManager(hackathonID, userID, userRole); err != nil {
+		return nil, err
+	}
+	if count <= 0 || count > inviteCodeMaxBatch {
+		return nil, fmt.Errorf("每次可生成 1-%d 个邀请码", inviteCodeMaxBatch)
+	}
+	if maxUses < 0 {
+		return nil, errors.New("邀请码使用次数不能为负数")
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, errors.New("邀请码过期时间必须晚于当前时间")
+	}
+
+	codes := make([]models.RegistrationInviteCode, count)
+	for i := range codes {
+		code, err := generateInvitationCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = models.RegistrationInviteCode{
+			HackathonID: hackathonID,
+			Code:        strings.ToUpper(code),
+			MaxUses:     maxUses,
+			ExpiresAt:   expiresAt,
+		}
+	}
+	if err := database.DB.Create(&codes).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DeleteInviteCode 删除报名邀请码
+func (s *EligibilityService) DeleteInviteCode(hackathonID, codeID, userID uint64, userRole string) error {
+	if _, err := s.checkEligibilityManager(hackathonID, userID, userRole); err != nil {
+		return err
+	}
+	result := database.DB.Where("id = ? AND hackathon_id = ?", codeID, hackathonID).Delete(&models.RegistrationInviteCode{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("邀请码不存在")
+	}
+	return nil
+}
+
+// evaluateEligibilityRule 校验单条规则，通过时返回空原因；邀请码规则通过时同时返回需核销的邀请码。
+// 数据库查询失败时返回错误，不视为不满足条件
+func evaluateEligibilityRule(rule *models.EligibilityRule, hackathon *models.Hackathon, participant *models.Participant, inviteCode string) (string, *models.RegistrationInviteCode, error) {
+	switch rule.Type {
+	case "allowlist":
+		var count int64
+		if err := database.DB.Model(&models.AllowlistEntry{}).
+			Where("hackathon_id = ? AND wallet_address = ?", hackathon.ID, normalizeWalletAddress(participant.WalletAddress)).
+			Count(&count).Error; err != nil {
+			return "", nil, fmt.Errorf("查询活动白名单失败: %w", err)
+		}
+		if count == 0 {
+			return "您的钱包不在活动白名单中", nil, nil
+		}
+
+	case "prior_attendance":
+		query := database.DB.Model(&models.Checkin{}).Where("participant_id = ? AND hackathon_id <> ?", participant.ID, hackathon.ID)
+		if rule.PriorHackathonID != nil {
+			query = query.Where("hackathon_id = ?", *rule.PriorHackathonID)
+		}
+		var count int64
+		if err := query.Count(&count).Error; err != nil {
+			return "", nil, fmt.Errorf("查询签到记录失败: %w", err)
+		}
+		if count > 0 {
+			return "", nil, nil
+		}
+		if rule.PriorHackathonID != nil {
+			var prior models.Hackathon
+			if err := database.DB.Unscoped().Where("id = ?", *rule.PriorHackathonID).First(&prior).Error; err == nil {
+				return fmt.Sprintf("需参加过活动「%s」并完成签到", prior.Name), nil, nil
+			}
+		}
+		return "需参加过本平台的其他活动并完成签到", nil, nil
+
+	case "invite_code":
+		inviteCode = strings.ToUpper(strings.TrimSpace(inviteCode))
+		if inviteCode == "" {
+			return "需要填写邀请码", nil, nil
+		}
+		var code models.RegistrationInviteCode
+		if err := database.DB.Where("hackathon_id = ? AND code = ?", hackathon.ID, inviteCode).First(&code).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return "邀请码无效", nil, nil
+			}
+			return "", nil, fmt.Errorf("查询邀请码失败: %w", err)
+		}
+		if code.ExpiresAt != nil && time.Now().After(*code.ExpiresAt) {
+			return "邀请码已过期", nil, nil
+		}
+		if code.MaxUses > 0 && code.UsedCount >= code.MaxUses {
+			return "邀请码已被用完", nil, nil
+		}
+		return "", &code, nil
+
+	case "token_balance", "nft_collection":
+		if participant.WalletType != "phantom" {
+			return "该规则要求使用 Solana 钱包（Phantom）报名", nil, nil
+		}
+		_, rpcURL, err := solana.PreparePublishConfig()
+		if err != nil {
+			return "暂时无法校验链上资产，请稍后重试", nil, nil
+		}
+		if rule.Type == "token_balance" {
+			balance, err := solana.FetchTokenBalance(rpcURL, participant.WalletAddress, rule.Address)
+			if err != nil {
+				return "暂时无法查询链上代币余额，请稍后重试", nil, nil
+			}
+			if balance < rule.MinAmount {
+				return fmt.Sprintf("需持有至少 %s 个代币 %s（当前 %s）",
+					strconv.FormatFloat(rule.MinAmount, 'f', -1, 64), rule.Address, strconv.FormatFloat(balance, 'f', -1, 64)), nil, nil
+			}
+			return "", nil, nil
+		}
+		owns, err := solana.OwnsCollectionNFT(rpcURL, participant.WalletAddress, rule.Address)
+		if err != nil {
+			return "暂时无法查询链上 NFT，请稍后重试", nil, nil
+		}
+		if !owns {
+			return fmt.Sprintf("需持有 NFT 合集 %s 中的 NFT", rule.Address), nil, nil
+		}
+	}
+	return "", nil, nil
+}
+
+// checkEligibility 按活动规则校验参赛者的报名资格，不满足时返回说明原因的错误；
+// 通过邀请码满足资格时返回需在报名时核销的邀请码
+func checkEligibility(hackathon *models.Hackathon, participant *models.Participant, inviteCode string) (*models.RegistrationInviteCode, error) {
+	rules, err := getEligibilityRules(hackathon.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		return eligibilityRuleCost[rules[i].Type] < eligibilityRuleCost[rules[j].Type]
+	})
+
+	matchAny := hackathon.EligibilityMatch == "any"
+	var invite *models.RegistrationInviteCode
+	reasons := make([]string, 0, len(rules))
+	for i := range rules {
+		reason, code, err := evaluateEligibilityRule(&rules[i], hackathon, participant, inviteCode)
+		if err != nil {
+			return nil, err
+		}
+		if reason == "" {
+			if matchAny {
+				return code, nil
+			}
+			if code != nil {
+				invite = code
+			}
+			continue
+		}
+		if rules[i].Description != "" {
+			reason = rules[i].Description + "：" + reason
+		}
+		if !matchAny {
+			return nil, errors.New("不符合报名条件：" + reason)
+		}
+		reasons = append(reasons, reason)
+	}
+	if matchAny {
+		return nil, errors.New("不符合报名条件（满足任一即可）：" + strings.Join(reasons, "；"))
+	}
+	return invite, nil
+}
+
+// consumeInviteCode 报名成功时核销邀请码（并发报名时以数据库条件更新保证不超过使用次数）
+func consumeInviteCode(tx *gorm.DB, code *models.RegistrationInviteCode) error {
+	if code == nil {
+		return nil
+	}
+	result := tx.Model(&models.RegistrationInviteCode{}).
+		Where("id = ? AND (max_uses = 0 OR used_count < max_uses)", code.ID).
+		Update("used_count", gorm.Expr("used_count + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("不符合报名条件：邀请码已被用完")
+	}
+	return nil
+}
+
+// releaseInviteCode 归还已核销的邀请码使用次数（候补递补过期、退出候补、取消报名或报名审核未通过时）
+func releaseInviteCode(tx *gorm.DB, codeID *uint64) error {
+	if codeID == nil {
+		return nil
+	}
+	return tx.Model(&models.RegistrationInviteCode{}).
+		Where("id = ? AND used_count > 0", *codeID).
+		Update("used_count", gorm.Expr("used_count - 1")).Error
+}
+
+// CheckParticipantEligibility 预先检查参赛者是否满足活动的报名资格（不校验邀请码），返回规则与不满足的原因
+func (s *EligibilityService) CheckParticipantEligibility(hackathonID, participantID uint64) (map[string]interface{}, error) {
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
+		return nil, errors.New("活动不存在")
+	}
+	var participant models.Participant
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", participantID).First(&participant).Error; err != nil {
+		return nil, errors.New("参赛者不存在")
+	}
+	rules, err := getEligibilityRules(hackathonID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{
+		"match":    hackathon.EligibilityMatch,
+		"rules":    rules,
+		"eligible": true,
+	}
+	if _, err := checkEligibility(&hackathon, &participant, ""); err != nil {
+		result["eligible"] = false
+		result["reason"] = err.Error()
+	}
+	return result, nil
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"hackathon-backend/database"
+	"hackathon-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// ExtensionService 迟交规则与队伍提交延期
+type ExtensionService struct{}
+
+// ValidateLatePolicy 校验活动的迟交规则
+func ValidateLatePolicy(hackathon *models.Hackathon) error {
+	if hackathon.LateGraceMinutes < 0 {
+		return errors.New("迟交宽限时间不能为负数")
+	}
+	if hackathon.LatePenalty < 0 || hackathon.LatePenalty > 1 {
+		return errors.New("迟交扣分比例必须在 0 到 1 之间")
+	}
+	return nil
+}
+
+// SetLatePolicy 设置活动的迟交规则：截止后的宽限时间（分钟）与迟交扣分比例（仅活动创建者，结果公布前）
+func (s *ExtensionService) SetLatePolicy(hackathonID uint64, graceMinutes int, penalty float64, userID uint64, userRole string) error {
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
+		return errors.New("活动不存在")
+	}
+	if userRole == "admin" {
+		return errors.New("Admin不能设置迟交规则")
+	}
+	if hackathon.OrganizerID != userID {
+		return errors.New("只能设置自己创建活动的迟交规则")
+	}
+	if hackathon.Status == "results" {
+		return errors.New("结果已公布，不能修改迟交规则")
+	}
+
+	policy := models.Hackathon{LateGraceMinutes: graceMinutes, LatePenalty: penalty}
+	if err := ValidateLatePolicy(&policy); err != nil {
+		return err
+	}
+	return database.DB.Model(&hackathon).Updates(map[string]interface{}{
+		"late_grace_minutes": graceMinutes,
+		"late_penalty":       penalty,
+	}).Error
+}
+
+// checkSubmissionWindow 检查队伍当前是否可以提交或修改作品：
+// 截止时间为提交阶段结束时间，队伍有延期时以延期时间为准；截止后的宽限期内仍可提交，但返回 late=true
+func checkSubmissionWindow(hackathon *models.Hackathon, teamID uint64) (bool, error) {
+	var stage models.HackathonStage
+	if err := database.DB.Where("hackathon_id = ? AND stage = ?", hackathon.ID, "submission").First(&stage).Error; err != nil {
+		return false, errors.New("提交阶段时间未设置")
+	}
+
+	now := time.Now()
+	if now.Before(stage.StartTime) {
+		return false, errors.New("不在提交时间范围内")
+	}
+
+	deadline := stage.EndTime
+	var extension models.SubmissionExtension
+	if err := database.DB.Where("hackathon_id = ? AND team_id = ?", hackathon.ID, teamID).First(&extension).Error; err == nil &&
+		extension.ExtendedUntil.After(deadline) {
+		deadline = extension.ExtendedUntil
+	}
+
+	if now.Before(deadline) {
+		return false, nil
+	}
+	if now.Before(deadline.Add(time.Duration(hackathon.LateGraceMinutes) * time.Minute)) {
+		return true, nil
+	}
+	if hackathon.LateGraceMinutes > 0 {
+		return false, errors.New("提交已截止（宽限期已过）")
+	}
+	return false, errors.New("不在提交时间范围内")
+}
+
+// pendingSubmissionDeadline 返回仍未结束的队伍延期或迟交宽限期的最晚截止时间，没有时返回 nil。
+// 提交阶段结束时间之前且无队伍延期时主办方可正常提前切换，不视为未结束
+func pendingSubmissionDeadline(hackathon *models.Hackathon) (*time.Time, error) {
+	var stage models.HackathonStage
+	if err := database.DB.Where("hackathon_id = ? AND stage = ?", hackathon.ID, "submission").First(&stage).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	grace := time.Duration(hackathon.LateGraceMinutes) * time.Minute
+	now := time.Now()
+	var deadline *time.Time
+	if end := stage.EndTime.Add(grace); !now.Before(stage.EndTime) && now.Before(end) {
+		deadline = &end
+	}
+
+	var latest models.SubmissionExtension
+	err := database.DB.Where("hackathon_id = ?", hackathon.ID).Order("extended_until DESC").First(&latest).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		if end := latest.ExtendedUntil.Add(grace); now.Before(end) && (deadline == nil || end.After(*deadline)) {
+			deadline = &end
+		}
+	}
+	return deadline, nil
+}
+
+// markSubmissionLate 将作品标记为迟交（保留首次迟交时间）
+func markSubmissionLate(tx *gorm.DB, submissionID uint64) error {
+	return tx.Model(&models.Submission{}).Where("id = ? AND late = ?", submissionID, false).
+		Updates(map[string]interface{}{"late": true, "late_at": time.Now()}).Error
+}
+
+// checkExtensionManager 检查用户是否可以管理活动的提交延期（仅活动创建者，投票开始前）
+func (s *ExtensionService) checkExtensionManager(hackathonID, userID uint64, userRole string) (*models.Hackathon, error) {
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
+		return nil, errors.New("活动不存在")
+	}
+	if userRole == "admin" {
+		return nil, errors.New("Admin不能管理提交延期")
+	}
+	if hackathon.OrganizerID != userID {
+		return nil, errors.New("只能管理自己创建活动的提交延期")
+	}
+	if hackathon.Status == "voting" || hackathon.Status == "results" {
+		return nil, errors.New("提交阶段已结束，不能调整提交延期")
+	}
+	return &hackathon, nil
+}
+
+// GrantExtension 为队伍延长提交截止时间（已有延期时覆盖），须填写原因
+func (s *ExtensionService) GrantExtension(hackathonID, teamID uint64, extendedUntil time.Time, reason string, userID uint64, userRole string) (*models.SubmissionExtension, error) {
+	if _, err := s.checkExtensionManager(hackathonID, userID, userRole); err != nil {
+		return nil, err
+	}
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.New("请填写延期原因")
+	}
+	if utf8.RuneCountInString(reason) > 500 {
+		return nil, errors.New("延期原因不能超过500个字符")
+	}
+
+	var team models.Team
+	if err := database.DB.Where("id = ? AND hackathon_id = ? AND deleted_at IS NULL", teamID, hackathonID).First(&team).Error; err != nil {
+		return nil, errors.New("队伍不存在")
+	}
+
+	var stage models.HackathonStage
+	if err := database.DB.Where("hackathon_id = ? AND stage = ?", hackathonID, "submission").First(&stage).Error; err != nil {
+		return nil, errors.New("提交阶段时间未设置")
+	}
+	if !extendedUntil.After(stage.EndTime) {
+		return nil, errors.New("延期时间必须晚于提交阶段结束时间")
+	}
+
+	var extension models.SubmissionExtension
+	err := database.DB.Where("hackathon_id = ? AND team_id = ?", hackathonID, teamID).First(&extension).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	extension.HackathonID = hackathonID
+	extension.TeamID = teamID
+	extension.ExtendedUntil = extendedUntil
+	extension.Reason = reason
+	extension.GrantedBy = userID
+	if err := database.DB.Save(&extension).Error; err != nil {
+		return nil, err
+	}
+	return &extension, nil
+}
+
+// RevokeExtension 取消队伍的提交延期
+func (s *ExtensionService) RevokeExtension(hackathonID, teamID, userID uint64, userRole string) error {
+	if _, err := s.checkExtensionManager(hackathonID, userID, userRole); err != nil {
+		return err
+	}
+	result := database.DB.Where("hackathon_id = ? AND team_id = ?", hackathonID, teamID).Delete(&models.SubmissionExtension{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("该队伍没有提交延期")
+	}
+	return nil
+}
+
+// GetExtensions 获取活动的提交延期列表
+func (s *ExtensionService) GetExtensions(hackathonID uint64) ([]models.SubmissionExtension, error) {
+	var extensions []models.SubmissionExtension
+	if err := database.DB.Where("hackathon_id = ?", hackathonID).Preload("Team").
+		Order("extended_until ASC, id ASC").Find(&extensions).Error; err != nil {
+		return nil, err
+	}
+	return extensions, nil
+}
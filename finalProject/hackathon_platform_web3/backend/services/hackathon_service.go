@@ -19,6 +19,19 @@ type HackathonService struct{}
 
 // CreateHackathon 创建活动
 func (s *HackathonService) CreateHackathon(hackathon *models.Hackathon, stages []models.HackathonStage, awards []models.HackathonAward, autoAssignStages bool) error {
+	if err := ValidateVotingPolicy(hackathon); err != nil {
+		return err
+	}
+	if err := ValidateTieBreakRules(hackathon.TieBreakRules); err != nil {
+		return err
+	}
+	if err := ValidateLatePolicy(hackathon); err != nil {
+		return err
+	}
+	if err := ValidateLiveTally(hackathon); err != nil {
+		return err
+	}
+
 	return database.DB.Transaction(func(tx *gorm.DB) error {
 		// 创建活动
 		if err := tx.Create(hackathon).Error; err != nil {
@@ -38,15 +51,16 @@ func (s *HackathonService) CreateHackathon(hackathon *models.Hackathon, stages [
 			}
 		}
 
-		// 创建奖项
+		// 创建奖项（赛道需在活动创建后添加，新建活动的奖项均为全场奖项）
 		for i := range awards {
 			awards[i].HackathonID = hackathon.ID
+			awards[i].TrackID = nil
 			if err := tx.Create(&awards[i]).Error; err != nil {
 				return fmt.Errorf("创建奖项失败: %w", err)
 			}
 		}
 
-		return nil
+		return scheduleStageReminders(tx, hackathon.ID)
 	})
 }
 
@@ -190,7 +204,9 @@ func (s *HackathonService) enrichChainCheckInsAddress(hackathons *[]models.Hacka
 // GetHackathonByID 根据ID获取活动详情
 func (s *HackathonService) GetHackathonByID(id uint64) (*models.Hackathon, error) {
 	var hackathon models.Hackathon
-	if err := database.DB.Preload("Stages").Preload("Awards").Where("id = ? AND deleted_at IS NULL", id).First(&hackathon).Error; err != nil {
+	if err := database.DB.Preload("Stages").Preload("Awards").
+		Preload("Tracks", func(db *gorm.DB) *gorm.DB { return db.Order("`order` ASC, id ASC") }).
+		Where("id = ? AND deleted_at IS NULL", id).First(&hackathon).Error; err != nil {
 		return nil, err
 	}
 
@@ -253,10 +269,29 @@ func (s *HackathonService) GetHackathonStatsDetail(hackathonID uint64, statsType
 			return nil, 0, err
 		}
 
+		// 报名表答案
+		registrationIDs := make([]uint64, 0, len(registrations))
 		for _, r := range registrations {
+			registrationIDs = append(registrationIDs, r.ID)
+		}
+		answers, err := getRegistrationAnswers(registrationIDs)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		for _, r := range registrations {
+			registrationAnswers := answers[r.ID]
+			if registrationAnswers == nil {
+				registrationAnswers = []models.RegistrationAnswer{}
+			}
 			list = append(list, map[string]interface{}{
+				"id":             r.ID,
+				"participant_id": r.ParticipantID,
 				"nickname":       r.Nickname,
 				"wallet_address": r.WalletAddress,
+				"status":         r.Status,
+				"review_note":    r.ReviewNote,
+				"answers":        registrationAnswers,
 				"created_at":     r.CreatedAt,
 			})
 		}
@@ -375,7 +410,8 @@ func (s *HackathonService) GetHackathonStatsDetail(hackathonID uint64, statsType
 // 根据权限矩阵：
 // - 预备状态：仅活动创建者可以编辑所有字段
 // - 发布状态及后续：活动创建者不能编辑活动基本信息，只能管理阶段
-func (s *HackathonService) UpdateHackathon(id uint64, hackathon *models.Hackathon, stages []models.HackathonStage, awards []models.HackathonAward, userID uint64, userRole string) error {
+// - 报名阶段结束前可调整最大参与人数，调高后在同一事务中递补候补名单
+func (s *HackathonService) UpdateHackathon(id uint64, hackathon *models.Hackathon, stages []models.HackathonStage, awards []models.HackathonAward, limits HackathonLimitUpdates, userID uint64, userRole string) error {
 	// 检查活动是否存在
 	var existing models.Hackathon
 	if err := database.DB.Where("id = ? AND deleted_at IS NULL", id).First(&existing).Error; err != nil {
@@ -392,10 +428,47 @@ func (s *HackathonService) UpdateHackathon(id uint64, hackathon *models.Hackatho
 		return errors.New("只能编辑自己创建的活动")
 	}
 
+	trackService := &TrackService{}
+
 	// 如果活动已发布，只能更新阶段，不能更新基本信息
 	if existing.Status != "preparation" {
-		// 已发布的活动只能更新阶段
+		maxParticipantsChanged := limits.MaxParticipants != nil && *limits.MaxParticipants != existing.MaxParticipants
+		if maxParticipantsChanged {
+			if *limits.MaxParticipants < 0 {
+				return errors.New("最大参与人数不能为负数")
+			}
+			if existing.Status != "published" && existing.Status != "registration" {
+				return errors.New("报名阶段结束后不能修改最大参与人数")
+			}
+		}
+
+		// 已发布的活动只能更新阶段（及报名结束前的最大参与人数）
 		return database.DB.Transaction(func(tx *gorm.DB) error {
+			// 调整最大参与人数时锁定活动行（与报名、取消报名串行），调高后立即递补候补名单
+			if maxParticipantsChanged {
+				locked, err := lockHackathon(tx, id)
+				if err != nil {
+					return err
+				}
+				maxParticipants := *limits.MaxParticipants
+				if maxParticipants > 0 {
+					seats, err := occupiedSeats(tx, id)
+					if err != nil {
+						return err
+					}
+					if int64(maxParticipants) < seats {
+						return fmt.Errorf("最大参与人数不能少于已占用的名额（%d）", seats)
+					}
+				}
+				if err := tx.Model(locked).Update("max_participants", maxParticipants).Error; err != nil {
+					return err
+				}
+				locked.MaxParticipants = maxParticipants
+				if err := promoteWaitlist(tx, locked); err != nil {
+					return err
+				}
+			}
+
 			// 删除旧阶段
 			if err := tx.Where("hackathon_id = ?", id).Delete(&models.HackathonStage{}).Error; err != nil {
 				return err
@@ -409,28 +482,66 @@ func (s *HackathonService) UpdateHackathon(id uint64, hackathon *models.Hackatho
 				}
 			}
 
-			// 删除旧奖项
-			if err := tx.Where("hackathon_id = ?", id).Delete(&models.HackathonAward{}).Error; err != nil {
+			if err := trackService.validateAwardTracks(tx, id, awards); err != nil {
 				return err
 			}
 
-			// 创建新奖项
-			for i := range awards {
-				awards[i].HackathonID = id
-				if err := tx.Create(&awards[i]).Error; err != nil {
+			// 按 ID 原地更新奖项，保持比赛结果与奖品关联的 award_id 有效
+			changed, err := syncHackathonAwards(tx, id, awards)
+			if err != nil {
+				return err
+			}
+			if changed {
+				// 结果已公布后不能再修改奖项；公布前奖项有变化时，已计算的比赛结果需重新计算
+				if existing.Status == "results" {
+					return errors.New("比赛结果已公布，不能修改奖项")
+				}
+				if err := tx.Where("hackathon_id = ?", id).Delete(&models.HackathonResult{}).Error; err != nil {
 					return err
 				}
 			}
 
-			return nil
+			return scheduleStageReminders(tx, id)
 		})
 	}
 
+	// 投票方式仅在预备状态可修改，与活动基本信息一同更新（未传的字段沿用原配置后校验）
+	policy := models.Hackathon{VotingScheme: hackathon.VotingScheme, VoteLimit: existing.VoteLimit, CreditBudget: hackathon.CreditBudget, VoteWeighters: hackathon.VoteWeighters}
+	if policy.VotingScheme == "" {
+		policy.VotingScheme = existing.VotingScheme
+	}
+	if limits.VoteLimit != nil {
+		policy.VoteLimit = *limits.VoteLimit
+	}
+	if policy.CreditBudget == 0 {
+		policy.CreditBudget = existing.CreditBudget
+	}
+	if policy.VoteWeighters == "" {
+		policy.VoteWeighters = existing.VoteWeighters
+	}
+	if err := ValidateVotingPolicy(&policy); err != nil {
+		return err
+	}
+	if err := ValidateTieBreakRules(hackathon.TieBreakRules); err != nil {
+		return err
+	}
+	if err := ValidateLatePolicy(hackathon); err != nil {
+		return err
+	}
+	if err := ValidateLiveTally(hackathon); err != nil {
+		return err
+	}
+
 	return database.DB.Transaction(func(tx *gorm.DB) error {
-		// 更新活动
-		if err := tx.Model(&models.Hackathon{}).Where("id = ?", id).Updates(hackathon).Error; err != nil {
+		// 更新活动（Updates 忽略零值，限制字段单独写入以支持改回 0 不限制）
+		if err := tx.Model(&models.Hackathon{}).Where("id = ?", id).Omit("vote_limit", "max_participants").Updates(hackathon).Error; err != nil {
 			return err
 		}
+		if fields := limits.updates(); len(fields) > 0 {
+			if err := tx.Model(&models.Hackathon{}).Where("id = ?", id).Updates(fields).Error; err != nil {
+				return err
+			}
+		}
 
 		// 删除旧阶段
 		if err := tx.Where("hackathon_id = ?", id).Delete(&models.HackathonStage{}).Error; err != nil {
@@ -445,21 +556,107 @@ func (s *HackathonService) UpdateHackathon(id uint64, hackathon *models.Hackatho
 			}
 		}
 
-		// 删除旧奖项
-		if err := tx.Where("hackathon_id = ?", id).Delete(&models.HackathonAward{}).Error; err != nil {
+		if err := trackService.validateAwardTracks(tx, id, awards); err != nil {
 			return err
 		}
 
-		// 创建新奖项
-		for i := range awards {
-			awards[i].HackathonID = id
+		// 同步奖项
+		if _, err := syncHackathonAwards(tx, id, awards); err != nil {
+			return err
+		}
+
+		return scheduleStageReminders(tx, id)
+	})
+}
+
+// HackathonLimitUpdates 修改活动时可改回 0（不限制）的限制字段，nil 表示请求未传该字段（保持不变）
+type HackathonLimitUpdates struct {
+	VoteLimit       *int
+	MaxParticipants *int
+}
+
+// updates 请求中传入的限制字段
+func (l HackathonLimitUpdates) updates() map[string]interface{} {
+	fields := make(map[string]interface{})
+	if l.VoteLimit != nil {
+		fields["vote_limit"] = *l.VoteLimit
+	}
+	if l.MaxParticipants != nil {
+		fields["max_participants"] = *l.MaxParticipants
+	}
+	return fields
+}
+
+// syncHackathonAwards 按 ID 原地同步活动奖项：带 ID 的更新、不带 ID 的新建、未提交的连同奖品删除。
+// 已有奖项的 ID 保持不变（比赛结果与奖品通过 award_id 关联），返回奖项是否有变化
+func syncHackathonAwards(tx *gorm.DB, hackathonID uint64, awards []models.HackathonAward) (bool, error) {
+	var existing []models.HackathonAward
+	if err := tx.Where("hackathon_id = ?", hackathonID).Find(&existing).Error; err != nil {
+		return false, err
+	}
+	existingByID := make(map[uint64]models.HackathonAward, len(existing))
+	for _, award := range existing {
+		existingByID[award.ID] = award
+	}
+
+	changed := false
+	kept := make(map[uint64]bool, len(awards))
+	for i := range awards {
+		awards[i].HackathonID = hackathonID
+		if awards[i].Quantity == 0 {
+			awards[i].Quantity = 1
+		}
+		if awards[i].ID == 0 {
 			if err := tx.Create(&awards[i]).Error; err != nil {
-				return err
+				return false, err
 			}
+			changed = true
+			continue
 		}
 
-		return nil
-	})
+		old, ok := existingByID[awards[i].ID]
+		if !ok {
+			return false, fmt.Errorf("奖项 %d 不属于该活动", awards[i].ID)
+		}
+		if kept[old.ID] {
+			return false, fmt.Errorf("奖项 %d 重复提交", old.ID)
+		}
+		kept[old.ID] = true
+		if sameAward(old, awards[i]) {
+			continue
+		}
+		if err := tx.Model(&models.HackathonAward{}).Where("id = ?", old.ID).Updates(map[string]interface{}{
+			"track_id": awards[i].TrackID,
+			"name":     awards[i].Name,
+			"prize":    awards[i].Prize,
+			"quantity": awards[i].Quantity,
+			"rank":     awards[i].Rank,
+		}).Error; err != nil {
+			return false, err
+		}
+		changed = true
+	}
+
+	for _, award := range existing {
+		if kept[award.ID] {
+			continue
+		}
+		if err := tx.Where("award_id = ?", award.ID).Delete(&models.HackathonPrize{}).Error; err != nil {
+			return false, err
+		}
+		if err := tx.Delete(&models.HackathonAward{}, award.ID).Error; err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+// sameAward 判断奖项的可编辑字段是否相同
+func sameAward(a, b models.HackathonAward) bool {
+	sameTrack := (a.TrackID == nil && b.TrackID == nil) ||
+		(a.TrackID != nil && b.TrackID != nil && *a.TrackID == *b.TrackID)
+	return sameTrack && a.Name == b.Name && a.Prize == b.Prize && a.Quantity == b.Quantity && a.Rank == b.Rank
 }
 
 // DeleteHackathon 删除活动（仅预备状态，且仅活动创建者可删除）
@@ -708,15 +905,22 @@ func (s *HackathonService) PrepareSwitchStage(id uint64, stage string, userID ui
 		if err := database.DB.Where("hackathon_id = ? AND draft = 0", id).Find(&submissions).Error; err != nil {
 			return nil, fmt.Errorf("获取作品列表失败: %w", err)
 		}
-		voteSvc := &VoteService{}
 		candidateIDs := make([]uint64, 0, len(submissions))
-		voteCounts := make([]uint64, 0, len(submissions))
 		for _, sub := range submissions {
-			count, _ := voteSvc.GetVoteCount(sub.ID)
+			candidateIDs = append(candidateIDs, sub.ID)
+		}
+		// 按活动投票方式计票，与比赛结果排名一致
+		voteSvc := &VoteService{}
+		tally, err := voteSvc.TallyVotes(&hackathon, 0, candidateIDs)
+		if err != nil {
+			return nil, fmt.Errorf("计票失败: %w", err)
+		}
+		voteCounts := make([]uint64, 0, len(candidateIDs))
+		for _, candidateID := range candidateIDs {
+			count := tally[candidateID]
 			if count < 0 {
 				count = 0
 			}
-			candidateIDs = append(candidateIDs, sub.ID)
 			voteCounts = append(voteCounts, uint64(count))
 		}
 		return map[string]interface{}{
@@ -749,7 +953,8 @@ func (s *HackathonService) PrepareSwitchStage(id uint64, stage string, userID ui
 }
 
 // SwitchStage 切换活动阶段（仅活动创建者可切换）。若阶段为 registration/checkin/team_formation/submission/voting/results 且活动已上链，需传入主办方已签名的链上交易并先更新链上状态再更新 DB。
-func (s *HackathonService) SwitchStage(id uint64, stage string, userID uint64, userRole string, signedTxBase64 string) error {
+// 离开提交阶段时若仍有队伍处于延期或迟交宽限期内，需 force=true 确认提前结束
+func (s *HackathonService) SwitchStage(id uint64, stage string, userID uint64, userRole string, signedTxBase64 string, force bool) error {
 	validStages := map[string]bool{
 		"published":      true,
 		"registration":   true,
@@ -779,6 +984,25 @@ func (s *HackathonService) SwitchStage(id uint64, stage string, userID uint64, u
 		return errors.New("只能切换自己创建的活动阶段")
 	}
 
+	// 提前结束提交阶段会截断队伍延期与迟交宽限期，须主办方确认
+	if hackathon.Status == "submission" && stage != "submission" && !force {
+		deadline, err := pendingSubmissionDeadline(&hackathon)
+		if err != nil {
+			return err
+		}
+		if deadline != nil {
+			return fmt.Errorf("仍有队伍处于提交延期或迟交宽限期内（最晚至 %s），如需提前结束提交阶段请确认强制切换", deadline.Format("2006-01-02 15:04:05"))
+		}
+	}
+
+	// 公布结果前确保比赛结果已计算，且不存在需主办方裁定的同分
+	if stage == "results" {
+		resultService := &ResultService{}
+		if err := resultService.PrepareForPublish(&hackathon); err != nil {
+			return err
+		}
+	}
+
 	// 若该阶段需要更新链上活动状态且活动已上链，则必须先提交已签名交易再更新 DB
 	if NeedChainStageUpdate(stage) && strings.TrimSpace(hackathon.ChainActivityAddress) != "" {
 		signedTxBase64 = strings.TrimSpace(signedTxBase64)
@@ -802,7 +1026,37 @@ func (s *HackathonService) SwitchStage(id uint64, stage string, userID uint64, u
 		}
 	}
 
-	return database.DB.Model(&hackathon).Update("status", stage).Error
+	previousStatus := hackathon.Status
+
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&hackathon).Update("status", stage).Error; err != nil {
+			return err
+		}
+		// 离开组队阶段时按设置为未组队的参赛者自动组队，再锁定所有队伍
+		if previousStatus == "team_formation" && stage != "team_formation" {
+			if hackathon.AutoGroupOnClose {
+				if err := autoGroupOnClose(tx, &hackathon); err != nil {
+					return fmt.Errorf("自动组队失败: %w", err)
+				}
+			}
+			return lockHackathonTeams(tx, hackathon.ID)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	publishStageChanged(&hackathon, previousStatus, stage)
+	notifyStageChanged(&hackathon, stage)
+	if stage == "results" {
+		emitResultsPublished(&hackathon)
+	}
+
+	// 提交阶段结束时在后台锁定尚未快照的作品仓库提交，并检测相似作品
+	if previousStatus == "submission" && stage != "submission" {
+		snapshotOnSubmissionEnd(hackathon.ID)
+		similarityOnSubmissionEnd(hackathon.ID)
+	}
+	return nil
 }
 
 // GetPublishedHackathons 获取已发布的活动列表（Arena平台）
@@ -982,7 +1236,8 @@ func (s *HackathonService) UpdateStageTimes(hackathonID uint64, stages []models.
 			}
 		}
 
-		return nil
+		// 阶段时间变化后重新计算阶段提醒
+		return scheduleStageReminders(tx, hackathonID)
 	})
 }
 
@@ -1049,8 +1304,8 @@ func (s *HackathonService) validateStageTimes(hackathonID uint64, stages []model
 	return nil
 }
 
-// GetArchiveHackathons 获取活动集锦列表（已结束的活动）
-func (s *HackathonService) GetArchiveHackathons(page, pageSize int, keyword, timeRange string) ([]models.Hackathon, int64, error) {
+// GetArchiveHackathons 获取活动集锦列表（已结束的活动），techStack 不为空时只返回有作品使用该技术栈的活动
+func (s *HackathonService) GetArchiveHackathons(page, pageSize int, keyword, timeRange, techStack string) ([]models.Hackathon, int64, error) {
 	var hackathons []models.Hackathon
 	var total int64
 
@@ -1079,6 +1334,11 @@ func (s *HackathonService) GetArchiveHackathons(page, pageSize int, keyword, tim
 		query = query.Where("name LIKE ?", "%"+keyword+"%")
 	}
 
+	// 技术栈搜索
+	if techStack = strings.ToLower(strings.TrimSpace(techStack)); techStack != "" {
+		query = query.Where("id IN (?)", database.DB.Model(&models.Submission{}).Select("hackathon_id").Where("draft = 0 AND FIND_IN_SET(?, tech_stack)", techStack))
+	}
+
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
@@ -1111,79 +1371,58 @@ func (s *HackathonService) GetArchiveDetail(hackathonID uint64) (map[string]inte
 		return nil, err
 	}
 
-	// 获取所有作品
-	var submissions []models.Submission
-	if err := database.DB.
-		Preload("Team").
-		Where("hackathon_id = ? AND draft = 0", hackathonID).
-		Find(&submissions).Error; err != nil {
+	// 全场排名（结果引擎落库的 hackathon_results）
+	resultService := &ResultService{}
+	records, err := resultService.GetResultRecords(hackathonID, 0)
+	if err != nil {
 		return nil, err
 	}
 
-	// 计算每个作品的得票数
-	submissionVoteCounts := make(map[uint64]int64)
-	voteService := &VoteService{}
-	for _, submission := range submissions {
-		count, _ := voteService.GetVoteCount(submission.ID)
-		submissionVoteCounts[submission.ID] = count
+	// 按名次排列作品，并计算得票率
+	submissions := make([]models.Submission, 0, len(records))
+	voteResults := make([]map[string]interface{}, 0, len(records))
+	var totalVotes int64
+	for _, record := range records {
+		totalVotes += record.VoteCount
 	}
+	for _, record := range records {
+		submission := record.Submission
+		submission.Team = record.Team
+		submissions = append(submissions, submission)
 
-	// 获取投票结果
-	var votes []models.Vote
-	if err := database.DB.
-		Where("hackathon_id = ?", hackathonID).
-		Find(&votes).Error; err != nil {
-		return nil, err
-	}
-
-	// 计算每个作品的得票数和得票率
-	voteResults := make([]map[string]interface{}, 0)
-	totalVotes := int64(len(votes))
-	for _, submission := range submissions {
-		voteCount := submissionVoteCounts[submission.ID]
 		var voteRate float64
 		if totalVotes > 0 {
-			voteRate = float64(voteCount) / float64(totalVotes) * 100
+			voteRate = float64(record.VoteCount) / float64(totalVotes) * 100
 		}
 		voteResults = append(voteResults, map[string]interface{}{
 			"submission_id":   submission.ID,
 			"submission_name": submission.Name,
-			"team_name":       submission.Team.Name,
-			"vote_count":      voteCount,
+			"team_name":       record.Team.Name,
+			"vote_count":      record.VoteCount,
 			"vote_rate":       voteRate,
 		})
 	}
 
-	// 按得票数排序作品
-	for i := 0; i < len(submissions)-1; i++ {
-		for j := i + 1; j < len(submissions); j++ {
-			if submissionVoteCounts[submissions[i].ID] < submissionVoteCounts[submissions[j].ID] {
-				submissions[i], submissions[j] = submissions[j], submissions[i]
-			}
-		}
-	}
-
-	// 获取比赛结果（获奖队伍）
+	// 获取比赛结果（获奖队伍，全场奖项；赛道奖项见 track_results）
 	var awards []models.HackathonAward
-	if err := database.DB.Where("hackathon_id = ?", hackathonID).Order("`rank` ASC").Find(&awards).Error; err != nil {
+	if err := database.DB.Where("hackathon_id = ? AND track_id IS NULL", hackathonID).Order("`rank` ASC").Find(&awards).Error; err != nil {
 		return nil, err
 	}
 
 	finalResults := make([]map[string]interface{}, 0)
-	submissionIndex := 0
 	for _, award := range awards {
-		// 根据奖项排名获取对应的作品（按得票数排序后的前N个）
+		// 获奖作品以结果表中分配的奖项为准（含主办方调整）
 		awardResults := make([]map[string]interface{}, 0)
-		for i := 0; i < award.Quantity && submissionIndex < len(submissions); i++ {
-			submission := submissions[submissionIndex]
-			voteCount := submissionVoteCounts[submission.ID]
+		for _, record := range records {
+			if record.AwardID == nil || *record.AwardID != award.ID {
+				continue
+			}
 			awardResults = append(awardResults, map[string]interface{}{
-				"team_name":       submission.Team.Name,
-				"submission_name": submission.Name,
-				"vote_count":      voteCount,
+				"team_name":       record.Team.Name,
+				"submission_name": record.Submission.Name,
+				"vote_count":      record.VoteCount,
 				"prize_money":     award.Prize,
 			})
-			submissionIndex++
 		}
 		finalResults = append(finalResults, map[string]interface{}{
 			"award_name": award.Name,
@@ -1193,11 +1432,19 @@ func (s *HackathonService) GetArchiveDetail(hackathonID uint64) (map[string]inte
 		})
 	}
 
+	// 各赛道结果
+	voteService := &VoteService{}
+	trackResults, err := voteService.GetTrackResults(hackathonID)
+	if err != nil {
+		return nil, err
+	}
+
 	return map[string]interface{}{
 		"hackathon":     hackathon,
 		"stats":         stats,
 		"submissions":   submissions,
 		"vote_results":  voteResults,
 		"final_results": finalResults,
+		"track_results": trackResults,
 	}, nil
 }
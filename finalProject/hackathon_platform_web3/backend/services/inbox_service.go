@@ -0,0 +1,145 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"hackathon-backend/database"
+	"hackathon-backend/models"
+
+	"gorm.io/gorm/clause"
+)
+
+// InboxService 站内通知：参赛者与后台用户查看、标记已读，以及按事件类型与渠道设置通知偏好
+type InboxService struct{}
+
+// NotificationPreferenceItem 通知偏好矩阵中的一行：事件类型及各渠道是否开启
+type NotificationPreferenceItem struct {
+	Type     string          `json:"type"`
+	Name     string          `json:"name"`
+	Channels map[string]bool `json:"channels"`
+	OptIn    bool            `json:"opt_in"` // 需主动开启，未设置时默认关闭
+}
+
+// NotificationPreferenceUpdate 修改一项通知偏好
+type NotificationPreferenceUpdate struct {
+	EventType string `json:"event_type" binding:"required"`
+	Channel   string `json:"channel" binding:"required"`
+	Enabled   bool   `json:"enabled"`
+}
+
+// GetNotifications 获取站内通知列表（按时间倒序），unreadOnly 为 true 时只返回未读
+func (s *InboxService) GetNotifications(recipientType string, recipientID uint64, page, pageSize int, unreadOnly bool) ([]models.Notification, int64, error) {
+	query := database.DB.Model(&models.Notification{}).
+		Where("recipient_type = ? AND recipient_id = ?", recipientType, recipientID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var notifications []models.Notification
+	offset := (page - 1) * pageSize
+	if err := query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&notifications).Error; err != nil {
+		return nil, 0, err
+	}
+	return notifications, total, nil
+}
+
+// GetUnreadCount 获取未读通知数量
+func (s *InboxService) GetUnreadCount(recipientType string, recipientID uint64) (int64, error) {
+	var count int64
+	err := database.DB.Model(&models.Notification{}).
+		Where("recipient_type = ? AND recipient_id = ? AND read_at IS NULL", recipientType, recipientID).
+		Count(&count).Error
+	return count, err
+}
+
+// MarkRead 将一条通知标记为已读
+func (s *InboxService) MarkRead(recipientType string, recipientID, notificationID uint64) error {
+	var notification models.Notification
+	if err := database.DB.Where("id = ? AND recipient_type = ? AND recipient_id = ?", notificationID, recipientType, recipientID).
+		First(&notification).Error; err != nil {
+		return errors.New("通知不存在")
+	}
+	if notification.ReadAt != nil {
+		return nil
+	}
+	return database.DB.Model(&notification).Update("read_at", time.Now()).Error
+}
+
+// MarkAllRead 将全部未读通知标记为已读
+func (s *InboxService) MarkAllRead(recipientType string, recipientID uint64) error {
+	return database.DB.Model(&models.Notification{}).
+		Where("recipient_type = ? AND recipient_id = ? AND read_at IS NULL", recipientType, recipientID).
+		Update("read_at", time.Now()).Error
+}
+
+// GetPreferences 获取通知偏好矩阵（未设置的事件类型与渠道默认开启，需主动开启的事件类型默认关闭）
+func (s *InboxService) GetPreferences(recipientType string, recipientID uint64) ([]NotificationPreferenceItem, error) {
+	var preferences []models.NotificationPreference
+	if err := database.DB.Where("recipient_type = ? AND recipient_id = ?", recipientType, recipientID).
+		Find(&preferences).Error; err != nil {
+		return nil, err
+	}
+	enabled := make(map[string]bool, len(preferences))
+	for _, preference := range preferences {
+		enabled[preference.EventType+"/"+preference.Channel] = preference.Enabled
+	}
+
+	eventTypes := notificationEventTypes[recipientType]
+	items := make([]NotificationPreferenceItem, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		item := NotificationPreferenceItem{
+			Type:     eventType.Type,
+			Name:     eventType.Name,
+			Channels: make(map[string]bool, len(eventType.Channels)),
+			OptIn:    eventType.OptIn,
+		}
+		for _, channel := range eventType.Channels {
+			value, ok := enabled[eventType.Type+"/"+channel]
+			if !ok {
+				value = !eventType.OptIn
+			}
+			item.Channels[channel] = value
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// UpdatePreferences 修改通知偏好（关闭即为屏蔽该类通知），只需传入要修改的项
+func (s *InboxService) UpdatePreferences(recipientType string, recipientID uint64, updates []NotificationPreferenceUpdate) ([]NotificationPreferenceItem, error) {
+	supported := make(map[string]bool)
+	for _, eventType := range notificationEventTypes[recipientType] {
+		for _, channel := range eventType.Channels {
+			supported[eventType.Type+"/"+channel] = true
+		}
+	}
+
+	preferences := make([]models.NotificationPreference, 0, len(updates))
+	for _, update := range updates {
+		if !supported[update.EventType+"/"+update.Channel] {
+			return nil, errors.New("不支持的通知类型或渠道: " + update.EventType + "/" + update.Channel)
+		}
+		preferences = append(preferences, models.NotificationPreference{
+			RecipientType: recipientType,
+			RecipientID:   recipientID,
+			EventType:     update.EventType,
+			Channel:       update.Channel,
+			Enabled:       update.Enabled,
+		})
+	}
+
+	if len(preferences) > 0 {
+		if err := database.DB.Clauses(clause.OnConflict{
+			DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+		}).Create(&preferences).Error; err != nil {
+			return nil, err
+		}
+	}
+	return s.GetPreferences(recipientType, recipientID)
+}
@@ -0,0 +1,474 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"hackathon-backend/database"
+	"hackathon-backend/models"
+
+	"gorm.io/gorm"
+)
+
+type JudgeService struct{}
+
+// checkJudgingManager 检查用户是否可以管理活动评审（仅活动创建者）
+func (s *JudgeService) checkJudgingManager(hackathonID, userID uint64, userRole string) (*models.Hackathon, error) {
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
+		return nil, errors.New("活动不存在")
+	}
+	if userRole == "admin" {
+		return nil, errors.New("Admin不能管理活动评审")
+	}
+	if hackathon.OrganizerID != userID {
+		return nil, errors.New("只能管理自己创建活动的评审")
+	}
+	if hackathon.Status == "results" {
+		return nil, errors.New("结果已公布，不能修改评审设置")
+	}
+	return &hackathon, nil
+}
+
+// AssignJudge 为活动指派评委（评委须为 role=judge 的启用账号）
+func (s *JudgeService) AssignJudge(hackathonID, judgeUserID, userID uint64, userRole string) (*models.HackathonJudge, error) {
+	if _, err := s.checkJudgingManager(hackathonID, userID, userRole); err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", judgeUserID).First(&user).Error; err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	if user.Role != "judge" {
+		return nil, errors.New("该用户不是评委")
+	}
+	if user.Status == 0 {
+		return nil, errors.New("该评委账号已被禁用")
+	}
+
+	var existing models.HackathonJudge
+	if err := database.DB.Where("hackathon_id = ? AND user_id = ?", hackathonID, judgeUserID).First(&existing).Error; err == nil {
+		return nil, errors.New("该评委已被指派")
+	}
+
+	judge := models.HackathonJudge{
+		HackathonID: hackathonID,
+		UserID:      judgeUserID,
+	}
+	if err := database.DB.Create(&judge).Error; err != nil {
+		return nil, fmt.Errorf("指派评委失败: %w", err)
+	}
+	judge.User = user
+	return &judge, nil
+}
+
+// RemoveJudge 取消指派评委，同时删除该评委在本活动的回避记录与打分
+func (s *JudgeService) RemoveJudge(hackathonID, judgeUserID, userID uint64, userRole string) error {
+	if _, err := s.checkJudgingManager(hackathonID, userID, userRole); err != nil {
+		return err
+	}
+
+	var judge models.HackathonJudge
+	if err := database.DB.Where("hackathon_id = ? AND user_id = ?", hackathonID, judgeUserID).First(&judge).Error; err != nil {
+		return errors.New("该评委未被指派")
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("hackathon_id = ? AND judge_id = ?", hackathonID, judgeUserID).Delete(&models.JudgeScore{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("hackathon_id = ? AND judge_id = ?", hackathonID, judgeUserID).Delete(&models.JudgeConflict{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&judge).Error
+	})
+}
+
+// GetJudges 获取活动评委列表
+func (s *JudgeService) GetJudges(hackathonID uint64) ([]models.HackathonJudge, error) {
+	var judges []models.HackathonJudge
+	if err := database.DB.Preload("User").Where("hackathon_id = ?", hackathonID).Order("created_at ASC").Find(&judges).Error; err != nil {
+		return nil, err
+	}
+	return judges, nil
+}
+
+// AddConflict 登记评委利益冲突（评委回避该队伍的作品）
+func (s *JudgeService) AddConflict(hackathonID, judgeUserID, teamID uint64, reason string, userID uint64, userRole string) (*models.JudgeConflict, error) {
+	if _, err := s.checkJudgingManager(hackathonID, userID, userRole); err != nil {
+		return nil, err
+	}
+	if !s.IsJudge(hackathonID, judgeUserID) {
+		return nil, errors.New("该评委未被指派")
+	}
+
+	var team models.Team
+	if err := database.DB.Where("id = ? AND hackathon_id = ? AND deleted_at IS NULL", teamID, hackathonID).First(&team).Error; err != nil {
+		return nil, errors.New("队伍不存在")
+	}
+
+	if s.HasConflict(hackathonID, judgeUserID, teamID) {
+		return nil, errors.New("已登记该回避关系")
+	}
+
+	conflict := models.JudgeConflict{
+		HackathonID: hackathonID,
+		JudgeID:     judgeUserID,
+		TeamID:      teamID,
+		Reason:      reason,
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&conflict).Error; err != nil {
+			return err
+		}
+		// 已有的打分作废
+		return tx.Where("hackathon_id = ? AND judge_id = ? AND submission_id IN (?)", hackathonID, judgeUserID,
+			tx.Model(&models.Submission{}).Select("id").Where("hackathon_id = ? AND team_id = ?", hackathonID, teamID)).
+			Delete(&models.JudgeScore{}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("登记回避失败: %w", err)
+	}
+	return &conflict, nil
+}
+
+// RemoveConflict 删除评委利益冲突登记
+func (s *JudgeService) RemoveConflict(hackathonID, conflictID, userID uint64, userRole string) error {
+	if _, err := s.checkJudgingManager(hackathonID, userID, userRole); err != nil {
+		return err
+	}
+	result := database.DB.Where("id = ? AND hackathon_id = ?", conflictID, hackathonID).Delete(&models.JudgeConflict{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("回避记录不存在")
+	}
+	return nil
+}
+
+// GetConflicts 获取活动的评委回避列表
+func (s *JudgeService) GetConflicts(hackathonID uint64) ([]models.JudgeConflict, error) {
+	var conflicts []models.JudgeConflict
+	if err := database.DB.Preload("Team").Where("hackathon_id = ?", hackathonID).Order("created_at ASC").Find(&conflicts).Error; err != nil {
+		return nil, err
+	}
+	return conflicts, nil
+}
+
+// IsJudge 判断用户是否为该活动的评委
+func (s *JudgeService) IsJudge(hackathonID, judgeUserID uint64) bool {
+	var count int64
+	database.DB.Model(&models.HackathonJudge{}).Where("hackathon_id = ? AND user_id = ?", hackathonID, judgeUserID).Count(&count)
+	return count > 0
+}
+
+// HasConflict 判断评委是否需要回避该队伍
+func (s *JudgeService) HasConflict(hackathonID, judgeUserID, teamID uint64) bool {
+	var count int64
+	database.DB.Model(&models.JudgeConflict{}).Where("hackathon_id = ? AND judge_id = ? AND team_id = ?", hackathonID, judgeUserID, teamID).Count(&count)
+	return count > 0
+}
+
+// SetRubric 设置活动评分标准（整体覆盖，投票阶段开始后不能修改）
+func (s *JudgeService) SetRubric(hackathonID uint64, criteria []models.RubricCriterion, userID uint64, userRole string) error {
+	hackathon, err := s.checkJudgingManager(hackathonID, userID, userRole)
+	if err != nil {
+		return err
+	}
+	if hackathon.Status == "voting" {
+		return errors.New("评审已开始，不能修改评分标准")
+	}
+
+	for _, c := range criteria {
+		if c.Name == "" {
+			return errors.New("评分标准名称不能为空")
+		}
+		if c.Weight <= 0 {
+			return fmt.Errorf("评分标准 %s 的权重必须大于 0", c.Name)
+		}
+		if c.MaxScore <= 0 {
+			return fmt.Errorf("评分标准 %s 的满分必须大于 0", c.Name)
+		}
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("hackathon_id = ?", hackathonID).Delete(&models.RubricCriterion{}).Error; err != nil {
+			return err
+		}
+		for i := range criteria {
+			criteria[i].ID = 0
+			criteria[i].HackathonID = hackathonID
+			if err := tx.Create(&criteria[i]).Error; err != nil {
+				return fmt.Errorf("创建评分标准失败: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetRubric 获取活动评分标准
+func (s *JudgeService) GetRubric(hackathonID uint64) ([]models.RubricCriterion, error) {
+	var criteria []models.RubricCriterion
+	if err := database.DB.Where("hackathon_id = ?", hackathonID).Order("`order` ASC, id ASC").Find(&criteria).Error; err != nil {
+		return nil, err
+	}
+	return criteria, nil
+}
+
+// SetJudgeWeight 设置评委评分在最终结果中的权重（0-1）
+func (s *JudgeService) SetJudgeWeight(hackathonID uint64, weight float64, userID uint64, userRole string) error {
+	hackathon, err := s.checkJudgingManager(hackathonID, userID, userRole)
+	if err != nil {
+		return err
+	}
+	if weight < 0 || weight > 1 {
+		return errors.New("评委权重必须在 0 到 1 之间")
+	}
+	return database.DB.Model(hackathon).Update("judge_weight", weight).Error
+}
+
+// GetJudgeHackathons 获取评委被指派的活动列表
+func (s *JudgeService) GetJudgeHackathons(judgeUserID uint64) ([]models.Hackathon, error) {
+	var hackathons []models.Hackathon
+	if err := database.DB.Model(&models.Hackathon{}).
+		Joins("INNER JOIN hackathon_judges ON hackathon_judges.hackathon_id = hackathons.id").
+		Where("hackathons.deleted_at IS NULL AND hackathon_judges.user_id = ?", judgeUserID).
+		Order("hackathons.start_time DESC").
+		Find(&hackathons).Error; err != nil {
+		return nil, err
+	}
+	return hackathons, nil
+}
+
+// GetJudgeSubmissions 获取评委可评审的作品列表（已排除回避队伍），附带评委已打的分数
+func (s *JudgeService) GetJudgeSubmissions(hackathonID, judgeUserID uint64) ([]map[string]interface{}, error) {
+	if !s.IsJudge(hackathonID, judgeUserID) {
+		return nil, errors.New("您不是该活动的评委")
+	}
+
+	var submissions []models.Submission
+	if err := database.DB.Preload("Team").
+		Where("hackathon_id = ? AND draft = 0", hackathonID).
+		Where("team_id NOT IN (?)", database.DB.Model(&models.JudgeConflict{}).Select("team_id").
+			Where("hackathon_id = ? AND judge_id = ?", hackathonID, judgeUserID)).
+		Order("created_at ASC").
+		Find(&submissions).Error; err != nil {
+		return nil, err
+	}
+
+	var scores []models.JudgeScore
+	if err := database.DB.Where("hackathon_id = ? AND judge_id = ?", hackathonID, judgeUserID).Find(&scores).Error; err != nil {
+		return nil, err
+	}
+	scoreMap := make(map[uint64][]models.JudgeScore)
+	for _, score := range scores {
+		scoreMap[score.SubmissionID] = append(scoreMap[score.SubmissionID], score)
+	}
+
+	// 提交截止时锁定的仓库提交
+	var snapshots []models.SubmissionSnapshot
+	if err := database.DB.Where("hackathon_id = ?", hackathonID).Find(&snapshots).Error; err != nil {
+		return nil, err
+	}
+	snapshotMap := make(map[uint64]models.SubmissionSnapshot, len(snapshots))
+	for _, snapshot := range snapshots {
+		snapshotMap[snapshot.SubmissionID] = snapshot
+	}
+
+	list := make([]map[string]interface{}, 0, len(submissions))
+	for _, submission := range submissions {
+		myScores := scoreMap[submission.ID]
+		if myScores == nil {
+			myScores = []models.JudgeScore{}
+		}
+		item := map[string]interface{}{
+			"submission": submission,
+			"scored":     len(myScores) > 0,
+			"my_scores":  myScores,
+			"snapshot":   nil,
+		}
+		if snapshot, ok := snapshotMap[submission.ID]; ok {
+			item["snapshot"] = snapshot
+		}
+		list = append(list, item)
+	}
+	return list, nil
+}
+
+// SubmitScores 评委为作品打分（投票阶段内，按评分标准逐项打分，可重复提交覆盖）
+func (s *JudgeService) SubmitScores(submissionID, judgeUserID uint64, scores []models.JudgeScore) error {
+	var submission models.Submission
+	if err := database.DB.Where("id = ? AND draft = 0", submissionID).First(&submission).Error; err != nil {
+		return errors.New("作品不存在")
+	}
+	hackathonID := submission.HackathonID
+
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
+		return errors.New("活动不存在")
+	}
+	if hackathon.Status != "voting" {
+		return errors.New("当前不在评审阶段")
+	}
+
+	hackathonService := &HackathonService{}
+	inTime, err := hackathonService.CheckStageTime(hackathonID, "voting")
+	if err != nil {
+		return errors.New("投票阶段时间未设置")
+	}
+	if !inTime {
+		return errors.New("不在评审时间范围内")
+	}
+
+	if !s.IsJudge(hackathonID, judgeUserID) {
+		return errors.New("您不是该活动的评委")
+	}
+	if s.HasConflict(hackathonID, judgeUserID, submission.TeamID) {
+		return errors.New("您需要回避该队伍，不能为其作品打分")
+	}
+
+	criteria, err := s.GetRubric(hackathonID)
+	if err != nil {
+		return err
+	}
+	if len(criteria) == 0 {
+		return errors.New("活动尚未设置评分标准")
+	}
+	criterionMap := make(map[uint64]models.RubricCriterion)
+	for _, c := range criteria {
+		criterionMap[c.ID] = c
+	}
+	// 须为每项评分标准打分，避免只打部分标准时按已打标准归一化得到虚高的得分率
+	seen := make(map[uint64]bool, len(scores))
+	for _, score := range scores {
+		criterion, ok := criterionMap[score.CriterionID]
+		if !ok {
+			return errors.New("评分标准不存在")
+		}
+		if seen[score.CriterionID] {
+			return fmt.Errorf("评分标准 %s 重复打分", criterion.Name)
+		}
+		seen[score.CriterionID] = true
+		if score.Score < 0 || score.Score > float64(criterion.MaxScore) {
+			return fmt.Errorf("评分标准 %s 的分数必须在 0 到 %d 之间", criterion.Name, criterion.MaxScore)
+		}
+	}
+	for _, criterion := range criteria {
+		if !seen[criterion.ID] {
+			return fmt.Errorf("请为评分标准 %s 打分", criterion.Name)
+		}
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, score := range scores {
+			var existing models.JudgeScore
+			err := tx.Where("submission_id = ? AND judge_id = ? AND criterion_id = ?", submissionID, judgeUserID, score.CriterionID).First(&existing).Error
+			if err == nil {
+				if err := tx.Model(&existing).Updates(map[string]interface{}{
+					"score":   score.Score,
+					"comment": score.Comment,
+				}).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			record := models.JudgeScore{
+				HackathonID:  hackathonID,
+				SubmissionID: submissionID,
+				JudgeID:      judgeUserID,
+				CriterionID:  score.CriterionID,
+				Score:        score.Score,
+				Comment:      score.Comment,
+			}
+			if err := tx.Create(&record).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetSubmissionScores 获取作品的全部评委打分（活动创建者或Admin查看）
+func (s *JudgeService) GetSubmissionScores(hackathonID, submissionID, userID uint64, userRole string) ([]models.JudgeScore, error) {
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
+		return nil, errors.New("活动不存在")
+	}
+	if userRole != "admin" && hackathon.OrganizerID != userID {
+		return nil, errors.New("只能查看自己创建活动的评分")
+	}
+	var scores []models.JudgeScore
+	if err := database.DB.Preload("Criterion").Where("hackathon_id = ? AND submission_id = ?", hackathonID, submissionID).
+		Order("judge_id ASC, criterion_id ASC").Find(&scores).Error; err != nil {
+		return nil, err
+	}
+	return scores, nil
+}
+
+// GetJudgeScores 计算活动内每个作品的评委综合得分（0-100），见 normalizeJudgeScores
+func (s *JudgeService) GetJudgeScores(hackathonID uint64) (map[uint64]float64, error) {
+	criteria, err := s.GetRubric(hackathonID)
+	if err != nil {
+		return nil, err
+	}
+	var scores []models.JudgeScore
+	if err := database.DB.Where("hackathon_id = ?", hackathonID).Find(&scores).Error; err != nil {
+		return nil, err
+	}
+	return normalizeJudgeScores(criteria, scores), nil
+}
+
+// normalizeJudgeScores 每位评委的得分为各标准按权重加权后的得分率，始终除以全部评分标准的总权重（未打分的标准按 0 分计）；
+// 作品得分为所有已打分评委的平均值，没有评委打分的作品不出现在结果中
+func normalizeJudgeScores(criteria []models.RubricCriterion, scores []models.JudgeScore) map[uint64]float64 {
+	criterionMap := make(map[uint64]models.RubricCriterion)
+	var totalWeight float64
+	for _, c := range criteria {
+		if c.MaxScore <= 0 || c.Weight <= 0 {
+			continue
+		}
+		criterionMap[c.ID] = c
+		totalWeight += c.Weight
+	}
+	result := make(map[uint64]float64)
+	if totalWeight <= 0 {
+		return result
+	}
+
+	// submission -> judge -> 加权得分率累计
+	perJudge := make(map[uint64]map[uint64]float64)
+	for _, score := range scores {
+		criterion, ok := criterionMap[score.CriterionID]
+		if !ok {
+			continue
+		}
+		if perJudge[score.SubmissionID] == nil {
+			perJudge[score.SubmissionID] = make(map[uint64]float64)
+		}
+		perJudge[score.SubmissionID][score.JudgeID] += criterion.Weight * score.Score / float64(criterion.MaxScore)
+	}
+
+	for submissionID, judges := range perJudge {
+		var total float64
+		for _, sum := range judges {
+			total += sum / totalWeight * 100
+		}
+		result[submissionID] = total / float64(len(judges))
+	}
+	return result
+}
+
+// CombineScore 按评委权重合成最终得分（0-100）：社区投票按最高票数归一化，与评委得分加权求和
+func CombineScore(voteCount, maxVoteCount int64, judgeScore, judgeWeight float64) float64 {
+	var voteScore float64
+	if maxVoteCount > 0 {
+		voteScore = float64(voteCount) / float64(maxVoteCount) * 100
+	}
+	return (1-judgeWeight)*voteScore + judgeWeight*judgeScore
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"hackathon-backend/models"
+)
+
+func TestNormalizeJudgeScores(t *testing.T) {
+	criteria := []models.RubricCriterion{
+		{ID: 1, Weight: 2, MaxScore: 10},
+		{ID: 2, Weight: 1, MaxScore: 5},
+		{ID: 3, Weight: 1, MaxScore: 0}, // 满分为 0 的标准不参与计算
+	}
+	tests := []struct {
+		name   string
+		scores []models.JudgeScore
+		want   map[uint64]float64
+	}{
+		{
+			name: "full sheet",
+			scores: []models.JudgeScore{
+				{SubmissionID: 10, JudgeID: 1, CriterionID: 1, Score: 10},
+				{SubmissionID: 10, JudgeID: 1, CriterionID: 2, Score: 5},
+			},
+			want: map[uint64]float64{10: 100},
+		},
+		{
+			name: "partial sheet divides by total weight",
+			scores: []models.JudgeScore{
+				{SubmissionID: 10, JudgeID: 1, CriterionID: 2, Score: 5},
+			},
+			want: map[uint64]float64{10: 100.0 / 3},
+		},
+		{
+			name: "average over judges",
+			scores: []models.JudgeScore{
+				{SubmissionID: 10, JudgeID: 1, CriterionID: 1, Score: 10},
+				{SubmissionID: 10, JudgeID: 1, CriterionID: 2, Score: 5},
+				{SubmissionID: 10, JudgeID: 2, CriterionID: 1, Score: 5},
+				{SubmissionID: 10, JudgeID: 2, CriterionID: 2, Score: 0},
+			},
+			want: map[uint64]float64{10: (100 + 100.0/3) / 2},
+		},
+		{
+			name: "unknown criterion ignored",
+			scores: []models.JudgeScore{
+				{SubmissionID: 11, JudgeID: 1, CriterionID: 99, Score: 10},
+				{SubmissionID: 12, JudgeID: 1, CriterionID: 3, Score: 10},
+			},
+			want: map[uint64]float64{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := normalizeJudgeScores(criteria, tt.scores)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for id, want := range tt.want {
+				if math.Abs(got[id]-want) > 1e-9 {
+					t.Errorf("submission %d = %v, want %v", id, got[id], want)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeJudgeScoresWithoutRubric(t *testing.T) {
+	got := normalizeJudgeScores(nil, []models.JudgeScore{{SubmissionID: 1, JudgeID: 1, CriterionID: 1, Score: 5}})
+	if len(got) != 0 {
+		t.Errorf("got %v, want empty", got)
+	}
+}
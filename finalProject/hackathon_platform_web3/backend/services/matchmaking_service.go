@@ -0,0 +1,619 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"hackathon-backend/database"
+	"hackathon-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// MatchmakingService 队友匹配：按技能、角色与时区为参赛者推荐队伍、为队伍推荐参赛者，以及组队阶段末的自动组队
+type MatchmakingService struct{}
+
+// 匹配评分权重
+const (
+	matchRoleScore       = 5 // 命中队伍招募的角色
+	matchNewRoleScore    = 2 // 参赛者希望担任的角色队内尚无人担任
+	matchNewSkillScore   = 1 // 参赛者的技能队内尚无人具备
+	matchMaxNewSkills    = 3 // 技能互补最多计入的技能数
+	defaultMatchLimit    = 10
+	maxMatchLimit        = 50
+	defaultAutoGroupSize = 3
+)
+
+// autoTeamNamePrefix 自动组队新建队伍的名称前缀，后接序号
+const autoTeamNamePrefix = "自动组队 #"
+
+// MatchDetail 匹配得分明细
+type MatchDetail struct {
+	Score               int      `json:"score"`
+	MatchedRoles        []string `json:"matched_roles"`        // 命中队伍招募的角色
+	NewRoles            []string `json:"new_roles"`            // 补齐队内空缺的角色
+	ComplementarySkills []string `json:"complementary_skills"` // 队内尚无人具备的技能
+	TimeZoneGap         *float64 `json:"time_zone_gap"`        // 与队员的最小时差（小时），任一方未填写时区为空
+}
+
+// TeamMatch 为参赛者推荐的队伍
+type TeamMatch struct {
+	MatchDetail
+	Team      models.Team `json:"team"`
+	OpenSlots int         `json:"open_slots"`
+}
+
+// ParticipantMatch 为队伍推荐的参赛者
+type ParticipantMatch struct {
+	MatchDetail
+	Participant models.Participant `json:"participant"`
+}
+
+// AutoGroupAssignment 自动组队结果（一支队伍新增的成员）
+type AutoGroupAssignment struct {
+	TeamID         uint64   `json:"team_id"`
+	TeamName       string   `json:"team_name"`
+	NewTeam        bool     `json:"new_team"`
+	ParticipantIDs []uint64 `json:"participant_ids"`
+}
+
+// parseTags 解析逗号分隔的技能/角色标签（去空格、转小写、去重）
+func parseTags(value string) []string {
+	tags := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// normalizeTags 将请求中的标签（逗号分隔字符串或字符串数组）规范化为逗号分隔字符串
+func normalizeTags(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return strings.Join(parseTags(v), ","), nil
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return "", errors.New("标签必须为字符串")
+			}
+			parts = append(parts, s)
+		}
+		return strings.Join(parseTags(strings.Join(parts, ",")), ","), nil
+	default:
+		return "", errors.New("标签必须为字符串或字符串数组")
+	}
+}
+
+// parseTimeZoneOffset 解析时区相对 UTC 的偏移（小时），支持 IANA 名称（Asia/Shanghai）与 UTC+8、+05:30 等写法
+func parseTimeZoneOffset(tz string) (float64, bool) {
+	tz = strings.TrimSpace(tz)
+	if tz == "" {
+		return 0, false
+	}
+
+	offset := strings.ToUpper(tz)
+	offset = strings.TrimPrefix(offset, "UTC")
+	offset = strings.TrimPrefix(offset, "GMT")
+	if offset == "" {
+		return 0, true
+	}
+	if offset[0] == '+' || offset[0] == '-' {
+		sign := 1.0
+		if offset[0] == '-' {
+			sign = -1
+		}
+		body := offset[1:]
+		var hours, minutes float64
+		var err error
+		switch {
+		case strings.Contains(body, ":"):
+			parts := strings.SplitN(body, ":", 2)
+			if hours, err = strconv.ParseFloat(parts[0], 64); err == nil {
+				minutes, err = strconv.ParseFloat(parts[1], 64)
+			}
+		case len(body) == 4:
+			if hours, err = strconv.ParseFloat(body[:2], 64); err == nil {
+				minutes, err = strconv.ParseFloat(body[2:], 64)
+			}
+		default:
+			hours, err = strconv.ParseFloat(body, 64)
+		}
+		if err != nil || hours > 14 || minutes >= 60 {
+			return 0, false
+		}
+		return sign * (hours + minutes/60), true
+	}
+
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return 0, false
+	}
+	_, seconds := time.Now().In(loc).Zone()
+	return float64(seconds) / 3600, true
+}
+
+// ValidateTimeZone 校验参赛者填写的时区
+func ValidateTimeZone(tz string) error {
+	if strings.TrimSpace(tz) == "" {
+		return nil
+	}
+	if _, ok := parseTimeZoneOffset(tz); !ok {
+		return errors.New("无效的时区")
+	}
+	return nil
+}
+
+// timeZoneGap 两个时区偏移的时差（小时，考虑跨日，最大 12）
+func timeZoneGap(a, b float64) float64 {
+	gap := math.Abs(a - b)
+	if gap > 12 {
+		gap = 24 - gap
+	}
+	return gap
+}
+
+// timeZoneScore 按时差计分，时差越小协作越方便
+func timeZoneScore(gap float64) int {
+	switch {
+	case gap <= 2:
+		return 3
+	case gap <= 5:
+		return 2
+	case gap <= 8:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// scoreCandidate 计算候选参赛者与一组队员（及队伍招募的角色）的匹配得分
+func scoreCandidate(members []models.Participant, lookingForRoles []string, candidate *models.Participant) MatchDetail {
+	detail := MatchDetail{
+		MatchedRoles:        make([]string, 0),
+		NewRoles:            make([]string, 0),
+		ComplementarySkills: make([]string, 0),
+	}
+
+	candidateSkills := parseTags(candidate.Skills)
+	candidateRoles := parseTags(candidate.SeekingRoles)
+	offered := make(map[string]bool)
+	for _, tag := range append(candidateRoles, candidateSkills...) {
+		offered[tag] = true
+	}
+
+	// 命中队伍招募的角色（参赛者希望担任的角色或具备的技能）
+	for _, role := range lookingForRoles {
+		if offered[role] {
+			detail.MatchedRoles = append(detail.MatchedRoles, role)
+		}
+	}
+
+	teamRoles := make(map[string]bool)
+	teamSkills := make(map[string]bool)
+	candidateOffset, candidateHasTZ := parseTimeZoneOffset(candidate.TimeZone)
+	for _, member := range members {
+		for _, role := range parseTags(member.SeekingRoles) {
+			teamRoles[role] = true
+		}
+		for _, skill := range parseTags(member.Skills) {
+			teamSkills[skill] = true
+		}
+		if offset, ok := parseTimeZoneOffset(member.TimeZone); ok && candidateHasTZ {
+			gap := timeZoneGap(candidateOffset, offset)
+			if detail.TimeZoneGap == nil || gap < *detail.TimeZoneGap {
+				detail.TimeZoneGap = &gap
+			}
+		}
+	}
+
+	// 补齐队内空缺的角色与技能
+	for _, role := range candidateRoles {
+		if !teamRoles[role] {
+			detail.NewRoles = append(detail.NewRoles, role)
+		}
+	}
+	for _, skill := range candidateSkills {
+		if !teamSkills[skill] {
+			detail.ComplementarySkills = append(detail.ComplementarySkills, skill)
+		}
+	}
+
+	newSkills := len(detail.ComplementarySkills)
+	if newSkills > matchMaxNewSkills {
+		newSkills = matchMaxNewSkills
+	}
+	detail.Score = len(detail.MatchedRoles)*matchRoleScore + len(detail.NewRoles)*matchNewRoleScore + newSkills*matchNewSkillScore
+	if detail.TimeZoneGap != nil {
+		detail.Score += timeZoneScore(*detail.TimeZoneGap)
+	}
+	return detail
+}
+
+// teamParticipants 队伍成员的参赛者信息（需已预加载 Members.Participant）
+func teamParticipants(team *models.Team) []models.Participant {
+	participants := make([]models.Participant, 0, len(team.Members))
+	for _, member := range team.Members {
+		participants = append(participants, member.Participant)
+	}
+	return participants
+}
+
+// normalizeMatchLimit 规范化推荐数量
+func normalizeMatchLimit(limit int) int {
+	if limit <= 0 {
+		return defaultMatchLimit
+	}
+	if limit > maxMatchLimit {
+		return maxMatchLimit
+	}
+	return limit
+}
+
+// getTeamFormationHackathon 获取处于组队阶段的活动
+func getTeamFormationHackathon(hackathonID uint64) (*models.Hackathon, error) {
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
+		return nil, errors.New("活动不存在")
+	}
+	if hackathon.Status != "team_formation" {
+		return nil, errors.New("当前不在组队阶段")
+	}
+	return &hackathon, nil
+}
+
+// getFreeParticipants 活动中已签到且尚未加入任何队伍的参赛者（按签到时间排序）
+func (s *MatchmakingService) getFreeParticipants(hackathonID uint64) ([]models.Participant, error) {
+	var participants []models.Participant
+	err := database.DB.Model(&models.Participant{}).
+		Joins("JOIN checkins ON checkins.participant_id = participants.id").
+		Where("checkins.hackathon_id = ? AND participants.deleted_at IS NULL", hackathonID).
+		Where("participants.id NOT IN (?)", database.DB.Table("team_members").
+			Select("team_members.participant_id").
+			Joins("JOIN teams ON teams.id = team_members.team_id").
+			Where("teams.hackathon_id = ? AND teams.deleted_at IS NULL", hackathonID)).
+		Order("checkins.created_at ASC, participants.id ASC").
+		Find(&participants).Error
+	return participants, err
+}
+
+// getRecruitingTeams 活动中仍在招募且未满员的队伍，joinPolicies 为空表示不限加入方式
+func (s *MatchmakingService) getRecruitingTeams(hackathonID uint64, joinPolicies ...string) ([]models.Team, error) {
+	var teams []models.Team
+	query := database.DB.Preload("Leader").Preload("Members").Preload("Members.Participant").
+		Where("hackathon_id = ? AND status = ? AND deleted_at IS NULL", hackathonID, "recruiting")
+	if len(joinPolicies) > 0 {
+		query = query.Where("join_policy IN ?", joinPolicies)
+	}
+	if err := query.Order("id ASC").Find(&teams).Error; err != nil {
+		return nil, err
+	}
+
+	open := make([]models.Team, 0, len(teams))
+	for _, team := range teams {
+		if len(team.Members) < team.MaxSize {
+			open = append(open, team)
+		}
+	}
+	return open, nil
+}
+
+// RecommendTeams 为尚未组队的参赛者推荐队伍（仅推荐可直接加入或可申请加入、且未满员的队伍）
+func (s *MatchmakingService) RecommendTeams(hackathonID, participantID uint64, limit int) ([]TeamMatch, error) {
+	if _, err := getTeamFormationHackathon(hackathonID); err != nil {
+		return nil, err
+	}
+
+	registrationService := &RegistrationService{}
+	checkedIn, _, err := registrationService.GetCheckinStatus(hackathonID, participantID)
+	if err != nil {
+		return nil, err
+	}
+	if !checkedIn {
+		return nil, errors.New("请先完成签到")
+	}
+
+	teamService := &TeamService{}
+	if team, _ := teamService.GetUserTeam(hackathonID, participantID); team != nil {
+		return nil, errors.New("您已经在队伍中")
+	}
+
+	var participant models.Participant
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", participantID).First(&participant).Error; err != nil {
+		return nil, errors.New("参赛者不存在")
+	}
+
+	teams, err := s.getRecruitingTeams(hackathonID, "open", "request")
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]TeamMatch, 0, len(teams))
+	for _, team := range teams {
+		matches = append(matches, TeamMatch{
+			MatchDetail: scoreCandidate(teamParticipants(&team), parseTags(team.LookingForRoles), &participant),
+			Team:        team,
+			OpenSlots:   team.MaxSize - len(team.Members),
+		})
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].OpenSlots > matches[j].OpenSlots
+	})
+
+	if limit = normalizeMatchLimit(limit); len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// RecommendParticipants 为队伍推荐已签到且尚未组队的参赛者（仅队长）
+func (s *MatchmakingService) RecommendParticipants(teamID, leaderID uint64, limit int) ([]ParticipantMatch, error) {
+	var team models.Team
+	if err := database.DB.Preload("Members").Preload("Members.Participant").
+		Where("id = ? AND deleted_at IS NULL", teamID).First(&team).Error; err != nil {
+		return nil, errors.New("队伍不存在")
+	}
+	if team.LeaderID != leaderID {
+		return nil, errors.New("只有队长可以查看推荐队友")
+	}
+	if team.Status != "recruiting" {
+		return nil, errors.New("队伍已锁定")
+	}
+	if _, err := getTeamFormationHackathon(team.HackathonID); err != nil {
+		return nil, err
+	}
+
+	candidates, err := s.getFreeParticipants(team.HackathonID)
+	if err != nil {
+		return nil, err
+	}
+
+	members := teamParticipants(&team)
+	lookingForRoles := parseTags(team.LookingForRoles)
+	matches := make([]ParticipantMatch, 0, len(candidates))
+	for i := range candidates {
+		matches = append(matches, ParticipantMatch{
+			MatchDetail: scoreCandidate(members, lookingForRoles, &candidates[i]),
+			Participant: candidates[i],
+		})
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+
+	if limit = normalizeMatchLimit(limit); len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// pickBestCandidate 从候选参赛者中选出与当前队员最匹配的一位，返回其下标
+func pickBestCandidate(members []models.Participant, lookingForRoles []string, candidates []models.Participant) int {
+	best, bestScore := 0, -1
+	for i := range candidates {
+		if score := scoreCandidate(members, lookingForRoles, &candidates[i]).Score; score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	return best
+}
+
+// AutoGroup 自动组队（仅活动创建者，组队阶段内），dryRun 为 true 时仅返回分组方案，不落库
+func (s *MatchmakingService) AutoGroup(hackathonID, userID uint64, userRole string, dryRun bool) ([]AutoGroupAssignment, error) {
+	if userRole == "admin" {
+		return nil, errors.New("Admin不能自动组队")
+	}
+	hackathon, err := getTeamFormationHackathon(hackathonID)
+	if err != nil {
+		return nil, err
+	}
+	if hackathon.OrganizerID != userID {
+		return nil, errors.New("只能为自己创建的活动自动组队")
+	}
+
+	assignments, err := s.planAutoGroup(hackathon)
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return assignments, nil
+	}
+
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return applyAutoGroup(tx, hackathon, assignments)
+	}); err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+// SetAutoGroupOnClose 设置组队阶段结束时是否自动组队（仅活动创建者，组队阶段结束前）
+func (s *MatchmakingService) SetAutoGroupOnClose(hackathonID uint64, enabled bool, userID uint64, userRole string) error {
+	if userRole == "admin" {
+		return errors.New("Admin不能修改自动组队设置")
+	}
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
+		return errors.New("活动不存在")
+	}
+	if hackathon.OrganizerID != userID {
+		return errors.New("只能修改自己创建的活动")
+	}
+	switch hackathon.Status {
+	case "submission", "voting", "results":
+		return errors.New("组队阶段已结束，无法修改自动组队设置")
+	}
+	return database.DB.Model(&hackathon).Update("auto_group_on_close", enabled).Error
+}
+
+// autoGroupOnClose 组队阶段结束时的自动组队（活动开启 auto_group_on_close 时，在切换阶段的事务中、锁定队伍前调用）
+func autoGroupOnClose(tx *gorm.DB, hackathon *models.Hackathon) error {
+	matchmakingService := &MatchmakingService{}
+	assignments, err := matchmakingService.planAutoGroup(hackathon)
+	if err != nil {
+		return err
+	}
+	return applyAutoGroup(tx, hackathon, assignments)
+}
+
+// planAutoGroup 生成自动组队方案：将已签到但尚未组队的参赛者先补入可直接加入且未满员的队伍，
+// 其余参赛者按匹配度分成人数均衡、不超过活动最大队伍人数的新队伍
+func (s *MatchmakingService) planAutoGroup(hackathon *models.Hackathon) ([]AutoGroupAssignment, error) {
+	free, err := s.getFreeParticipants(hackathon.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(free) == 0 {
+		return []AutoGroupAssignment{}, nil
+	}
+
+	maxSize := hackathon.MaxTeamSize
+	if maxSize <= 0 {
+		maxSize = defaultAutoGroupSize
+	}
+
+	assignments := make([]AutoGroupAssignment, 0)
+
+	// 先补入可直接加入且未满员的队伍
+	teams, err := s.getRecruitingTeams(hackathon.ID, "open")
+	if err != nil {
+		return nil, err
+	}
+	for _, team := range teams {
+		members := teamParticipants(&team)
+		lookingForRoles := parseTags(team.LookingForRoles)
+		slots := team.MaxSize - len(team.Members)
+		if team.MaxSize > maxSize {
+			slots = maxSize - len(team.Members)
+		}
+		assignment := AutoGroupAssignment{TeamID: team.ID, TeamName: team.Name, ParticipantIDs: make([]uint64, 0)}
+		for ; slots > 0 && len(free) > 0; slots-- {
+			i := pickBestCandidate(members, lookingForRoles, free)
+			members = append(members, free[i])
+			assignment.ParticipantIDs = append(assignment.ParticipantIDs, free[i].ID)
+			free = append(free[:i], free[i+1:]...)
+		}
+		if len(assignment.ParticipantIDs) > 0 {
+			assignments = append(assignments, assignment)
+		}
+	}
+
+	// 其余参赛者组成新队伍，队伍人数尽量均衡（例如 7 人、最大 3 人时分为 3、2、2）
+	lastNumber, err := lastAutoTeamNumber(hackathon.ID)
+	if err != nil {
+		return nil, err
+	}
+	teamCount := (len(free) + maxSize - 1) / maxSize
+	for k := 0; k < teamCount; k++ {
+		size := len(free) / (teamCount - k)
+		if len(free)%(teamCount-k) != 0 {
+			size++
+		}
+		// 签到最早的参赛者担任队长，再依次加入最匹配的参赛者
+		members := []models.Participant{free[0]}
+		free = free[1:]
+		for len(members) < size {
+			i := pickBestCandidate(members, nil, free)
+			members = append(members, free[i])
+			free = append(free[:i], free[i+1:]...)
+		}
+
+		assignment := AutoGroupAssignment{
+			TeamName:       fmt.Sprintf("%s%d", autoTeamNamePrefix, lastNumber+k+1),
+			NewTeam:        true,
+			ParticipantIDs: make([]uint64, 0, len(members)),
+		}
+		for _, member := range members {
+			assignment.ParticipantIDs = append(assignment.ParticipantIDs, member.ID)
+		}
+		assignments = append(assignments, assignment)
+	}
+
+	return assignments, nil
+}
+
+// lastAutoTeamNumber 活动中已有自动组队队伍的最大序号，新队伍从其后继续编号（队伍解散后序号不复用）
+func lastAutoTeamNumber(hackathonID uint64) (int, error) {
+	var names []string
+	if err := database.DB.Unscoped().Model(&models.Team{}).
+		Where("hackathon_id = ? AND name LIKE ?", hackathonID, autoTeamNamePrefix+"%").
+		Pluck("name", &names).Error; err != nil {
+		return 0, err
+	}
+	last := 0
+	for _, name := range names {
+		if n, err := strconv.Atoi(strings.TrimPrefix(name, autoTeamNamePrefix)); err == nil && n > last {
+			last = n
+		}
+	}
+	return last, nil
+}
+
+// applyAutoGroup 按自动组队方案创建新队伍并写入成员记录，取消被分组参赛者待审批的加入申请
+func applyAutoGroup(tx *gorm.DB, hackathon *models.Hackathon, assignments []AutoGroupAssignment) error {
+	maxSize := hackathon.MaxTeamSize
+	if maxSize <= 0 {
+		maxSize = defaultAutoGroupSize
+	}
+	now := time.Now()
+	for i := range assignments {
+		assignment := &assignments[i]
+		ids := assignment.ParticipantIDs
+		if assignment.NewTeam {
+			team := models.Team{
+				HackathonID: hackathon.ID,
+				Name:        assignment.TeamName,
+				LeaderID:    ids[0],
+				MaxSize:     maxSize,
+				Status:      "recruiting",
+				JoinPolicy:  "open",
+			}
+			if err := tx.Create(&team).Error; err != nil {
+				return fmt.Errorf("创建队伍失败: %w", err)
+			}
+			assignment.TeamID = team.ID
+			if err := emitTeamCreated(tx, &team, ids, true); err != nil {
+				return err
+			}
+		}
+
+		for _, participantID := range ids {
+			role := "member"
+			if assignment.NewTeam && participantID == ids[0] {
+				role = "leader"
+			}
+			member := models.TeamMember{
+				TeamID:        assignment.TeamID,
+				ParticipantID: participantID,
+				Role:          role,
+				JoinedAt:      now,
+			}
+			if err := tx.Create(&member).Error; err != nil {
+				return fmt.Errorf("创建成员记录失败: %w", err)
+			}
+		}
+
+		if err := tx.Model(&models.TeamJoinRequest{}).
+			Where("hackathon_id = ? AND participant_id IN ? AND status = ?", hackathon.ID, ids, "pending").
+			Update("status", "cancelled").Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
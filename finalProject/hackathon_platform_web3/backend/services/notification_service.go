@@ -0,0 +1,566 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"text/template"
+	"time"
+
+	"hackathon-backend/config"
+	"hackathon-backend/database"
+	"hackathon-backend/models"
+	"hackathon-backend/notify"
+
+	"gorm.io/gorm"
+)
+
+// NotificationService 事务性通知：业务操作在同一事务中写入发送队列，由后台任务发送，失败后按指数退避重试
+type NotificationService struct{}
+
+// 通知模板名称
+const (
+	NotifySponsorApproved       = "sponsor_approved"
+	NotifyStageChanged          = "stage_changed"
+	NotifyRegistrationConfirmed = "registration_confirmed"
+	NotifyTeamInvite            = "team_invite"
+	NotifyResultsPublished      = "results_published"
+	NotifyTeamMemberJoined      = "team_member_joined"
+	NotifyTeamMemberLeft        = "team_member_left"
+	NotifyTeamMemberRemoved     = "team_member_removed"
+	NotifyJoinRequestReviewed   = "join_request_reviewed"
+	NotifySponsorWelcome        = "sponsor_welcome"
+	NotifySponsorJoined         = "sponsor_joined"
+	NotifyRegistrationClosing   = "registration_closing"
+	NotifyCheckinOpen           = "checkin_open"
+	NotifySubmissionClosing     = "submission_closing"
+	NotifySponsorEventRequested = "sponsor_event_requested"
+	NotifySponsorEventReviewed  = "sponsor_event_reviewed"
+	NotifyBountyAwarded         = "bounty_awarded"
+	NotifyBountyPaid            = "bounty_paid"
+	NotifySponsorExpired        = "sponsor_expired"
+	NotifyWaitlistOffered       = "waitlist_offered"
+	notificationSendLease       = 5 * time.Minute // 任务被领取后的发送时限，超时未完成（如进程退出）可被再次领取
+	notificationMaxBackoff      = time.Hour
+	notificationBatchSize       = 50
+)
+
+// notificationTemplate 通知模板：邮件标题与正文（短信只使用正文）
+type notificationTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+var notificationTemplates = map[string]notificationTemplate{
+	NotifySponsorApproved: newNotificationTemplate(
+		"赞助申请已通过",
+		"您的赞助申请已审核通过。登录账号：{{.Phone}}，请在 {{.ExpiresAt}} 前打开以下链接设置登录密码（仅可使用一次）：{{.SetupURL}}",
+	),
+	NotifyStageChanged: newNotificationTemplate(
+		"「{{.HackathonName}}」已进入{{.StageName}}",
+		"您报名的活动「{{.HackathonName}}」已进入{{.StageName}}，请及时查看活动安排。",
+	),
+	NotifyRegistrationConfirmed: newNotificationTemplate(
+		`「{{.HackathonName}}」{{if eq .Status "pending"}}报名申请已提交{{else if eq .Status "rejected"}}报名未通过审核{{else}}报名成功{{end}}`,
+		`{{if eq .Status "pending"}}您已提交活动「{{.HackathonName}}」的报名申请，主办方审核后将另行通知。`+
+			`{{else if eq .Status "rejected"}}很遗憾，您在活动「{{.HackathonName}}」的报名申请未通过审核。{{with .ReviewNote}}审核备注：{{.}}{{end}}`+
+			`{{else}}您已成功报名活动「{{.HackathonName}}」，请留意签到与组队安排。{{end}}`,
+	),
+	NotifyTeamInvite: newNotificationTemplate(
+		"{{.InviterName}} 邀请您加入队伍「{{.TeamName}}」",
+		"{{.InviterName}} 邀请您加入活动「{{.HackathonName}}」的队伍「{{.TeamName}}」。邀请码：{{.Code}}，有效期至 {{.ExpiresAt}}。",
+	),
+	NotifyResultsPublished: newNotificationTemplate(
+		"「{{.HackathonName}}」结果已公布",
+		"您参加的活动「{{.HackathonName}}」已公布比赛结果，欢迎前往活动页面查看。",
+	),
+	NotifyTeamMemberJoined: newNotificationTemplate(
+		"{{.MemberName}} 加入了队伍「{{.TeamName}}」",
+		"{{.MemberName}} 已加入您在活动「{{.HackathonName}}」中的队伍「{{.TeamName}}」。",
+	),
+	NotifyTeamMemberLeft: newNotificationTemplate(
+		"{{.MemberName}} 退出了队伍「{{.TeamName}}」",
+		"{{.MemberName}} 已退出您在活动「{{.HackathonName}}」中的队伍「{{.TeamName}}」。",
+	),
+	NotifyTeamMemberRemoved: newNotificationTemplate(
+		"您已被移出队伍「{{.TeamName}}」",
+		"队长已将您移出活动「{{.HackathonName}}」的队伍「{{.TeamName}}」，您可以加入其他队伍或创建新队伍。",
+	),
+	NotifyJoinRequestReviewed: newNotificationTemplate(
+		"加入队伍「{{.TeamName}}」的申请{{if .Approved}}已通过{{else}}未通过{{end}}",
+		"{{if .Approved}}您已加入活动「{{.HackathonName}}」的队伍「{{.TeamName}}」。{{else}}队长拒绝了您加入活动「{{.HackathonName}}」队伍「{{.TeamName}}」的申请，您可以申请加入其他队伍。{{end}}",
+	),
+	NotifySponsorWelcome: newNotificationTemplate(
+		"欢迎成为赞助商",
+		"您的赞助申请已审核通过，赞助商账号已开通，设置登录密码的链接已通过短信发送到申请手机号。",
+	),
+	NotifySponsorJoined: newNotificationTemplate(
+		"「{{.HackathonName}}」新增赞助商",
+		"新的赞助商已通过审核，开始赞助您的活动「{{.HackathonName}}」{{with .TrackName}}的赛道「{{.}}」{{end}}。",
+	),
+	NotifySponsorEventRequested: newNotificationTemplate(
+		"{{.SponsorName}} 申请赞助「{{.HackathonName}}」",
+		"赞助商 {{.SponsorName}} 申请赞助您的活动「{{.HackathonName}}」{{with .TrackName}}的赛道「{{.}}」{{end}}，请及时审核。",
+	),
+	NotifySponsorEventReviewed: newNotificationTemplate(
+		"赞助「{{.HackathonName}}」的申请{{if .Approved}}已通过{{else}}未通过{{end}}",
+		"{{if .Approved}}主办方已同意您赞助活动「{{.HackathonName}}」{{with .TrackName}}的赛道「{{.}}」{{end}}，可在赞助商门户查看活动数据。"+
+			"{{else}}主办方未同意您赞助活动「{{.HackathonName}}」的申请。{{with .RejectReason}}原因：{{.}}{{end}}{{end}}",
+	),
+	NotifyRegistrationClosing: newNotificationTemplate(
+		"「{{.HackathonName}}」报名即将截止",
+		"活动「{{.HackathonName}}」的报名将于 {{.Deadline}} 截止，如需参加请尽快报名。",
+	),
+	NotifyCheckinOpen: newNotificationTemplate(
+		"「{{.HackathonName}}」签到已开放",
+		"您报名的活动「{{.HackathonName}}」已开放签到，请在 {{.Deadline}} 前完成签到。",
+	),
+	NotifySubmissionClosing: newNotificationTemplate(
+		"「{{.HackathonName}}」作品提交即将截止",
+		"活动「{{.HackathonName}}」的作品提交将于 {{.Deadline}} 截止，"+
+			"{{if .HasDraft}}您的队伍「{{.TeamName}}」目前只有作品草稿，请尽快完成正式提交。{{else}}您的队伍「{{.TeamName}}」尚未提交作品，请尽快提交。{{end}}",
+	),
+	NotifyBountyAwarded: newNotificationTemplate(
+		"作品「{{.SubmissionName}}」赢得赏金「{{.BountyTitle}}」",
+		"恭喜！您的队伍「{{.TeamName}}」在活动「{{.HackathonName}}」中凭作品「{{.SubmissionName}}」赢得赞助商 {{.SponsorName}} 的赏金「{{.BountyTitle}}」，"+
+			"奖励 {{.Reward}}，将发放至队长钱包 {{.Wallet}}。",
+	),
+	NotifySponsorExpired: newNotificationTemplate(
+		"长期赞助已到期",
+		"您的长期赞助已于 {{.ExpiredAt}} 到期，Logo 不再展示在平台长期赞助商列表中，已赞助活动中的展示不受影响。如需续期请联系平台管理员。",
+	),
+	NotifyWaitlistOffered: newNotificationTemplate(
+		"「{{.HackathonName}}」候补名额已递补给您",
+		"您在活动「{{.HackathonName}}」的候补已轮到递补，请在 {{.ExpiresAt}} 前确认报名，逾期未确认名额将递补给下一位。",
+	),
+	NotifyBountyPaid: newNotificationTemplate(
+		"赏金「{{.BountyTitle}}」奖励已发放",
+		"赞助商 {{.SponsorName}} 已向钱包 {{.Wallet}} 发放赏金「{{.BountyTitle}}」的奖励 {{.Reward}}，交易签名：{{.TxHash}}。",
+	),
+}
+
+// 通知接收方类型与渠道
+const (
+	RecipientParticipant = "participant"
+	RecipientUser        = "user"
+	ChannelInApp         = "in_app"
+)
+
+// NotificationEventType 可在通知偏好中单独开关的事件类型
+type NotificationEventType struct {
+	Type     string   `json:"type"`
+	Name     string   `json:"name"`
+	Channels []string `json:"channels"` // 支持的渠道：in_app、email
+	OptIn    bool     `json:"opt_in"`   // 需接收方主动开启（未设置时默认关闭），用于面向未报名参赛者的推广类提醒
+}
+
+// notificationEventTypes 各类接收方可收到的事件类型（赞助商设置密码短信不可关闭，不在此列）
+var notificationEventTypes = map[string][]NotificationEventType{
+	RecipientParticipant: {
+		{Type: NotifyStageChanged, Name: "活动阶段变更", Channels: []string{ChannelInApp, notify.ChannelEmail}},
+		{Type: NotifyResultsPublished, Name: "比赛结果公布", Channels: []string{ChannelInApp, notify.ChannelEmail}},
+		{Type: NotifyRegistrationConfirmed, Name: "报名确认与审核结果", Channels: []string{ChannelInApp, notify.ChannelEmail}},
+		{Type: NotifyWaitlistOffered, Name: "候补递补待确认", Channels: []string{ChannelInApp, notify.ChannelEmail}},
+		{Type: NotifyTeamInvite, Name: "组队邀请", Channels: []string{ChannelInApp, notify.ChannelEmail}},
+		{Type: NotifyTeamMemberJoined, Name: "新队员加入", Channels: []string{ChannelInApp, notify.ChannelEmail}},
+		{Type: NotifyTeamMemberLeft, Name: "队员退出", Channels: []string{ChannelInApp, notify.ChannelEmail}},
+		{Type: NotifyTeamMemberRemoved, Name: "被移出队伍", Channels: []string{ChannelInApp, notify.ChannelEmail}},
+		{Type: NotifyJoinRequestReviewed, Name: "加入申请审批结果", Channels: []string{ChannelInApp, notify.ChannelEmail}},
+		{Type: NotifyRegistrationClosing, Name: "报名截止提醒（未报名的活动）", Channels: []string{ChannelInApp, notify.ChannelEmail}, OptIn: true},
+		{Type: NotifyCheckinOpen, Name: "签到开放提醒", Channels: []string{ChannelInApp, notify.ChannelEmail}},
+		{Type: NotifySubmissionClosing, Name: "作品提交截止提醒", Channels: []string{ChannelInApp, notify.ChannelEmail}},
+		{Type: NotifyBountyAwarded, Name: "赢得赞助商赏金", Channels: []string{ChannelInApp, notify.ChannelEmail}},
+		{Type: NotifyBountyPaid, Name: "赏金奖励发放", Channels: []string{ChannelInApp, notify.ChannelEmail}},
+	},
+	RecipientUser: {
+		{Type: NotifySponsorWelcome, Name: "赞助商账号开通", Channels: []string{ChannelInApp}},
+		{Type: NotifySponsorJoined, Name: "活动新增赞助商", Channels: []string{ChannelInApp}},
+		{Type: NotifySponsorEventRequested, Name: "赞助商申请赞助活动", Channels: []string{ChannelInApp}},
+		{Type: NotifySponsorEventReviewed, Name: "追加赞助审核结果", Channels: []string{ChannelInApp}},
+		{Type: NotifySponsorExpired, Name: "长期赞助到期", Channels: []string{ChannelInApp}},
+	},
+}
+
+// stageNames 活动阶段的展示名称
+var stageNames = map[string]string{
+	"published":      "发布阶段",
+	"registration":   "报名阶段",
+	"checkin":        "签到阶段",
+	"team_formation": "组队阶段",
+	"submission":     "提交阶段",
+	"voting":         "投票阶段",
+	"results":        "公布结果阶段",
+}
+
+func newNotificationTemplate(subject, body string) notificationTemplate {
+	return notificationTemplate{
+		subject: template.Must(template.New("subject").Option("missingkey=zero").Parse(subject)),
+		body:    template.Must(template.New("body").Option("missingkey=zero").Parse(body)),
+	}
+}
+
+// renderNotification 渲染通知模板，返回标题与正文
+func renderNotification(name string, data map[string]interface{}) (string, string, error) {
+	tpl, ok := notificationTemplates[name]
+	if !ok {
+		return "", "", fmt.Errorf("通知模板不存在: %s", name)
+	}
+	var subject, body strings.Builder
+	if err := tpl.subject.Execute(&subject, data); err != nil {
+		return "", "", err
+	}
+	if err := tpl.body.Execute(&body, data); err != nil {
+		return "", "", err
+	}
+	return subject.String(), body.String(), nil
+}
+
+// newNotificationJob 渲染模板生成待发送的通知任务
+func newNotificationJob(channel, recipient, name string, data map[string]interface{}, sensitive bool) (*models.NotificationJob, error) {
+	subject, body, err := renderNotification(name, data)
+	if err != nil {
+		return nil, err
+	}
+	return &models.NotificationJob{
+		Channel:       channel,
+		Recipient:     strings.TrimSpace(recipient),
+		Template:      name,
+		Subject:       subject,
+		Body:          body,
+		Sensitive:     sensitive,
+		Status:        "pending",
+		NextAttemptAt: time.Now(),
+	}, nil
+}
+
+// enqueueNotification 在事务中写入一条通知，收件人为空时忽略；sensitive 表示正文含设置密码链接等敏感内容，发送结束后清空
+func enqueueNotification(tx *gorm.DB, channel, recipient, name string, data map[string]interface{}, sensitive bool) error {
+	if strings.TrimSpace(recipient) == "" {
+		return nil
+	}
+	job, err := newNotificationJob(channel, recipient, name, data, sensitive)
+	if err != nil {
+		return err
+	}
+	return tx.Create(job).Error
+}
+
+// optInEvent 判断事件类型是否需接收方主动开启
+func optInEvent(recipientType, eventType string) bool {
+	for _, item := range notificationEventTypes[recipientType] {
+		if item.Type == eventType {
+			return item.OptIn
+		}
+	}
+	return false
+}
+
+// disabledRecipients 查询关闭了某事件类型某渠道通知的接收方；需主动开启的事件类型中未开启的接收方同样视为关闭
+func disabledRecipients(tx *gorm.DB, recipientType string, recipientIDs []uint64, eventType, channel string) (map[uint64]bool, error) {
+	optIn := optInEvent(recipientType, eventType)
+	var ids []uint64
+	if err := tx.Model(&models.NotificationPreference{}).
+		Where("recipient_type = ? AND recipient_id IN ? AND event_type = ? AND channel = ? AND enabled = ?",
+			recipientType, recipientIDs, eventType, channel, optIn).
+		Pluck("recipient_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	disabled := make(map[uint64]bool, len(recipientIDs))
+	if optIn {
+		enabled := make(map[uint64]bool, len(ids))
+		for _, id := range ids {
+			enabled[id] = true
+		}
+		for _, id := range recipientIDs {
+			if !enabled[id] {
+				disabled[id] = true
+			}
+		}
+		return disabled, nil
+	}
+	for _, id := range ids {
+		disabled[id] = true
+	}
+	return disabled, nil
+}
+
+// createInAppNotifications 为未关闭站内通知的接收方写入站内通知
+func createInAppNotifications(tx *gorm.DB, recipientType string, recipientIDs []uint64, hackathonID uint64, name string, data map[string]interface{}) error {
+	title, content, err := renderNotification(name, data)
+	if err != nil {
+		return err
+	}
+	disabled, err := disabledRecipients(tx, recipientType, recipientIDs, name, ChannelInApp)
+	if err != nil {
+		return err
+	}
+
+	var hackathon *uint64
+	if hackathonID > 0 {
+		hackathon = &hackathonID
+	}
+	notifications := make([]models.Notification, 0, len(recipientIDs))
+	for _, id := range recipientIDs {
+		if disabled[id] {
+			continue
+		}
+		notifications = append(notifications, models.Notification{
+			RecipientType: recipientType,
+			RecipientID:   id,
+			Type:          name,
+			Title:         title,
+			Content:       content,
+			HackathonID:   hackathon,
+		})
+	}
+	if len(notifications) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(&notifications, 500).Error
+}
+
+// notifyParticipants 向参赛者发送通知：写入站内通知，并向填写了邮箱的参赛者发送邮件（均按各自的通知偏好）
+func notifyParticipants(tx *gorm.DB, participantIDs []uint64, hackathonID uint64, name string, data map[string]interface{}) error {
+	if len(participantIDs) == 0 {
+		return nil
+	}
+	if err := createInAppNotifications(tx, RecipientParticipant, participantIDs, hackathonID, name, data); err != nil {
+		return err
+	}
+
+	var participants []models.Participant
+	if err := tx.Select("id", "email").
+		Where("id IN ? AND email <> '' AND deleted_at IS NULL", participantIDs).
+		Find(&participants).Error; err != nil {
+		return err
+	}
+	if len(participants) == 0 {
+		return nil
+	}
+	disabled, err := disabledRecipients(tx, RecipientParticipant, participantIDs, name, notify.ChannelEmail)
+	if err != nil {
+		return err
+	}
+
+	jobs := make([]models.NotificationJob, 0, len(participants))
+	for _, participant := range participants {
+		if disabled[participant.ID] {
+			continue
+		}
+		job, err := newNotificationJob(notify.ChannelEmail, participant.Email, name, data, false)
+		if err != nil {
+			return err
+		}
+		jobs = append(jobs, *job)
+	}
+	if len(jobs) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(&jobs, 500).Error
+}
+
+// notifyUsers 向后台用户（主办方、赞助商等）发送站内通知
+func notifyUsers(tx *gorm.DB, userIDs []uint64, hackathonID uint64, name string, data map[string]interface{}) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	return createInAppNotifications(tx, RecipientUser, userIDs, hackathonID, name, data)
+}
+
+// participantDisplayName 参赛者在通知中的展示名称（未设置昵称时使用钱包地址）
+func participantDisplayName(tx *gorm.DB, participantID uint64) string {
+	var participant models.Participant
+	if err := tx.Select("id", "nickname", "wallet_address").Where("id = ?", participantID).First(&participant).Error; err != nil {
+		return ""
+	}
+	if participant.Nickname != "" {
+		return participant.Nickname
+	}
+	return participant.WalletAddress
+}
+
+// notifyRegistrants 向活动的报名者（不含审核未通过的）发送邮件通知
+func notifyRegistrants(tx *gorm.DB, hackathonID uint64, name string, data map[string]interface{}) error {
+	var participantIDs []uint64
+	if err := tx.Model(&models.Registration{}).
+		Where("hackathon_id = ? AND status <> ?", hackathonID, "rejected").
+		Pluck("participant_id", &participantIDs).Error; err != nil {
+		return err
+	}
+	return notifyParticipants(tx, participantIDs, hackathonID, name, data)
+}
+
+// notifyStageChanged 活动切换阶段后通知报名者，进入公布结果阶段时发送结果通知；发送失败不影响阶段切换
+func notifyStageChanged(hackathon *models.Hackathon, stage string) {
+	data := map[string]interface{}{
+		"HackathonName": hackathon.Name,
+		"StageName":     stageNames[stage],
+	}
+	name := NotifyStageChanged
+	if stage == "results" {
+		name = NotifyResultsPublished
+	}
+	if err := notifyRegistrants(database.DB, hackathon.ID, name, data); err != nil {
+		log.Printf("活动 %d 阶段通知写入失败: %v", hackathon.ID, err)
+	}
+}
+
+// notifyRegistrationConfirmed 按报名状态通知参赛者报名成功、申请已提交或审核未通过
+func notifyRegistrationConfirmed(tx *gorm.DB, hackathon *models.Hackathon, registration *models.Registration) error {
+	return notifyParticipants(tx, []uint64{registration.ParticipantID}, hackathon.ID, NotifyRegistrationConfirmed, map[string]interface{}{
+		"HackathonName": hackathon.Name,
+		"Status":        registration.Status,
+		"ReviewNote":    registration.ReviewNote,
+	})
+}
+
+// notificationBackoff 第 attempts 次发送失败后的重试间隔：1 分钟起按 2 的幂增长，最长 1 小时
+func notificationBackoff(attempts int) time.Duration {
+	backoff := time.Minute
+	for i := 1; i < attempts && backoff < notificationMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > notificationMaxBackoff {
+		backoff = notificationMaxBackoff
+	}
+	return backoff
+}
+
+// StartNotificationWorker 启动后台发送任务，按配置的间隔轮询发送队列
+func StartNotificationWorker() {
+	interval := time.Duration(config.AppConfig.Notification.PollSeconds) * time.Second
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			processNotificationJobs()
+			<-ticker.C
+		}
+	}()
+}
+
+// processNotificationJobs 领取并发送到期的通知，直到队列中没有到期任务
+func processNotificationJobs() {
+	for {
+		var jobs []models.NotificationJob
+		if err := database.DB.Where("status = ? AND next_attempt_at <= ?", "pending", time.Now()).
+			Order("next_attempt_at ASC, id ASC").Limit(notificationBatchSize).Find(&jobs).Error; err != nil {
+			log.Printf("查询通知队列失败: %v", err)
+			return
+		}
+		sent := 0
+		for i := range jobs {
+			if claimNotificationJob(&jobs[i]) {
+				deliverNotificationJob(&jobs[i])
+				sent++
+			}
+		}
+		if len(jobs) < notificationBatchSize || sent == 0 {
+			return
+		}
+	}
+}
+
+// claimNotificationJob 将任务的下次尝试时间推后以领取任务，多个实例同时运行时只有一个能领取成功
+func claimNotificationJob(job *models.NotificationJob) bool {
+	result := database.DB.Model(&models.NotificationJob{}).
+		Where("id = ? AND status = ? AND next_attempt_at = ?", job.ID, "pending", job.NextAttemptAt).
+		Update("next_attempt_at", time.Now().Add(notificationSendLease))
+	return result.Error == nil && result.RowsAffected == 1
+}
+
+// deliverNotificationJob 发送通知并记录结果，失败时安排重试或标记为失败
+func deliverNotificationJob(job *models.NotificationJob) {
+	err := notify.Send(notify.Message{
+		Channel:   job.Channel,
+		To:        job.Recipient,
+		Subject:   job.Subject,
+		Body:      job.Body,
+		Sensitive: job.Sensitive,
+	})
+
+	now := time.Now()
+	attempts := job.Attempts + 1
+	updates := map[string]interface{}{"attempts": attempts}
+	if err == nil {
+		updates["status"] = "sent"
+		updates["sent_at"] = now
+		updates["last_error"] = ""
+	} else {
+		lastError := err.Error()
+		if len(lastError) > 1000 {
+			lastError = lastError[:1000]
+		}
+		updates["last_error"] = lastError
+
+		maxAttempts := config.AppConfig.Notification.MaxAttempts
+		if maxAttempts <= 0 {
+			maxAttempts = 5
+		}
+		if attempts >= maxAttempts {
+			updates["status"] = "failed"
+			log.Printf("通知 %d 发送失败（已尝试 %d 次）: %v", job.ID, attempts, err)
+		} else {
+			updates["next_attempt_at"] = now.Add(notificationBackoff(attempts))
+		}
+	}
+	// 含设置密码链接等敏感内容的通知发送结束后不再保留正文
+	if job.Sensitive && updates["status"] != nil {
+		updates["body"] = ""
+	}
+
+	if err := database.DB.Model(&models.NotificationJob{}).Where("id = ?", job.ID).Updates(updates).Error; err != nil {
+		log.Printf("更新通知 %d 状态失败: %v", job.ID, err)
+	}
+}
+
+// GetNotificationJobs 获取通知发送记录（可按状态、模板筛选），不返回敏感通知的正文
+func (s *NotificationService) GetNotificationJobs(page, pageSize int, status, templateName string) ([]models.NotificationJob, int64, error) {
+	query := database.DB.Model(&models.NotificationJob{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if templateName != "" {
+		query = query.Where("template = ?", templateName)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var jobs []models.NotificationJob
+	offset := (page - 1) * pageSize
+	if err := query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&jobs).Error; err != nil {
+		return nil, 0, err
+	}
+	for i := range jobs {
+		if jobs[i].Sensitive {
+			jobs[i].Body = ""
+		}
+	}
+	return jobs, total, nil
+}
+
+// RetryNotificationJob 重新发送失败的通知（重置尝试次数）
+func (s *NotificationService) RetryNotificationJob(id uint64) error {
+	var job models.NotificationJob
+	if err := database.DB.Where("id = ?", id).First(&job).Error; err != nil {
+		return errors.New("通知不存在")
+	}
+	if job.Status != "failed" {
+		return errors.New("只能重新发送失败的通知")
+	}
+	if job.Sensitive {
+		return errors.New("含敏感内容的通知不能重新发送，请为该账号重置密码")
+	}
+	return database.DB.Model(&job).Updates(map[string]interface{}{
+		"status":          "pending",
+		"attempts":        0,
+		"next_attempt_at": time.Now(),
+	}).Error
+}
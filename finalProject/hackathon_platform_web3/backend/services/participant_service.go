@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"net/mail"
 	"regexp"
 	"strings"
 	"time"
@@ -216,6 +217,39 @@ func (s *ParticipantService) UpdateProfile(participantID uint64, updates map[str
 		return errors.New("不允许修改钱包类型")
 	}
 
+	// 技能与希望担任的角色统一存为逗号分隔的小写标签，时区需可解析
+	for _, field := range []string{"skills", "seeking_roles"} {
+		if value, ok := updates[field]; ok {
+			tags, err := normalizeTags(value)
+			if err != nil {
+				return err
+			}
+			updates[field] = tags
+		}
+	}
+	if value, ok := updates["time_zone"]; ok {
+		tz, isString := value.(string)
+		if !isString {
+			return errors.New("无效的时区")
+		}
+		if err := ValidateTimeZone(tz); err != nil {
+			return err
+		}
+	}
+	if value, ok := updates["email"]; ok {
+		email, isString := value.(string)
+		if !isString {
+			return errors.New("无效的邮箱地址")
+		}
+		email = strings.TrimSpace(email)
+		if email != "" {
+			if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+				return errors.New("无效的邮箱地址")
+			}
+		}
+		updates["email"] = email
+	}
+
 	return database.DB.Model(&models.Participant{}).Where("id = ? AND deleted_at IS NULL", participantID).Updates(updates).Error
 }
 
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"hackathon-backend/database"
+	"hackathon-backend/events"
+	"hackathon-backend/models"
+)
+
+// RealtimeService 实时推送：活动阶段切换、队伍成员变化与实时计票
+type RealtimeService struct{}
+
+// liveTallyInterval 实时计票的推送间隔，投票密集时合并为一次推送
+const liveTallyInterval = time.Second
+
+var (
+	liveTallyMu      sync.Mutex
+	liveTallyPending = make(map[uint64]bool)
+)
+
+// ValidateLiveTally 校验实时计票可见性配置，为空时使用默认值 hidden
+func ValidateLiveTally(hackathon *models.Hackathon) error {
+	switch hackathon.LiveTally {
+	case "", "hidden", "public":
+		return nil
+	}
+	return errors.New("实时计票可见性必须是 hidden 或 public")
+}
+
+// liveTallyStaffOnly 实时计票是否仅推送给主办方（投票期间未公开时）
+func liveTallyStaffOnly(hackathon *models.Hackathon) bool {
+	return hackathon.Status == "voting" && hackathon.LiveTally != "public"
+}
+
+// publishStageChanged 推送活动阶段切换，进入投票阶段时同时推送初始计票
+func publishStageChanged(hackathon *models.Hackathon, previous, stage string) {
+	events.Publish(events.Event{
+		Type:        events.TypeStageChanged,
+		HackathonID: hackathon.ID,
+		Data: map[string]interface{}{
+			"previous": previous,
+			"status":   stage,
+		},
+	})
+	if stage == "voting" {
+		scheduleVoteTally(hackathon.ID)
+	}
+}
+
+// publishTeamUpdated 推送队伍成员变化，action 为 created、member_joined、member_left、member_removed、dissolved、leader_transferred
+func publishTeamUpdated(team *models.Team, action string, participantID uint64) {
+	if !events.HasSubscribers(team.HackathonID) {
+		return
+	}
+	var memberCount int64
+	database.DB.Model(&models.TeamMember{}).Where("team_id = ?", team.ID).Count(&memberCount)
+	events.Publish(events.Event{
+		Type:        events.TypeTeamUpdated,
+		HackathonID: team.HackathonID,
+		Data: map[string]interface{}{
+			"team_id":        team.ID,
+			"team_name":      team.Name,
+			"action":         action,
+			"participant_id": participantID,
+			"member_count":   memberCount,
+			"max_size":       team.MaxSize,
+		},
+	})
+}
+
+// scheduleVoteTally 投票变化后推送实时计票，liveTallyInterval 内的多次投票合并为一次推送
+func scheduleVoteTally(hackathonID uint64) {
+	if !events.HasSubscribers(hackathonID) {
+		return
+	}
+	liveTallyMu.Lock()
+	defer liveTallyMu.Unlock()
+	if liveTallyPending[hackathonID] {
+		return
+	}
+	liveTallyPending[hackathonID] = true
+	time.AfterFunc(liveTallyInterval, func() {
+		liveTallyMu.Lock()
+		delete(liveTallyPending, hackathonID)
+		liveTallyMu.Unlock()
+		publishVoteTally(hackathonID)
+	})
+}
+
+// publishVoteTally 计算并推送活动的实时计票
+func publishVoteTally(hackathonID uint64) {
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
+		return
+	}
+	tallies, err := computeLiveTally(&hackathon)
+	if err != nil {
+		log.Printf("活动 %d 实时计票失败: %v", hackathonID, err)
+		return
+	}
+	events.Publish(events.Event{
+		Type:        events.TypeVoteTally,
+		HackathonID: hackathonID,
+		Data:        tallies,
+		StaffOnly:   liveTallyStaffOnly(&hackathon),
+	})
+}
+
+// computeLiveTally 按活动投票方式计算全场与各赛道的当前得票（不含评委评分与同分决胜）
+func computeLiveTally(hackathon *models.Hackathon) ([]map[string]interface{}, error) {
+	trackService := &TrackService{}
+	tracks, err := trackService.GetTracks(hackathon.ID)
+	if err != nil {
+		return nil, err
+	}
+	trackIDs := []uint64{0}
+	for _, track := range tracks {
+		trackIDs = append(trackIDs, track.ID)
+	}
+
+	voteService := &VoteService{}
+	result := make([]map[string]interface{}, 0, len(trackIDs))
+	for _, trackID := range trackIDs {
+		query := database.DB.Model(&models.Submission{}).Where("hackathon_id = ? AND draft = 0", hackathon.ID)
+		if trackID > 0 {
+			query = query.Where("id IN (?)", database.DB.Model(&models.SubmissionTrack{}).Select("submission_id").Where("track_id = ?", trackID))
+		}
+		var candidateIDs []uint64
+		if err := query.Pluck("id", &candidateIDs).Error; err != nil {
+			return nil, err
+		}
+		tally, err := voteService.TallyVotes(hackathon, trackID, candidateIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		counts := make([]map[string]interface{}, 0, len(candidateIDs))
+		for _, id := range candidateIDs {
+			counts = append(counts, map[string]interface{}{
+				"submission_id": id,
+				"votes":         tally[id],
+			})
+		}
+		result = append(result, map[string]interface{}{
+			"track_id": trackID,
+			"tallies":  counts,
+		})
+	}
+	return result, nil
+}
+
+// streamSnapshot 建立推送连接时发送的当前状态，staff 为 true 时投票期间也包含实时计票
+func streamSnapshot(hackathon *models.Hackathon, staff bool) (map[string]interface{}, error) {
+	snapshot := map[string]interface{}{
+		"hackathon_id": hackathon.ID,
+		"status":       hackathon.Status,
+		"live_tally":   hackathon.LiveTally,
+	}
+	if hackathon.Status == "voting" && (staff || !liveTallyStaffOnly(hackathon)) {
+		tallies, err := computeLiveTally(hackathon)
+		if err != nil {
+			return nil, err
+		}
+		snapshot["tallies"] = tallies
+	}
+	return snapshot, nil
+}
+
+// SubscribeArena 参赛者订阅活动的实时推送，返回订阅与当前状态
+func (s *RealtimeService) SubscribeArena(hackathonID uint64) (*events.Subscription, map[string]interface{}, error) {
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND status <> ? AND deleted_at IS NULL", hackathonID, "preparation").First(&hackathon).Error; err != nil {
+		return nil, nil, errors.New("活动不存在")
+	}
+	snapshot, err := streamSnapshot(&hackathon, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return events.Subscribe(hackathonID), snapshot, nil
+}
+
+// SubscribeAdmin 主办方（活动创建者）或 Admin 订阅活动的实时推送，投票期间始终包含实时计票
+func (s *RealtimeService) SubscribeAdmin(hackathonID, userID uint64, userRole string) (*events.Subscription, map[string]interface{}, error) {
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
+		return nil, nil, errors.New("活动不存在")
+	}
+	if userRole != "admin" && hackathon.OrganizerID != userID {
+		return nil, nil, errors.New("只能订阅自己创建活动的实时推送")
+	}
+	snapshot, err := streamSnapshot(&hackathon, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	return events.Subscribe(hackathonID), snapshot, nil
+}
+
+// SetLiveTally 设置投票期间实时计票是否向参赛者公开（仅活动创建者，结果公布前）
+func (s *RealtimeService) SetLiveTally(hackathonID uint64, visibility string, userID uint64, userRole string) error {
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
+		return errors.New("活动不存在")
+	}
+	if userRole == "admin" {
+		return errors.New("Admin不能设置实时计票")
+	}
+	if hackathon.OrganizerID != userID {
+		return errors.New("只能设置自己创建活动的实时计票")
+	}
+	if hackathon.Status == "results" {
+		return errors.New("结果已公布，不能修改实时计票设置")
+	}
+	if visibility == "" {
+		return errors.New("实时计票可见性必须是 hidden 或 public")
+	}
+	if err := ValidateLiveTally(&models.Hackathon{LiveTally: visibility}); err != nil {
+		return err
+	}
+
+	if err := database.DB.Model(&hackathon).Update("live_tally", visibility).Error; err != nil {
+		return err
+	}
+	// 投票期间公开后立即向参赛者推送当前计票
+	if hackathon.Status == "voting" && visibility == "public" {
+		scheduleVoteTally(hackathonID)
+	}
+	return nil
+}
@@ -0,0 +1,477 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"hackathon-backend/database"
+	"hackathon-backend/models"
+	"hackathon-backend/storage"
+
+	"gorm.io/gorm"
+)
+
+// RegistrationFormService 自定义报名表与报名审核（申请制）
+type RegistrationFormService struct{}
+
+const (
+	registrationFormMaxFields = 50   // 报名表最多字段数
+	registrationTextMaxLength = 2000 // 文本答案最大长度
+)
+
+var registrationFieldTypes = map[string]bool{
+	"text":     true,
+	"select":   true,
+	"url":      true,
+	"checkbox": true,
+	"file":     true,
+}
+
+// decodeFieldOptions 解析 select 字段的可选项
+func decodeFieldOptions(fields []models.RegistrationField) {
+	for i := range fields {
+		fields[i].OptionList = []string{}
+		if fields[i].Options != "" {
+			_ = json.Unmarshal([]byte(fields[i].Options), &fields[i].OptionList)
+		}
+	}
+}
+
+// getRegistrationFields 获取活动报名表字段
+func getRegistrationFields(db *gorm.DB, hackathonID uint64) ([]models.RegistrationField, error) {
+	var fields []models.RegistrationField
+	if err := db.Where("hackathon_id = ?", hackathonID).Order("`order` ASC, id ASC").Find(&fields).Error; err != nil {
+		return nil, err
+	}
+	decodeFieldOptions(fields)
+	return fields, nil
+}
+
+// normalizeRegistrationField 校验并整理报名表字段
+func normalizeRegistrationField(field *models.RegistrationField) error {
+	field.Label = strings.TrimSpace(field.Label)
+	if field.Label == "" {
+		return errors.New("报名表字段名称不能为空")
+	}
+	if utf8.RuneCountInString(field.Label) > 100 {
+		return errors.New("报名表字段名称不能超过100个字符")
+	}
+	if !registrationFieldTypes[field.Type] {
+		return fmt.Errorf("报名表字段 %s 的类型无效", field.Label)
+	}
+
+	field.Options = ""
+	if field.Type != "select" {
+		field.OptionList = nil
+		return nil
+	}
+
+	options := make([]string, 0, len(field.OptionList))
+	seen := make(map[string]bool, len(field.OptionList))
+	for _, option := range field.OptionList {
+		option = strings.TrimSpace(option)
+		if option == "" || seen[option] {
+			continue
+		}
+		seen[option] = true
+		options = append(options, option)
+	}
+	if len(options) == 0 {
+		return fmt.Errorf("报名表字段 %s 至少需要一个选项", field.Label)
+	}
+	encoded, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+	field.Options = string(encoded)
+	field.OptionList = options
+	return nil
+}
+
+// deleteAnswerFiles 删除报名表答案中上传的文件
+func deleteAnswerFiles(answers []models.RegistrationAnswer) {
+	keys := make([]string, 0)
+	for _, answer := range answers {
+		if answer.FileName != "" && answer.Value != "" {
+			keys = append(keys, answer.Value)
+		}
+	}
+	if len(keys) > 0 {
+		storage.DeleteFiles(keys...)
+	}
+}
+
+// deleteParticipantAnswers 删除参赛者在活动中的报名表答案，返回被删除的答案，提交事务后由调用方删除其中的文件
+func deleteParticipantAnswers(tx *gorm.DB, hackathonID, participantID uint64) ([]models.RegistrationAnswer, error) {
+	var answers []models.RegistrationAnswer
+	if err := tx.Where("hackathon_id = ? AND participant_id = ?", hackathonID, participantID).Find(&answers).Error; err != nil {
+		return nil, err
+	}
+	if len(answers) == 0 {
+		return nil, nil
+	}
+	if err := tx.Where("hackathon_id = ? AND participant_id = ?", hackathonID, participantID).Delete(&models.RegistrationAnswer{}).Error; err != nil {
+		return nil, err
+	}
+	return answers, nil
+}
+
+// fillAnswerURLs 为文件答案生成限时有效的签名下载链接
+func fillAnswerURLs(answers []models.RegistrationAnswer) {
+	if storage.Default == nil {
+		return
+	}
+	expiry := storage.URLExpiry()
+	for i := range answers {
+		if answers[i].FileName != "" && answers[i].Value != "" {
+			answers[i].URL, _ = storage.Default.SignedURL(answers[i].Value, expiry)
+		}
+	}
+}
+
+// prepareRegistrationAnswers 按报名表校验参赛者的答案并上传文件，返回待保存的答案。
+// answers 以字段ID为键，files 为 file 字段上传的文件；保存失败时调用方需删除已上传的文件
+func prepareRegistrationAnswers(hackathonID, participantID uint64, answers map[uint64]string, files map[uint64]*multipart.FileHeader) ([]models.RegistrationAnswer, error) {
+	fields, err := getRegistrationFields(database.DB, hackathonID)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[uint64]bool, len(fields))
+	for _, field := range fields {
+		known[field.ID] = true
+	}
+	for id := range answers {
+		if !known[id] {
+			return nil, errors.New("无效的报名表字段")
+		}
+	}
+	for id := range files {
+		if !known[id] {
+			return nil, errors.New("无效的报名表字段")
+		}
+	}
+
+	rows := make([]models.RegistrationAnswer, 0, len(fields))
+	for _, field := range fields {
+		row := models.RegistrationAnswer{
+			HackathonID:   hackathonID,
+			ParticipantID: participantID,
+			FieldID:       field.ID,
+		}
+		value := strings.TrimSpace(answers[field.ID])
+
+		switch field.Type {
+		case "text":
+			if utf8.RuneCountInString(value) > registrationTextMaxLength {
+				return nil, fmt.Errorf("%s 不能超过%d个字符", field.Label, registrationTextMaxLength)
+			}
+		case "select":
+			if value != "" {
+				valid := false
+				for _, option := range field.OptionList {
+					if option == value {
+						valid = true
+						break
+					}
+				}
+				if !valid {
+					return nil, fmt.Errorf("%s 的选项无效", field.Label)
+				}
+			}
+		case "url":
+			if value != "" {
+				u, err := url.Parse(value)
+				if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+					return nil, fmt.Errorf("%s 必须是有效的 http(s) 链接", field.Label)
+				}
+			}
+		case "checkbox":
+			switch strings.ToLower(value) {
+			case "true", "1", "on":
+				value = "true"
+			case "", "false", "0", "off":
+				value = "false"
+			default:
+				return nil, fmt.Errorf("%s 的取值无效", field.Label)
+			}
+			if field.Required && value != "true" {
+				return nil, fmt.Errorf("请勾选%s", field.Label)
+			}
+		case "file":
+			value = ""
+			if files[field.ID] != nil {
+				row.FileName = files[field.ID].Filename
+			}
+		}
+
+		if field.Required && field.Type != "checkbox" && value == "" && row.FileName == "" {
+			return nil, fmt.Errorf("请填写%s", field.Label)
+		}
+		if value == "" && row.FileName == "" {
+			continue
+		}
+		row.Value = value
+		rows = append(rows, row)
+	}
+
+	// 校验通过后再上传文件，避免校验失败留下无用文件
+	for i := range rows {
+		if rows[i].FileName == "" {
+			continue
+		}
+		uploaded, err := storage.Upload(storage.KindDocument, fmt.Sprintf("registrations/%d/%d", hackathonID, participantID), files[rows[i].FieldID])
+		if err != nil {
+			deleteAnswerFiles(rows[:i])
+			return nil, err
+		}
+		rows[i].Value = uploaded.Key
+		rows[i].FileName = uploaded.FileName
+	}
+	return rows, nil
+}
+
+// saveRegistrationAnswers 保存参赛者的报名表答案（覆盖之前的答案），registrationID 为空表示候补中；
+// 返回被覆盖的答案，提交事务后由调用方删除其中的文件
+func saveRegistrationAnswers(tx *gorm.DB, hackathonID, participantID uint64, registrationID *uint64, rows []models.RegistrationAnswer) ([]models.RegistrationAnswer, error) {
+	replaced, err := deleteParticipantAnswers(tx, hackathonID, participantID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		rows[i].RegistrationID = registrationID
+		if err := tx.Create(&rows[i]).Error; err != nil {
+			return nil, err
+		}
+	}
+	return replaced, nil
+}
+
+// newRegistration 生成报名记录，申请制活动的报名需审核
+func newRegistration(hackathon *models.Hackathon, participantID uint64) models.Registration {
+	registration := models.Registration{
+		HackathonID:   hackathon.ID,
+		ParticipantID: participantID,
+		Status:        "approved",
+	}
+	if hackathon.RequireApproval {
+		registration.Status = "pending"
+	}
+	return registration
+}
+
+// checkFormManager 检查用户是否可以管理活动报名表与审核报名（仅活动创建者）
+func (s *RegistrationFormService) checkFormManager(hackathonID, userID uint64, userRole string) (*models.Hackathon, error) {
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
+		return nil, errors.New("活动不存在")
+	}
+	if userRole == "admin" {
+		return nil, errors.New("Admin不能管理报名表与报名审核")
+	}
+	if hackathon.OrganizerID != userID {
+		return nil, errors.New("只能管理自己创建活动的报名")
+	}
+	return &hackathon, nil
+}
+
+// GetForm 获取活动报名表（是否需要审核与字段列表）
+func (s *RegistrationFormService) GetForm(hackathonID uint64) (map[string]interface{}, error) {
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
+		return nil, errors.New("活动不存在")
+	}
+	fields, err := getRegistrationFields(database.DB, hackathonID)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"require_approval": hackathon.RequireApproval,
+		"fields":           fields,
+	}, nil
+}
+
+// SetForm 设置活动报名表（仅活动创建者，签到开始前）。
+// 带 ID 的字段为修改（已有报名时不能修改类型），不带 ID 的为新增，未列出的字段连同答案一起删除；
+// 关闭审核时，待审核的报名自动通过
+func (s *RegistrationFormService) SetForm(hackathonID uint64, requireApproval bool, fields []models.RegistrationField, userID uint64, userRole string) ([]models.RegistrationField, error) {
+	hackathon, err := s.checkFormManager(hackathonID, userID, userRole)
+	if err != nil {
+		return nil, err
+	}
+	if hackathon.Status != "preparation" && hackathon.Status != "published" && hackathon.Status != "registration" {
+		return nil, errors.New("报名已结束，不能修改报名表")
+	}
+	if len(fields) > registrationFormMaxFields {
+		return nil, fmt.Errorf("报名表最多 %d 个字段", registrationFormMaxFields)
+	}
+	for i := range fields {
+		if err := normalizeRegistrationField(&fields[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	var removedAnswers []models.RegistrationAnswer
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		existing, err := getRegistrationFields(tx, hackathonID)
+		if err != nil {
+			return err
+		}
+		existingByID := make(map[uint64]models.RegistrationField, len(existing))
+		for _, field := range existing {
+			existingByID[field.ID] = field
+		}
+
+		kept := make(map[uint64]bool, len(fields))
+		for i := range fields {
+			fields[i].HackathonID = hackathonID
+			if fields[i].ID == 0 {
+				if err := tx.Create(&fields[i]).Error; err != nil {
+					return fmt.Errorf("创建报名表字段失败: %w", err)
+				}
+				continue
+			}
+
+			old, ok := existingByID[fields[i].ID]
+			if !ok || kept[fields[i].ID] {
+				return errors.New("无效的报名表字段")
+			}
+			kept[fields[i].ID] = true
+			if old.Type != fields[i].Type {
+				var answered int64
+				if err := tx.Model(&models.RegistrationAnswer{}).Where("field_id = ?", old.ID).Count(&answered).Error; err != nil {
+					return err
+				}
+				if answered > 0 {
+					return fmt.Errorf("报名表字段 %s 已有报名答案，不能修改类型", old.Label)
+				}
+			}
+			if err := tx.Model(&old).Updates(map[string]interface{}{
+				"label":    fields[i].Label,
+				"type":     fields[i].Type,
+				"options":  fields[i].Options,
+				"required": fields[i].Required,
+				"order":    fields[i].Order,
+			}).Error; err != nil {
+				return err
+			}
+			fields[i].CreatedAt = old.CreatedAt
+		}
+
+		for _, field := range existing {
+			if kept[field.ID] {
+				continue
+			}
+			var answers []models.RegistrationAnswer
+			if err := tx.Where("field_id = ?", field.ID).Find(&answers).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("field_id = ?", field.ID).Delete(&models.RegistrationAnswer{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(&field).Error; err != nil {
+				return err
+			}
+			removedAnswers = append(removedAnswers, answers...)
+		}
+
+		if hackathon.RequireApproval && !requireApproval {
+			if err := tx.Model(&models.Registration{}).Where("hackathon_id = ? AND status = ?", hackathonID, "pending").
+				Updates(map[string]interface{}{"status": "approved", "reviewed_by": userID, "reviewed_at": time.Now()}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(hackathon).Update("require_approval", requireApproval).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	deleteAnswerFiles(removedAnswers)
+	return getRegistrationFields(database.DB, hackathonID)
+}
+
+// ReviewRegistration 审核报名申请（仅活动创建者，签到结束前），拒绝后空出的名额递补给候补名单
+func (s *RegistrationFormService) ReviewRegistration(hackathonID, registrationID uint64, status, note string, userID uint64, userRole string) error {
+	hackathon, err := s.checkFormManager(hackathonID, userID, userRole)
+	if err != nil {
+		return err
+	}
+	if hackathon.Status != "registration" && hackathon.Status != "checkin" {
+		return errors.New("当前不能审核报名")
+	}
+	if status != "approved" && status != "rejected" {
+		return errors.New("审核结果必须是 approved 或 rejected")
+	}
+	note = strings.TrimSpace(note)
+	if utf8.RuneCountInString(note) > 500 {
+		return errors.New("审核备注不能超过500个字符")
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		hackathon, err := lockHackathon(tx, hackathonID)
+		if err != nil {
+			return err
+		}
+
+		var registration models.Registration
+		if err := tx.Where("id = ? AND hackathon_id = ?", registrationID, hackathonID).First(&registration).Error; err != nil {
+			return errors.New("报名记录不存在")
+		}
+		if registration.Status != "pending" {
+			return errors.New("该报名已审核")
+		}
+
+		if err := tx.Model(&registration).Updates(map[string]interface{}{
+			"status":      status,
+			"review_note": note,
+			"reviewed_by": userID,
+			"reviewed_at": time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+		registration.Status = status
+		registration.ReviewNote = note
+		if err := notifyRegistrationConfirmed(tx, hackathon, &registration); err != nil {
+			return err
+		}
+		if err := emitRegistrationWebhook(tx, hackathon, &registration); err != nil {
+			return err
+		}
+		if status == "rejected" {
+			// 审核未通过不占用名额，归还报名时核销的邀请码
+			if err := releaseInviteCode(tx, registration.InviteCodeID); err != nil {
+				return err
+			}
+			if err := tx.Model(&registration).Update("invite_code_id", nil).Error; err != nil {
+				return err
+			}
+			return promoteWaitlist(tx, hackathon)
+		}
+		return nil
+	})
+}
+
+// getRegistrationAnswers 获取报名记录的答案（含字段信息与文件下载链接），以报名ID为键
+func getRegistrationAnswers(registrationIDs []uint64) (map[uint64][]models.RegistrationAnswer, error) {
+	result := make(map[uint64][]models.RegistrationAnswer, len(registrationIDs))
+	if len(registrationIDs) == 0 {
+		return result, nil
+	}
+
+	var answers []models.RegistrationAnswer
+	if err := database.DB.Where("registration_id IN ?", registrationIDs).Preload("Field").
+		Order("id ASC").Find(&answers).Error; err != nil {
+		return nil, err
+	}
+	fillAnswerURLs(answers)
+	for _, answer := range answers {
+		result[*answer.RegistrationID] = append(result[*answer.RegistrationID], answer)
+	}
+	return result, nil
+}
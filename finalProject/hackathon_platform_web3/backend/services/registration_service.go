@@ -3,85 +3,174 @@ package services
 import (
 	"errors"
 	"fmt"
+	"mime/multipart"
 	"time"
 
 	"hackathon-backend/database"
 	"hackathon-backend/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type RegistrationService struct{}
 
-// Register 报名参加活动
-func (s *RegistrationService) Register(hackathonID, participantID uint64) error {
+// lockRegistration 以 SELECT ... FOR UPDATE 锁定参赛者在活动中的报名记录，
+// 用于串行化同一参赛者的并发操作（投票额度检查、加入队伍等）
+func lockRegistration(tx *gorm.DB, hackathonID, participantID uint64) error {
+	var registration models.Registration
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("hackathon_id = ? AND participant_id = ?", hackathonID, participantID).
+		First(&registration).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("您未报名该活动")
+		}
+		return err
+	}
+	return nil
+}
+
+// Register 报名参加活动并提交报名表答案（answers 以字段ID为键，files 为 file 字段上传的文件），
+// 活动设置了报名资格规则时先校验资格（inviteCode 为邀请码规则使用的邀请码）；
+// 报名人数已满时加入候补名单并返回候补记录（直接报名成功时返回 nil）
+func (s *RegistrationService) Register(hackathonID, participantID uint64, inviteCode string, answers map[uint64]string, files map[uint64]*multipart.FileHeader) (*models.WaitlistEntry, error) {
 	// 检查参赛者是否存在
 	var participant models.Participant
 	if err := database.DB.Where("id = ? AND deleted_at IS NULL", participantID).First(&participant).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("参赛者不存在")
+			return nil, errors.New("参赛者不存在")
 		}
-		return fmt.Errorf("查询参赛者失败: %w", err)
+		return nil, fmt.Errorf("查询参赛者失败: %w", err)
 	}
 
 	// 检查活动状态
 	var hackathon models.Hackathon
 	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
-		return errors.New("活动不存在")
+		return nil, errors.New("活动不存在")
 	}
 
 	if hackathon.Status != "registration" {
-		return errors.New("当前不在报名阶段")
+		return nil, errors.New("当前不在报名阶段")
 	}
 
 	// 检查阶段时间
 	hackathonService := &HackathonService{}
 	inTime, err := hackathonService.CheckStageTime(hackathonID, "registration")
 	if err != nil {
-		return errors.New("报名阶段时间未设置")
+		return nil, errors.New("报名阶段时间未设置")
 	}
 	if !inTime {
-		return errors.New("不在报名时间范围内")
+		return nil, errors.New("不在报名时间范围内")
 	}
 
-	// 检查是否已报名
-	var existing models.Registration
-	if err := database.DB.Where("hackathon_id = ? AND participant_id = ?", hackathonID, participantID).First(&existing).Error; err == nil {
-		return errors.New("已经报名过该活动")
+	// 校验报名资格
+	invite, err := checkEligibility(&hackathon, &participant, inviteCode)
+	if err != nil {
+		return nil, err
+	}
+
+	// 校验报名表答案并上传文件
+	rows, err := prepareRegistrationAnswers(hackathonID, participantID, answers, files)
+	if err != nil {
+		return nil, err
 	}
 
-	// 检查是否达到最大参与人数限制
-	if hackathon.MaxParticipants > 0 {
-		var registeredCount int64
-		if err := database.DB.Model(&models.Registration{}).Where("hackathon_id = ?", hackathonID).Count(&registeredCount).Error; err != nil {
-			return fmt.Errorf("查询报名人数失败: %w", err)
+	// 锁定活动行后再检查名额，避免并发报名超出人数限制
+	var entry *models.WaitlistEntry
+	var replaced []models.RegistrationAnswer
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		hackathon, err := lockHackathon(tx, hackathonID)
+		if err != nil {
+			return err
 		}
-		if registeredCount >= int64(hackathon.MaxParticipants) {
-			return errors.New("活动报名人数已满")
+
+		// 检查是否已报名
+		var existing models.Registration
+		if err := tx.Where("hackathon_id = ? AND participant_id = ?", hackathonID, participantID).First(&existing).Error; err == nil {
+			return errors.New("已经报名过该活动")
 		}
-	}
 
-	// 创建报名记录
-	registration := models.Registration{
-		HackathonID:   hackathonID,
-		ParticipantID: participantID,
-	}
+		// 检查是否已在候补名单中
+		var waiting models.WaitlistEntry
+		if err := tx.Where("hackathon_id = ? AND participant_id = ? AND status <> ?", hackathonID, participantID, "expired").First(&waiting).Error; err == nil {
+			return errors.New("您已在候补名单中")
+		}
 
-	return database.DB.Create(&registration).Error
+		// 核销邀请码（报名或加入候补时）
+		if err := consumeInviteCode(tx, invite); err != nil {
+			return err
+		}
+
+		// 达到最大参与人数限制时加入候补名单（先递补已排队的候补者，避免插队）
+		if hackathon.MaxParticipants > 0 {
+			if err := promoteWaitlist(tx, hackathon); err != nil {
+				return err
+			}
+			seats, err := occupiedSeats(tx, hackathonID)
+			if err != nil {
+				return fmt.Errorf("查询报名人数失败: %w", err)
+			}
+			if seats >= int64(hackathon.MaxParticipants) {
+				var inviteCodeID *uint64
+				if invite != nil {
+					inviteCodeID = &invite.ID
+				}
+				if entry, err = joinWaitlist(tx, hackathonID, participantID, inviteCodeID); err != nil {
+					return err
+				}
+				replaced, err = saveRegistrationAnswers(tx, hackathonID, participantID, nil, rows)
+				return err
+			}
+		}
+
+		// 创建报名记录（申请制活动待审核）
+		registration := newRegistration(hackathon, participantID)
+		if invite != nil {
+			registration.InviteCodeID = &invite.ID
+		}
+		if err := tx.Create(&registration).Error; err != nil {
+			return err
+		}
+		if replaced, err = saveRegistrationAnswers(tx, hackathonID, participantID, &registration.ID, rows); err != nil {
+			return err
+		}
+		if err := notifyRegistrationConfirmed(tx, hackathon, &registration); err != nil {
+			return err
+		}
+		return emitRegistrationWebhook(tx, hackathon, &registration)
+	})
+	if err != nil {
+		deleteAnswerFiles(rows)
+		return nil, err
+	}
+	deleteAnswerFiles(replaced)
+	if entry != nil {
+		entries := []models.WaitlistEntry{*entry}
+		if err := fillQueuePositions(database.DB, entries); err != nil {
+			return nil, err
+		}
+		entry = &entries[0]
+	}
+	return entry, nil
 }
 
-// GetRegistrationStatus 获取报名状态
-func (s *RegistrationService) GetRegistrationStatus(hackathonID, participantID uint64) (bool, *time.Time, error) {
+// GetRegistrationStatus 获取报名记录（含报名表答案），未报名时返回 nil
+func (s *RegistrationService) GetRegistrationStatus(hackathonID, participantID uint64) (*models.Registration, error) {
 	var registration models.Registration
 	err := database.DB.Where("hackathon_id = ? AND participant_id = ?", hackathonID, participantID).First(&registration).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return false, nil, nil
+		return nil, nil
 	}
 	if err != nil {
-		return false, nil, err
+		return nil, err
 	}
 
-	return true, &registration.CreatedAt, nil
+	answers, err := getRegistrationAnswers([]uint64{registration.ID})
+	if err != nil {
+		return nil, err
+	}
+	registration.Answers = answers[registration.ID]
+	return &registration, nil
 }
 
 // CancelRegistration 取消报名
@@ -118,8 +207,30 @@ func (s *RegistrationService) CancelRegistration(hackathonID, participantID uint
 		return errors.New("已签到，不能取消报名")
 	}
 
-	// 删除报名记录
-	return database.DB.Delete(&registration).Error
+	// 删除报名记录与报名表答案并归还邀请码，空出的名额递补给候补名单中的下一位
+	var answers []models.RegistrationAnswer
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		hackathon, err := lockHackathon(tx, hackathonID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(&registration).Error; err != nil {
+			return err
+		}
+		// 归还报名时核销的邀请码
+		if err := releaseInviteCode(tx, registration.InviteCodeID); err != nil {
+			return err
+		}
+		if answers, err = deleteParticipantAnswers(tx, hackathonID, participantID); err != nil {
+			return err
+		}
+		return promoteWaitlist(tx, hackathon)
+	})
+	if err != nil {
+		return err
+	}
+	deleteAnswerFiles(answers)
+	return nil
 }
 
 // Checkin 签到
@@ -129,6 +240,12 @@ func (s *RegistrationService) Checkin(hackathonID, participantID uint64) error {
 	if err := database.DB.Where("hackathon_id = ? AND participant_id = ?", hackathonID, participantID).First(&registration).Error; err != nil {
 		return errors.New("请先报名")
 	}
+	switch registration.Status {
+	case "pending":
+		return errors.New("报名申请尚在审核中，审核通过后才能签到")
+	case "rejected":
+		return errors.New("报名申请未通过，不能签到")
+	}
 
 	// 检查活动状态
 	var hackathon models.Hackathon
@@ -179,3 +296,21 @@ func (s *RegistrationService) GetCheckinStatus(hackathonID, participantID uint64
 	return true, &checkin.CreatedAt, nil
 }
 
+
+// SetSponsorConsent 设置是否同意向活动赞助商提供个人信息（昵称、钱包地址、邮箱），可随时撤回
+func (s *RegistrationService) SetSponsorConsent(hackathonID, participantID uint64, consent bool) error {
+	var registration models.Registration
+	if err := database.DB.Where("hackathon_id = ? AND participant_id = ?", hackathonID, participantID).First(&registration).Error; err != nil {
+		return errors.New("请先报名")
+	}
+
+	var consentAt *time.Time
+	if consent {
+		now := time.Now()
+		consentAt = &now
+	}
+	return database.DB.Model(&registration).Updates(map[string]interface{}{
+		"sponsor_consent":    consent,
+		"sponsor_consent_at": consentAt,
+	}).Error
+}
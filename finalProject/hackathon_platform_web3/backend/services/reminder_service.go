@@ -0,0 +1,386 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"hackathon-backend/database"
+	"hackathon-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// ReminderService 活动阶段提醒：按阶段时间生成提醒任务并持久化，后台任务到期后通过站内通知与邮件发送
+type ReminderService struct{}
+
+const (
+	reminderPollInterval = time.Minute
+	reminderSendLease    = 5 * time.Minute // 提醒被领取后的发送时限，超时未完成（如进程退出）可被再次领取
+	reminderDeferDelay   = 5 * time.Minute // 活动尚未切换到对应阶段时，推迟后再检查
+	reminderBatchSize    = 500             // 报名截止提醒按批次发送，每批一个事务
+)
+
+var errReminderHandled = errors.New("提醒已被处理")
+
+// stageReminder 阶段提醒定义：在阶段开始（或结束前 Lead）时发送
+type stageReminder struct {
+	Kind  string
+	Stage string
+	AtEnd bool          // 以阶段结束时间为基准（截止提醒），否则以开始时间为基准
+	Lead  time.Duration // 提前量
+}
+
+var stageReminders = []stageReminder{
+	{Kind: NotifyRegistrationClosing, Stage: "registration", AtEnd: true, Lead: 24 * time.Hour},
+	{Kind: NotifyCheckinOpen, Stage: "checkin"},
+	{Kind: NotifySubmissionClosing, Stage: "submission", AtEnd: true, Lead: time.Hour},
+}
+
+// hackathonStatusOrder 活动状态的先后顺序，用于判断发送提醒时活动是否已处于对应阶段
+var hackathonStatusOrder = map[string]int{
+	"preparation":    0,
+	"published":      1,
+	"registration":   2,
+	"checkin":        3,
+	"team_formation": 4,
+	"submission":     5,
+	"voting":         6,
+	"results":        7,
+}
+
+// runAt 计算提醒的发送时间
+func (r stageReminder) runAt(stage *models.HackathonStage) time.Time {
+	if r.AtEnd {
+		return stage.EndTime.Add(-r.Lead).Truncate(time.Second)
+	}
+	return stage.StartTime.Add(-r.Lead).Truncate(time.Second)
+}
+
+// scheduleStageReminders 按活动当前的阶段时间重新计算提醒任务：新增或调整发送时间，阶段被删除时取消；
+// 已发送的提醒仅在阶段时间调整且新的发送时间未到时重新发送
+func scheduleStageReminders(tx *gorm.DB, hackathonID uint64) error {
+	var stages []models.HackathonStage
+	if err := tx.Where("hackathon_id = ?", hackathonID).Find(&stages).Error; err != nil {
+		return err
+	}
+	stageByName := make(map[string]*models.HackathonStage, len(stages))
+	for i := range stages {
+		stageByName[stages[i].Stage] = &stages[i]
+	}
+
+	now := time.Now()
+	for _, def := range stageReminders {
+		var existing models.ScheduledReminder
+		err := tx.Where("hackathon_id = ? AND kind = ?", hackathonID, def.Kind).First(&existing).Error
+		found := err == nil
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		stage, ok := stageByName[def.Stage]
+		if !ok {
+			if found && existing.Status == "pending" {
+				if err := tx.Model(&existing).Updates(map[string]interface{}{"status": "cancelled", "note": "阶段时间已删除"}).Error; err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		runAt := def.runAt(stage)
+		if !found {
+			reminder := models.ScheduledReminder{HackathonID: hackathonID, Kind: def.Kind, RunAt: runAt, Status: "pending"}
+			if err := tx.Create(&reminder).Error; err != nil {
+				return err
+			}
+			continue
+		}
+		if existing.RunAt.Truncate(time.Second).Equal(runAt) && existing.Status != "cancelled" {
+			continue
+		}
+		if existing.Status == "sent" && !runAt.After(now) {
+			continue
+		}
+		if err := tx.Model(&existing).Updates(map[string]interface{}{
+			"run_at": runAt,
+			"status": "pending",
+			"note":   "",
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StartReminderWorker 启动后台提醒任务：先为已有活动补齐提醒，再定时发送到期的提醒。
+// 提醒持久化在数据库中，服务重启后继续按计划发送
+func StartReminderWorker() {
+	go func() {
+		var hackathonIDs []uint64
+		if err := database.DB.Model(&models.Hackathon{}).
+			Where("deleted_at IS NULL AND status NOT IN ?", []string{"voting", "results"}).
+			Pluck("id", &hackathonIDs).Error; err != nil {
+			log.Printf("查询活动失败，未能补齐阶段提醒: %v", err)
+		}
+		for _, hackathonID := range hackathonIDs {
+			if err := scheduleStageReminders(database.DB, hackathonID); err != nil {
+				log.Printf("活动 %d 阶段提醒生成失败: %v", hackathonID, err)
+			}
+		}
+
+		ticker := time.NewTicker(reminderPollInterval)
+		defer ticker.Stop()
+		for {
+			processDueReminders()
+			<-ticker.C
+		}
+	}()
+}
+
+// processDueReminders 领取并发送到期的提醒
+func processDueReminders() {
+	var reminders []models.ScheduledReminder
+	if err := database.DB.Where("status = ? AND run_at <= ?", "pending", time.Now()).
+		Order("run_at ASC, id ASC").Find(&reminders).Error; err != nil {
+		log.Printf("查询阶段提醒失败: %v", err)
+		return
+	}
+	for i := range reminders {
+		if claimReminder(&reminders[i]) {
+			sendStageReminder(&reminders[i])
+		}
+	}
+}
+
+// claimReminder 将提醒的发送时间推后以领取提醒，多个实例同时运行时只有一个能领取成功
+func claimReminder(reminder *models.ScheduledReminder) bool {
+	result := database.DB.Model(&models.ScheduledReminder{}).
+		Where("id = ? AND status = ? AND run_at = ?", reminder.ID, "pending", reminder.RunAt).
+		Update("run_at", time.Now().Add(reminderSendLease))
+	return result.Error == nil && result.RowsAffected == 1
+}
+
+// sendStageReminder 发送提醒：活动尚未切换到对应阶段时推迟，已错过（阶段已结束或活动已进入后续阶段）时取消
+func sendStageReminder(reminder *models.ScheduledReminder) {
+	var def *stageReminder
+	for i := range stageReminders {
+		if stageReminders[i].Kind == reminder.Kind {
+			def = &stageReminders[i]
+		}
+	}
+
+	var hackathon models.Hackathon
+	var stage models.HackathonStage
+	now := time.Now()
+	note := ""
+	switch {
+	case def == nil:
+		note = "未知的提醒类型"
+	case database.DB.Where("id = ? AND deleted_at IS NULL", reminder.HackathonID).First(&hackathon).Error != nil:
+		note = "活动不存在"
+	case database.DB.Where("hackathon_id = ? AND stage = ?", reminder.HackathonID, def.Stage).First(&stage).Error != nil:
+		note = "阶段时间已删除"
+	case !now.Before(stage.EndTime):
+		note = "阶段已结束"
+	case hackathonStatusOrder[hackathon.Status] > hackathonStatusOrder[def.Stage]:
+		note = "活动已进入后续阶段"
+	case hackathonStatusOrder[hackathon.Status] < hackathonStatusOrder[def.Stage]:
+		// 阶段切换由主办方手动操作，尚未切换时稍后再检查
+		updateReminder(reminder.ID, map[string]interface{}{"run_at": now.Add(reminderDeferDelay)})
+		return
+	}
+	if note != "" {
+		updateReminder(reminder.ID, map[string]interface{}{"status": "cancelled", "note": note})
+		return
+	}
+	if reminder.Kind == NotifyRegistrationClosing {
+		sendRegistrationClosingReminder(reminder, &hackathon, &stage, now)
+		return
+	}
+
+	var recipients int
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if recipients, err = notifyStageReminder(tx, &hackathon, &stage, reminder.Kind); err != nil {
+			return err
+		}
+		// 发送期间阶段时间被重新计算时提醒可能被再次领取，只有先标记为已发送的一方提交
+		result := tx.Model(&models.ScheduledReminder{}).Where("id = ? AND status = ?", reminder.ID, "pending").Updates(map[string]interface{}{
+			"status":     "sent",
+			"recipients": recipients,
+			"sent_at":    now,
+			"note":       "",
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errReminderHandled
+		}
+		return nil
+	})
+	if errors.Is(err, errReminderHandled) {
+		return
+	}
+	if err != nil {
+		deferReminder(reminder, now, err)
+	}
+}
+
+// deferReminder 写入失败时稍后重试
+func deferReminder(reminder *models.ScheduledReminder, now time.Time, err error) {
+	log.Printf("活动 %d 阶段提醒 %s 发送失败: %v", reminder.HackathonID, reminder.Kind, err)
+	note := err.Error()
+	if len(note) > 255 {
+		note = note[:255]
+	}
+	updateReminder(reminder.ID, map[string]interface{}{"run_at": now.Add(reminderDeferDelay), "note": note})
+}
+
+// sendRegistrationClosingReminder 发送报名截止提醒：只发给主动开启了该提醒且尚未报名的参赛者。
+// 先将提醒标记为已发送（防止多个实例重复发送），再在事务外按批次写入通知，单批失败只记录日志
+func sendRegistrationClosingReminder(reminder *models.ScheduledReminder, hackathon *models.Hackathon, stage *models.HackathonStage, now time.Time) {
+	participantIDs, err := registrationClosingRecipients(hackathon.ID)
+	if err != nil {
+		deferReminder(reminder, now, err)
+		return
+	}
+	result := database.DB.Model(&models.ScheduledReminder{}).Where("id = ? AND status = ?", reminder.ID, "pending").Updates(map[string]interface{}{
+		"status":     "sent",
+		"recipients": len(participantIDs),
+		"sent_at":    now,
+		"note":       "",
+	})
+	if result.Error != nil {
+		deferReminder(reminder, now, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		return
+	}
+
+	data := map[string]interface{}{
+		"HackathonName": hackathon.Name,
+		"Deadline":      stage.EndTime.Format("2006-01-02 15:04 MST"),
+	}
+	failed := 0
+	for start := 0; start < len(participantIDs); start += reminderBatchSize {
+		end := start + reminderBatchSize
+		if end > len(participantIDs) {
+			end = len(participantIDs)
+		}
+		batch := participantIDs[start:end]
+		if err := database.DB.Transaction(func(tx *gorm.DB) error {
+			return notifyParticipants(tx, batch, hackathon.ID, NotifyRegistrationClosing, data)
+		}); err != nil {
+			log.Printf("活动 %d 报名截止提醒第 %d-%d 人发送失败: %v", hackathon.ID, start+1, end, err)
+			failed += len(batch)
+		}
+	}
+	if failed > 0 {
+		updateReminder(reminder.ID, map[string]interface{}{
+			"recipients": len(participantIDs) - failed,
+			"note":       fmt.Sprintf("%d 人发送失败", failed),
+		})
+	}
+}
+
+// registrationClosingRecipients 报名截止提醒的接收人：在任一渠道开启了该提醒、尚未报名且不在候补名单中的参赛者
+func registrationClosingRecipients(hackathonID uint64) ([]uint64, error) {
+	var participantIDs []uint64
+	err := database.DB.Model(&models.NotificationPreference{}).Distinct("recipient_id").
+		Where("recipient_type = ? AND event_type = ? AND enabled = ?", RecipientParticipant, NotifyRegistrationClosing, true).
+		Where("recipient_id IN (?)", database.DB.Model(&models.Participant{}).Select("id").Where("deleted_at IS NULL")).
+		Where("recipient_id NOT IN (?)", database.DB.Model(&models.Registration{}).Select("participant_id").Where("hackathon_id = ?", hackathonID)).
+		Where("recipient_id NOT IN (?)", database.DB.Model(&models.WaitlistEntry{}).Select("participant_id").Where("hackathon_id = ?", hackathonID)).
+		Order("recipient_id").
+		Pluck("recipient_id", &participantIDs).Error
+	return participantIDs, err
+}
+
+// updateReminder 更新提醒状态，失败只记录日志
+func updateReminder(reminderID uint64, updates map[string]interface{}) {
+	if err := database.DB.Model(&models.ScheduledReminder{}).Where("id = ?", reminderID).Updates(updates).Error; err != nil {
+		log.Printf("更新阶段提醒 %d 失败: %v", reminderID, err)
+	}
+}
+
+// notifyStageReminder 向提醒对象发送通知，返回接收人数：
+// 签到提醒发给已通过报名但未签到的参赛者，提交截止提醒发给尚未正式提交作品的队伍成员（已获延期的队伍除外）；
+// 报名截止提醒由 sendRegistrationClosingReminder 按批次发送
+func notifyStageReminder(tx *gorm.DB, hackathon *models.Hackathon, stage *models.HackathonStage, kind string) (int, error) {
+	data := map[string]interface{}{
+		"HackathonName": hackathon.Name,
+		"Deadline":      stage.EndTime.Format("2006-01-02 15:04 MST"),
+	}
+
+	var participantIDs []uint64
+	switch kind {
+	case NotifyCheckinOpen:
+		if err := tx.Model(&models.Registration{}).
+			Where("hackathon_id = ? AND status = ?", hackathon.ID, "approved").
+			Where("participant_id NOT IN (?)", tx.Model(&models.Checkin{}).Select("participant_id").Where("hackathon_id = ?", hackathon.ID)).
+			Pluck("participant_id", &participantIDs).Error; err != nil {
+			return 0, err
+		}
+	case NotifySubmissionClosing:
+		return notifySubmissionClosing(tx, hackathon, stage, data)
+	}
+	return len(participantIDs), notifyParticipants(tx, participantIDs, hackathon.ID, kind, data)
+}
+
+// notifySubmissionClosing 按队伍发送提交截止提醒（区分仅有草稿与尚未提交）
+func notifySubmissionClosing(tx *gorm.DB, hackathon *models.Hackathon, stage *models.HackathonStage, data map[string]interface{}) (int, error) {
+	var teams []models.Team
+	if err := tx.Preload("Members").
+		Where("hackathon_id = ? AND deleted_at IS NULL", hackathon.ID).
+		Where("id NOT IN (?)", tx.Model(&models.Submission{}).Select("team_id").Where("hackathon_id = ? AND draft = ?", hackathon.ID, 0)).
+		Where("id NOT IN (?)", tx.Model(&models.SubmissionExtension{}).Select("team_id").Where("hackathon_id = ? AND extended_until > ?", hackathon.ID, stage.EndTime)).
+		Find(&teams).Error; err != nil {
+		return 0, err
+	}
+	if len(teams) == 0 {
+		return 0, nil
+	}
+
+	var draftTeamIDs []uint64
+	if err := tx.Model(&models.Submission{}).Where("hackathon_id = ? AND draft = ?", hackathon.ID, 1).
+		Pluck("team_id", &draftTeamIDs).Error; err != nil {
+		return 0, err
+	}
+	hasDraft := make(map[uint64]bool, len(draftTeamIDs))
+	for _, teamID := range draftTeamIDs {
+		hasDraft[teamID] = true
+	}
+
+	recipients := 0
+	for _, team := range teams {
+		memberIDs := make([]uint64, 0, len(team.Members))
+		for _, member := range team.Members {
+			memberIDs = append(memberIDs, member.ParticipantID)
+		}
+		teamData := map[string]interface{}{
+			"TeamName": team.Name,
+			"HasDraft": hasDraft[team.ID],
+		}
+		for key, value := range data {
+			teamData[key] = value
+		}
+		if err := notifyParticipants(tx, memberIDs, hackathon.ID, NotifySubmissionClosing, teamData); err != nil {
+			return 0, err
+		}
+		recipients += len(memberIDs)
+	}
+	return recipients, nil
+}
+
+// GetReminders 获取活动的阶段提醒任务
+func (s *ReminderService) GetReminders(hackathonID uint64) ([]models.ScheduledReminder, error) {
+	var reminders []models.ScheduledReminder
+	if err := database.DB.Where("hackathon_id = ?", hackathonID).Order("run_at ASC").Find(&reminders).Error; err != nil {
+		return nil, err
+	}
+	return reminders, nil
+}
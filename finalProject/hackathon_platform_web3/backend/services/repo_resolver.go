@@ -0,0 +1,162 @@
+package services
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RepoResolver 查询 git 仓库分支当前指向的提交（相当于 git ls-remote）
+type RepoResolver interface {
+	// ResolveHead 返回仓库 ref（分支或标签名，为空表示默认分支 HEAD）当前的提交哈希
+	ResolveHead(repoURL, ref string) (string, error)
+}
+
+// repoResolver 当前使用的仓库解析实现
+var repoResolver RepoResolver = &gitHTTPResolver{client: &http.Client{Timeout: 15 * time.Second}}
+
+// SetRepoResolver 替换仓库解析实现（用于测试或接入代理），返回原实现
+func SetRepoResolver(r RepoResolver) RepoResolver {
+	old := repoResolver
+	repoResolver = r
+	return old
+}
+
+// gitHosts 支持快照的公开 git 托管平台。仅允许这些主机，避免后台按作品链接访问内网或任意地址
+var gitHosts = map[string]bool{
+	"github.com":    true,
+	"gitlab.com":    true,
+	"bitbucket.org": true,
+	"gitee.com":     true,
+	"codeberg.org":  true,
+}
+
+// ParseGitLink 从作品链接解析 git 仓库地址与分支，如
+// https://github.com/org/repo/tree/main -> (https://github.com/org/repo.git, main)。
+// 非 git 仓库链接或不在 gitHosts 中的主机（含自建仓库）返回 ok=false
+func ParseGitLink(link string) (repoURL, ref string, ok bool) {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.User != nil || u.Port() != "" {
+		return "", "", false
+	}
+	host := strings.ToLower(strings.TrimPrefix(u.Hostname(), "www."))
+	if !gitHosts[host] {
+		return "", "", false
+	}
+	trimmedPath := strings.Trim(u.Path, "/")
+
+	// GitLab 使用 /-/tree/<分支>，其他平台使用 /tree/<分支>（Bitbucket 为 /src/<分支>），/commit/<哈希> 直接指向提交
+	repoPath, rest := trimmedPath, ""
+	if i := strings.Index(trimmedPath, "/-/"); i >= 0 {
+		repoPath, rest = trimmedPath[:i], trimmedPath[i+3:]
+	} else if segments := strings.Split(trimmedPath, "/"); len(segments) > 2 {
+		repoPath, rest = strings.Join(segments[:2], "/"), strings.Join(segments[2:], "/")
+	}
+	repoPath = strings.TrimSuffix(repoPath, ".git")
+	if strings.Count(repoPath, "/") < 1 {
+		return "", "", false
+	}
+
+	for _, prefix := range []string{"tree/", "blob/", "src/", "commit/"} {
+		if strings.HasPrefix(rest, prefix) {
+			ref = strings.TrimPrefix(rest, prefix)
+			break
+		}
+	}
+	return fmt.Sprintf("https://%s/%s.git", host, repoPath), ref, true
+}
+
+// gitHTTPResolver 通过 git smart HTTP 协议（info/refs）读取远端引用，无需本地安装 git
+type gitHTTPResolver struct {
+	client *http.Client
+}
+
+func (r *gitHTTPResolver) ResolveHead(repoURL, ref string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(repoURL, "/")+"/info/refs?service=git-upload-pack", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "git/2.0 hackathon-platform")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("访问仓库失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("访问仓库失败: HTTP %d（仓库不存在或非公开）", resp.StatusCode)
+	}
+
+	refs, err := parseGitRefs(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	// 链接中的分支后可能跟着子目录（tree/main/src），分支名本身也可能含 /，从长到短依次尝试
+	candidates := []string{"HEAD"}
+	if ref != "" {
+		candidates = []string{ref}
+		segments := strings.Split(ref, "/")
+		for i := len(segments); i > 0; i-- {
+			name := strings.Join(segments[:i], "/")
+			candidates = append(candidates, "refs/heads/"+name, "refs/tags/"+name+"^{}", "refs/tags/"+name)
+		}
+	}
+	for _, name := range candidates {
+		if hash, ok := refs[name]; ok {
+			return hash, nil
+		}
+	}
+	if ref != "" {
+		return "", fmt.Errorf("仓库中不存在分支或标签 %s", ref)
+	}
+	return "", errors.New("仓库为空")
+}
+
+// parseGitRefs 解析 git-upload-pack 引用公告（pkt-line 格式），返回 引用名 -> 提交哈希
+func parseGitRefs(body io.Reader) (map[string]string, error) {
+	reader := bufio.NewReader(io.LimitReader(body, 16<<20))
+	refs := make(map[string]string)
+	for {
+		lengthHex := make([]byte, 4)
+		if _, err := io.ReadFull(reader, lengthHex); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			return nil, err
+		}
+		length, err := strconv.ParseUint(string(lengthHex), 16, 16)
+		if err != nil {
+			return nil, errors.New("无效的 git 引用响应")
+		}
+		if length == 0 {
+			continue // flush-pkt
+		}
+		if length < 4 {
+			return nil, errors.New("无效的 git 引用响应")
+		}
+		payload := make([]byte, length-4)
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			return nil, errors.New("无效的 git 引用响应")
+		}
+
+		line := strings.TrimRight(string(payload), "\n")
+		if strings.HasPrefix(line, "#") {
+			continue // "# service=git-upload-pack"
+		}
+		if i := strings.IndexByte(line, 0); i >= 0 {
+			line = line[:i] // 第一条引用后附带能力列表
+		}
+		fields := strings.SplitN(line, " ", 2)
+		if len(fields) == 2 && len(fields[0]) >= 40 {
+			refs[fields[1]] = fields[0]
+		}
+	}
+	return refs, nil
+}
@@ -0,0 +1,434 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"hackathon-backend/database"
+	"hackathon-backend/models"
+
+	"gorm.io/gorm"
+)
+
+type ResultService struct{}
+
+// 同分决胜规则
+const (
+	TieBreakJudgeScore         = "judge_score"         // 评委评分高者在前
+	TieBreakEarliestSubmission = "earliest_submission" // 作品提交早者在前
+	TieBreakOrganizerDecision  = "organizer_decision"  // 由主办方裁定
+)
+
+// defaultTieBreakRules 活动未配置时的同分决胜规则
+const defaultTieBreakRules = TieBreakJudgeScore + "," + TieBreakEarliestSubmission
+
+// parseTieBreakRules 解析活动的同分决胜规则（逗号分隔，按顺序依次比较）
+func parseTieBreakRules(value string) []string {
+	if strings.TrimSpace(value) == "" {
+		value = defaultTieBreakRules
+	}
+	rules := make([]string, 0)
+	for _, rule := range strings.Split(value, ",") {
+		if rule = strings.TrimSpace(rule); rule != "" {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// ValidateTieBreakRules 校验同分决胜规则
+func ValidateTieBreakRules(value string) error {
+	for _, rule := range parseTieBreakRules(value) {
+		switch rule {
+		case TieBreakJudgeScore, TieBreakEarliestSubmission, TieBreakOrganizerDecision:
+		default:
+			return errors.New("无效的同分决胜规则: " + rule)
+		}
+	}
+	return nil
+}
+
+// rankingEntry 结果引擎排名中的一项
+type rankingEntry struct {
+	submission models.Submission
+	voteCount  int64
+	judgeScore float64
+	score      float64
+}
+
+// compareEntries 比较两个作品的名次，返回负数表示 a 在前；并返回决定先后的规则（综合得分不同时为空）。
+// 所有规则都无法区分时按作品ID升序，保证结果确定
+func compareEntries(a, b rankingEntry, rules []string) (int, string) {
+	if a.score != b.score {
+		if a.score > b.score {
+			return -1, ""
+		}
+		return 1, ""
+	}
+	for _, rule := range rules {
+		switch rule {
+		case TieBreakJudgeScore:
+			if a.judgeScore != b.judgeScore {
+				if a.judgeScore > b.judgeScore {
+					return -1, rule
+				}
+				return 1, rule
+			}
+		case TieBreakEarliestSubmission:
+			if !a.submission.CreatedAt.Equal(b.submission.CreatedAt) {
+				if a.submission.CreatedAt.Before(b.submission.CreatedAt) {
+					return -1, rule
+				}
+				return 1, rule
+			}
+		case TieBreakOrganizerDecision:
+			// 交由主办方裁定，暂按作品ID排列
+			if a.submission.ID < b.submission.ID {
+				return -1, rule
+			}
+			return 1, rule
+		}
+	}
+	if a.submission.ID < b.submission.ID {
+		return -1, "submission_id"
+	}
+	return 1, "submission_id"
+}
+
+// computeRanking 计算活动（trackID 为 0 时为全场，否则为该赛道）的排名与获奖，不落库
+func (s *ResultService) computeRanking(hackathon *models.Hackathon, trackID uint64) ([]models.HackathonResult, error) {
+	submissionQuery := database.DB.Where("hackathon_id = ? AND draft = 0", hackathon.ID)
+	awardQuery := database.DB.Where("hackathon_id = ?", hackathon.ID)
+	if trackID > 0 {
+		submissionQuery = submissionQuery.Where("id IN (?)", database.DB.Model(&models.SubmissionTrack{}).Select("submission_id").Where("track_id = ?", trackID))
+		awardQuery = awardQuery.Where("track_id = ?", trackID)
+	} else {
+		awardQuery = awardQuery.Where("track_id IS NULL")
+	}
+
+	var submissions []models.Submission
+	if err := submissionQuery.Find(&submissions).Error; err != nil {
+		return nil, err
+	}
+	var awards []models.HackathonAward
+	if err := awardQuery.Order("`rank` ASC, id ASC").Find(&awards).Error; err != nil {
+		return nil, err
+	}
+
+	// 按活动投票方式计票
+	candidateIDs := make([]uint64, 0, len(submissions))
+	for _, submission := range submissions {
+		candidateIDs = append(candidateIDs, submission.ID)
+	}
+	voteService := &VoteService{}
+	tally, err := voteService.TallyVotes(hackathon, trackID, candidateIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// 评委评分（评委权重为 0 时不影响综合得分，但仍可用于同分决胜）
+	judgeService := &JudgeService{}
+	judgeScores, err := judgeService.GetJudgeScores(hackathon.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]rankingEntry, 0, len(submissions))
+	var maxVoteCount int64
+	for _, submission := range submissions {
+		if tally[submission.ID] > maxVoteCount {
+			maxVoteCount = tally[submission.ID]
+		}
+	}
+	for _, submission := range submissions {
+		entry := rankingEntry{
+			submission: submission,
+			voteCount:  tally[submission.ID],
+			judgeScore: judgeScores[submission.ID],
+		}
+		entry.score = CombineScore(entry.voteCount, maxVoteCount, entry.judgeScore, hackathon.JudgeWeight)
+		// 迟交作品按活动的迟交规则扣分
+		if submission.Late && hackathon.LatePenalty > 0 {
+			entry.score *= 1 - hackathon.LatePenalty
+		}
+		entries = append(entries, entry)
+	}
+
+	rules := parseTieBreakRules(hackathon.TieBreakRules)
+	sort.SliceStable(entries, func(i, j int) bool {
+		cmp, _ := compareEntries(entries[i], entries[j], rules)
+		return cmp < 0
+	})
+
+	// 按奖项名次与数量展开获奖名额
+	awardSlots := make([]uint64, 0)
+	for _, award := range awards {
+		quantity := award.Quantity
+		if quantity <= 0 {
+			quantity = 1
+		}
+		for i := 0; i < quantity; i++ {
+			awardSlots = append(awardSlots, award.ID)
+		}
+	}
+
+	results := make([]models.HackathonResult, 0, len(entries))
+	for i, entry := range entries {
+		result := models.HackathonResult{
+			HackathonID:   hackathon.ID,
+			TrackID:       trackID,
+			SubmissionID:  entry.submission.ID,
+			TeamID:        entry.submission.TeamID,
+			Rank:          i + 1,
+			VoteCount:     entry.voteCount,
+			JudgeScore:    entry.judgeScore,
+			Score:         entry.score,
+			LatePenalized: entry.submission.Late && hackathon.LatePenalty > 0,
+		}
+		if i > 0 {
+			if _, rule := compareEntries(entries[i-1], entry, rules); rule != "" {
+				result.TieBreak = rule
+				// 仅当同分影响获奖时才需主办方裁定
+				if rule == TieBreakOrganizerDecision && i-1 < len(awardSlots) && (i >= len(awardSlots) || awardSlots[i-1] != awardSlots[i]) {
+					result.TiePending = true
+					results[i-1].TiePending = true
+				}
+			}
+		}
+		if i < len(awardSlots) {
+			awardID := awardSlots[i]
+			result.AwardID = &awardID
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// computeAndSave 计算全场及各赛道结果并覆盖保存（之前的主办方调整会被清除）
+func (s *ResultService) computeAndSave(hackathon *models.Hackathon) error {
+	trackService := &TrackService{}
+	tracks, err := trackService.GetTracks(hackathon.ID)
+	if err != nil {
+		return err
+	}
+
+	all, err := s.computeRanking(hackathon, 0)
+	if err != nil {
+		return err
+	}
+	for _, track := range tracks {
+		results, err := s.computeRanking(hackathon, track.ID)
+		if err != nil {
+			return err
+		}
+		all = append(all, results...)
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("hackathon_id = ?", hackathon.ID).Delete(&models.HackathonResult{}).Error; err != nil {
+			return err
+		}
+		for i := range all {
+			if err := tx.Create(&all[i]).Error; err != nil {
+				return fmt.Errorf("保存比赛结果失败: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// checkResultManager 检查用户是否可以计算或调整结果（仅活动创建者，且结果公布前）
+func (s *ResultService) checkResultManager(hackathonID, userID uint64, userRole string) (*models.Hackathon, error) {
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
+		return nil, errors.New("活动不存在")
+	}
+	if userRole == "admin" {
+		return nil, errors.New("Admin不能管理比赛结果")
+	}
+	if hackathon.OrganizerID != userID {
+		return nil, errors.New("只能管理自己创建活动的比赛结果")
+	}
+	if hackathon.Status != "voting" {
+		return nil, errors.New("只能在投票阶段计算或调整比赛结果")
+	}
+	return &hackathon, nil
+}
+
+// ComputeResults 计算比赛结果（仅活动创建者，投票阶段），重新计算会清除之前的获奖调整
+func (s *ResultService) ComputeResults(hackathonID, userID uint64, userRole string) error {
+	hackathon, err := s.checkResultManager(hackathonID, userID, userRole)
+	if err != nil {
+		return err
+	}
+
+	// 投票结束后票数与评委评分不再变化，结果才是最终的
+	hackathonService := &HackathonService{}
+	if inTime, err := hackathonService.CheckStageTime(hackathonID, "voting"); err == nil && inTime {
+		return errors.New("投票尚未结束，不能计算比赛结果")
+	}
+
+	return s.computeAndSave(hackathon)
+}
+
+// PrepareForPublish 公布结果前的检查：尚未计算结果时自动计算；存在需主办方裁定的同分时不能公布
+func (s *ResultService) PrepareForPublish(hackathon *models.Hackathon) error {
+	var count int64
+	if err := database.DB.Model(&models.HackathonResult{}).Where("hackathon_id = ?", hackathon.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		if err := s.computeAndSave(hackathon); err != nil {
+			return err
+		}
+	}
+
+	var pending int64
+	if err := database.DB.Model(&models.HackathonResult{}).
+		Where("hackathon_id = ? AND tie_pending = ?", hackathon.ID, true).
+		Count(&pending).Error; err != nil {
+		return err
+	}
+	if pending > 0 {
+		return errors.New("存在需主办方裁定的同分名次，请先调整获奖结果")
+	}
+	return nil
+}
+
+// GetResultRecords 获取已落库的比赛结果（trackID 为 0 时为全场排名），按名次排序
+func (s *ResultService) GetResultRecords(hackathonID, trackID uint64) ([]models.HackathonResult, error) {
+	var results []models.HackathonResult
+	if err := database.DB.Preload("Submission").Preload("Team").Preload("Team.Members").Preload("Team.Members.Participant").
+		Preload("Award").
+		Where("hackathon_id = ? AND track_id = ?", hackathonID, trackID).
+		Order("`rank` ASC").Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// OverrideResult 调整作品的获奖结果（仅活动创建者，投票阶段，需填写原因）。awardID 为 nil 表示取消获奖；
+// 对需裁定的同分作品，保持原奖项提交即视为确认裁定
+func (s *ResultService) OverrideResult(hackathonID, resultID uint64, awardID *uint64, reason string, userID uint64, userRole string) error {
+	if _, err := s.checkResultManager(hackathonID, userID, userRole); err != nil {
+		return err
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return errors.New("请填写调整原因")
+	}
+
+	var result models.HackathonResult
+	if err := database.DB.Where("id = ? AND hackathon_id = ?", resultID, hackathonID).First(&result).Error; err != nil {
+		return errors.New("结果记录不存在")
+	}
+
+	if awardID != nil {
+		// 奖项须属于该结果所在的排名范围（全场或同一赛道）
+		var award models.HackathonAward
+		query := database.DB.Where("id = ? AND hackathon_id = ?", *awardID, hackathonID)
+		if result.TrackID > 0 {
+			query = query.Where("track_id = ?", result.TrackID)
+		} else {
+			query = query.Where("track_id IS NULL")
+		}
+		if err := query.First(&award).Error; err != nil {
+			return errors.New("奖项不存在")
+		}
+
+		// 检查奖项名额
+		var winners int64
+		database.DB.Model(&models.HackathonResult{}).
+			Where("hackathon_id = ? AND track_id = ? AND award_id = ? AND id != ?", hackathonID, result.TrackID, award.ID, result.ID).
+			Count(&winners)
+		quantity := award.Quantity
+		if quantity <= 0 {
+			quantity = 1
+		}
+		if winners >= int64(quantity) {
+			return errors.New("该奖项名额已满，请先调整其他获奖作品")
+		}
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&result).Updates(map[string]interface{}{
+			"award_id":        awardID,
+			"overridden":      true,
+			"override_reason": reason,
+			"overridden_by":   userID,
+			"tie_pending":     false,
+		}).Error; err != nil {
+			return err
+		}
+		if !result.TiePending {
+			return nil
+		}
+		// 裁定一次即解决整组同分，同组其他作品不再待裁定
+		tiedIDs, err := s.tiedResultIDs(tx, &result)
+		if err != nil {
+			return err
+		}
+		if len(tiedIDs) == 0 {
+			return nil
+		}
+		return tx.Model(&models.HackathonResult{}).Where("id IN ?", tiedIDs).Update("tie_pending", false).Error
+	})
+}
+
+// tiedResultIDs 返回与指定结果处于同一组需裁定同分的其他结果ID（同一排名范围内名次连续、按主办方裁定决胜的记录）
+func (s *ResultService) tiedResultIDs(tx *gorm.DB, result *models.HackathonResult) ([]uint64, error) {
+	var rows []models.HackathonResult
+	if err := tx.Where("hackathon_id = ? AND track_id = ?", result.HackathonID, result.TrackID).
+		Order("`rank` ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	index := -1
+	for i := range rows {
+		if rows[i].ID == result.ID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, nil
+	}
+
+	// TieBreak 记录的是与上一名的比较结果
+	start, end := index, index
+	for start > 0 && rows[start].TieBreak == TieBreakOrganizerDecision {
+		start--
+	}
+	for end+1 < len(rows) && rows[end+1].TieBreak == TieBreakOrganizerDecision {
+		end++
+	}
+
+	var ids []uint64
+	for i := start; i <= end; i++ {
+		if i != index && rows[i].TiePending {
+			ids = append(ids, rows[i].ID)
+		}
+	}
+	return ids, nil
+}
+
+// BackfillResults 为已进入结果公布阶段但尚未落库结果的活动补算结果（升级前公布的活动），已有结果的活动不受影响
+func (s *ResultService) BackfillResults() error {
+	var hackathons []models.Hackathon
+	if err := database.DB.Where("status = ? AND deleted_at IS NULL", "results").
+		Where("NOT EXISTS (SELECT 1 FROM hackathon_results WHERE hackathon_results.hackathon_id = hackathons.id)").
+		Find(&hackathons).Error; err != nil {
+		return err
+	}
+	for i := range hackathons {
+		if err := s.computeAndSave(&hackathons[i]); err != nil {
+			return fmt.Errorf("补算活动 %d 的比赛结果失败: %w", hackathons[i].ID, err)
+		}
+	}
+	if len(hackathons) > 0 {
+		log.Printf("已为 %d 个已公布结果的活动补算比赛结果", len(hackathons))
+	}
+	return nil
+}
@@ -0,0 +1,398 @@
+package services
+
+import (
+	"errors"
+	"hash/fnv"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"hackathon-backend/database"
+	"hackathon-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// SimilarityService 作品相似度检测：用 MinHash 比较作品描述并比对规范化后的仓库链接，
+// 找出同一活动内及与往届活动（已公布结果）重复提交的作品
+type SimilarityService struct{}
+
+const (
+	similarityShingleSize  = 3   // 文本切片（shingle）包含的词数
+	similarityHashCount    = 128 // MinHash 签名长度
+	similarityBandRows     = 4   // LSH 每个分段的行数（共 128/4=32 段，相似度约 0.42 以上的作品对会成为候选）
+	similarityMinShingles  = 5   // 描述过短时不比较文本相似度
+	similarityThreshold    = 0.6 // 综合相似度达到该值时标记
+	similarityScanStaleAge = time.Hour
+)
+
+// similaritySeeds MinHash 各哈希函数的种子
+var similaritySeeds = func() []uint64 {
+	seeds := make([]uint64, similarityHashCount)
+	for i := range seeds {
+		seeds[i] = splitMix64(uint64(i) + 1)
+	}
+	return seeds
+}()
+
+// similarityDoc 参与比较的作品
+type similarityDoc struct {
+	submission models.Submission
+	shingles   map[uint64]struct{}
+	signature  []uint64
+	repo       string
+	target     bool // 属于本次检测的活动
+}
+
+func splitMix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// similarityTokens 将文本切分为小写词，中日韩文字每个字作为一个词
+func similarityTokens(text string) []string {
+	tokens := make([]string, 0)
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			flush()
+			tokens = append(tokens, string(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+// similarityShingles 文本的切片哈希集合
+func similarityShingles(text string) map[uint64]struct{} {
+	tokens := similarityTokens(text)
+	shingles := make(map[uint64]struct{})
+	if len(tokens) == 0 {
+		return shingles
+	}
+	size := similarityShingleSize
+	if len(tokens) < size {
+		size = len(tokens)
+	}
+	for i := 0; i+size <= len(tokens); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(tokens[i:i+size], " ")))
+		shingles[h.Sum64()] = struct{}{}
+	}
+	return shingles
+}
+
+// minHashSignature 切片集合的 MinHash 签名
+func minHashSignature(shingles map[uint64]struct{}) []uint64 {
+	signature := make([]uint64, similarityHashCount)
+	for i := range signature {
+		signature[i] = ^uint64(0)
+	}
+	for shingle := range shingles {
+		for i, seed := range similaritySeeds {
+			if v := splitMix64(shingle ^ seed); v < signature[i] {
+				signature[i] = v
+			}
+		}
+	}
+	return signature
+}
+
+// jaccard 两个切片集合的 Jaccard 相似度
+func jaccard(a, b map[uint64]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	intersection := 0
+	for shingle := range a {
+		if _, ok := b[shingle]; ok {
+			intersection++
+		}
+	}
+	return float64(intersection) / float64(len(a)+len(b)-intersection)
+}
+
+// normalizeRepoLink 规范化作品的仓库链接（忽略协议、www、大小写、.git 后缀与分支路径），用于判断是否同一仓库
+func normalizeRepoLink(submission *models.Submission) string {
+	link := snapshotLink(submission)
+	if repoURL, _, ok := ParseGitLink(link); ok {
+		link = repoURL
+	}
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	path := strings.TrimSuffix(strings.ToLower(strings.Trim(u.Path, "/")), ".git")
+	if path == "" {
+		// 只有域名的链接（如项目官网首页）不作为仓库比对
+		return ""
+	}
+	return host + "/" + path
+}
+
+// newSimilarityDoc 计算作品的切片、签名与规范化仓库链接
+func newSimilarityDoc(submission models.Submission, target bool) *similarityDoc {
+	doc := &similarityDoc{
+		submission: submission,
+		shingles:   similarityShingles(submission.Description),
+		repo:       normalizeRepoLink(&submission),
+		target:     target,
+	}
+	if len(doc.shingles) >= similarityMinShingles {
+		doc.signature = minHashSignature(doc.shingles)
+	}
+	return doc
+}
+
+// findSimilarPairs 用 LSH 分段找出候选作品对并计算相似度，只比较涉及本次检测活动作品的作品对
+func findSimilarPairs(docs []*similarityDoc) []models.SubmissionSimilarity {
+	type pair struct{ a, b int }
+	candidates := make(map[pair]bool)
+	addCandidates := func(bucket []int) {
+		for i := 0; i < len(bucket); i++ {
+			for j := i + 1; j < len(bucket); j++ {
+				a, b := bucket[i], bucket[j]
+				if !docs[a].target && !docs[b].target {
+					continue
+				}
+				if !docs[a].target || (docs[b].target && docs[b].submission.ID < docs[a].submission.ID) {
+					a, b = b, a
+				}
+				candidates[pair{a, b}] = true
+			}
+		}
+	}
+
+	// 仓库链接相同的作品
+	repoBuckets := make(map[string][]int)
+	for i, doc := range docs {
+		if doc.repo != "" {
+			repoBuckets[doc.repo] = append(repoBuckets[doc.repo], i)
+		}
+	}
+	for _, bucket := range repoBuckets {
+		addCandidates(bucket)
+	}
+
+	// 描述签名在任一分段完全相同的作品
+	for band := 0; band*similarityBandRows < similarityHashCount; band++ {
+		buckets := make(map[uint64][]int)
+		for i, doc := range docs {
+			if doc.signature == nil {
+				continue
+			}
+			h := fnv.New64a()
+			for _, v := range doc.signature[band*similarityBandRows : (band+1)*similarityBandRows] {
+				var b [8]byte
+				for k := range b {
+					b[k] = byte(v >> (8 * k))
+				}
+				h.Write(b[:])
+			}
+			key := h.Sum64()
+			buckets[key] = append(buckets[key], i)
+		}
+		for _, bucket := range buckets {
+			addCandidates(bucket)
+		}
+	}
+
+	pairs := make([]models.SubmissionSimilarity, 0)
+	for candidate := range candidates {
+		a, b := docs[candidate.a], docs[candidate.b]
+		descriptionScore := 0.0
+		if a.signature != nil && b.signature != nil {
+			descriptionScore = jaccard(a.shingles, b.shingles)
+		}
+		sameRepo := a.repo != "" && a.repo == b.repo
+		score := descriptionScore
+		if sameRepo {
+			score = 1
+		}
+		if score < similarityThreshold {
+			continue
+		}
+		pairs = append(pairs, models.SubmissionSimilarity{
+			HackathonID:         a.submission.HackathonID,
+			SubmissionID:        a.submission.ID,
+			MatchedHackathonID:  b.submission.HackathonID,
+			MatchedSubmissionID: b.submission.ID,
+			DescriptionScore:    descriptionScore,
+			SameRepo:            sameRepo,
+			Score:               score,
+		})
+	}
+	return pairs
+}
+
+// runSimilarityScan 执行相似度检测并保存结果
+func runSimilarityScan(scan *models.SimilarityScan) error {
+	var submissions []models.Submission
+	if err := database.DB.Where("hackathon_id = ? AND draft = 0", scan.HackathonID).Find(&submissions).Error; err != nil {
+		return err
+	}
+
+	// 往届活动：已公布结果的其他活动
+	var archived []models.Submission
+	if err := database.DB.Where("draft = 0 AND hackathon_id <> ?", scan.HackathonID).
+		Where("hackathon_id IN (?)", database.DB.Model(&models.Hackathon{}).Select("id").Where("deleted_at IS NULL AND status = 'results'")).
+		Find(&archived).Error; err != nil {
+		return err
+	}
+
+	docs := make([]*similarityDoc, 0, len(submissions)+len(archived))
+	for _, submission := range submissions {
+		docs = append(docs, newSimilarityDoc(submission, true))
+	}
+	for _, submission := range archived {
+		docs = append(docs, newSimilarityDoc(submission, false))
+	}
+	pairs := findSimilarPairs(docs)
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("hackathon_id = ?", scan.HackathonID).Delete(&models.SubmissionSimilarity{}).Error; err != nil {
+			return err
+		}
+		if len(pairs) > 0 {
+			if err := tx.CreateInBatches(&pairs, 200).Error; err != nil {
+				return err
+			}
+		}
+		now := time.Now()
+		return tx.Model(scan).Updates(map[string]interface{}{
+			"status":      "done",
+			"error":       "",
+			"compared":    len(docs),
+			"flagged":     len(pairs),
+			"finished_at": &now,
+		}).Error
+	})
+}
+
+// startSimilarityScan 登记检测任务并在后台执行；已有未超时的检测在进行时返回错误
+func startSimilarityScan(hackathonID uint64) (*models.SimilarityScan, error) {
+	var scan models.SimilarityScan
+	err := database.DB.Where("hackathon_id = ?", hackathonID).First(&scan).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err == nil && scan.Status == "running" && time.Since(scan.StartedAt) < similarityScanStaleAge {
+		return nil, errors.New("相似度检测正在进行中")
+	}
+
+	scan.HackathonID = hackathonID
+	scan.Status = "running"
+	scan.Error = ""
+	scan.StartedAt = time.Now()
+	scan.FinishedAt = nil
+	if err := database.DB.Save(&scan).Error; err != nil {
+		return nil, err
+	}
+
+	go func(scan models.SimilarityScan) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("活动 %d 相似度检测异常: %v", scan.HackathonID, r)
+				markSimilarityScanFailed(&scan, "检测异常")
+			}
+		}()
+		if err := runSimilarityScan(&scan); err != nil {
+			log.Printf("活动 %d 相似度检测失败: %v", scan.HackathonID, err)
+			markSimilarityScanFailed(&scan, err.Error())
+		}
+	}(scan)
+
+	return &scan, nil
+}
+
+// markSimilarityScanFailed 将检测任务标记为失败
+func markSimilarityScanFailed(scan *models.SimilarityScan, message string) {
+	if len(message) > 500 {
+		message = message[:500]
+	}
+	now := time.Now()
+	database.DB.Model(scan).Updates(map[string]interface{}{
+		"status":      "failed",
+		"error":       message,
+		"finished_at": &now,
+	})
+}
+
+// similarityOnSubmissionEnd 提交阶段结束时自动在后台检测相似作品
+func similarityOnSubmissionEnd(hackathonID uint64) {
+	if _, err := startSimilarityScan(hackathonID); err != nil {
+		log.Printf("活动 %d 启动相似度检测失败: %v", hackathonID, err)
+	}
+}
+
+// checkSimilarityManager 检查用户是否可以查看或发起活动的相似度检测
+func (s *SimilarityService) checkSimilarityManager(hackathonID, userID uint64, userRole string) (*models.Hackathon, error) {
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
+		return nil, errors.New("活动不存在")
+	}
+	if userRole != "admin" && hackathon.OrganizerID != userID {
+		return nil, errors.New("只能查看自己创建活动的相似度检测")
+	}
+	return &hackathon, nil
+}
+
+// StartScan 主办方手动发起相似度检测（仅活动创建者，提交阶段开始后）
+func (s *SimilarityService) StartScan(hackathonID, userID uint64, userRole string) (*models.SimilarityScan, error) {
+	if userRole == "admin" {
+		return nil, errors.New("Admin不能发起相似度检测")
+	}
+	hackathon, err := s.checkSimilarityManager(hackathonID, userID, userRole)
+	if err != nil {
+		return nil, err
+	}
+	if hackathon.Status != "submission" && hackathon.Status != "voting" && hackathon.Status != "results" {
+		return nil, errors.New("提交阶段开始后才能进行相似度检测")
+	}
+	return startSimilarityScan(hackathonID)
+}
+
+// GetReport 获取相似度检测报告：最近一次检测的状态及按相似度排序的相似作品对
+func (s *SimilarityService) GetReport(hackathonID, userID uint64, userRole string) (map[string]interface{}, error) {
+	if _, err := s.checkSimilarityManager(hackathonID, userID, userRole); err != nil {
+		return nil, err
+	}
+
+	var scan *models.SimilarityScan
+	var record models.SimilarityScan
+	if err := database.DB.Where("hackathon_id = ?", hackathonID).First(&record).Error; err == nil {
+		scan = &record
+	}
+
+	var pairs []models.SubmissionSimilarity
+	if err := database.DB.Where("hackathon_id = ?", hackathonID).
+		Preload("Submission").Preload("Submission.Team").
+		Preload("MatchedSubmission").Preload("MatchedSubmission.Team").Preload("MatchedSubmission.Hackathon").
+		Order("score DESC, description_score DESC, id ASC").Find(&pairs).Error; err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"scan":  scan,
+		"pairs": pairs,
+	}, nil
+}
@@ -0,0 +1,351 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"hackathon-backend/database"
+	"hackathon-backend/models"
+)
+
+// SnapshotService 作品仓库快照：作品到达提交截止时间时锁定其 git 仓库的提交，供评委核对
+type SnapshotService struct{}
+
+const (
+	snapshotConcurrency  = 8           // 同时解析的仓库数
+	snapshotPollInterval = time.Minute // 检查作品是否已到提交截止时间的间隔
+)
+
+// snapshotMu 串行执行快照任务，避免截止检查与阶段切换同时为同一作品生成快照
+var snapshotMu sync.Mutex
+
+var commitHashPattern = regexp.MustCompile(`^[0-9a-f]{40}([0-9a-f]{24})?$`)
+
+// snapshotLeafHash 默克尔树叶子哈希：sha256(作品ID:仓库地址:提交)
+func snapshotLeafHash(snapshot *models.SubmissionSnapshot) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d:%s:%s", snapshot.SubmissionID, snapshot.RepoURL, snapshot.CommitHash)))
+	return hex.EncodeToString(sum[:])
+}
+
+// snapshotLink 作品用于仓库快照的链接：优先使用仓库地址，未填写时使用作品链接
+func snapshotLink(submission *models.Submission) string {
+	if submission.RepoURL != "" {
+		return submission.RepoURL
+	}
+	return submission.Link
+}
+
+// resolveSnapshot 解析作品链接对应仓库的当前提交
+func resolveSnapshot(submission *models.Submission, snapshot *models.SubmissionSnapshot) {
+	snapshot.Link = snapshotLink(submission)
+	snapshot.SnapshotAt = time.Now()
+	snapshot.Error = ""
+
+	repoURL, ref, ok := ParseGitLink(snapshot.Link)
+	if !ok {
+		snapshot.Status = "not_git"
+		snapshot.RepoURL, snapshot.Ref, snapshot.CommitHash, snapshot.LeafHash = "", "", "", ""
+		return
+	}
+	snapshot.RepoURL, snapshot.Ref = repoURL, ref
+
+	// 链接本身指向某个提交时直接锁定该提交
+	if commitHashPattern.MatchString(ref) {
+		snapshot.CommitHash = ref
+	} else {
+		hash, err := repoResolver.ResolveHead(repoURL, ref)
+		if err != nil {
+			snapshot.Status = "failed"
+			snapshot.Error = err.Error()
+			if len(snapshot.Error) > 500 {
+				snapshot.Error = snapshot.Error[:500]
+			}
+			return
+		}
+		snapshot.CommitHash = hash
+	}
+	snapshot.Status = "pinned"
+	snapshot.LeafHash = snapshotLeafHash(snapshot)
+}
+
+// SnapshotSubmissions 为活动所有已提交作品生成仓库快照。已锁定（pinned）的快照不会被覆盖，
+// 因此重复执行只会补全新作品或重试解析失败的仓库
+func (s *SnapshotService) SnapshotSubmissions(hackathonID uint64) error {
+	var submissions []models.Submission
+	if err := database.DB.Where("hackathon_id = ? AND draft = 0", hackathonID).Find(&submissions).Error; err != nil {
+		return err
+	}
+	return snapshotSubmissionList(hackathonID, submissions)
+}
+
+// snapshotSubmissionList 为活动的指定作品生成仓库快照，跳过已锁定的快照
+func snapshotSubmissionList(hackathonID uint64, submissions []models.Submission) error {
+	snapshotMu.Lock()
+	defer snapshotMu.Unlock()
+
+	var existing []models.SubmissionSnapshot
+	if err := database.DB.Where("hackathon_id = ?", hackathonID).Find(&existing).Error; err != nil {
+		return err
+	}
+	existingMap := make(map[uint64]models.SubmissionSnapshot, len(existing))
+	for _, snapshot := range existing {
+		existingMap[snapshot.SubmissionID] = snapshot
+	}
+
+	pending := make([]models.SubmissionSnapshot, 0, len(submissions))
+	pendingSubmissions := make([]*models.Submission, 0, len(submissions))
+	for i := range submissions {
+		snapshot, ok := existingMap[submissions[i].ID]
+		if ok && snapshot.Status == "pinned" {
+			continue
+		}
+		if !ok {
+			snapshot = models.SubmissionSnapshot{HackathonID: hackathonID, SubmissionID: submissions[i].ID}
+		}
+		pending = append(pending, snapshot)
+		pendingSubmissions = append(pendingSubmissions, &submissions[i])
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, snapshotConcurrency)
+	for i := range pending {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			resolveSnapshot(pendingSubmissions[i], &pending[i])
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range pending {
+		if err := database.DB.Save(&pending[i]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshotOnSubmissionEnd 提交阶段结束时在后台为尚未快照的作品生成仓库快照（提前切换阶段时截止时间即为切换时间），
+// 失败只记录日志，不阻塞阶段切换（主办方可手动重试）
+func snapshotOnSubmissionEnd(hackathonID uint64) {
+	go func() {
+		snapshotService := &SnapshotService{}
+		if err := snapshotService.SnapshotSubmissions(hackathonID); err != nil {
+			log.Printf("活动 %d 生成仓库快照失败: %v", hackathonID, err)
+		}
+	}()
+}
+
+// StartSnapshotWorker 启动提交截止快照：作品到达实际截止时间后立即锁定仓库提交
+func StartSnapshotWorker() {
+	go func() {
+		ticker := time.NewTicker(snapshotPollInterval)
+		defer ticker.Stop()
+		for {
+			snapshotDueSubmissions()
+			<-ticker.C
+		}
+	}()
+}
+
+// snapshotDueSubmissions 为已过实际提交截止时间（提交阶段结束时间，队伍有延期时以延期为准，再加迟交宽限期）
+// 且尚未生成快照的作品生成快照
+func snapshotDueSubmissions() {
+	var submissions []models.Submission
+	if err := database.DB.Model(&models.Submission{}).
+		Joins("JOIN hackathons ON hackathons.id = submissions.hackathon_id AND hackathons.deleted_at IS NULL").
+		Joins("JOIN hackathon_stages ON hackathon_stages.hackathon_id = submissions.hackathon_id AND hackathon_stages.stage = ?", "submission").
+		Joins("LEFT JOIN submission_extensions ON submission_extensions.hackathon_id = submissions.hackathon_id AND submission_extensions.team_id = submissions.team_id").
+		Joins("LEFT JOIN submission_snapshots ON submission_snapshots.submission_id = submissions.id").
+		Where("submissions.draft = 0 AND submission_snapshots.id IS NULL").
+		Where("hackathons.status IN ?", []string{"submission", "voting", "results"}).
+		Where("DATE_ADD(GREATEST(hackathon_stages.end_time, COALESCE(submission_extensions.extended_until, hackathon_stages.end_time)), INTERVAL hackathons.late_grace_minutes MINUTE) <= ?", time.Now()).
+		Select("submissions.*").
+		Find(&submissions).Error; err != nil {
+		log.Printf("查询待快照作品失败: %v", err)
+		return
+	}
+
+	byHackathon := make(map[uint64][]models.Submission)
+	for _, submission := range submissions {
+		byHackathon[submission.HackathonID] = append(byHackathon[submission.HackathonID], submission)
+	}
+	for hackathonID, list := range byHackathon {
+		if err := snapshotSubmissionList(hackathonID, list); err != nil {
+			log.Printf("活动 %d 生成仓库快照失败: %v", hackathonID, err)
+		}
+	}
+}
+
+// RetrySnapshots 主办方手动补全或重试仓库快照（仅活动创建者，提交阶段结束后）
+func (s *SnapshotService) RetrySnapshots(hackathonID, userID uint64, userRole string) error {
+	if userRole == "admin" {
+		return errors.New("Admin不能生成仓库快照")
+	}
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
+		return errors.New("活动不存在")
+	}
+	if hackathon.OrganizerID != userID {
+		return errors.New("只能为自己创建的活动生成仓库快照")
+	}
+	if hackathon.Status != "voting" && hackathon.Status != "results" {
+		return errors.New("提交阶段结束后才能生成仓库快照")
+	}
+	return s.SnapshotSubmissions(hackathonID)
+}
+
+// pinnedLeaves 活动已锁定快照的叶子哈希（按作品ID排序）
+func pinnedLeaves(snapshots []models.SubmissionSnapshot) ([]uint64, []string) {
+	sorted := make([]models.SubmissionSnapshot, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		if snapshot.Status == "pinned" {
+			sorted = append(sorted, snapshot)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].SubmissionID < sorted[j].SubmissionID })
+
+	ids := make([]uint64, len(sorted))
+	leaves := make([]string, len(sorted))
+	for i, snapshot := range sorted {
+		ids[i] = snapshot.SubmissionID
+		leaves[i] = snapshot.LeafHash
+	}
+	return ids, leaves
+}
+
+// merkleLevels 逐层计算默克尔树（父节点为 sha256(左子节点 || 右子节点)，奇数个时最后一个与自身配对），返回从叶子到根的各层
+func merkleLevels(leaves []string) [][][]byte {
+	if len(leaves) == 0 {
+		return nil
+	}
+	level := make([][]byte, len(leaves))
+	for i, leaf := range leaves {
+		level[i], _ = hex.DecodeString(leaf)
+	}
+	levels := [][][]byte{level}
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			right := level[i]
+			if i+1 < len(level) {
+				right = level[i+1]
+			}
+			sum := sha256.Sum256(append(append([]byte{}, level[i]...), right...))
+			next = append(next, sum[:])
+		}
+		levels = append(levels, next)
+		level = next
+	}
+	return levels
+}
+
+// merkleProof 叶子的默克尔证明：自下而上的兄弟节点哈希及其位置（left 表示兄弟节点在左侧）
+func merkleProof(levels [][][]byte, index int) []map[string]interface{} {
+	proof := make([]map[string]interface{}, 0, len(levels))
+	for _, level := range levels[:len(levels)-1] {
+		sibling := index ^ 1
+		if sibling >= len(level) {
+			sibling = index
+		}
+		proof = append(proof, map[string]interface{}{
+			"hash": hex.EncodeToString(level[sibling]),
+			"left": sibling < index,
+		})
+		index /= 2
+	}
+	return proof
+}
+
+// GetSnapshots 获取活动的仓库快照列表及所有已锁定提交的默克尔根（可上链或公开以证明快照未被篡改）
+func (s *SnapshotService) GetSnapshots(hackathonID uint64) (map[string]interface{}, error) {
+	var snapshots []models.SubmissionSnapshot
+	if err := database.DB.Where("hackathon_id = ?", hackathonID).Order("submission_id ASC").Find(&snapshots).Error; err != nil {
+		return nil, err
+	}
+
+	_, leaves := pinnedLeaves(snapshots)
+	merkleRoot := ""
+	if levels := merkleLevels(leaves); levels != nil {
+		merkleRoot = hex.EncodeToString(levels[len(levels)-1][0])
+	}
+
+	return map[string]interface{}{
+		"merkle_root": merkleRoot,
+		"snapshots":   snapshots,
+	}, nil
+}
+
+// CheckSnapshot 评委查看作品的锁定提交，并实时查询仓库当前提交，已变化时给出提示
+func (s *SnapshotService) CheckSnapshot(submissionID, judgeUserID uint64) (map[string]interface{}, error) {
+	var submission models.Submission
+	if err := database.DB.Where("id = ? AND draft = 0", submissionID).First(&submission).Error; err != nil {
+		return nil, errors.New("作品不存在")
+	}
+	judgeService := &JudgeService{}
+	if !judgeService.IsJudge(submission.HackathonID, judgeUserID) {
+		return nil, errors.New("您不是该活动的评委")
+	}
+
+	var snapshot models.SubmissionSnapshot
+	if err := database.DB.Where("submission_id = ?", submissionID).First(&snapshot).Error; err != nil {
+		return map[string]interface{}{
+			"snapshot": nil,
+			"warning":  "该作品尚未生成仓库快照",
+		}, nil
+	}
+
+	result := map[string]interface{}{
+		"snapshot": snapshot,
+	}
+	if snapshot.Status != "pinned" {
+		if snapshot.Status == "failed" {
+			result["warning"] = "提交截止时未能锁定仓库提交: " + snapshot.Error
+		}
+		return result, nil
+	}
+
+	// 默克尔证明
+	var snapshots []models.SubmissionSnapshot
+	if err := database.DB.Where("hackathon_id = ?", submission.HackathonID).Find(&snapshots).Error; err != nil {
+		return nil, err
+	}
+	ids, leaves := pinnedLeaves(snapshots)
+	levels := merkleLevels(leaves)
+	for i, id := range ids {
+		if id == submissionID {
+			result["merkle_root"] = hex.EncodeToString(levels[len(levels)-1][0])
+			result["merkle_proof"] = merkleProof(levels, i)
+			break
+		}
+	}
+
+	// 链接指向固定提交时无需比较
+	if commitHashPattern.MatchString(snapshot.Ref) {
+		result["diverged"] = false
+		return result, nil
+	}
+	current, err := repoResolver.ResolveHead(snapshot.RepoURL, snapshot.Ref)
+	if err != nil {
+		result["warning"] = "无法查询仓库当前提交: " + err.Error()
+		return result, nil
+	}
+	result["current_commit"] = current
+	result["diverged"] = current != snapshot.CommitHash
+	if current != snapshot.CommitHash {
+		result["warning"] = "仓库在提交截止后有新的提交，请以锁定的提交 " + snapshot.CommitHash + " 为准评审"
+	}
+	if snapshotLink(&submission) != snapshot.Link {
+		result["link_changed"] = true
+	}
+	return result, nil
+}
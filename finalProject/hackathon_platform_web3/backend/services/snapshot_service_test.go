@@ -0,0 +1,113 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"hackathon-backend/models"
+)
+
+// fakeRepoResolver 按仓库地址返回预设提交，记录调用以便断言
+type fakeRepoResolver struct {
+	heads map[string]string
+	calls []string
+}
+
+func (r *fakeRepoResolver) ResolveHead(repoURL, ref string) (string, error) {
+	r.calls = append(r.calls, repoURL+"@"+ref)
+	hash, ok := r.heads[repoURL+"@"+ref]
+	if !ok {
+		return "", errors.New("仓库不存在")
+	}
+	return hash, nil
+}
+
+func withFakeResolver(t *testing.T, heads map[string]string) *fakeRepoResolver {
+	t.Helper()
+	fake := &fakeRepoResolver{heads: heads}
+	old := SetRepoResolver(fake)
+	t.Cleanup(func() { SetRepoResolver(old) })
+	return fake
+}
+
+const testCommit = "0123456789abcdef0123456789abcdef01234567"
+
+func TestResolveSnapshotPinsBranchHead(t *testing.T) {
+	fake := withFakeResolver(t, map[string]string{
+		"https://github.com/org/repo.git@main": testCommit,
+	})
+
+	submission := &models.Submission{ID: 7, RepoURL: "https://github.com/org/repo/tree/main"}
+	var snapshot models.SubmissionSnapshot
+	resolveSnapshot(submission, &snapshot)
+
+	if snapshot.Status != "pinned" || snapshot.CommitHash != testCommit {
+		t.Fatalf("snapshot = %s/%s, want pinned/%s", snapshot.Status, snapshot.CommitHash, testCommit)
+	}
+	if snapshot.RepoURL != "https://github.com/org/repo.git" || snapshot.Ref != "main" {
+		t.Errorf("repo = %s ref = %s", snapshot.RepoURL, snapshot.Ref)
+	}
+	if snapshot.LeafHash != snapshotLeafHash(&snapshot) || snapshot.LeafHash == "" {
+		t.Errorf("leaf hash not set")
+	}
+	if len(fake.calls) != 1 {
+		t.Errorf("resolver calls = %v, want 1", fake.calls)
+	}
+}
+
+func TestResolveSnapshotCommitLinkSkipsResolver(t *testing.T) {
+	fake := withFakeResolver(t, nil)
+
+	submission := &models.Submission{ID: 8, Link: "https://gitlab.com/org/repo/-/commit/" + testCommit}
+	var snapshot models.SubmissionSnapshot
+	resolveSnapshot(submission, &snapshot)
+
+	if snapshot.Status != "pinned" || snapshot.CommitHash != testCommit {
+		t.Fatalf("snapshot = %s/%s, want pinned/%s", snapshot.Status, snapshot.CommitHash, testCommit)
+	}
+	if len(fake.calls) != 0 {
+		t.Errorf("resolver called for a commit link: %v", fake.calls)
+	}
+}
+
+func TestResolveSnapshotFailureAndNonGit(t *testing.T) {
+	withFakeResolver(t, nil)
+
+	var failed models.SubmissionSnapshot
+	resolveSnapshot(&models.Submission{ID: 9, RepoURL: "https://github.com/org/missing"}, &failed)
+	if failed.Status != "failed" || failed.Error == "" || failed.LeafHash != "" {
+		t.Errorf("missing repo snapshot = %+v, want failed with error", failed)
+	}
+
+	var notGit models.SubmissionSnapshot
+	resolveSnapshot(&models.Submission{ID: 10, Link: "https://example.com/demo"}, &notGit)
+	if notGit.Status != "not_git" || notGit.RepoURL != "" {
+		t.Errorf("non-git snapshot = %+v, want not_git", notGit)
+	}
+}
+
+func TestParseGitLinkHostAllowlist(t *testing.T) {
+	tests := []struct {
+		link    string
+		repoURL string
+		ref     string
+		ok      bool
+	}{
+		{"https://github.com/org/repo", "https://github.com/org/repo.git", "", true},
+		{"https://www.github.com/org/repo/tree/feature/x", "https://github.com/org/repo.git", "feature/x", true},
+		{"http://gitlab.com/group/repo/-/tree/dev", "https://gitlab.com/group/repo.git", "dev", true},
+		{"https://bitbucket.org/team/repo/src/main", "https://bitbucket.org/team/repo.git", "main", true},
+		{"https://git.internal.example/org/repo.git", "", "", false},
+		{"http://10.0.0.5/org/repo.git", "", "", false},
+		{"https://github.com:8443/org/repo", "", "", false},
+		{"https://user@github.com/org/repo", "", "", false},
+		{"https://github.com/org", "", "", false},
+		{"ftp://github.com/org/repo", "", "", false},
+	}
+	for _, tt := range tests {
+		repoURL, ref, ok := ParseGitLink(tt.link)
+		if ok != tt.ok || repoURL != tt.repoURL || ref != tt.ref {
+			t.Errorf("ParseGitLink(%q) = (%q, %q, %v), want (%q, %q, %v)", tt.link, repoURL, ref, ok, tt.repoURL, tt.ref, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,494 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/url"
+	"strings"
+	"time"
+
+	"hackathon-backend/database"
+	"hackathon-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// SponsorPortalService 赞助商门户：维护资料、查看所赞助活动的数据、导出同意共享信息的报名者、追加赞助活动
+type SponsorPortalService struct{}
+
+// SponsorProfileInput 赞助商资料
+type SponsorProfileInput struct {
+	Name        string `json:"name"` // 展示名称（即账号名称）
+	Website     string `json:"website"`
+	Description string `json:"description"`
+}
+
+// SponsorEventMetrics 所赞助活动的实时数据（赞助指定赛道时，作品与投票只统计该赛道）
+type SponsorEventMetrics struct {
+	HackathonID           uint64    `json:"hackathon_id"`
+	HackathonName         string    `json:"hackathon_name"`
+	Status                string    `json:"status"`
+	TrackID               *uint64   `json:"track_id"`
+	Registrations         int64     `json:"registrations"`          // 已通过的报名
+	PendingRegistrations  int64     `json:"pending_registrations"`  // 待审核的报名
+	Waitlist              int64     `json:"waitlist"`               // 候补人数
+	Checkins              int64     `json:"checkins"`               // 签到人数
+	ConsentedParticipants int64     `json:"consented_participants"` // 同意向赞助商提供信息的报名者
+	Teams                 int64     `json:"teams"`
+	Submissions           int64     `json:"submissions"`       // 正式提交的作品
+	DraftSubmissions      int64     `json:"draft_submissions"` // 草稿
+	Votes                 int64     `json:"votes"`
+	GeneratedAt           time.Time `json:"generated_at"`
+}
+
+// ConsentedParticipant 同意向赞助商提供信息的报名者
+type ConsentedParticipant struct {
+	ParticipantID uint64     `json:"participant_id"`
+	Nickname      string     `json:"nickname"`
+	WalletAddress string     `json:"wallet_address"`
+	Email         string     `json:"email"`
+	RegisteredAt  time.Time  `json:"registered_at"`
+	ConsentedAt   *time.Time `json:"consented_at"`
+	CheckedIn     bool       `json:"checked_in"`
+}
+
+// SponsorEventRequestInput 追加赞助活动的申请参数
+type SponsorEventRequestInput struct {
+	HackathonID uint64  `json:"hackathon_id"`
+	TrackID     *uint64 `json:"track_id"`
+	AmountSol   float64 `json:"amount_sol"`
+	Message     string  `json:"message"`
+}
+
+// currentSponsor 获取登录用户对应的赞助商记录
+func currentSponsor(userID uint64) (*models.Sponsor, error) {
+	var sponsor models.Sponsor
+	if err := database.DB.Preload("User").Where("user_id = ?", userID).First(&sponsor).Error; err != nil {
+		return nil, errors.New("赞助商不存在")
+	}
+	return &sponsor, nil
+}
+
+// activeSponsor 获取登录用户对应的赞助商记录，已停用的赞助商不能查看活动数据或追加赞助（长期赞助到期不受影响）
+func activeSponsor(userID uint64) (*models.Sponsor, error) {
+	sponsor, err := currentSponsor(userID)
+	if err != nil {
+		return nil, err
+	}
+	if sponsor.Status == "inactive" {
+		return nil, errors.New("赞助商账号已停用")
+	}
+	return sponsor, nil
+}
+
+// sponsoredEvent 检查赞助商是否赞助了该活动
+func sponsoredEvent(sponsorID, hackathonID uint64) (*models.HackathonSponsorEvent, error) {
+	var event models.HackathonSponsorEvent
+	if err := database.DB.Preload("Hackathon").
+		Where("sponsor_id = ? AND hackathon_id = ?", sponsorID, hackathonID).
+		First(&event).Error; err != nil {
+		return nil, errors.New("未赞助该活动")
+	}
+	if event.Hackathon.ID == 0 || event.Hackathon.DeletedAt.Valid {
+		return nil, errors.New("活动不存在")
+	}
+	return &event, nil
+}
+
+// GetProfile 获取赞助商资料
+func (s *SponsorPortalService) GetProfile(userID uint64) (*models.Sponsor, error) {
+	return currentSponsor(userID)
+}
+
+// UpdateProfile 修改赞助商资料（展示名称、官网、简介）
+func (s *SponsorPortalService) UpdateProfile(userID uint64, input SponsorProfileInput) (*models.Sponsor, error) {
+	sponsor, err := currentSponsor(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	input.Website = strings.TrimSpace(input.Website)
+	if input.Name == "" {
+		return nil, errors.New("名称不能为空")
+	}
+	if len([]rune(input.Name)) > 100 {
+		return nil, errors.New("名称不能超过100个字符")
+	}
+	if input.Website != "" {
+		parsed, err := url.Parse(input.Website)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" || len(input.Website) > 255 {
+			return nil, errors.New("官网地址须为有效的 http(s) 地址")
+		}
+	}
+	if len([]rune(input.Description)) > 2000 {
+		return nil, errors.New("简介不能超过2000个字符")
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Update("name", input.Name).Error; err != nil {
+			return err
+		}
+		return tx.Model(sponsor).Updates(map[string]interface{}{
+			"website":     input.Website,
+			"description": input.Description,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return currentSponsor(userID)
+}
+
+// UpdateLogo 上传并更换赞助商 Logo，返回新的 Logo 地址（原 Logo 仍被申请记录引用，不删除）
+func (s *SponsorPortalService) UpdateLogo(userID uint64, header *multipart.FileHeader) (string, error) {
+	sponsor, err := currentSponsor(userID)
+	if err != nil {
+		return "", err
+	}
+	logoURL, err := (&SponsorService{}).UploadLogo(header)
+	if err != nil {
+		return "", err
+	}
+	if err := database.DB.Model(sponsor).Update("logo_url", logoURL).Error; err != nil {
+		return "", err
+	}
+	return logoURL, nil
+}
+
+// GetSponsoredEvents 获取赞助商赞助的活动（含赞助的赛道）
+func (s *SponsorPortalService) GetSponsoredEvents(userID uint64) ([]models.HackathonSponsorEvent, error) {
+	sponsor, err := currentSponsor(userID)
+	if err != nil {
+		return nil, err
+	}
+	var events []models.HackathonSponsorEvent
+	if err := database.DB.Preload("Hackathon").Preload("Track").
+		Joins("INNER JOIN hackathons ON hackathons.id = hackathon_sponsor_events.hackathon_id AND hackathons.deleted_at IS NULL").
+		Where("hackathon_sponsor_events.sponsor_id = ?", sponsor.ID).
+		Order("hackathons.start_time DESC").
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// GetEventMetrics 获取所赞助活动的实时数据
+func (s *SponsorPortalService) GetEventMetrics(userID, hackathonID uint64) (*SponsorEventMetrics, error) {
+	sponsor, err := activeSponsor(userID)
+	if err != nil {
+		return nil, err
+	}
+	event, err := sponsoredEvent(sponsor.ID, hackathonID)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := SponsorEventMetrics{
+		HackathonID:   hackathonID,
+		HackathonName: event.Hackathon.Name,
+		Status:        event.Hackathon.Status,
+		TrackID:       event.TrackID,
+		GeneratedAt:   time.Now(),
+	}
+	registrations := database.DB.Model(&models.Registration{}).Where("hackathon_id = ?", hackathonID)
+	submissions := database.DB.Model(&models.Submission{}).Where("hackathon_id = ?", hackathonID)
+	votes := database.DB.Model(&models.Vote{}).Where("hackathon_id = ?", hackathonID)
+	if event.TrackID != nil {
+		submissions = submissions.Where("id IN (?)", database.DB.Model(&models.SubmissionTrack{}).Select("submission_id").Where("track_id = ?", *event.TrackID))
+		votes = votes.Where("track_id = ?", *event.TrackID)
+	}
+
+	counts := []struct {
+		query *gorm.DB
+		dest  *int64
+	}{
+		{registrations.Session(&gorm.Session{}).Where("status = ?", "approved"), &metrics.Registrations},
+		{registrations.Session(&gorm.Session{}).Where("status = ?", "pending"), &metrics.PendingRegistrations},
+		{registrations.Session(&gorm.Session{}).Where("status = ? AND sponsor_consent = ?", "approved", true), &metrics.ConsentedParticipants},
+		{database.DB.Model(&models.WaitlistEntry{}).Where("hackathon_id = ? AND status IN ?", hackathonID, []string{"waiting", "offered"}), &metrics.Waitlist},
+		{database.DB.Model(&models.Checkin{}).Where("hackathon_id = ?", hackathonID), &metrics.Checkins},
+		{database.DB.Model(&models.Team{}).Where("hackathon_id = ?", hackathonID), &metrics.Teams},
+		{submissions.Session(&gorm.Session{}).Where("draft = ?", 0), &metrics.Submissions},
+		{submissions.Session(&gorm.Session{}).Where("draft = ?", 1), &metrics.DraftSubmissions},
+		{votes, &metrics.Votes},
+	}
+	for _, count := range counts {
+		if err := count.query.Count(count.dest).Error; err != nil {
+			return nil, fmt.Errorf("统计活动数据失败: %w", err)
+		}
+	}
+	return &metrics, nil
+}
+
+// GetConsentedParticipants 获取所赞助活动中同意向赞助商提供信息的报名者（仅已通过的报名）
+func (s *SponsorPortalService) GetConsentedParticipants(userID, hackathonID uint64) ([]ConsentedParticipant, error) {
+	sponsor, err := activeSponsor(userID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := sponsoredEvent(sponsor.ID, hackathonID); err != nil {
+		return nil, err
+	}
+
+	var registrations []models.Registration
+	if err := database.DB.Preload("Participant").
+		Where("hackathon_id = ? AND status = ? AND sponsor_consent = ?", hackathonID, "approved", true).
+		Order("id ASC").
+		Find(&registrations).Error; err != nil {
+		return nil, err
+	}
+
+	var checkedIn []uint64
+	if err := database.DB.Model(&models.Checkin{}).Where("hackathon_id = ?", hackathonID).
+		Pluck("participant_id", &checkedIn).Error; err != nil {
+		return nil, err
+	}
+	checkedInSet := make(map[uint64]bool, len(checkedIn))
+	for _, participantID := range checkedIn {
+		checkedInSet[participantID] = true
+	}
+
+	participants := make([]ConsentedParticipant, 0, len(registrations))
+	for _, registration := range registrations {
+		if registration.Participant.ID == 0 || registration.Participant.DeletedAt.Valid {
+			continue
+		}
+		participants = append(participants, ConsentedParticipant{
+			ParticipantID: registration.ParticipantID,
+			Nickname:      registration.Participant.Nickname,
+			WalletAddress: registration.Participant.WalletAddress,
+			Email:         registration.Participant.Email,
+			RegisteredAt:  registration.CreatedAt,
+			ConsentedAt:   registration.SponsorConsentAt,
+			CheckedIn:     checkedInSet[registration.ParticipantID],
+		})
+	}
+	return participants, nil
+}
+
+// GetEventRequests 获取赞助商提交的追加赞助申请
+func (s *SponsorPortalService) GetEventRequests(userID uint64) ([]models.SponsorEventRequest, error) {
+	sponsor, err := currentSponsor(userID)
+	if err != nil {
+		return nil, err
+	}
+	var requests []models.SponsorEventRequest
+	if err := database.DB.Preload("Hackathon").Preload("Track").
+		Where("sponsor_id = ?", sponsor.ID).
+		Order("id DESC").
+		Find(&requests).Error; err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// CreateEventRequest 已开通账号的赞助商申请赞助其他活动（无需重新提交手机号申请），由活动主办方审核
+func (s *SponsorPortalService) CreateEventRequest(userID uint64, input SponsorEventRequestInput) (*models.SponsorEventRequest, error) {
+	sponsor, err := activeSponsor(userID)
+	if err != nil {
+		return nil, err
+	}
+	if input.AmountSol < 0 {
+		return nil, errors.New("赞助金额不能为负数")
+	}
+	input.Message = strings.TrimSpace(input.Message)
+	if len([]rune(input.Message)) > 1000 {
+		return nil, errors.New("留言不能超过1000个字符")
+	}
+
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", input.HackathonID).First(&hackathon).Error; err != nil {
+		return nil, errors.New("活动不存在")
+	}
+	if hackathon.Status == "preparation" || hackathon.Status == "results" {
+		return nil, errors.New("只能赞助已发布且尚未公布结果的活动")
+	}
+
+	trackName := ""
+	if input.TrackID != nil {
+		var track models.HackathonTrack
+		if err := database.DB.Where("id = ? AND hackathon_id = ?", *input.TrackID, hackathon.ID).First(&track).Error; err != nil {
+			return nil, errors.New("赛道不存在")
+		}
+		trackName = track.Name
+	}
+
+	var count int64
+	if err := database.DB.Model(&models.HackathonSponsorEvent{}).
+		Where("sponsor_id = ? AND hackathon_id = ?", sponsor.ID, hackathon.ID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("已赞助该活动")
+	}
+
+	request := models.SponsorEventRequest{
+		SponsorID:   sponsor.ID,
+		HackathonID: hackathon.ID,
+		TrackID:     input.TrackID,
+		AmountSol:   input.AmountSol,
+		Message:     input.Message,
+		Status:      "pending",
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// 锁定活动行，避免重复提交待审核申请
+		if _, err := lockHackathon(tx, hackathon.ID); err != nil {
+			return err
+		}
+		var pending int64
+		if err := tx.Model(&models.SponsorEventRequest{}).
+			Where("sponsor_id = ? AND hackathon_id = ? AND status = ?", sponsor.ID, hackathon.ID, "pending").
+			Count(&pending).Error; err != nil {
+			return err
+		}
+		if pending > 0 {
+			return errors.New("已提交该活动的赞助申请，请等待主办方审核")
+		}
+		if err := tx.Create(&request).Error; err != nil {
+			return err
+		}
+		return notifyUsers(tx, []uint64{hackathon.OrganizerID}, hackathon.ID, NotifySponsorEventRequested, map[string]interface{}{
+			"SponsorName":   sponsor.User.Name,
+			"HackathonName": hackathon.Name,
+			"TrackName":     trackName,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// GetHackathonEventRequests 获取活动收到的追加赞助申请（活动创建者或Admin）
+func (s *SponsorPortalService) GetHackathonEventRequests(hackathonID, userID uint64, userRole string, status string) ([]models.SponsorEventRequest, error) {
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
+		return nil, errors.New("活动不存在")
+	}
+	if userRole != "admin" && hackathon.OrganizerID != userID {
+		return nil, errors.New("只能查看自己创建活动的赞助申请")
+	}
+
+	query := database.DB.Preload("Sponsor").Preload("Sponsor.User").Preload("Track").
+		Where("hackathon_id = ?", hackathonID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var requests []models.SponsorEventRequest
+	if err := query.Order("id DESC").Find(&requests).Error; err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// ReviewEventRequest 主办方审核追加赞助申请，通过后建立活动赞助关联
+func (s *SponsorPortalService) ReviewEventRequest(hackathonID, requestID uint64, action string, rejectReason string, userID uint64, userRole string) error {
+	if action != "approved" && action != "rejected" {
+		return errors.New("无效的审核操作")
+	}
+	if userRole == "admin" {
+		return errors.New("Admin不能审核活动赞助申请")
+	}
+	rejectReason = strings.TrimSpace(rejectReason)
+	if len([]rune(rejectReason)) > 500 {
+		return errors.New("拒绝原因不能超过500个字符")
+	}
+
+	var sponsor models.Sponsor
+	var hackathon *models.Hackathon
+	var request models.SponsorEventRequest
+	trackName := ""
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if hackathon, err = lockHackathon(tx, hackathonID); err != nil {
+			return err
+		}
+		if hackathon.OrganizerID != userID {
+			return errors.New("只能审核自己创建活动的赞助申请")
+		}
+		if err := tx.Where("id = ? AND hackathon_id = ?", requestID, hackathonID).First(&request).Error; err != nil {
+			return errors.New("申请不存在")
+		}
+		if request.Status != "pending" {
+			return errors.New("该申请已审核，无法重复审核")
+		}
+		if err := tx.Preload("User").Where("id = ?", request.SponsorID).First(&sponsor).Error; err != nil {
+			return errors.New("赞助商不存在")
+		}
+
+		if action == "approved" {
+			if sponsor.Status == "inactive" {
+				return errors.New("赞助商账号已停用")
+			}
+			if hackathon.Status == "results" {
+				return errors.New("活动已公布结果，不能再添加赞助商")
+			}
+			event := models.HackathonSponsorEvent{
+				HackathonID: hackathonID,
+				SponsorID:   sponsor.ID,
+			}
+			if request.TrackID != nil {
+				var track models.HackathonTrack
+				if err := tx.Where("id = ? AND hackathon_id = ?", *request.TrackID, hackathonID).First(&track).Error; err != nil {
+					return errors.New("赞助的赛道已不存在")
+				}
+				event.TrackID = &track.ID
+				trackName = track.Name
+			}
+			var count int64
+			if err := tx.Model(&models.HackathonSponsorEvent{}).
+				Where("sponsor_id = ? AND hackathon_id = ?", sponsor.ID, hackathonID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return errors.New("该赞助商已赞助本活动")
+			}
+			if err := tx.Create(&event).Error; err != nil {
+				return fmt.Errorf("创建活动赞助关联失败: %w", err)
+			}
+			// 追加赞助金额计入累计金额，并重新匹配等级
+			if err := addSponsorAmount(tx, sponsor.ID, request.AmountSol); err != nil {
+				return fmt.Errorf("计算赞助商等级失败: %w", err)
+			}
+		}
+
+		now := time.Now()
+		updateData := map[string]interface{}{
+			"status":      action,
+			"reviewer_id": userID,
+			"reviewed_at": now,
+		}
+		if action == "rejected" {
+			updateData["reject_reason"] = rejectReason
+		}
+		if err := tx.Model(&request).Updates(updateData).Error; err != nil {
+			return err
+		}
+
+		if err := notifyUsers(tx, []uint64{sponsor.UserID}, hackathonID, NotifySponsorEventReviewed, map[string]interface{}{
+			"HackathonName": hackathon.Name,
+			"TrackName":     trackName,
+			"Approved":      action == "approved",
+			"RejectReason":  rejectReason,
+		}); err != nil {
+			return fmt.Errorf("写入通知失败: %w", err)
+		}
+		if action == "approved" {
+			if err := emitWebhookEvent(tx, WebhookSponsorApproved, map[string]interface{}{
+				"sponsor_id":       sponsor.ID,
+				"event_request_id": request.ID,
+				"sponsor_type":     sponsor.SponsorType,
+				"logo_url":         sponsor.LogoURL,
+				"amount_sol":       request.AmountSol,
+				"track_id":         request.TrackID,
+				"hackathon_ids":    []uint64{hackathonID},
+			}, hackathonID); err != nil {
+				return fmt.Errorf("写入 Webhook 事件失败: %w", err)
+			}
+		}
+		return nil
+	})
+	return err
+}
@@ -1,14 +1,25 @@
 package services
 
 import (
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"math/rand"
+	"log"
+	"mime/multipart"
+	"strconv"
+	"strings"
 	"time"
 
+	"hackathon-backend/config"
 	"hackathon-backend/database"
 	"hackathon-backend/models"
+	"hackathon-backend/notify"
+	"hackathon-backend/storage"
 	"hackathon-backend/utils"
 
 	"gorm.io/gorm"
@@ -16,6 +27,15 @@ import (
 
 type SponsorService struct{}
 
+const (
+	sponsorLogoPrefix = "logos"                // Logo 在文件存储中的目录
+	sponsorLogoPath   = "/api/v1/files/logos/" // Logo 公开地址前缀
+
+	sponsorLogoTokenTTL        = 30 * time.Minute // 申请页上传 Logo 凭证有效期
+	sponsorLogoUnclaimedTTL    = 24 * time.Hour   // 上传后超过该时间仍未被申请引用的 Logo 会被清理
+	sponsorLogoCleanupInterval = time.Hour
+)
+
 // CreateApplication 创建赞助商申请
 func (s *SponsorService) CreateApplication(application *models.SponsorApplication) error {
 	// 检查手机号是否已申请
@@ -29,9 +49,59 @@ func (s *SponsorService) CreateApplication(application *models.SponsorApplicatio
 		application.Status = "pending"
 	}
 
+	if err := validateEventTracks(database.DB, application); err != nil {
+		return err
+	}
+
+	// base64 Logo 存入文件存储，数据库只保存地址
+	logoURL, err := s.StoreLogo(application.LogoURL)
+	if err != nil {
+		return err
+	}
+	application.LogoURL = logoURL
+
+	// 申请页上传的 Logo 记录保留到后台清理时再删除（清理时检查引用，不会删除已被申请引用的文件），
+	// 以免凭证在有效期内被重复使用
 	return database.DB.Create(application).Error
 }
 
+// validateEventTracks 校验申请中赞助的赛道：只能为所申请的活动指定赛道，且赛道须属于该活动
+func validateEventTracks(tx *gorm.DB, application *models.SponsorApplication) error {
+	if application.EventTracks == "" {
+		return nil
+	}
+	eventTracks := make(map[string]uint64)
+	if err := json.Unmarshal([]byte(application.EventTracks), &eventTracks); err != nil {
+		return errors.New("申请中的赛道数据格式错误")
+	}
+	var eventIDs []uint64
+	if application.EventIDs != "" {
+		if err := json.Unmarshal([]byte(application.EventIDs), &eventIDs); err != nil {
+			return errors.New("活动ID格式错误")
+		}
+	}
+	requested := make(map[string]bool, len(eventIDs))
+	for _, eventID := range eventIDs {
+		requested[strconv.FormatUint(eventID, 10)] = true
+	}
+	for key, trackID := range eventTracks {
+		if trackID == 0 {
+			continue
+		}
+		if !requested[key] {
+			return fmt.Errorf("赛道 %d 对应的活动 %s 不在申请赞助的活动中", trackID, key)
+		}
+		var count int64
+		if err := tx.Model(&models.HackathonTrack{}).Where("id = ? AND hackathon_id = ?", trackID, key).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return fmt.Errorf("活动 %s 中不存在赛道 %d", key, trackID)
+		}
+	}
+	return nil
+}
+
 // GetApplicationByID 根据 ID 查询申请
 func (s *SponsorService) GetApplicationByID(id uint64) (*models.SponsorApplication, error) {
 	var application models.SponsorApplication
@@ -127,8 +197,8 @@ func (s *SponsorService) ReviewApplication(applicationID uint64, action string,
 
 		// 如果审核通过，创建赞助商账号
 		if action == "approved" {
-			// 生成随机密码（8位）
-			password := generateRandomPassword(8)
+			// 生成随机初始密码（不告知赞助商，赞助商通过短信中的一次性链接自行设置密码）
+			password := generateRandomPassword(32)
 
 			// 创建用户
 			user := models.User{
@@ -157,14 +227,27 @@ func (s *SponsorService) ReviewApplication(applicationID uint64, action string,
 				SponsorType:   application.SponsorType,
 				Status:        "active",
 				ApplicationID: application.ID,
+				AmountSol:     application.AmountSol,
 			}
 
 			if err := tx.Create(&sponsor).Error; err != nil {
 				return fmt.Errorf("创建赞助商记录失败: %w", err)
 			}
+			// 按赞助金额匹配等级
+			if err := refreshSponsorTier(tx, sponsor.ID); err != nil {
+				return fmt.Errorf("计算赞助商等级失败: %w", err)
+			}
 
 			// 如果是活动指定赞助，创建关联关系
+			linkedIDs := []uint64{}
 			if application.SponsorType == "event_specific" && application.EventIDs != "" {
+				// 活动ID -> 赞助的赛道ID（可选）
+				eventTracks := make(map[string]uint64)
+				if application.EventTracks != "" {
+					if err := json.Unmarshal([]byte(application.EventTracks), &eventTracks); err != nil {
+						return errors.New("申请中的赛道数据格式错误")
+					}
+				}
 				var eventIDs []uint64
 				if err := json.Unmarshal([]byte(application.EventIDs), &eventIDs); err == nil {
 					for _, eventID := range eventIDs {
@@ -175,57 +258,302 @@ func (s *SponsorService) ReviewApplication(applicationID uint64, action string,
 								HackathonID: eventID,
 								SponsorID:   sponsor.ID,
 							}
+							// 赞助指定赛道（赛道须属于该活动；申请后赛道被删除时拒绝审核通过，由管理员与赞助商确认后处理）
+							trackName := ""
+							if trackID, ok := eventTracks[strconv.FormatUint(eventID, 10)]; ok && trackID > 0 {
+								var track models.HackathonTrack
+								if err := tx.Where("id = ? AND hackathon_id = ?", trackID, eventID).First(&track).Error; err != nil {
+									if errors.Is(err, gorm.ErrRecordNotFound) {
+										return fmt.Errorf("申请赞助的赛道已不存在（活动「%s」），无法审核通过", hackathon.Name)
+									}
+									return err
+								}
+								hackathonSponsorEvent.TrackID = &track.ID
+								trackName = track.Name
+							}
 							if err := tx.Create(&hackathonSponsorEvent).Error; err != nil {
 								// 忽略错误，继续处理其他活动
 								continue
 							}
+							linkedIDs = append(linkedIDs, eventID)
+							// 通知活动主办方
+							if err := notifyUsers(tx, []uint64{hackathon.OrganizerID}, eventID, NotifySponsorJoined, map[string]interface{}{
+								"HackathonName": hackathon.Name,
+								"TrackName":     trackName,
+							}); err != nil {
+								return fmt.Errorf("写入通知失败: %w", err)
+							}
 						}
 					}
 				}
 			}
 
-			// TODO: 发送通知（短信或邮件）告知赞助商账号信息
-			// 这里可以集成短信或邮件服务
+			// 短信告知赞助商登录账号与一次性设置密码链接（与账号创建在同一事务中写入发送队列）
+			setupToken, expiresAt, err := issuePasswordSetupToken(tx, user.ID)
+			if err != nil {
+				return fmt.Errorf("签发设置密码链接失败: %w", err)
+			}
+			if err := enqueueNotification(tx, notify.ChannelSMS, application.Phone, NotifySponsorApproved, map[string]interface{}{
+				"Phone":     application.Phone,
+				"SetupURL":  passwordSetupLink(setupToken),
+				"ExpiresAt": expiresAt.Format("2006-01-02 15:04 MST"),
+			}, true); err != nil {
+				return fmt.Errorf("写入通知失败: %w", err)
+			}
+			if err := notifyUsers(tx, []uint64{user.ID}, 0, NotifySponsorWelcome, nil); err != nil {
+				return fmt.Errorf("写入通知失败: %w", err)
+			}
+			if err := emitWebhookEvent(tx, WebhookSponsorApproved, map[string]interface{}{
+				"sponsor_id":     sponsor.ID,
+				"application_id": application.ID,
+				"sponsor_type":   application.SponsorType,
+				"logo_url":       application.LogoURL,
+				"amount_sol":     application.AmountSol,
+				"wallet_address": application.WalletAddress,
+				"hackathon_ids":  linkedIDs,
+			}, linkedIDs...); err != nil {
+				return fmt.Errorf("写入 Webhook 事件失败: %w", err)
+			}
 		}
 
 		return nil
 	})
 }
 
-// GetLongTermSponsors 获取长期赞助商列表
-func (s *SponsorService) GetLongTermSponsors() ([]models.Sponsor, error) {
+// GetLongTermSponsors 获取长期赞助商列表（不含已到期的），按等级排序；placement 非空时只返回在该位置展示的赞助商
+func (s *SponsorService) GetLongTermSponsors(placement string) ([]models.Sponsor, error) {
 	var sponsors []models.Sponsor
-	if err := database.DB.
+	query := database.DB.
 		Preload("User").
-		Where("sponsor_type = ? AND status = 'active' AND deleted_at IS NULL", "long_term").
-		Order("created_at DESC").
-		Find(&sponsors).Error; err != nil {
+		Preload("Tier").
+		Where("sponsors.sponsor_type = ? AND sponsors.status = 'active' AND sponsors.deleted_at IS NULL", "long_term").
+		Where("(sponsors.expires_at IS NULL OR sponsors.expires_at > ?)", time.Now())
+	if err := sponsorPlacementQuery(query, placement).Find(&sponsors).Error; err != nil {
 		return nil, err
 	}
+	fillSponsorPlacements(sponsors)
 	return sponsors, nil
 }
 
-// GetEventSponsors 获取活动的指定赞助商列表
-func (s *SponsorService) GetEventSponsors(hackathonID uint64) ([]models.Sponsor, error) {
+// GetEventSponsors 获取活动的指定赞助商列表（长期赞助到期不影响已赞助活动的展示），按等级排序；
+// trackID 大于 0 时只返回赞助该赛道的赞助商，placement 非空时只返回在该位置展示的赞助商
+func (s *SponsorService) GetEventSponsors(hackathonID, trackID uint64, placement string) ([]models.Sponsor, error) {
 	var sponsors []models.Sponsor
-	if err := database.DB.
+	query := database.DB.
 		Preload("User").
+		Preload("Tier").
+		Preload("Events", "hackathon_id = ?", hackathonID).
+		Preload("Events.Track").
 		Joins("INNER JOIN hackathon_sponsor_events ON hackathon_sponsor_events.sponsor_id = sponsors.id").
-		Where("hackathon_sponsor_events.hackathon_id = ? AND sponsors.status = 'active' AND sponsors.deleted_at IS NULL", hackathonID).
-		Order("sponsors.created_at DESC").
-		Find(&sponsors).Error; err != nil {
+		Where("hackathon_sponsor_events.hackathon_id = ? AND sponsors.status IN ('active','expired') AND sponsors.deleted_at IS NULL", hackathonID)
+	if trackID > 0 {
+		query = query.Where("hackathon_sponsor_events.track_id = ?", trackID)
+	}
+	if err := sponsorPlacementQuery(query, placement).Find(&sponsors).Error; err != nil {
 		return nil, err
 	}
+	fillSponsorPlacements(sponsors)
 	return sponsors, nil
 }
 
-// generateRandomPassword 生成随机密码
+// UploadLogo 上传赞助商 Logo，返回 Logo 的公开地址
+func (s *SponsorService) UploadLogo(header *multipart.FileHeader) (string, error) {
+	uploaded, err := storage.Upload(storage.KindImage, sponsorLogoPrefix, header)
+	if err != nil {
+		return "", err
+	}
+	// Logo 直接使用原图，缩略图不需要
+	storage.DeleteFiles(uploaded.ThumbnailKey)
+	return sponsorLogoURL(uploaded.Key), nil
+}
+
+// IssueLogoUploadToken 签发申请页匿名上传 Logo 的凭证（限时、仅可使用一次）
+func (s *SponsorService) IssueLogoUploadToken() string {
+	buf := make([]byte, 16)
+	_, _ = crand.Read(buf)
+	tokenID := hex.EncodeToString(buf)
+	expires := strconv.FormatInt(time.Now().Add(sponsorLogoTokenTTL).Unix(), 10)
+	return tokenID + "." + expires + "." + signLogoUploadToken(tokenID, expires)
+}
+
+// verifyLogoUploadToken 校验上传凭证的签名与有效期，返回凭证标识
+func verifyLogoUploadToken(token string) (string, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return "", errors.New("无效的上传凭证")
+	}
+	tokenID, expires, signature := parts[0], parts[1], parts[2]
+	if !hmac.Equal([]byte(signLogoUploadToken(tokenID, expires)), []byte(signature)) {
+		return "", errors.New("无效的上传凭证")
+	}
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return "", errors.New("无效的上传凭证")
+	}
+	if time.Now().Unix() > exp {
+		return "", errors.New("上传凭证已过期，请刷新页面后重试")
+	}
+	return tokenID, nil
+}
+
+// signLogoUploadToken 计算上传凭证签名（HMAC-SHA256，密钥与文件下载签名相同）
+func signLogoUploadToken(tokenID, expires string) string {
+	mac := hmac.New(sha256.New, []byte(config.AppConfig.Storage.SigningSecret))
+	mac.Write([]byte("sponsor_logo\n" + tokenID + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// UploadApplicationLogo 申请页上传赞助商 Logo（无需登录，需持有效上传凭证），未被申请引用的 Logo 由后台定期清理
+func (s *SponsorService) UploadApplicationLogo(token string, header *multipart.FileHeader) (string, error) {
+	tokenID, err := verifyLogoUploadToken(token)
+	if err != nil {
+		return "", err
+	}
+	// 上传前先检查一次，避免无效上传；凭证是否已使用最终由 token_id 唯一索引判定
+	var used int64
+	if err := database.DB.Model(&models.SponsorLogoUpload{}).Where("token_id = ?", tokenID).Count(&used).Error; err != nil {
+		return "", err
+	}
+	if used > 0 {
+		return "", errors.New("上传凭证已使用，请刷新页面后重试")
+	}
+
+	uploaded, err := storage.Upload(storage.KindImage, sponsorLogoPrefix, header)
+	if err != nil {
+		return "", err
+	}
+	storage.DeleteFiles(uploaded.ThumbnailKey)
+	// 凭证标识唯一，并发重复使用时仅一次写入成功
+	if err := database.DB.Create(&models.SponsorLogoUpload{FileKey: uploaded.Key, TokenID: tokenID}).Error; err != nil {
+		storage.DeleteFiles(uploaded.Key)
+		if strings.Contains(err.Error(), "Duplicate entry") {
+			return "", errors.New("上传凭证已使用，请刷新页面后重试")
+		}
+		return "", err
+	}
+	return sponsorLogoURL(uploaded.Key), nil
+}
+
+// StoreLogo 规范化申请中提交的 Logo：base64 data URL 存入文件存储并返回公开地址，其余地址原样返回
+func (s *SponsorService) StoreLogo(logo string) (string, error) {
+	logo = strings.TrimSpace(logo)
+	if !strings.HasPrefix(logo, "data:") {
+		if len(logo) > 500 {
+			return "", errors.New("Logo 地址过长")
+		}
+		return logo, nil
+	}
+
+	comma := strings.Index(logo, ",")
+	if comma < 0 || !strings.Contains(logo[:comma], ";base64") {
+		return "", errors.New("无效的 Logo 图片")
+	}
+	data, err := base64.StdEncoding.DecodeString(logo[comma+1:])
+	if err != nil {
+		return "", errors.New("无效的 Logo 图片")
+	}
+	uploaded, err := storage.UploadBytes(storage.KindImage, sponsorLogoPrefix, "logo", data)
+	if err != nil {
+		return "", err
+	}
+	return sponsorLogoURL(uploaded.Key), nil
+}
+
+// MigrateSponsorLogos 将历史以 base64 存在数据库中的赞助商 Logo 迁移到文件存储（启动时执行，可重复执行）
+func (s *SponsorService) MigrateSponsorLogos() error {
+	// 赞助商记录的 Logo 复制自申请，相同内容只上传一次
+	migrated := make(map[[32]byte]string)
+	migrate := func(model interface{}, table string) error {
+		var ids []uint64
+		if err := database.DB.Unscoped().Model(model).Where("logo_url LIKE ?", "data:%").Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		for _, id := range ids {
+			var logo string
+			if err := database.DB.Table(table).Where("id = ?", id).Pluck("logo_url", &logo).Error; err != nil {
+				return err
+			}
+			digest := sha256.Sum256([]byte(logo))
+			logoURL, ok := migrated[digest]
+			if !ok {
+				var err error
+				if logoURL, err = s.StoreLogo(logo); err != nil {
+					log.Printf("迁移 %s(id=%d) 的 Logo 失败: %v", table, id, err)
+					continue
+				}
+				migrated[digest] = logoURL
+			}
+			if err := database.DB.Table(table).Where("id = ?", id).Update("logo_url", logoURL).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := migrate(&models.SponsorApplication{}, "sponsor_applications"); err != nil {
+		return err
+	}
+	return migrate(&models.Sponsor{}, "sponsors")
+}
+
+// sponsorLogoURL Logo 的公开地址（由 FileController.GetLogo 提供）
+func sponsorLogoURL(key string) string {
+	return sponsorLogoPath + strings.TrimPrefix(key, sponsorLogoPrefix+"/")
+}
+
+// StartLogoCleanupWorker 启动未引用 Logo 的定期清理
+func StartLogoCleanupWorker() {
+	go func() {
+		ticker := time.NewTicker(sponsorLogoCleanupInterval)
+		defer ticker.Stop()
+		for {
+			cleanupUnclaimedLogos()
+			<-ticker.C
+		}
+	}()
+}
+
+// cleanupUnclaimedLogos 删除申请页上传后超时仍未被任何申请或赞助商引用的 Logo
+func cleanupUnclaimedLogos() {
+	var uploads []models.SponsorLogoUpload
+	if err := database.DB.Where("created_at < ?", time.Now().Add(-sponsorLogoUnclaimedTTL)).
+		Limit(500).Find(&uploads).Error; err != nil {
+		log.Printf("查询未引用 Logo 失败: %v", err)
+		return
+	}
+	for _, upload := range uploads {
+		logoURL := sponsorLogoURL(upload.FileKey)
+		var refs int64
+		if err := database.DB.Unscoped().Model(&models.SponsorApplication{}).Where("logo_url = ?", logoURL).Count(&refs).Error; err != nil {
+			log.Printf("检查 Logo 引用失败: %v", err)
+			continue
+		}
+		if refs == 0 {
+			if err := database.DB.Unscoped().Model(&models.Sponsor{}).Where("logo_url = ?", logoURL).Count(&refs).Error; err != nil {
+				log.Printf("检查 Logo 引用失败: %v", err)
+				continue
+			}
+		}
+		if refs == 0 {
+			if err := storage.Default.Delete(upload.FileKey); err != nil {
+				log.Printf("删除未引用 Logo %s 失败: %v", upload.FileKey, err)
+				continue
+			}
+		}
+		if err := database.DB.Delete(&upload).Error; err != nil {
+			log.Printf("删除 Logo 上传记录失败: %v", err)
+		}
+	}
+}
+
+// generateRandomPassword 生成随机密码（使用加密随机数）
 func generateRandomPassword(length int) string {
 	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	rand.Seed(time.Now().UnixNano())
+	buf := make([]byte, length)
+	_, _ = crand.Read(buf)
 	password := make([]byte, length)
 	for i := range password {
-		password[i] = charset[rand.Intn(len(charset))]
+		password[i] = charset[int(buf[i])%len(charset)]
 	}
 	return string(password)
 }
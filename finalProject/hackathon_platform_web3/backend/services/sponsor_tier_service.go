@@ -0,0 +1,408 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"hackathon-backend/database"
+	"hackathon-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// SponsorTierService 赞助商等级与长期赞助期限管理
+type SponsorTierService struct{}
+
+// 赞助商 Logo 的展示位置
+const (
+	PlacementHero          = "hero"           // 活动页顶部冠名位
+	PlacementHackathonPage = "hackathon_page" // 活动页赞助商区域
+	PlacementPoster        = "poster"         // 活动海报
+	PlacementFooter        = "footer"         // 页脚赞助商墙
+)
+
+const (
+	sponsorExpiryPollInterval = 10 * time.Minute
+	untieredSponsorLogoSize   = "sm"
+)
+
+// sponsorPlacements 可配置的展示位置
+var sponsorPlacements = map[string]bool{
+	PlacementHero:          true,
+	PlacementHackathonPage: true,
+	PlacementPoster:        true,
+	PlacementFooter:        true,
+}
+
+// untieredSponsorPlacements 未达到任何等级的赞助商的展示位置
+var untieredSponsorPlacements = []string{PlacementHackathonPage, PlacementFooter}
+
+// defaultSponsorTiers 首次启动时创建的默认等级
+var defaultSponsorTiers = []models.SponsorTier{
+	{Code: "title", Name: "冠名赞助", MinAmountSol: 100, Priority: 1, LogoSize: "xl", Placements: "hero,hackathon_page,poster,footer"},
+	{Code: "gold", Name: "金牌赞助", MinAmountSol: 50, Priority: 2, LogoSize: "lg", Placements: "hackathon_page,poster,footer"},
+	{Code: "silver", Name: "银牌赞助", MinAmountSol: 10, Priority: 3, LogoSize: "md", Placements: "hackathon_page,footer"},
+}
+
+// SponsorTierInput 创建或修改等级的参数
+type SponsorTierInput struct {
+	Code         string   `json:"code"`
+	Name         string   `json:"name"`
+	MinAmountSol float64  `json:"min_amount_sol"`
+	Priority     int      `json:"priority"`
+	LogoSize     string   `json:"logo_size"`
+	Placements   []string `json:"placements"`
+}
+
+// validateSponsorTierInput 校验等级参数，返回逗号分隔的展示位置
+func validateSponsorTierInput(input *SponsorTierInput) (string, error) {
+	input.Code = strings.ToLower(strings.TrimSpace(input.Code))
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Code == "" || len(input.Code) > 20 {
+		return "", errors.New("等级标识不能为空且不能超过20个字符")
+	}
+	if input.Name == "" || len([]rune(input.Name)) > 50 {
+		return "", errors.New("等级名称不能为空且不能超过50个字符")
+	}
+	if input.MinAmountSol < 0 {
+		return "", errors.New("等级门槛金额不能为负数")
+	}
+	switch input.LogoSize {
+	case "":
+		input.LogoSize = "md"
+	case "xl", "lg", "md", "sm":
+	default:
+		return "", errors.New("无效的 Logo 尺寸")
+	}
+	seen := make(map[string]bool, len(input.Placements))
+	placements := make([]string, 0, len(input.Placements))
+	for _, placement := range input.Placements {
+		placement = strings.TrimSpace(placement)
+		if !sponsorPlacements[placement] {
+			return "", errors.New("无效的展示位置: " + placement)
+		}
+		if !seen[placement] {
+			seen[placement] = true
+			placements = append(placements, placement)
+		}
+	}
+	if len(placements) == 0 {
+		return "", errors.New("请至少选择一个展示位置")
+	}
+	return strings.Join(placements, ","), nil
+}
+
+// fillSponsorPlacements 按等级填充赞助商 Logo 的尺寸与展示位置
+func fillSponsorPlacements(sponsors []models.Sponsor) {
+	for i := range sponsors {
+		if sponsors[i].Tier != nil {
+			sponsors[i].LogoSize = sponsors[i].Tier.LogoSize
+			sponsors[i].Placements = strings.Split(sponsors[i].Tier.Placements, ",")
+		} else {
+			sponsors[i].LogoSize = untieredSponsorLogoSize
+			sponsors[i].Placements = untieredSponsorPlacements
+		}
+	}
+}
+
+// sponsorPlacementQuery 按等级排序（冠名在前，同等级按累计金额），placement 非空时只保留在该位置展示的赞助商
+func sponsorPlacementQuery(query *gorm.DB, placement string) *gorm.DB {
+	query = query.Joins("LEFT JOIN sponsor_tiers ON sponsor_tiers.id = sponsors.tier_id")
+	if placement != "" {
+		untiered := false
+		for _, p := range untieredSponsorPlacements {
+			if p == placement {
+				untiered = true
+			}
+		}
+		if untiered {
+			query = query.Where("(sponsor_tiers.id IS NULL OR FIND_IN_SET(?, sponsor_tiers.placements) > 0)", placement)
+		} else {
+			query = query.Where("FIND_IN_SET(?, sponsor_tiers.placements) > 0", placement)
+		}
+	}
+	return query.Order("sponsor_tiers.id IS NULL ASC, sponsor_tiers.priority ASC, sponsors.amount_sol DESC, sponsors.created_at ASC")
+}
+
+// matchSponsorTier 按累计赞助金额匹配门槛最高的等级（门槛为 0 的等级仅由 Admin 指定）
+func matchSponsorTier(tx *gorm.DB, amountSol float64) (*uint64, error) {
+	var tier models.SponsorTier
+	err := tx.Where("min_amount_sol > 0 AND min_amount_sol <= ?", amountSol).
+		Order("min_amount_sol DESC, priority ASC").First(&tier).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tier.ID, nil
+}
+
+// refreshSponsorTier 重新计算赞助商的等级（Admin 指定的等级保持不变）
+func refreshSponsorTier(tx *gorm.DB, sponsorID uint64) error {
+	var sponsor models.Sponsor
+	if err := tx.Where("id = ?", sponsorID).First(&sponsor).Error; err != nil {
+		return err
+	}
+	if sponsor.TierManual {
+		return nil
+	}
+	tierID, err := matchSponsorTier(tx, sponsor.AmountSol)
+	if err != nil {
+		return err
+	}
+	return tx.Model(&sponsor).Update("tier_id", tierID).Error
+}
+
+// refreshAllSponsorTiers 等级门槛变化后重新计算全部赞助商的等级
+func refreshAllSponsorTiers(tx *gorm.DB) error {
+	var sponsorIDs []uint64
+	if err := tx.Model(&models.Sponsor{}).Where("tier_manual = ?", false).Pluck("id", &sponsorIDs).Error; err != nil {
+		return err
+	}
+	for _, sponsorID := range sponsorIDs {
+		if err := refreshSponsorTier(tx, sponsorID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addSponsorAmount 累加赞助商的赞助金额并重新计算等级
+func addSponsorAmount(tx *gorm.DB, sponsorID uint64, amountSol float64) error {
+	if amountSol > 0 {
+		if err := tx.Model(&models.Sponsor{}).Where("id = ?", sponsorID).
+			Update("amount_sol", gorm.Expr("amount_sol + ?", amountSol)).Error; err != nil {
+			return err
+		}
+	}
+	return refreshSponsorTier(tx, sponsorID)
+}
+
+// InitSponsorTiers 首次启动（尚无任何等级）时创建默认等级并为已有赞助商匹配等级；
+// 已有赞助商的累计金额在数据库迁移新增该列时一次性补齐（见 database.AutoMigrate）
+func (s *SponsorTierService) InitSponsorTiers() error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.SponsorTier{}).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+		tiers := append([]models.SponsorTier(nil), defaultSponsorTiers...)
+		if err := tx.Create(&tiers).Error; err != nil {
+			return err
+		}
+		return refreshAllSponsorTiers(tx)
+	})
+}
+
+// GetTiers 获取全部等级
+func (s *SponsorTierService) GetTiers() ([]models.SponsorTier, error) {
+	var tiers []models.SponsorTier
+	if err := database.DB.Order("priority ASC, min_amount_sol DESC").Find(&tiers).Error; err != nil {
+		return nil, err
+	}
+	return tiers, nil
+}
+
+// CreateTier 创建等级
+func (s *SponsorTierService) CreateTier(input SponsorTierInput) (*models.SponsorTier, error) {
+	placements, err := validateSponsorTierInput(&input)
+	if err != nil {
+		return nil, err
+	}
+	tier := models.SponsorTier{
+		Code:         input.Code,
+		Name:         input.Name,
+		MinAmountSol: input.MinAmountSol,
+		Priority:     input.Priority,
+		LogoSize:     input.LogoSize,
+		Placements:   placements,
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.SponsorTier{}).Where("code = ?", input.Code).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return errors.New("等级标识已存在")
+		}
+		if err := tx.Create(&tier).Error; err != nil {
+			return err
+		}
+		return refreshAllSponsorTiers(tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &tier, nil
+}
+
+// UpdateTier 修改等级，门槛变化后重新计算赞助商等级
+func (s *SponsorTierService) UpdateTier(id uint64, input SponsorTierInput) (*models.SponsorTier, error) {
+	placements, err := validateSponsorTierInput(&input)
+	if err != nil {
+		return nil, err
+	}
+	var tier models.SponsorTier
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", id).First(&tier).Error; err != nil {
+			return errors.New("等级不存在")
+		}
+		var count int64
+		if err := tx.Model(&models.SponsorTier{}).Where("code = ? AND id <> ?", input.Code, id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return errors.New("等级标识已存在")
+		}
+		if err := tx.Model(&tier).Updates(map[string]interface{}{
+			"code":           input.Code,
+			"name":           input.Name,
+			"min_amount_sol": input.MinAmountSol,
+			"priority":       input.Priority,
+			"logo_size":      input.LogoSize,
+			"placements":     placements,
+		}).Error; err != nil {
+			return err
+		}
+		return refreshAllSponsorTiers(tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &tier, nil
+}
+
+// DeleteTier 删除等级，原属该等级的赞助商（含 Admin 指定的）改为按金额自动匹配
+func (s *SponsorTierService) DeleteTier(id uint64) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var tier models.SponsorTier
+		if err := tx.Where("id = ?", id).First(&tier).Error; err != nil {
+			return errors.New("等级不存在")
+		}
+		if err := tx.Model(&models.Sponsor{}).Where("tier_id = ?", id).Updates(map[string]interface{}{
+			"tier_id":     nil,
+			"tier_manual": false,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&tier).Error; err != nil {
+			return err
+		}
+		return refreshAllSponsorTiers(tx)
+	})
+}
+
+// GetSponsors 获取赞助商列表（Admin），按等级排序
+func (s *SponsorTierService) GetSponsors(page, pageSize int, sponsorType, status string) ([]models.Sponsor, int64, error) {
+	query := database.DB.Model(&models.Sponsor{}).Where("sponsors.deleted_at IS NULL")
+	if sponsorType != "" {
+		query = query.Where("sponsors.sponsor_type = ?", sponsorType)
+	}
+	if status != "" {
+		query = query.Where("sponsors.status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var sponsors []models.Sponsor
+	if err := sponsorPlacementQuery(query, "").
+		Preload("User").Preload("Tier").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&sponsors).Error; err != nil {
+		return nil, 0, err
+	}
+	fillSponsorPlacements(sponsors)
+	return sponsors, total, nil
+}
+
+// AssignTier Admin 指定赞助商等级，tierID 为空表示恢复按赞助金额自动匹配
+func (s *SponsorTierService) AssignTier(sponsorID uint64, tierID *uint64) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var sponsor models.Sponsor
+		if err := tx.Where("id = ?", sponsorID).First(&sponsor).Error; err != nil {
+			return errors.New("赞助商不存在")
+		}
+		if tierID == nil {
+			if err := tx.Model(&sponsor).Update("tier_manual", false).Error; err != nil {
+				return err
+			}
+			return refreshSponsorTier(tx, sponsorID)
+		}
+		var tier models.SponsorTier
+		if err := tx.Where("id = ?", *tierID).First(&tier).Error; err != nil {
+			return errors.New("等级不存在")
+		}
+		return tx.Model(&sponsor).Updates(map[string]interface{}{
+			"tier_id":     tier.ID,
+			"tier_manual": true,
+		}).Error
+	})
+}
+
+// SetSponsorTerm 设置长期赞助的到期时间，为空表示不限期；为已到期的赞助商续期时恢复展示
+func (s *SponsorTierService) SetSponsorTerm(sponsorID uint64, expiresAt *time.Time) error {
+	var sponsor models.Sponsor
+	if err := database.DB.Where("id = ?", sponsorID).First(&sponsor).Error; err != nil {
+		return errors.New("赞助商不存在")
+	}
+	if sponsor.SponsorType != "long_term" {
+		return errors.New("只有长期赞助商可以设置赞助期限")
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return errors.New("到期时间须晚于当前时间")
+	}
+
+	updateData := map[string]interface{}{"expires_at": expiresAt}
+	if sponsor.Status == "expired" {
+		updateData["status"] = "active"
+	}
+	return database.DB.Model(&sponsor).Updates(updateData).Error
+}
+
+// StartSponsorExpiryWorker 启动后台任务，定时将到期的长期赞助商标记为已到期并通知赞助商
+func StartSponsorExpiryWorker() {
+	go func() {
+		ticker := time.NewTicker(sponsorExpiryPollInterval)
+		defer ticker.Stop()
+		for {
+			expireSponsors()
+			<-ticker.C
+		}
+	}()
+}
+
+// expireSponsors 标记到期的长期赞助商
+func expireSponsors() {
+	var sponsors []models.Sponsor
+	if err := database.DB.Where("sponsor_type = ? AND status = ? AND expires_at <= ?", "long_term", "active", time.Now()).
+		Find(&sponsors).Error; err != nil {
+		log.Printf("查询到期赞助商失败: %v", err)
+		return
+	}
+	for _, sponsor := range sponsors {
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			// 仅更新仍处于有效期内的记录，避免与续期操作并发时误标记
+			result := tx.Model(&models.Sponsor{}).
+				Where("id = ? AND status = ? AND expires_at <= ?", sponsor.ID, "active", time.Now()).
+				Update("status", "expired")
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			return notifyUsers(tx, []uint64{sponsor.UserID}, 0, NotifySponsorExpired, map[string]interface{}{
+				"ExpiredAt": sponsor.ExpiresAt.Format("2006-01-02 15:04"),
+			})
+		})
+		if err != nil {
+			log.Printf("赞助商 %d 到期处理失败: %v", sponsor.ID, err)
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package services
+
+import (
+	"errors"
+
+	"hackathon-backend/database"
+	"hackathon-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// submissionContentFields 作品修改记录中参与比较与恢复的字段（列名与显示名称）
+var submissionContentFields = []struct {
+	column string
+	label  string
+	value  func(h *models.SubmissionHistory) interface{}
+}{
+	{"name", "作品名称", func(h *models.SubmissionHistory) interface{} { return h.Name }},
+	{"description", "作品描述", func(h *models.SubmissionHistory) interface{} { return h.Description }},
+	{"link", "作品链接", func(h *models.SubmissionHistory) interface{} { return h.Link }},
+	{"tech_stack", "技术栈", func(h *models.SubmissionHistory) interface{} { return h.TechStack }},
+	{"demo_url", "演示地址", func(h *models.SubmissionHistory) interface{} { return h.DemoURL }},
+	{"video_url", "视频地址", func(h *models.SubmissionHistory) interface{} { return h.VideoURL }},
+	{"repo_url", "仓库地址", func(h *models.SubmissionHistory) interface{} { return h.RepoURL }},
+	{"draft", "草稿", func(h *models.SubmissionHistory) interface{} { return h.Draft }},
+}
+
+// submissionDiffMaxLines 描述逐行比较的最大行数，超过时只给出字段级差异
+const submissionDiffMaxLines = 2000
+
+// historyFromSubmission 由作品当前内容生成修改记录
+func historyFromSubmission(submission *models.Submission) models.SubmissionHistory {
+	return models.SubmissionHistory{
+		SubmissionID: submission.ID,
+		Name:         submission.Name,
+		Description:  submission.Description,
+		Link:         submission.Link,
+		TechStack:    submission.TechStack,
+		DemoURL:      submission.DemoURL,
+		VideoURL:     submission.VideoURL,
+		RepoURL:      submission.RepoURL,
+		Draft:        submission.Draft,
+	}
+}
+
+// ensureSubmissionBaseline 作品尚无版本记录（早期创建的作品）时，先将修改前的内容记为第 1 版。
+// 无法确定当时的修改人，作者留空，时间取作品最后修改时间
+func ensureSubmissionBaseline(tx *gorm.DB, existing *models.Submission) error {
+	var count int64
+	if err := tx.Model(&models.SubmissionHistory{}).Where("submission_id = ? AND revision > 0", existing.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	revision := 1
+	history := historyFromSubmission(existing)
+	history.Revision = &revision
+	history.Action = "create"
+	history.CreatedAt = existing.UpdatedAt
+	return tx.Create(&history).Error
+}
+
+// recordSubmissionHistory 记录作品写入后的完整内容为新版本
+func recordSubmissionHistory(tx *gorm.DB, submissionID, participantID uint64, action string, restoredFrom *uint64) error {
+	var submission models.Submission
+	if err := tx.Where("id = ?", submissionID).First(&submission).Error; err != nil {
+		return err
+	}
+
+	var revision int
+	if err := tx.Model(&models.SubmissionHistory{}).Where("submission_id = ?", submissionID).
+		Select("COALESCE(MAX(revision), 0)").Scan(&revision).Error; err != nil {
+		return err
+	}
+
+	revision++
+	history := historyFromSubmission(&submission)
+	history.ParticipantID = &participantID
+	history.Revision = &revision
+	history.Action = action
+	history.RestoredFrom = restoredFrom
+	return tx.Create(&history).Error
+}
+
+// getSubmissionRevision 获取作品的某条修改记录
+func getSubmissionRevision(submissionID, historyID uint64) (*models.SubmissionHistory, error) {
+	var history models.SubmissionHistory
+	if err := database.DB.Preload("Participant").
+		Where("id = ? AND submission_id = ?", historyID, submissionID).First(&history).Error; err != nil {
+		return nil, errors.New("修改记录不存在")
+	}
+	return &history, nil
+}
+
+// revisionInfo 版本的作者与时间
+func revisionInfo(history *models.SubmissionHistory) map[string]interface{} {
+	return map[string]interface{}{
+		"id":               history.ID,
+		"revision":         history.Revision,
+		"action":           history.Action,
+		"participant_id":   history.ParticipantID,
+		"participant_name": history.Participant.Nickname,
+		"created_at":       history.CreatedAt,
+	}
+}
+
+// GetSubmissionDiff 比较作品的两个版本，返回变化的字段；描述额外给出逐行差异
+func (s *SubmissionService) GetSubmissionDiff(submissionID, fromID, toID uint64) (map[string]interface{}, error) {
+	from, err := getSubmissionRevision(submissionID, fromID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := getSubmissionRevision(submissionID, toID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]map[string]interface{}, 0)
+	for _, field := range submissionContentFields {
+		oldValue, newValue := field.value(from), field.value(to)
+		if oldValue == newValue {
+			continue
+		}
+		change := map[string]interface{}{
+			"field": field.column,
+			"label": field.label,
+			"old":   oldValue,
+			"new":   newValue,
+		}
+		if field.column == "description" {
+			if lines := diffLines(from.Description, to.Description); lines != nil {
+				change["lines"] = lines
+			}
+		}
+		changes = append(changes, change)
+	}
+
+	return map[string]interface{}{
+		"from":    revisionInfo(from),
+		"to":      revisionInfo(to),
+		"changes": changes,
+	}, nil
+}
+
+// RestoreSubmissionRevision 将作品内容恢复为某个历史版本（提交阶段内），恢复本身记为新版本。
+// 作品的草稿/已提交状态保持不变，避免恢复旧草稿时撤回已提交的作品
+func (s *SubmissionService) RestoreSubmissionRevision(submissionID, historyID, teamID, participantID uint64) error {
+	var existing models.Submission
+	if err := database.DB.Where("id = ? AND team_id = ?", submissionID, teamID).First(&existing).Error; err != nil {
+		return errors.New("作品不存在")
+	}
+
+	late, err := s.checkSubmissionEditable(existing.HackathonID, existing.TeamID)
+	if err != nil {
+		return err
+	}
+
+	history, err := getSubmissionRevision(submissionID, historyID)
+	if err != nil {
+		return err
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := ensureSubmissionBaseline(tx, &existing); err != nil {
+			return err
+		}
+
+		updates := make(map[string]interface{}, len(submissionContentFields))
+		for _, field := range submissionContentFields {
+			if field.column != "draft" {
+				updates[field.column] = field.value(history)
+			}
+		}
+		if err := tx.Model(&existing).Updates(updates).Error; err != nil {
+			return err
+		}
+		if late {
+			if err := markSubmissionLate(tx, existing.ID); err != nil {
+				return err
+			}
+		}
+		return recordSubmissionHistory(tx, submissionID, participantID, "restore", &history.ID)
+	})
+}
+
+// diffLines 逐行比较文本（最长公共子序列，Hirschberg 算法，内存占用与行数成线性），返回 equal、add、remove 操作序列；
+// 行数过多时返回 nil
+func diffLines(oldText, newText string) []map[string]string {
+	oldLines, newLines := splitLines(oldText), splitLines(newText)
+	if len(oldLines) > submissionDiffMaxLines || len(newLines) > submissionDiffMaxLines {
+		return nil
+	}
+
+	ops := make([]map[string]string, 0, len(oldLines)+len(newLines))
+	emit := func(op, text string) {
+		ops = append(ops, map[string]string{"op": op, "text": text})
+	}
+
+	// 相同的首尾行无需参与比较
+	prefix := 0
+	for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix] == newLines[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldLines)-prefix && suffix < len(newLines)-prefix &&
+		oldLines[len(oldLines)-1-suffix] == newLines[len(newLines)-1-suffix] {
+		suffix++
+	}
+
+	for _, line := range oldLines[:prefix] {
+		emit("equal", line)
+	}
+	diffLineRange(oldLines[prefix:len(oldLines)-suffix], newLines[prefix:len(newLines)-suffix], emit)
+	for _, line := range oldLines[len(oldLines)-suffix:] {
+		emit("equal", line)
+	}
+	return ops
+}
+
+// diffLineRange 按 Hirschberg 算法递归比较：将旧文本对半切分，找到新文本中使两侧公共子序列之和最大的切分点
+func diffLineRange(oldLines, newLines []string, emit func(op, text string)) {
+	switch {
+	case len(oldLines) == 0:
+		for _, line := range newLines {
+			emit("add", line)
+		}
+		return
+	case len(newLines) == 0:
+		for _, line := range oldLines {
+			emit("remove", line)
+		}
+		return
+	case len(oldLines) == 1:
+		for k, line := range newLines {
+			if line == oldLines[0] {
+				for _, added := range newLines[:k] {
+					emit("add", added)
+				}
+				emit("equal", line)
+				for _, added := range newLines[k+1:] {
+					emit("add", added)
+				}
+				return
+			}
+		}
+		emit("remove", oldLines[0])
+		for _, line := range newLines {
+			emit("add", line)
+		}
+		return
+	}
+
+	mid := len(oldLines) / 2
+	head := lcsLengths(oldLines[:mid], newLines, false)
+	tail := lcsLengths(oldLines[mid:], newLines, true)
+	split, best := 0, -1
+	for k := 0; k <= len(newLines); k++ {
+		if total := head[k] + tail[len(newLines)-k]; total > best {
+			split, best = k, total
+		}
+	}
+	diffLineRange(oldLines[:mid], newLines[:split], emit)
+	diffLineRange(oldLines[mid:], newLines[split:], emit)
+}
+
+// lcsLengths 计算 a 与 b 各前缀的最长公共子序列长度（reverse 时为各后缀），
+// 结果第 j 项对应 b 的前（后）j 行，只保留两行状态
+func lcsLengths(a, b []string, reverse bool) []int {
+	at := func(lines []string, i int) string {
+		if reverse {
+			return lines[len(lines)-1-i]
+		}
+		return lines[i]
+	}
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for i := range a {
+		line := at(a, i)
+		for j := range b {
+			switch {
+			case line == at(b, j):
+				curr[j+1] = prev[j] + 1
+			case prev[j+1] >= curr[j]:
+				curr[j+1] = prev[j+1]
+			default:
+				curr[j+1] = curr[j]
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev
+}
+
+// splitLines 按行拆分文本（空文本没有行）
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := make([]string, 0)
+	start := 0
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			lines = append(lines, text[start:i])
+			start = i + 1
+		}
+	}
+	if start < len(text) {
+		lines = append(lines, text[start:])
+	}
+	return lines
+}
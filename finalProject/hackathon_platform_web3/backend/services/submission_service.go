@@ -2,15 +2,33 @@ package services
 
 import (
 	"errors"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"hackathon-backend/database"
 	"hackathon-backend/models"
+	"hackathon-backend/utils"
+
+	"gorm.io/gorm"
 )
 
 type SubmissionService struct{}
 
-// CreateSubmission 提交作品
-func (s *SubmissionService) CreateSubmission(hackathonID, teamID uint64, submission *models.Submission) error {
+// CreateSubmission 提交作品（已有作品时更新），participantID 为提交的队长
+func (s *SubmissionService) CreateSubmission(hackathonID, teamID, participantID uint64, submission *models.Submission, supplied map[string]bool) error {
+	// 附件通过附件接口单独上传，忽略请求中携带的附件
+	submission.Attachments = nil
+	// 成员贡献说明单独维护，未传时保持不变
+	contributions := submission.Contributions
+	submission.Contributions = nil
+	// 迟交标记由系统维护
+	submission.Late, submission.LateAt = false, nil
+	if err := normalizeSubmissionContent(submission); err != nil {
+		return err
+	}
+
 	// 检查活动状态
 	var hackathon models.Hackathon
 	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
@@ -21,14 +39,10 @@ func (s *SubmissionService) CreateSubmission(hackathonID, teamID uint64, submiss
 		return errors.New("当前不在提交阶段")
 	}
 
-	// 检查阶段时间
-	hackathonService := &HackathonService{}
-	inTime, err := hackathonService.CheckStageTime(hackathonID, "submission")
+	// 检查提交时间（含队伍延期与迟交宽限期）
+	late, err := checkSubmissionWindow(&hackathon, teamID)
 	if err != nil {
-		return errors.New("提交阶段时间未设置")
-	}
-	if !inTime {
-		return errors.New("不在提交时间范围内")
+		return err
 	}
 
 	// 检查队伍是否存在
@@ -37,23 +51,213 @@ func (s *SubmissionService) CreateSubmission(hackathonID, teamID uint64, submiss
 		return errors.New("队伍不存在")
 	}
 
+	trackService := &TrackService{}
+
 	// 检查是否已有提交
 	var existing models.Submission
 	if err := database.DB.Where("hackathon_id = ? AND team_id = ?", hackathonID, teamID).First(&existing).Error; err == nil {
+		if err := s.checkSubmissionTracks(hackathonID, existing.ID, submission); err != nil {
+			return err
+		}
 		// 更新现有提交
 		submission.ID = existing.ID
-		return database.DB.Model(&existing).Updates(submission).Error
+		return database.DB.Transaction(func(tx *gorm.DB) error {
+			if err := ensureSubmissionBaseline(tx, &existing); err != nil {
+				return err
+			}
+			if err := tx.Model(&existing).Updates(submissionUpdates(submission, supplied)).Error; err != nil {
+				return err
+			}
+			if late {
+				if err := markSubmissionLate(tx, existing.ID); err != nil {
+					return err
+				}
+			}
+			if err := recordSubmissionHistory(tx, existing.ID, participantID, "update", nil); err != nil {
+				return err
+			}
+			if contributions != nil {
+				if err := setSubmissionContributions(tx, existing.ID, teamID, contributions); err != nil {
+					return err
+				}
+			}
+			if err := emitSubmissionSaved(tx, existing.ID, existing.Draft == 1); err != nil {
+				return err
+			}
+			if submission.TrackIDs != nil {
+				return trackService.setSubmissionTracks(tx, hackathonID, existing.ID, submission.TrackIDs)
+			}
+			return nil
+		})
+	}
+
+	if err := s.checkSubmissionTracks(hackathonID, 0, submission); err != nil {
+		return err
 	}
 
 	// 创建新提交
 	submission.HackathonID = hackathonID
 	submission.TeamID = teamID
+	if late {
+		now := time.Now()
+		submission.Late, submission.LateAt = true, &now
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(submission).Error; err != nil {
+			return err
+		}
+		if err := recordSubmissionHistory(tx, submission.ID, participantID, "create", nil); err != nil {
+			return err
+		}
+		if contributions != nil {
+			if err := setSubmissionContributions(tx, submission.ID, teamID, contributions); err != nil {
+				return err
+			}
+		}
+		if err := emitSubmissionSaved(tx, submission.ID, true); err != nil {
+			return err
+		}
+		return trackService.setSubmissionTracks(tx, hackathonID, submission.ID, submission.TrackIDs)
+	})
+}
+
+// submissionOptionalFields 作品的选填字段（技术栈与演示、视频、仓库地址）
+var submissionOptionalFields = map[string]func(*models.Submission) string{
+	"tech_stack": func(s *models.Submission) string { return s.TechStack },
+	"demo_url":   func(s *models.Submission) string { return s.DemoURL },
+	"video_url":  func(s *models.Submission) string { return s.VideoURL },
+	"repo_url":   func(s *models.Submission) string { return s.RepoURL },
+}
 
-	return database.DB.Create(submission).Error
+// submissionUpdates 修改作品时写入的字段（supplied 为请求中传入的 JSON 字段）：名称、简介、链接为必填项，未传或传空时保持不变；
+// 选填字段与草稿状态仅在请求中传入时写入，选填字段传空即清除
+func submissionUpdates(submission *models.Submission, supplied map[string]bool) map[string]interface{} {
+	updates := make(map[string]interface{})
+	for field, value := range submissionOptionalFields {
+		if supplied[field] {
+			updates[field] = value(submission)
+		}
+	}
+	if submission.Name != "" {
+		updates["name"] = submission.Name
+	}
+	if submission.Description != "" {
+		updates["description"] = submission.Description
+	}
+	if submission.Link != "" {
+		updates["link"] = submission.Link
+	}
+	if supplied["draft"] {
+		updates["draft"] = submission.Draft
+	}
+	return updates
 }
 
-// GetSubmissionList 获取作品列表
-func (s *SubmissionService) GetSubmissionList(hackathonID uint64, page, pageSize int, keyword, sort string) ([]models.Submission, int64, error) {
+// normalizeSubmissionContent 规范化技术栈标签并校验作品的各类链接
+func normalizeSubmissionContent(submission *models.Submission) error {
+	submission.TechStack = strings.Join(parseTags(submission.TechStack), ",")
+	if len(submission.TechStack) > 500 {
+		return errors.New("技术栈标签过多")
+	}
+
+	links := []struct {
+		value *string
+		name  string
+	}{
+		{&submission.DemoURL, "演示地址"},
+		{&submission.VideoURL, "视频地址"},
+		{&submission.RepoURL, "仓库地址"},
+	}
+	for _, link := range links {
+		*link.value = strings.TrimSpace(*link.value)
+		if *link.value == "" {
+			continue
+		}
+		u, err := url.Parse(*link.value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New(link.name + "必须是有效的 http(s) 链接")
+		}
+	}
+	return nil
+}
+
+// setSubmissionContributions 覆盖作品的成员贡献说明（仅限本队成员，说明为空表示删除）
+func setSubmissionContributions(tx *gorm.DB, submissionID, teamID uint64, contributions []models.SubmissionContribution) error {
+	var memberIDs []uint64
+	if err := tx.Model(&models.TeamMember{}).Where("team_id = ?", teamID).Pluck("participant_id", &memberIDs).Error; err != nil {
+		return err
+	}
+	members := make(map[uint64]bool, len(memberIDs))
+	for _, id := range memberIDs {
+		members[id] = true
+	}
+
+	rows := make([]models.SubmissionContribution, 0, len(contributions))
+	seen := make(map[uint64]bool, len(contributions))
+	for _, contribution := range contributions {
+		if !members[contribution.ParticipantID] {
+			return errors.New("贡献说明只能填写本队成员")
+		}
+		if seen[contribution.ParticipantID] {
+			return errors.New("每位成员只能填写一条贡献说明")
+		}
+		seen[contribution.ParticipantID] = true
+
+		note := strings.TrimSpace(contribution.Note)
+		if note == "" {
+			continue
+		}
+		if utf8.RuneCountInString(note) > 500 {
+			return errors.New("贡献说明不能超过500个字符")
+		}
+		rows = append(rows, models.SubmissionContribution{
+			SubmissionID:  submissionID,
+			ParticipantID: contribution.ParticipantID,
+			Note:          note,
+		})
+	}
+
+	if err := tx.Where("submission_id = ?", submissionID).Delete(&models.SubmissionContribution{}).Error; err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return tx.Create(&rows).Error
+}
+
+// checkSubmissionTracks 活动设置了赛道时，正式提交的作品至少需要选择一个赛道
+func (s *SubmissionService) checkSubmissionTracks(hackathonID, submissionID uint64, submission *models.Submission) error {
+	if submission.Draft == 1 {
+		return nil
+	}
+	trackService := &TrackService{}
+	hasTracks, err := trackService.HasTracks(hackathonID)
+	if err != nil || !hasTracks {
+		return err
+	}
+	if submission.TrackIDs != nil {
+		if len(submission.TrackIDs) == 0 {
+			return errors.New("请至少选择一个赛道")
+		}
+		return nil
+	}
+	// 未传赛道时沿用已选择的赛道
+	if submissionID > 0 {
+		trackIDs, err := trackService.GetSubmissionTrackIDs(submissionID)
+		if err != nil {
+			return err
+		}
+		if len(trackIDs) > 0 {
+			return nil
+		}
+	}
+	return errors.New("请至少选择一个赛道")
+}
+
+// GetSubmissionList 获取作品列表，trackID 大于 0 时只返回参加该赛道的作品，tag 不为空时只返回使用该技术栈的作品
+func (s *SubmissionService) GetSubmissionList(hackathonID uint64, page, pageSize int, keyword, sort string, trackID uint64, tag string) ([]models.Submission, int64, error) {
 	var submissions []models.Submission
 	var total int64
 
@@ -63,6 +267,14 @@ func (s *SubmissionService) GetSubmissionList(hackathonID uint64, page, pageSize
 		query = query.Where("name LIKE ?", "%"+keyword+"%")
 	}
 
+	if trackID > 0 {
+		query = query.Where("id IN (?)", database.DB.Model(&models.SubmissionTrack{}).Select("submission_id").Where("track_id = ?", trackID))
+	}
+
+	if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+		query = query.Where("FIND_IN_SET(?, tech_stack)", tag)
+	}
+
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
@@ -80,6 +292,43 @@ func (s *SubmissionService) GetSubmissionList(hackathonID uint64, page, pageSize
 		return nil, 0, err
 	}
 
+	trackService := &TrackService{}
+	trackService.fillSubmissionTrackIDs(submissions)
+	for i := range submissions {
+		submissions[i].DescriptionHTML = utils.RenderMarkdown(submissions[i].Description)
+	}
+
+	return submissions, total, nil
+}
+
+// SearchArchiveSubmissions 在已结束活动的作品中按技术栈搜索（如赞助商查找使用其 SDK 的项目）
+func (s *SubmissionService) SearchArchiveSubmissions(techStack, keyword string, page, pageSize int) ([]models.Submission, int64, error) {
+	var submissions []models.Submission
+	var total int64
+
+	techStack = strings.ToLower(strings.TrimSpace(techStack))
+	if techStack == "" {
+		return nil, 0, errors.New("请指定技术栈")
+	}
+
+	query := database.DB.Model(&models.Submission{}).
+		Where("draft = 0 AND FIND_IN_SET(?, tech_stack)", techStack).
+		Where("hackathon_id IN (?)", database.DB.Model(&models.Hackathon{}).Select("id").Where("deleted_at IS NULL AND status = 'results'"))
+
+	if keyword != "" {
+		query = query.Where("name LIKE ?", "%"+keyword+"%")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Preload("Hackathon").Preload("Team").
+		Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&submissions).Error; err != nil {
+		return nil, 0, err
+	}
+
 	return submissions, total, nil
 }
 
@@ -87,55 +336,98 @@ func (s *SubmissionService) GetSubmissionList(hackathonID uint64, page, pageSize
 func (s *SubmissionService) GetSubmissionByID(submissionID uint64) (*models.Submission, error) {
 	var submission models.Submission
 	if err := database.DB.Preload("Team").Preload("Team.Members").Preload("Team.Members.Participant").
+		Preload("Attachments", func(db *gorm.DB) *gorm.DB { return db.Order("kind ASC, id ASC") }).
+		Preload("Contributions", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Contributions.Participant").
 		Where("id = ?", submissionID).First(&submission).Error; err != nil {
 		return nil, err
 	}
+	fillAttachmentURLs(submission.Attachments)
+	submission.DescriptionHTML = utils.RenderMarkdown(submission.Description)
+
+	trackService := &TrackService{}
+	trackIDs, err := trackService.GetSubmissionTrackIDs(submission.ID)
+	if err != nil {
+		return nil, err
+	}
+	submission.TrackIDs = trackIDs
 
 	return &submission, nil
 }
 
 // UpdateSubmission 更新作品（提交阶段内）
-func (s *SubmissionService) UpdateSubmission(submissionID, teamID, participantID uint64, submission *models.Submission) error {
+func (s *SubmissionService) UpdateSubmission(submissionID, teamID, participantID uint64, submission *models.Submission, supplied map[string]bool) error {
+	// 附件通过附件接口单独上传，忽略请求中携带的附件
+	submission.Attachments = nil
+	// 成员贡献说明单独维护，未传时保持不变
+	contributions := submission.Contributions
+	submission.Contributions = nil
+	// 迟交标记由系统维护
+	submission.Late, submission.LateAt = false, nil
+	if err := normalizeSubmissionContent(submission); err != nil {
+		return err
+	}
+
 	// 检查作品是否存在
 	var existing models.Submission
 	if err := database.DB.Where("id = ? AND team_id = ?", submissionID, teamID).First(&existing).Error; err != nil {
 		return errors.New("作品不存在")
 	}
 
-	// 检查活动状态
-	var hackathon models.Hackathon
-	if err := database.DB.Where("id = ? AND deleted_at IS NULL", existing.HackathonID).First(&hackathon).Error; err != nil {
-		return errors.New("活动不存在")
+	late, err := s.checkSubmissionEditable(existing.HackathonID, existing.TeamID)
+	if err != nil {
+		return err
 	}
 
-	if hackathon.Status != "submission" {
-		return errors.New("提交阶段已结束，无法修改")
+	if err := s.checkSubmissionTracks(existing.HackathonID, existing.ID, submission); err != nil {
+		return err
 	}
 
-	// 检查阶段时间
-	hackathonService := &HackathonService{}
-	inTime, err := hackathonService.CheckStageTime(existing.HackathonID, "submission")
-	if err != nil {
-		return errors.New("提交阶段时间未设置")
-	}
-	if !inTime {
-		return errors.New("不在提交时间范围内")
-	}
+	// 更新作品，并将修改后的内容记为新版本
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := ensureSubmissionBaseline(tx, &existing); err != nil {
+			return err
+		}
+		if err := tx.Model(&existing).Updates(submissionUpdates(submission, supplied)).Error; err != nil {
+			return err
+		}
+		if late {
+			if err := markSubmissionLate(tx, existing.ID); err != nil {
+				return err
+			}
+		}
+		if err := recordSubmissionHistory(tx, existing.ID, participantID, "update", nil); err != nil {
+			return err
+		}
+		if contributions != nil {
+			if err := setSubmissionContributions(tx, existing.ID, teamID, contributions); err != nil {
+				return err
+			}
+		}
+		if err := emitSubmissionSaved(tx, existing.ID, existing.Draft == 1); err != nil {
+			return err
+		}
+		if submission.TrackIDs != nil {
+			trackService := &TrackService{}
+			return trackService.setSubmissionTracks(tx, existing.HackathonID, existing.ID, submission.TrackIDs)
+		}
+		return nil
+	})
+}
 
-	// 保存修改记录
-	history := models.SubmissionHistory{
-		SubmissionID:  submissionID,
-		ParticipantID: participantID,
-		Name:          existing.Name,
-		Description:   existing.Description,
-		Link:          existing.Link,
+// checkSubmissionEditable 检查队伍的作品当前是否可修改（活动处于提交阶段且在提交时间、队伍延期或迟交宽限期内），
+// late 表示此时修改属于迟交
+func (s *SubmissionService) checkSubmissionEditable(hackathonID, teamID uint64) (bool, error) {
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
+		return false, errors.New("活动不存在")
 	}
-	if err := database.DB.Create(&history).Error; err != nil {
-		return errors.New("保存修改记录失败: " + err.Error())
+
+	if hackathon.Status != "submission" {
+		return false, errors.New("提交阶段已结束，无法修改")
 	}
 
-	// 更新作品
-	return database.DB.Model(&existing).Updates(submission).Error
+	return checkSubmissionWindow(&hackathon, teamID)
 }
 
 // GetSubmissionHistory 获取作品修改记录
@@ -143,7 +435,7 @@ func (s *SubmissionService) GetSubmissionHistory(submissionID uint64) ([]models.
 	var histories []models.SubmissionHistory
 	if err := database.DB.Where("submission_id = ?", submissionID).
 		Preload("Participant").
-		Order("created_at DESC").
+		Order("created_at DESC, id DESC").
 		Find(&histories).Error; err != nil {
 		return nil, err
 	}
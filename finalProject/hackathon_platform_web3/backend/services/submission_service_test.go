@@ -0,0 +1,40 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"hackathon-backend/models"
+)
+
+func TestSubmissionUpdates(t *testing.T) {
+	submission := &models.Submission{Name: "Demo", TechStack: "go", DemoURL: "", Draft: 0}
+	tests := []struct {
+		name     string
+		supplied map[string]bool
+		want     map[string]interface{}
+	}{
+		{
+			name:     "omitted optional fields are kept",
+			supplied: map[string]bool{"name": true},
+			want:     map[string]interface{}{"name": "Demo"},
+		},
+		{
+			name:     "supplied empty optional field is cleared",
+			supplied: map[string]bool{"name": true, "tech_stack": true, "demo_url": true},
+			want:     map[string]interface{}{"name": "Demo", "tech_stack": "go", "demo_url": ""},
+		},
+		{
+			name:     "supplied draft flag is written",
+			supplied: map[string]bool{"draft": true},
+			want:     map[string]interface{}{"name": "Demo", "draft": 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := submissionUpdates(submission, tt.supplied); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("submissionUpdates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
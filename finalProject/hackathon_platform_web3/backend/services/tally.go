@@ -0,0 +1,236 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"hackathon-backend/models"
+)
+
+// 投票方式
+const (
+	VotingSchemeSimple      = "simple"
+	VotingSchemeLimited     = "limited"
+	VotingSchemeQuadratic   = "quadratic"
+	VotingSchemeRankedIRV   = "ranked_irv"
+	VotingSchemeRankedBorda = "ranked_borda"
+)
+
+// Tallier 计票接口，每种投票方式一个实现，比赛结果（GetResults）与链上计票上传共用
+type Tallier interface {
+	// Tally 根据候选作品与投票记录计算每个作品的得票，得票越高排名越靠前。
+	// 每条投票按防女巫加权系数（SybilWeight）计入，加权后四舍五入为整数
+	Tally(candidateIDs []uint64, votes []models.Vote) map[uint64]int64
+}
+
+// NewTallier 根据活动的投票方式返回对应的计票实现
+func NewTallier(scheme string) Tallier {
+	switch scheme {
+	case VotingSchemeLimited:
+		return limitedTallier{}
+	case VotingSchemeQuadratic:
+		return quadraticTallier{}
+	case VotingSchemeRankedIRV:
+		return irvTallier{}
+	case VotingSchemeRankedBorda:
+		return bordaTallier{}
+	default:
+		return simpleTallier{}
+	}
+}
+
+// IsRankedScheme 判断是否为排序投票
+func IsRankedScheme(scheme string) bool {
+	return scheme == VotingSchemeRankedIRV || scheme == VotingSchemeRankedBorda
+}
+
+// ValidateVotingPolicy 校验活动的投票方式配置
+func ValidateVotingPolicy(hackathon *models.Hackathon) error {
+	if hackathon.VoteLimit < 0 || hackathon.CreditBudget < 0 {
+		return errors.New("投票数限制和积分预算不能为负数")
+	}
+	switch hackathon.VotingScheme {
+	case "", VotingSchemeSimple, VotingSchemeRankedIRV, VotingSchemeRankedBorda:
+	case VotingSchemeLimited:
+		if hackathon.VoteLimit <= 0 {
+			return errors.New("限制票数投票需设置每人最多投票数")
+		}
+	case VotingSchemeQuadratic:
+		if hackathon.CreditBudget <= 0 {
+			return errors.New("二次方投票需设置每人投票积分预算")
+		}
+	default:
+		return errors.New("无效的投票方式")
+	}
+	return validateVoteWeighters(hackathon.VoteWeighters)
+}
+
+// newCandidateTally 初始化候选作品得票（未得票的作品为 0）
+func newCandidateTally(candidateIDs []uint64) map[uint64]float64 {
+	result := make(map[uint64]float64, len(candidateIDs))
+	for _, id := range candidateIDs {
+		result[id] = 0
+	}
+	return result
+}
+
+// roundTally 将加权得票四舍五入为整数
+func roundTally(tally map[uint64]float64) map[uint64]int64 {
+	result := make(map[uint64]int64, len(tally))
+	for id, value := range tally {
+		result[id] = int64(math.Round(value))
+	}
+	return result
+}
+
+// voteWeight 投票的防女巫加权系数，未设置时按 1 计
+func voteWeight(vote models.Vote) float64 {
+	if vote.SybilWeight <= 0 {
+		return 1
+	}
+	return vote.SybilWeight
+}
+
+// simpleTallier 每个作品一票，按投票记录数计票
+type simpleTallier struct{}
+
+func (simpleTallier) Tally(candidateIDs []uint64, votes []models.Vote) map[uint64]int64 {
+	result := newCandidateTally(candidateIDs)
+	for _, vote := range votes {
+		if _, ok := result[vote.SubmissionID]; ok {
+			result[vote.SubmissionID] += voteWeight(vote)
+		}
+	}
+	return roundTally(result)
+}
+
+// limitedTallier 限制总票数投票，票数上限在投票时校验，计票同每个作品一票
+type limitedTallier struct{}
+
+func (limitedTallier) Tally(candidateIDs []uint64, votes []models.Vote) map[uint64]int64 {
+	return simpleTallier{}.Tally(candidateIDs, votes)
+}
+
+// quadraticTallier 二次方投票，按票数累加（积分消耗在投票时校验）
+type quadraticTallier struct{}
+
+func (quadraticTallier) Tally(candidateIDs []uint64, votes []models.Vote) map[uint64]int64 {
+	result := newCandidateTally(candidateIDs)
+	for _, vote := range votes {
+		if _, ok := result[vote.SubmissionID]; ok {
+			result[vote.SubmissionID] += float64(vote.Weight) * voteWeight(vote)
+		}
+	}
+	return roundTally(result)
+}
+
+// rankedBallot 一张排序选票
+type rankedBallot struct {
+	submissionIDs []uint64 // 按名次从高到低排列
+	weight        float64  // 投票人的防女巫加权系数
+}
+
+// rankedBallots 将排序投票记录按投票人（及赛道）整理为选票，选票内按名次排序，仅保留候选作品
+func rankedBallots(candidateIDs []uint64, votes []models.Vote) []rankedBallot {
+	candidates := make(map[uint64]bool, len(candidateIDs))
+	for _, id := range candidateIDs {
+		candidates[id] = true
+	}
+
+	type ballotKey struct {
+		participantID uint64
+		trackID       uint64
+	}
+	grouped := make(map[ballotKey][]models.Vote)
+	keys := make([]ballotKey, 0)
+	for _, vote := range votes {
+		if !candidates[vote.SubmissionID] {
+			continue
+		}
+		key := ballotKey{vote.ParticipantID, vote.TrackID}
+		if _, ok := grouped[key]; !ok {
+			keys = append(keys, key)
+		}
+		grouped[key] = append(grouped[key], vote)
+	}
+
+	ballots := make([]rankedBallot, 0, len(keys))
+	for _, key := range keys {
+		entries := grouped[key]
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].Rank < entries[j].Rank })
+		ballot := rankedBallot{
+			submissionIDs: make([]uint64, 0, len(entries)),
+			weight:        voteWeight(entries[0]),
+		}
+		for _, entry := range entries {
+			ballot.submissionIDs = append(ballot.submissionIDs, entry.SubmissionID)
+		}
+		ballots = append(ballots, ballot)
+	}
+	return ballots
+}
+
+// irvTallier 即时决选（Instant-Runoff）。每轮统计各选票中排名最高且未淘汰的作品，淘汰得票最少者，
+// 直至只剩一个作品；作品的得票为其被淘汰（或最终胜出）时那一轮的票数，因此越晚淘汰得票越高
+type irvTallier struct{}
+
+func (irvTallier) Tally(candidateIDs []uint64, votes []models.Vote) map[uint64]int64 {
+	result := newCandidateTally(candidateIDs)
+	ballots := rankedBallots(candidateIDs, votes)
+
+	active := make(map[uint64]bool, len(candidateIDs))
+	for _, id := range candidateIDs {
+		active[id] = true
+	}
+
+	for len(active) > 0 {
+		counts := make(map[uint64]float64, len(active))
+		for id := range active {
+			counts[id] = 0
+		}
+		for _, ballot := range ballots {
+			for _, id := range ballot.submissionIDs {
+				if active[id] {
+					counts[id] += ballot.weight
+					break
+				}
+			}
+		}
+
+		if len(active) == 1 {
+			for id := range active {
+				result[id] = counts[id]
+				delete(active, id)
+			}
+			break
+		}
+
+		// 淘汰得票最少的作品，并列时淘汰 ID 较大（提交较晚）的作品
+		var eliminated uint64
+		first := true
+		for id, count := range counts {
+			if first || count < counts[eliminated] || (count == counts[eliminated] && id > eliminated) {
+				eliminated = id
+				first = false
+			}
+		}
+		result[eliminated] = counts[eliminated]
+		delete(active, eliminated)
+	}
+	return roundTally(result)
+}
+
+// bordaTallier 波达计数。共有 N 个候选作品时，选票中第 i 名（从 1 开始）得 N-i+1 分，未排序的作品不得分
+type bordaTallier struct{}
+
+func (bordaTallier) Tally(candidateIDs []uint64, votes []models.Vote) map[uint64]int64 {
+	result := newCandidateTally(candidateIDs)
+	n := len(candidateIDs)
+	for _, ballot := range rankedBallots(candidateIDs, votes) {
+		for i, id := range ballot.submissionIDs {
+			result[id] += float64(n-i) * ballot.weight
+		}
+	}
+	return roundTally(result)
+}
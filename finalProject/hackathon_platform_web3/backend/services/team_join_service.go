@@ -0,0 +1,437 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"hackathon-backend/database"
+	"hackathon-backend/models"
+
+	"gorm.io/gorm"
+)
+
+type TeamJoinService struct{}
+
+// 邀请默认有效期与上限
+const (
+	defaultInvitationTTL = 72 * time.Hour
+	maxInvitationTTL     = 30 * 24 * time.Hour
+)
+
+// getFormingTeam 获取处于组队阶段的队伍
+func (s *TeamJoinService) getFormingTeam(teamID uint64) (*models.Team, error) {
+	var team models.Team
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", teamID).First(&team).Error; err != nil {
+		return nil, errors.New("队伍不存在")
+	}
+
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", team.HackathonID).First(&hackathon).Error; err != nil {
+		return nil, errors.New("活动不存在")
+	}
+	if hackathon.Status != "team_formation" {
+		return nil, errors.New("当前不在组队阶段")
+	}
+	return &team, nil
+}
+
+// getLeaderTeam 获取处于组队阶段且由该参赛者担任队长的队伍
+func (s *TeamJoinService) getLeaderTeam(teamID, leaderID uint64) (*models.Team, error) {
+	team, err := s.getFormingTeam(teamID)
+	if err != nil {
+		return nil, err
+	}
+	if team.LeaderID != leaderID {
+		return nil, errors.New("只有队长可以管理邀请和加入申请")
+	}
+	return team, nil
+}
+
+// generateInvitationCode 生成邀请码
+func generateInvitationCode() (string, error) {
+	bytes := make([]byte, 8)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// CreateInvitation 队长发放邀请（inviteeID 为空时生成任何人可用的邀请码/链接），ttl 为 0 时默认 72 小时有效
+func (s *TeamJoinService) CreateInvitation(teamID, leaderID uint64, inviteeID *uint64, maxUses int, ttl time.Duration) (*models.TeamInvitation, error) {
+	team, err := s.getLeaderTeam(teamID, leaderID)
+	if err != nil {
+		return nil, err
+	}
+	if team.Status != "recruiting" {
+		return nil, errors.New("队伍已锁定，无法邀请")
+	}
+
+	if ttl <= 0 {
+		ttl = defaultInvitationTTL
+	}
+	if ttl > maxInvitationTTL {
+		return nil, errors.New("邀请有效期不能超过30天")
+	}
+	if maxUses <= 0 {
+		maxUses = 1
+	}
+
+	if inviteeID != nil {
+		if *inviteeID == leaderID {
+			return nil, errors.New("不能邀请自己")
+		}
+		var invitee models.Participant
+		if err := database.DB.Where("id = ?", *inviteeID).First(&invitee).Error; err != nil {
+			return nil, errors.New("受邀参赛者不存在")
+		}
+		// 指定受邀人的邀请只能使用一次
+		maxUses = 1
+	}
+
+	code, err := generateInvitationCode()
+	if err != nil {
+		return nil, fmt.Errorf("生成邀请码失败: %w", err)
+	}
+
+	invitation := models.TeamInvitation{
+		TeamID:    team.ID,
+		InviterID: leaderID,
+		InviteeID: inviteeID,
+		Code:      code,
+		MaxUses:   maxUses,
+		Status:    "active",
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&invitation).Error; err != nil {
+			return fmt.Errorf("创建邀请失败: %w", err)
+		}
+		if inviteeID == nil {
+			return nil
+		}
+		return notifyTeamInvite(tx, team, &invitation)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// notifyTeamInvite 通知受邀参赛者（站内通知，填写了邮箱时同时发送邮件）
+func notifyTeamInvite(tx *gorm.DB, team *models.Team, invitation *models.TeamInvitation) error {
+	var hackathon models.Hackathon
+	if err := tx.Where("id = ?", team.HackathonID).First(&hackathon).Error; err != nil {
+		return err
+	}
+	return notifyParticipants(tx, []uint64{*invitation.InviteeID}, team.HackathonID, NotifyTeamInvite, map[string]interface{}{
+		"InviterName":   participantDisplayName(tx, invitation.InviterID),
+		"TeamName":      team.Name,
+		"HackathonName": hackathon.Name,
+		"Code":          invitation.Code,
+		"ExpiresAt":     invitation.ExpiresAt.Format("2006-01-02 15:04 MST"),
+	})
+}
+
+// GetTeamInvitations 获取队伍发放的邀请（仅队长）
+func (s *TeamJoinService) GetTeamInvitations(teamID, leaderID uint64) ([]models.TeamInvitation, error) {
+	var team models.Team
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", teamID).First(&team).Error; err != nil {
+		return nil, errors.New("队伍不存在")
+	}
+	if team.LeaderID != leaderID {
+		return nil, errors.New("只有队长可以查看邀请")
+	}
+
+	var invitations []models.TeamInvitation
+	if err := database.DB.Preload("Invitee").Where("team_id = ?", teamID).Order("created_at DESC").Find(&invitations).Error; err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+// RevokeInvitation 撤销邀请（仅队长）
+func (s *TeamJoinService) RevokeInvitation(teamID, invitationID, leaderID uint64) error {
+	var team models.Team
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", teamID).First(&team).Error; err != nil {
+		return errors.New("队伍不存在")
+	}
+	if team.LeaderID != leaderID {
+		return errors.New("只有队长可以撤销邀请")
+	}
+
+	var invitation models.TeamInvitation
+	if err := database.DB.Where("id = ? AND team_id = ?", invitationID, teamID).First(&invitation).Error; err != nil {
+		return errors.New("邀请不存在")
+	}
+	if invitation.Status != "active" {
+		return errors.New("邀请已失效")
+	}
+	return database.DB.Model(&invitation).Update("status", "revoked").Error
+}
+
+// GetInvitationByCode 根据邀请码获取邀请详情
+func (s *TeamJoinService) GetInvitationByCode(code string) (*models.TeamInvitation, error) {
+	var invitation models.TeamInvitation
+	if err := database.DB.Preload("Team").Preload("Team.Leader").Preload("Team.Members").Preload("Team.Members.Participant").
+		Where("code = ?", code).First(&invitation).Error; err != nil {
+		return nil, errors.New("邀请不存在")
+	}
+	return &invitation, nil
+}
+
+// checkInvitationUsable 检查邀请对该参赛者有效
+func (s *TeamJoinService) checkInvitationUsable(invitation *models.TeamInvitation, participantID uint64) error {
+	if invitation.Status != "active" {
+		return errors.New("邀请已失效")
+	}
+	if time.Now().After(invitation.ExpiresAt) {
+		return errors.New("邀请已过期")
+	}
+	if invitation.InviteeID != nil && *invitation.InviteeID != participantID {
+		return errors.New("该邀请不是发给您的")
+	}
+	return nil
+}
+
+// AcceptInvitation 接受邀请加入队伍（不受队伍加入方式限制）
+func (s *TeamJoinService) AcceptInvitation(code string, participantID uint64) (*models.Team, error) {
+	invitation, err := s.GetInvitationByCode(code)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkInvitationUsable(invitation, participantID); err != nil {
+		return nil, err
+	}
+
+	team, err := s.getFormingTeam(invitation.TeamID)
+	if err != nil {
+		return nil, err
+	}
+
+	teamService := &TeamService{}
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// 以条件更新占用一次使用次数，避免并发接受时超出 max_uses；加入失败时随事务回滚
+		claim := tx.Model(&models.TeamInvitation{}).
+			Where("id = ? AND status = ? AND used_count < max_uses", invitation.ID, "active").
+			Update("used_count", gorm.Expr("used_count + 1"))
+		if claim.Error != nil {
+			return claim.Error
+		}
+		if claim.RowsAffected == 0 {
+			return errors.New("邀请已失效")
+		}
+
+		if err := teamService.addMember(tx, team, participantID); err != nil {
+			return err
+		}
+
+		return tx.Model(&models.TeamInvitation{}).
+			Where("id = ? AND status = ? AND used_count >= max_uses", invitation.ID, "active").
+			Update("status", "used").Error
+	}); err != nil {
+		return nil, err
+	}
+	publishTeamUpdated(team, "member_joined", participantID)
+	notifyTeamEvent(team, team.LeaderID, participantID, NotifyTeamMemberJoined, nil)
+	return team, nil
+}
+
+// DeclineInvitation 拒绝邀请（仅指定受邀人的邀请）
+func (s *TeamJoinService) DeclineInvitation(code string, participantID uint64) error {
+	invitation, err := s.GetInvitationByCode(code)
+	if err != nil {
+		return err
+	}
+	if invitation.InviteeID == nil {
+		return errors.New("公开邀请无需拒绝")
+	}
+	if err := s.checkInvitationUsable(invitation, participantID); err != nil {
+		return err
+	}
+	return database.DB.Model(invitation).Update("status", "declined").Error
+}
+
+// CreateJoinRequest 申请加入队伍（队伍加入方式为 request）
+func (s *TeamJoinService) CreateJoinRequest(teamID, participantID uint64, message string) (*models.TeamJoinRequest, error) {
+	team, err := s.getFormingTeam(teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch team.JoinPolicy {
+	case "open":
+		return nil, errors.New("该队伍可直接加入，无需申请")
+	case "invite_only":
+		return nil, errors.New("该队伍仅限邀请加入")
+	}
+	if team.Status != "recruiting" {
+		return nil, errors.New("队伍已锁定，无法加入")
+	}
+
+	// 检查是否已签到
+	registrationService := &RegistrationService{}
+	checkedIn, _, err := registrationService.GetCheckinStatus(team.HackathonID, participantID)
+	if err != nil {
+		return nil, err
+	}
+	if !checkedIn {
+		return nil, errors.New("请先完成签到")
+	}
+
+	// 检查是否已在队伍中
+	var existingMember models.TeamMember
+	if err := database.DB.Joins("JOIN teams ON team_members.team_id = teams.id").
+		Where("team_members.participant_id = ? AND teams.hackathon_id = ? AND teams.deleted_at IS NULL", participantID, team.HackathonID).
+		First(&existingMember).Error; err == nil {
+		return nil, errors.New("您已经在其他队伍中")
+	}
+
+	// 检查是否已有待审批的申请
+	var existing models.TeamJoinRequest
+	if err := database.DB.Where("team_id = ? AND participant_id = ? AND status = ?", teamID, participantID, "pending").
+		First(&existing).Error; err == nil {
+		return nil, errors.New("您已提交过加入申请，请等待队长审批")
+	}
+
+	request := models.TeamJoinRequest{
+		TeamID:        teamID,
+		HackathonID:   team.HackathonID,
+		ParticipantID: participantID,
+		Message:       message,
+		Status:        "pending",
+	}
+	if err := database.DB.Create(&request).Error; err != nil {
+		return nil, fmt.Errorf("提交加入申请失败: %w", err)
+	}
+	return &request, nil
+}
+
+// GetTeamJoinRequests 获取队伍的加入申请（仅队长），status 为空时返回全部
+func (s *TeamJoinService) GetTeamJoinRequests(teamID, leaderID uint64, status string) ([]models.TeamJoinRequest, error) {
+	var team models.Team
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", teamID).First(&team).Error; err != nil {
+		return nil, errors.New("队伍不存在")
+	}
+	if team.LeaderID != leaderID {
+		return nil, errors.New("只有队长可以查看加入申请")
+	}
+
+	query := database.DB.Preload("Participant").Where("team_id = ?", teamID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var requests []models.TeamJoinRequest
+	if err := query.Order("created_at DESC").Find(&requests).Error; err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// getPendingRequest 获取队伍中待审批的加入申请
+func (s *TeamJoinService) getPendingRequest(teamID, requestID uint64) (*models.TeamJoinRequest, error) {
+	var request models.TeamJoinRequest
+	if err := database.DB.Where("id = ? AND team_id = ?", requestID, teamID).First(&request).Error; err != nil {
+		return nil, errors.New("加入申请不存在")
+	}
+	if request.Status != "pending" {
+		return nil, errors.New("该申请已处理")
+	}
+	return &request, nil
+}
+
+// ApproveJoinRequest 批准加入申请（仅队长）
+func (s *TeamJoinService) ApproveJoinRequest(teamID, requestID, leaderID uint64) error {
+	team, err := s.getLeaderTeam(teamID, leaderID)
+	if err != nil {
+		return err
+	}
+	request, err := s.getPendingRequest(teamID, requestID)
+	if err != nil {
+		return err
+	}
+
+	teamService := &TeamService{}
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// addMember 会取消该参赛者所有待审批申请，需先将本申请标记为已批准
+		now := time.Now()
+		result := tx.Model(&models.TeamJoinRequest{}).
+			Where("id = ? AND status = ?", request.ID, "pending").
+			Updates(map[string]interface{}{
+				"status":      "approved",
+				"reviewed_at": &now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("该申请已处理")
+		}
+		return teamService.addMember(tx, team, request.ParticipantID)
+	}); err != nil {
+		return err
+	}
+	publishTeamUpdated(team, "member_joined", request.ParticipantID)
+	notifyTeamEvent(team, request.ParticipantID, request.ParticipantID, NotifyJoinRequestReviewed, map[string]interface{}{"Approved": true})
+	return nil
+}
+
+// DeclineJoinRequest 拒绝加入申请（仅队长）
+func (s *TeamJoinService) DeclineJoinRequest(teamID, requestID, leaderID uint64) error {
+	team, err := s.getLeaderTeam(teamID, leaderID)
+	if err != nil {
+		return err
+	}
+	request, err := s.getPendingRequest(teamID, requestID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := database.DB.Model(request).Updates(map[string]interface{}{
+		"status":      "declined",
+		"reviewed_at": &now,
+	}).Error; err != nil {
+		return err
+	}
+	notifyTeamEvent(team, request.ParticipantID, request.ParticipantID, NotifyJoinRequestReviewed, map[string]interface{}{"Approved": false})
+	return nil
+}
+
+// CancelJoinRequest 撤回自己的加入申请
+func (s *TeamJoinService) CancelJoinRequest(requestID, participantID uint64) error {
+	var request models.TeamJoinRequest
+	if err := database.DB.Where("id = ? AND participant_id = ?", requestID, participantID).First(&request).Error; err != nil {
+		return errors.New("加入申请不存在")
+	}
+	if request.Status != "pending" {
+		return errors.New("该申请已处理")
+	}
+	return database.DB.Model(&request).Update("status", "cancelled").Error
+}
+
+// GetMyJoinStatus 获取参赛者在活动中待处理的加入申请与收到的有效邀请
+func (s *TeamJoinService) GetMyJoinStatus(hackathonID, participantID uint64) (map[string]interface{}, error) {
+	var requests []models.TeamJoinRequest
+	if err := database.DB.Preload("Team").
+		Where("hackathon_id = ? AND participant_id = ? AND status = ?", hackathonID, participantID, "pending").
+		Order("created_at DESC").Find(&requests).Error; err != nil {
+		return nil, err
+	}
+
+	var invitations []models.TeamInvitation
+	if err := database.DB.Preload("Team").
+		Joins("JOIN teams ON teams.id = team_invitations.team_id").
+		Where("teams.hackathon_id = ? AND teams.deleted_at IS NULL AND team_invitations.invitee_id = ? AND team_invitations.status = ? AND team_invitations.expires_at > ?",
+			hackathonID, participantID, "active", time.Now()).
+		Order("team_invitations.created_at DESC").Find(&invitations).Error; err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"join_requests": requests,
+		"invitations":   invitations,
+	}, nil
+}
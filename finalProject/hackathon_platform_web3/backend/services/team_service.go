@@ -3,17 +3,35 @@ package services
 import (
 	"errors"
 	"fmt"
+	"log"
 	"strings"
 	"time"
 
 	"hackathon-backend/database"
 	"hackathon-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TeamService struct{}
 
+// validJoinPolicies 队伍加入方式
+var validJoinPolicies = map[string]bool{
+	"open":        true,
+	"request":     true,
+	"invite_only": true,
+}
+
 // CreateTeam 创建队伍
-func (s *TeamService) CreateTeam(hackathonID, leaderID uint64, name string, maxSize int) (*models.Team, error) {
+func (s *TeamService) CreateTeam(hackathonID, leaderID uint64, name string, maxSize int, joinPolicy string) (*models.Team, error) {
+	if joinPolicy == "" {
+		joinPolicy = "open"
+	}
+	if !validJoinPolicies[joinPolicy] {
+		return nil, errors.New("无效的加入方式")
+	}
+
 	// 检查活动状态
 	var hackathon models.Hackathon
 	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
@@ -34,22 +52,6 @@ func (s *TeamService) CreateTeam(hackathonID, leaderID uint64, name string, maxS
 		return nil, errors.New("请先完成签到")
 	}
 
-	// 检查是否已在其他队伍（作为成员或队长）
-	// 注意：只检查当前活动，同一个人可以在不同活动中创建或加入不同的队伍
-	var existingMember models.TeamMember
-	if err := database.DB.Joins("JOIN teams ON team_members.team_id = teams.id").
-		Where("team_members.participant_id = ? AND teams.hackathon_id = ? AND teams.deleted_at IS NULL", leaderID, hackathonID).
-		First(&existingMember).Error; err == nil {
-		return nil, errors.New("您已经在其他队伍中")
-	}
-
-	// 检查队长是否已创建队伍（一个队长在一个活动中只能创建一个队伍）
-	// 注意：只检查当前活动，同一个人可以在不同活动中创建不同的队伍
-	var existingTeam models.Team
-	if err := database.DB.Where("hackathon_id = ? AND leader_id = ? AND deleted_at IS NULL", hackathonID, leaderID).First(&existingTeam).Error; err == nil {
-		return nil, errors.New("您已经创建了队伍")
-	}
-
 	// 创建队伍
 	team := models.Team{
 		HackathonID: hackathonID,
@@ -57,49 +59,65 @@ func (s *TeamService) CreateTeam(hackathonID, leaderID uint64, name string, maxS
 		LeaderID:    leaderID,
 		MaxSize:     maxSize,
 		Status:      "recruiting",
+		JoinPolicy:  joinPolicy,
 	}
 
-	if err := database.DB.Create(&team).Error; err != nil {
-		// 检查是否是唯一索引冲突错误
-		if strings.Contains(err.Error(), "Duplicate entry") {
-			// 检查是哪个唯一索引冲突
-			if strings.Contains(err.Error(), "uk_hackathon_leader") {
-				// 再次查询确认是否真的已创建队伍
-				var checkTeam models.Team
-				if checkErr := database.DB.Where("hackathon_id = ? AND leader_id = ? AND deleted_at IS NULL", hackathonID, leaderID).First(&checkTeam).Error; checkErr == nil {
-					return nil, errors.New("您已经创建了队伍")
+	// 锁定队长的报名记录，与加入队伍串行执行，队伍与队长成员记录在同一事务中创建
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockRegistration(tx, hackathonID, leaderID); err != nil {
+			return err
+		}
+
+		// 检查是否已在其他队伍（作为成员或队长）
+		// 注意：只检查当前活动，同一个人可以在不同活动中创建或加入不同的队伍
+		var existingMember models.TeamMember
+		if err := tx.Joins("JOIN teams ON team_members.team_id = teams.id").
+			Where("team_members.participant_id = ? AND teams.hackathon_id = ? AND teams.deleted_at IS NULL", leaderID, hackathonID).
+			First(&existingMember).Error; err == nil {
+			return errors.New("您已经在其他队伍中")
+		}
+
+		// 检查队长是否已创建队伍（一个队长在一个活动中只能创建一个队伍）
+		// 注意：只检查当前活动，同一个人可以在不同活动中创建不同的队伍
+		var existingTeam models.Team
+		if err := tx.Where("hackathon_id = ? AND leader_id = ? AND deleted_at IS NULL", hackathonID, leaderID).First(&existingTeam).Error; err == nil {
+			return errors.New("您已经创建了队伍")
+		}
+
+		if err := tx.Create(&team).Error; err != nil {
+			// 检查是否是唯一索引冲突错误
+			if strings.Contains(err.Error(), "Duplicate entry") {
+				// 检查是哪个唯一索引冲突
+				if strings.Contains(err.Error(), "uk_hackathon_leader") {
+					return errors.New("您已经创建了队伍")
+				}
+				// 如果是旧的 uk_hackathon_name 索引冲突，说明数据库迁移未完成
+				if strings.Contains(err.Error(), "uk_hackathon_name") {
+					return errors.New("队伍名称已存在，请先执行数据库迁移脚本 migrate_team_index.go")
 				}
-				// 如果查询不到，可能是数据库同步问题，返回通用错误
-				return nil, errors.New("创建队伍失败，请稍后重试")
-			}
-			// 如果是旧的 uk_hackathon_name 索引冲突，说明数据库迁移未完成
-			if strings.Contains(err.Error(), "uk_hackathon_name") {
-				return nil, errors.New("队伍名称已存在，请先执行数据库迁移脚本 migrate_team_index.go")
-			}
-			// 其他唯一索引冲突，尝试查询确认
-			var checkTeam models.Team
-			if checkErr := database.DB.Where("hackathon_id = ? AND leader_id = ? AND deleted_at IS NULL", hackathonID, leaderID).First(&checkTeam).Error; checkErr == nil {
-				return nil, errors.New("您已经创建了队伍")
 			}
-			return nil, fmt.Errorf("创建队伍失败: %w", err)
+			return fmt.Errorf("创建队伍失败: %w", err)
 		}
-		return nil, fmt.Errorf("创建队伍失败: %w", err)
-	}
 
-	// 创建队长成员记录
-	member := models.TeamMember{
-		TeamID:        team.ID,
-		ParticipantID: leaderID,
-		Role:          "leader",
-		JoinedAt:      time.Now(), // 设置加入时间为当前时间
+		// 创建队长成员记录
+		member := models.TeamMember{
+			TeamID:        team.ID,
+			ParticipantID: leaderID,
+			Role:          "leader",
+			JoinedAt:      time.Now(), // 设置加入时间为当前时间
+		}
+		if err := tx.Create(&member).Error; err != nil {
+			return fmt.Errorf("创建成员记录失败: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
 	}
 
-	if err := database.DB.Create(&member).Error; err != nil {
-		// 如果创建成员失败，删除队伍
-		database.DB.Delete(&team)
-		return nil, fmt.Errorf("创建成员记录失败: %w", err)
+	publishTeamUpdated(&team, "created", leaderID)
+	if err := emitTeamCreated(database.DB, &team, []uint64{leaderID}, false); err != nil {
+		log.Printf("写入队伍 %d 创建事件失败: %v", team.ID, err)
 	}
-
 	return &team, nil
 }
 
@@ -138,7 +156,7 @@ func (s *TeamService) GetTeamByID(teamID uint64) (*models.Team, error) {
 	return &team, nil
 }
 
-// JoinTeam 加入队伍
+// JoinTeam 加入队伍（仅加入方式为 open 的队伍可直接加入）
 func (s *TeamService) JoinTeam(teamID, participantID uint64) error {
 	// 获取队伍信息
 	var team models.Team
@@ -146,11 +164,28 @@ func (s *TeamService) JoinTeam(teamID, participantID uint64) error {
 		return errors.New("队伍不存在")
 	}
 
-	// 检查活动状态
-	if team.Status != "recruiting" {
-		return errors.New("队伍已锁定，无法加入")
+	// 检查加入方式
+	switch team.JoinPolicy {
+	case "request":
+		return errors.New("该队伍需提交加入申请，由队长审批")
+	case "invite_only":
+		return errors.New("该队伍仅限邀请加入")
 	}
 
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return s.addMember(tx, &team, participantID)
+	}); err != nil {
+		return err
+	}
+	publishTeamUpdated(&team, "member_joined", participantID)
+	notifyTeamEvent(&team, team.LeaderID, participantID, NotifyTeamMemberJoined, nil)
+	return nil
+}
+
+// addMember 在事务中校验并将参赛者加入队伍，加入后取消其在该活动中其他待审批的加入申请。
+// 先锁定队伍行与参赛者的报名记录再检查人数与是否已在队伍中，避免并发加入时超员或同时加入多个队伍；
+// 调用方在事务提交后发布队伍更新事件
+func (s *TeamService) addMember(tx *gorm.DB, team *models.Team, participantID uint64) error {
 	// 检查是否已签到
 	registrationService := &RegistrationService{}
 	checkedIn, _, err := registrationService.GetCheckinStatus(team.HackathonID, participantID)
@@ -161,9 +196,29 @@ func (s *TeamService) JoinTeam(teamID, participantID uint64) error {
 		return errors.New("请先完成签到")
 	}
 
+	// 锁定队伍行，并以锁定后读到的最新状态校验
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND deleted_at IS NULL", team.ID).First(team).Error; err != nil {
+		return errors.New("队伍不存在")
+	}
+	if err := lockRegistration(tx, team.HackathonID, participantID); err != nil {
+		return err
+	}
+
+	// 检查队伍状态
+	if team.Status != "recruiting" {
+		return errors.New("队伍已锁定，无法加入")
+	}
+
+	// 检查是否已在队伍中
+	var existing models.TeamMember
+	if err := tx.Where("team_id = ? AND participant_id = ?", team.ID, participantID).First(&existing).Error; err == nil {
+		return errors.New("您已经在该队伍中")
+	}
+
 	// 检查是否已在其他队伍
 	var existingMember models.TeamMember
-	if err := database.DB.Joins("JOIN teams ON team_members.team_id = teams.id").
+	if err := tx.Joins("JOIN teams ON team_members.team_id = teams.id").
 		Where("team_members.participant_id = ? AND teams.hackathon_id = ? AND teams.deleted_at IS NULL", participantID, team.HackathonID).
 		First(&existingMember).Error; err == nil {
 		return errors.New("您已经在其他队伍中")
@@ -171,26 +226,27 @@ func (s *TeamService) JoinTeam(teamID, participantID uint64) error {
 
 	// 检查队伍是否已满
 	var memberCount int64
-	database.DB.Model(&models.TeamMember{}).Where("team_id = ?", teamID).Count(&memberCount)
+	if err := tx.Model(&models.TeamMember{}).Where("team_id = ?", team.ID).Count(&memberCount).Error; err != nil {
+		return err
+	}
 	if int(memberCount) >= team.MaxSize {
 		return errors.New("队伍已满")
 	}
 
-	// 检查是否已在队伍中
-	var existing models.TeamMember
-	if err := database.DB.Where("team_id = ? AND participant_id = ?", teamID, participantID).First(&existing).Error; err == nil {
-		return errors.New("您已经在该队伍中")
-	}
-
 	// 创建成员记录
 	member := models.TeamMember{
-		TeamID:        teamID,
+		TeamID:        team.ID,
 		ParticipantID: participantID,
 		Role:          "member",
 		JoinedAt:      time.Now(), // 设置加入时间为当前时间
 	}
+	if err := tx.Create(&member).Error; err != nil {
+		return err
+	}
 
-	return database.DB.Create(&member).Error
+	return tx.Model(&models.TeamJoinRequest{}).
+		Where("hackathon_id = ? AND participant_id = ? AND status = ?", team.HackathonID, participantID, "pending").
+		Update("status", "cancelled").Error
 }
 
 // LeaveTeam 退出队伍
@@ -203,7 +259,7 @@ func (s *TeamService) LeaveTeam(teamID, participantID uint64) error {
 
 	// 检查是否是队长
 	if team.LeaderID == participantID {
-		return errors.New("队长不能退出，请解散队伍")
+		return errors.New("队长不能退出，请先转让队长或解散队伍")
 	}
 
 	// 检查活动状态
@@ -216,8 +272,17 @@ func (s *TeamService) LeaveTeam(teamID, participantID uint64) error {
 		return errors.New("组队阶段已结束，无法退出")
 	}
 
+	if team.Status == "locked" {
+		return errors.New("队伍已锁定，无法退出")
+	}
+
 	// 删除成员记录
-	return database.DB.Where("team_id = ? AND participant_id = ?", teamID, participantID).Delete(&models.TeamMember{}).Error
+	if err := database.DB.Where("team_id = ? AND participant_id = ?", teamID, participantID).Delete(&models.TeamMember{}).Error; err != nil {
+		return err
+	}
+	publishTeamUpdated(&team, "member_left", participantID)
+	notifyTeamEvent(&team, team.LeaderID, participantID, NotifyTeamMemberLeft, nil)
+	return nil
 }
 
 // RemoveMember 移除成员（仅队长）
@@ -248,8 +313,20 @@ func (s *TeamService) RemoveMember(teamID, leaderID, memberID uint64) error {
 		return errors.New("组队阶段已结束，无法移除成员")
 	}
 
+	if team.Status == "locked" {
+		return errors.New("队伍已锁定，请先解锁再移除成员")
+	}
+
 	// 删除成员记录
-	return database.DB.Where("team_id = ? AND participant_id = ?", teamID, memberID).Delete(&models.TeamMember{}).Error
+	result := database.DB.Where("team_id = ? AND participant_id = ?", teamID, memberID).Delete(&models.TeamMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		publishTeamUpdated(&team, "member_removed", memberID)
+		notifyTeamEvent(&team, memberID, memberID, NotifyTeamMemberRemoved, nil)
+	}
+	return nil
 }
 
 // DissolveTeam 解散队伍（仅队长）
@@ -282,13 +359,17 @@ func (s *TeamService) DissolveTeam(teamID, leaderID uint64) error {
 		return errors.New("组队阶段已结束，无法解散队伍")
 	}
 
-	// 物理删除成员记录
-	if err := database.DB.Unscoped().Where("team_id = ?", teamID).Delete(&models.TeamMember{}).Error; err != nil {
-		return fmt.Errorf("删除成员记录失败: %w", err)
+	// 删除成员记录并软删除队伍（已删除的队伍不占用队长唯一索引）
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("team_id = ?", teamID).Delete(&models.TeamMember{}).Error; err != nil {
+			return fmt.Errorf("删除成员记录失败: %w", err)
+		}
+		return tx.Delete(&team).Error
+	}); err != nil {
+		return err
 	}
-
-	// 物理删除队伍（直接删除数据库数据）
-	return database.DB.Unscoped().Delete(&team).Error
+	publishTeamUpdated(&team, "dissolved", leaderID)
+	return nil
 }
 
 // GetUserTeam 获取用户在指定活动中的队伍信息
@@ -301,6 +382,15 @@ func (s *TeamService) GetUserTeam(hackathonID, participantID uint64) (*models.Te
 		First(&team).Error; err != nil {
 		return nil, nil // 用户不在任何队伍中，返回 nil 而不是错误
 	}
+
+	// 队长可查看待审批的加入申请
+	if team.LeaderID == participantID {
+		if err := database.DB.Preload("Participant").
+			Where("team_id = ? AND status = ?", team.ID, "pending").
+			Order("created_at ASC").Find(&team.JoinRequests).Error; err != nil {
+			return nil, err
+		}
+	}
 	return &team, nil
 }
 
@@ -327,6 +417,20 @@ func (s *TeamService) UpdateTeam(teamID, leaderID uint64, updates map[string]int
 		return errors.New("组队阶段已结束，无法修改队伍信息")
 	}
 
+	if joinPolicy, ok := updates["join_policy"]; ok {
+		if policy, isString := joinPolicy.(string); !isString || !validJoinPolicies[policy] {
+			return errors.New("无效的加入方式")
+		}
+	}
+
+	if value, ok := updates["looking_for_roles"]; ok {
+		roles, err := normalizeTags(value)
+		if err != nil {
+			return err
+		}
+		updates["looking_for_roles"] = roles
+	}
+
 	// 如果修改名称，检查是否重复
 	if name, ok := updates["name"].(string); ok {
 		var existing models.Team
@@ -338,3 +442,108 @@ func (s *TeamService) UpdateTeam(teamID, leaderID uint64, updates map[string]int
 	return database.DB.Model(&models.Team{}).Where("id = ?", teamID).Updates(updates).Error
 }
 
+// TransferLeadership 转让队长（仅队长，组队与提交阶段内），新队长须为本队成员
+func (s *TeamService) TransferLeadership(teamID, leaderID, newLeaderID uint64) error {
+	var team models.Team
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", teamID).First(&team).Error; err != nil {
+		return errors.New("队伍不存在")
+	}
+
+	if team.LeaderID != leaderID {
+		return errors.New("只有队长可以转让队长")
+	}
+	if newLeaderID == leaderID {
+		return errors.New("不能转让给自己")
+	}
+
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", team.HackathonID).First(&hackathon).Error; err != nil {
+		return errors.New("活动不存在")
+	}
+	if hackathon.Status != "team_formation" && hackathon.Status != "submission" {
+		return errors.New("当前阶段无法转让队长")
+	}
+
+	var newLeader models.TeamMember
+	if err := database.DB.Where("team_id = ? AND participant_id = ?", teamID, newLeaderID).First(&newLeader).Error; err != nil {
+		return errors.New("新队长必须是本队成员")
+	}
+
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Team{}).Where("id = ?", teamID).Update("leader_id", newLeaderID).Error; err != nil {
+			if strings.Contains(err.Error(), "Duplicate entry") {
+				return errors.New("新队长已是本活动其他队伍的队长")
+			}
+			return fmt.Errorf("转让队长失败: %w", err)
+		}
+		if err := tx.Model(&models.TeamMember{}).Where("team_id = ? AND participant_id = ?", teamID, leaderID).Update("role", "member").Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.TeamMember{}).Where("team_id = ? AND participant_id = ?", teamID, newLeaderID).Update("role", "leader").Error
+	}); err != nil {
+		return err
+	}
+	publishTeamUpdated(&team, "leader_transferred", newLeaderID)
+	return nil
+}
+
+// SetTeamLocked 锁定或解锁队伍（仅队长，组队阶段内）。锁定后不能加入、退出或移除成员
+func (s *TeamService) SetTeamLocked(teamID, leaderID uint64, locked bool) error {
+	var team models.Team
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", teamID).First(&team).Error; err != nil {
+		return errors.New("队伍不存在")
+	}
+
+	if team.LeaderID != leaderID {
+		return errors.New("只有队长可以锁定或解锁队伍")
+	}
+
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", team.HackathonID).First(&hackathon).Error; err != nil {
+		return errors.New("活动不存在")
+	}
+	if hackathon.Status != "team_formation" {
+		return errors.New("组队阶段已结束，无法修改队伍锁定状态")
+	}
+
+	status := "recruiting"
+	if locked {
+		status = "locked"
+	}
+	if team.Status == status {
+		return nil
+	}
+	return database.DB.Model(&team).Update("status", status).Error
+}
+
+// lockHackathonTeams 锁定活动的所有队伍，并取消尚未处理的加入申请（活动离开组队阶段时调用）
+func lockHackathonTeams(tx *gorm.DB, hackathonID uint64) error {
+	if err := tx.Model(&models.Team{}).
+		Where("hackathon_id = ? AND status = ? AND deleted_at IS NULL", hackathonID, "recruiting").
+		Update("status", "locked").Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.TeamJoinRequest{}).
+		Where("hackathon_id = ? AND status = ?", hackathonID, "pending").
+		Update("status", "cancelled").Error
+}
+
+// notifyTeamEvent 向 recipientID 发送与队伍成员 memberID 相关的通知，写入失败只记录日志，不影响队伍操作
+func notifyTeamEvent(team *models.Team, recipientID, memberID uint64, name string, extra map[string]interface{}) {
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ?", team.HackathonID).First(&hackathon).Error; err != nil {
+		log.Printf("队伍 %d 通知写入失败: %v", team.ID, err)
+		return
+	}
+	data := map[string]interface{}{
+		"MemberName":    participantDisplayName(database.DB, memberID),
+		"TeamName":      team.Name,
+		"HackathonName": hackathon.Name,
+	}
+	for key, value := range extra {
+		data[key] = value
+	}
+	if err := notifyParticipants(database.DB, []uint64{recipientID}, team.HackathonID, name, data); err != nil {
+		log.Printf("队伍 %d 通知写入失败: %v", team.ID, err)
+	}
+}
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"hackathon-backend/database"
+	"hackathon-backend/models"
+
+	"gorm.io/gorm"
+)
+
+type TrackService struct{}
+
+// trackEditableStatuses 允许维护赛道的活动状态（作品提交开始后赛道不可再变更）
+var trackEditableStatuses = map[string]bool{
+	"preparation":    true,
+	"published":      true,
+	"registration":   true,
+	"checkin":        true,
+	"team_formation": true,
+}
+
+// checkTrackManager 检查用户是否可以维护该活动的赛道（仅活动创建者，且作品提交开始前）
+func (s *TrackService) checkTrackManager(hackathonID, userID uint64, userRole string) (*models.Hackathon, error) {
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
+		return nil, errors.New("活动不存在")
+	}
+	if userRole == "admin" {
+		return nil, errors.New("Admin不能管理活动赛道")
+	}
+	if hackathon.OrganizerID != userID {
+		return nil, errors.New("只能管理自己创建活动的赛道")
+	}
+	if !trackEditableStatuses[hackathon.Status] {
+		return nil, errors.New("作品提交阶段开始后不能修改赛道")
+	}
+	return &hackathon, nil
+}
+
+// GetTracks 获取活动的赛道列表
+func (s *TrackService) GetTracks(hackathonID uint64) ([]models.HackathonTrack, error) {
+	var tracks []models.HackathonTrack
+	if err := database.DB.Where("hackathon_id = ?", hackathonID).Order("`order` ASC, id ASC").Find(&tracks).Error; err != nil {
+		return nil, err
+	}
+	return tracks, nil
+}
+
+// HasTracks 判断活动是否设置了赛道
+func (s *TrackService) HasTracks(hackathonID uint64) (bool, error) {
+	var count int64
+	if err := database.DB.Model(&models.HackathonTrack{}).Where("hackathon_id = ?", hackathonID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetTrack 获取活动下的指定赛道
+func (s *TrackService) GetTrack(hackathonID, trackID uint64) (*models.HackathonTrack, error) {
+	var track models.HackathonTrack
+	if err := database.DB.Where("id = ? AND hackathon_id = ?", trackID, hackathonID).First(&track).Error; err != nil {
+		return nil, errors.New("赛道不存在")
+	}
+	return &track, nil
+}
+
+// CreateTrack 创建赛道（仅活动创建者）
+func (s *TrackService) CreateTrack(hackathonID uint64, track *models.HackathonTrack, userID uint64, userRole string) error {
+	if _, err := s.checkTrackManager(hackathonID, userID, userRole); err != nil {
+		return err
+	}
+
+	var existing models.HackathonTrack
+	if err := database.DB.Where("hackathon_id = ? AND name = ?", hackathonID, track.Name).First(&existing).Error; err == nil {
+		return errors.New("赛道名称已存在")
+	}
+
+	track.ID = 0
+	track.HackathonID = hackathonID
+	if err := database.DB.Create(track).Error; err != nil {
+		return fmt.Errorf("创建赛道失败: %w", err)
+	}
+	return nil
+}
+
+// UpdateTrack 更新赛道（仅活动创建者）
+func (s *TrackService) UpdateTrack(hackathonID, trackID uint64, updates map[string]interface{}, userID uint64, userRole string) error {
+	if _, err := s.checkTrackManager(hackathonID, userID, userRole); err != nil {
+		return err
+	}
+	if _, err := s.GetTrack(hackathonID, trackID); err != nil {
+		return err
+	}
+
+	// 不允许修改所属活动
+	delete(updates, "id")
+	delete(updates, "hackathon_id")
+
+	if name, ok := updates["name"].(string); ok {
+		var existing models.HackathonTrack
+		if err := database.DB.Where("hackathon_id = ? AND name = ? AND id != ?", hackathonID, name, trackID).First(&existing).Error; err == nil {
+			return errors.New("赛道名称已存在")
+		}
+	}
+
+	return database.DB.Model(&models.HackathonTrack{}).Where("id = ?", trackID).Updates(updates).Error
+}
+
+// DeleteTrack 删除赛道（仅活动创建者，已有作品选择的赛道不能删除）
+func (s *TrackService) DeleteTrack(hackathonID, trackID uint64, userID uint64, userRole string) error {
+	if _, err := s.checkTrackManager(hackathonID, userID, userRole); err != nil {
+		return err
+	}
+	track, err := s.GetTrack(hackathonID, trackID)
+	if err != nil {
+		return err
+	}
+
+	var submissionCount int64
+	database.DB.Model(&models.SubmissionTrack{}).Where("track_id = ?", trackID).Count(&submissionCount)
+	if submissionCount > 0 {
+		return errors.New("已有作品选择该赛道，不能删除")
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		// 赛道奖项、赛道赞助回退为全场
+		if err := tx.Model(&models.HackathonAward{}).Where("track_id = ?", trackID).Update("track_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.HackathonSponsorEvent{}).Where("track_id = ?", trackID).Update("track_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(track).Error
+	})
+}
+
+// validateTrackIDs 校验赛道均属于该活动
+func (s *TrackService) validateTrackIDs(tx *gorm.DB, hackathonID uint64, trackIDs []uint64) error {
+	if len(trackIDs) == 0 {
+		return nil
+	}
+	unique := make(map[uint64]bool)
+	for _, id := range trackIDs {
+		unique[id] = true
+	}
+	var count int64
+	if err := tx.Model(&models.HackathonTrack{}).Where("hackathon_id = ? AND id IN ?", hackathonID, trackIDs).Count(&count).Error; err != nil {
+		return err
+	}
+	if int(count) != len(unique) {
+		return errors.New("赛道不存在")
+	}
+	return nil
+}
+
+// setSubmissionTracks 覆盖设置作品参加的赛道
+func (s *TrackService) setSubmissionTracks(tx *gorm.DB, hackathonID, submissionID uint64, trackIDs []uint64) error {
+	if err := s.validateTrackIDs(tx, hackathonID, trackIDs); err != nil {
+		return err
+	}
+	if err := tx.Where("submission_id = ?", submissionID).Delete(&models.SubmissionTrack{}).Error; err != nil {
+		return err
+	}
+	seen := make(map[uint64]bool)
+	for _, trackID := range trackIDs {
+		if seen[trackID] {
+			continue
+		}
+		seen[trackID] = true
+		if err := tx.Create(&models.SubmissionTrack{SubmissionID: submissionID, TrackID: trackID}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetSubmissionTrackIDs 获取作品参加的赛道ID列表
+func (s *TrackService) GetSubmissionTrackIDs(submissionID uint64) ([]uint64, error) {
+	trackIDs := make([]uint64, 0)
+	if err := database.DB.Model(&models.SubmissionTrack{}).Where("submission_id = ?", submissionID).
+		Pluck("track_id", &trackIDs).Error; err != nil {
+		return nil, err
+	}
+	return trackIDs, nil
+}
+
+// IsSubmissionInTrack 判断作品是否参加了指定赛道
+func (s *TrackService) IsSubmissionInTrack(submissionID, trackID uint64) bool {
+	var count int64
+	database.DB.Model(&models.SubmissionTrack{}).Where("submission_id = ? AND track_id = ?", submissionID, trackID).Count(&count)
+	return count > 0
+}
+
+// fillSubmissionTrackIDs 为作品列表填充 track_ids
+func (s *TrackService) fillSubmissionTrackIDs(submissions []models.Submission) {
+	if len(submissions) == 0 {
+		return
+	}
+	ids := make([]uint64, 0, len(submissions))
+	for _, sub := range submissions {
+		ids = append(ids, sub.ID)
+	}
+	var links []models.SubmissionTrack
+	if err := database.DB.Where("submission_id IN ?", ids).Find(&links).Error; err != nil {
+		return
+	}
+	trackMap := make(map[uint64][]uint64)
+	for _, link := range links {
+		trackMap[link.SubmissionID] = append(trackMap[link.SubmissionID], link.TrackID)
+	}
+	for i := range submissions {
+		submissions[i].TrackIDs = trackMap[submissions[i].ID]
+	}
+}
+
+// validateAwardTracks 校验奖项关联的赛道均属于该活动
+func (s *TrackService) validateAwardTracks(tx *gorm.DB, hackathonID uint64, awards []models.HackathonAward) error {
+	trackIDs := make([]uint64, 0)
+	for _, award := range awards {
+		if award.TrackID != nil {
+			trackIDs = append(trackIDs, *award.TrackID)
+		}
+	}
+	if err := s.validateTrackIDs(tx, hackathonID, trackIDs); err != nil {
+		return fmt.Errorf("奖项关联的%w", err)
+	}
+	return nil
+}
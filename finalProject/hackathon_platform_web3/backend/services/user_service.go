@@ -1,9 +1,16 @@
 package services
 
 import (
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"net/url"
+	"strings"
+	"time"
 
+	"hackathon-backend/config"
 	"hackathon-backend/database"
 	"hackathon-backend/models"
 	"hackathon-backend/utils"
@@ -13,6 +20,9 @@ import (
 
 type UserService struct{}
 
+// passwordSetupTokenTTL 一次性设置密码链接有效期
+const passwordSetupTokenTTL = 72 * time.Hour
+
 // Login 用户登录（手机号+密码）
 func (s *UserService) Login(phone, password string) (*models.User, string, error) {
 	var user models.User
@@ -341,3 +351,84 @@ func (s *UserService) UpdateCurrentUser(userID uint64, updates map[string]interf
 
 	return database.DB.Model(&models.User{}).Where("id = ? AND deleted_at IS NULL", userID).Updates(updates).Error
 }
+
+// issuePasswordSetupToken 在事务中为用户签发一次性设置密码凭证，返回凭证原文与过期时间（库中只保存哈希）
+func issuePasswordSetupToken(tx *gorm.DB, userID uint64) (string, time.Time, error) {
+	buf := make([]byte, 32)
+	if _, err := crand.Read(buf); err != nil {
+		return "", time.Time{}, err
+	}
+	token := hex.EncodeToString(buf)
+	expiresAt := time.Now().Add(passwordSetupTokenTTL)
+	record := models.PasswordSetupToken{
+		UserID:    userID,
+		TokenHash: hashPasswordSetupToken(token),
+		ExpiresAt: expiresAt,
+	}
+	if err := tx.Create(&record).Error; err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// hashPasswordSetupToken 计算凭证哈希
+func hashPasswordSetupToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// passwordSetupLink 生成设置密码页面链接
+func passwordSetupLink(token string) string {
+	base := config.AppConfig.Notification.PasswordSetupURL
+	sep := "?"
+	if strings.Contains(base, "?") {
+		sep = "&"
+	}
+	return base + sep + "token=" + url.QueryEscape(token)
+}
+
+// SetPasswordWithToken 使用一次性凭证设置登录密码，凭证使用后失效
+func (s *UserService) SetPasswordWithToken(token, newPassword string) error {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return errors.New("设置密码链接无效或已过期")
+	}
+	hashedPassword, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("密码加密失败: %w", err)
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var record models.PasswordSetupToken
+		if err := tx.Where("token_hash = ?", hashPasswordSetupToken(token)).First(&record).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("设置密码链接无效或已过期")
+			}
+			return err
+		}
+
+		// 条件更新领取凭证，并发请求只有一个能成功
+		now := time.Now()
+		result := tx.Model(&models.PasswordSetupToken{}).
+			Where("id = ? AND used_at IS NULL AND expires_at > ?", record.ID, now).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("设置密码链接无效或已过期")
+		}
+
+		result = tx.Model(&models.User{}).Where("id = ? AND deleted_at IS NULL", record.UserID).Update("password", hashedPassword)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("用户不存在")
+		}
+		// 同一用户的其他未使用凭证一并失效
+		return tx.Model(&models.PasswordSetupToken{}).
+			Where("user_id = ? AND used_at IS NULL", record.UserID).
+			Update("used_at", now).Error
+	})
+}
@@ -2,72 +2,323 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"sort"
+	"time"
 
 	"hackathon-backend/database"
 	"hackathon-backend/models"
+
+	"gorm.io/gorm"
 )
 
 type VoteService struct{}
 
-// Vote 投票
-func (s *VoteService) Vote(hackathonID, participantID, submissionID uint64) error {
+// checkVotingOpen 检查活动处于投票阶段且投票人已签到
+func (s *VoteService) checkVotingOpen(hackathonID, participantID uint64) (*models.Hackathon, error) {
 	// 检查活动状态
 	var hackathon models.Hackathon
 	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
-		return errors.New("活动不存在")
+		return nil, errors.New("活动不存在")
 	}
 
 	if hackathon.Status != "voting" {
-		return errors.New("当前不在投票阶段")
+		return nil, errors.New("当前不在投票阶段")
 	}
 
 	// 检查阶段时间
 	hackathonService := &HackathonService{}
 	inTime, err := hackathonService.CheckStageTime(hackathonID, "voting")
 	if err != nil {
-		return errors.New("投票阶段时间未设置")
+		return nil, errors.New("投票阶段时间未设置")
 	}
 	if !inTime {
-		return errors.New("不在投票时间范围内")
+		return nil, errors.New("不在投票时间范围内")
 	}
 
 	// 检查是否已签到
 	registrationService := &RegistrationService{}
 	checkedIn, _, err := registrationService.GetCheckinStatus(hackathonID, participantID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if !checkedIn {
-		return errors.New("请先完成签到")
+		return nil, errors.New("请先完成签到")
 	}
 
+	return &hackathon, nil
+}
+
+// checkVoteTarget 检查作品可被投票。不能给自己队伍的作品投票；活动设置了赛道时需指定 trackID，且作品必须参加了该赛道；未设置赛道时 trackID 为 0
+func (s *VoteService) checkVoteTarget(hackathonID, participantID, submissionID, trackID uint64) error {
 	// 检查作品是否存在
 	var submission models.Submission
 	if err := database.DB.Where("id = ? AND hackathon_id = ? AND draft = 0", submissionID, hackathonID).First(&submission).Error; err != nil {
 		return errors.New("作品不存在")
 	}
 
-	// 检查是否已投票
-	var existing models.Vote
-	if err := database.DB.Where("participant_id = ? AND submission_id = ?", participantID, submissionID).First(&existing).Error; err == nil {
-		return errors.New("您已经对该作品投过票了")
+	// 检查是否为自己队伍的作品
+	var memberCount int64
+	if err := database.DB.Model(&models.TeamMember{}).Where("team_id = ? AND participant_id = ?", submission.TeamID, participantID).
+		Count(&memberCount).Error; err != nil {
+		return err
+	}
+	if memberCount > 0 {
+		return errors.New("不能给自己队伍的作品投票")
+	}
+
+	// 检查赛道
+	trackService := &TrackService{}
+	hasTracks, err := trackService.HasTracks(hackathonID)
+	if err != nil {
+		return err
+	}
+	if hasTracks {
+		if trackID == 0 {
+			return errors.New("请选择投票的赛道")
+		}
+		if !trackService.IsSubmissionInTrack(submissionID, trackID) {
+			return errors.New("该作品未参加此赛道")
+		}
+	} else if trackID != 0 {
+		return errors.New("该活动未设置赛道")
+	}
+	return nil
+}
+
+// Vote 投票。weight 为票数，仅二次方投票可大于 1（消耗 weight² 积分）；排序投票需通过 SubmitBallot 提交完整选票
+func (s *VoteService) Vote(hackathonID, participantID, submissionID, trackID uint64, weight int) error {
+	hackathon, err := s.checkVotingOpen(hackathonID, participantID)
+	if err != nil {
+		return err
+	}
+
+	if IsRankedScheme(hackathon.VotingScheme) {
+		return errors.New("该活动为排序投票，请提交排序选票")
+	}
+	if weight <= 0 {
+		weight = 1
+	}
+	if weight > 1 && hackathon.VotingScheme != VotingSchemeQuadratic {
+		return errors.New("该活动每个作品只能投一票")
+	}
+
+	if err := s.checkVoteTarget(hackathonID, participantID, submissionID, trackID); err != nil {
+		return err
+	}
+
+	sybilWeight, err := s.getSybilWeight(hackathon, participantID)
+	if err != nil {
+		return err
+	}
+
+	// 锁定投票人的报名记录，使同一投票人的并发投票串行执行，额度检查与写入在同一事务中
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockRegistration(tx, hackathonID, participantID); err != nil {
+			return err
+		}
+
+		// 检查是否已投票
+		var existing models.Vote
+		if err := tx.Where("participant_id = ? AND submission_id = ? AND track_id = ?", participantID, submissionID, trackID).First(&existing).Error; err == nil {
+			return errors.New("您已经对该作品投过票了")
+		}
+
+		// 检查票数上限与积分预算
+		usedVotes, usedCredits, err := s.getUsage(tx, hackathonID, participantID)
+		if err != nil {
+			return err
+		}
+		if err := checkVoteBudget(hackathon, usedVotes, usedCredits, weight); err != nil {
+			return err
+		}
+
+		// 创建投票记录
+		vote := models.Vote{
+			HackathonID:   hackathonID,
+			ParticipantID: participantID,
+			SubmissionID:  submissionID,
+			TrackID:       trackID,
+			Weight:        weight,
+			SybilWeight:   sybilWeight,
+		}
+		return tx.Create(&vote).Error
+	}); err != nil {
+		return err
+	}
+	scheduleVoteTally(hackathonID)
+	return nil
+}
+
+// checkVoteBudget 检查再投一票（weight 票）是否超出票数上限（限制票数投票）或积分预算（二次方投票，消耗 weight² 积分）
+func checkVoteBudget(hackathon *models.Hackathon, usedVotes, usedCredits int64, weight int) error {
+	switch hackathon.VotingScheme {
+	case VotingSchemeLimited:
+		if usedVotes >= int64(hackathon.VoteLimit) {
+			return fmt.Errorf("每人最多投%d票", hackathon.VoteLimit)
+		}
+	case VotingSchemeQuadratic:
+		if usedCredits+int64(weight*weight) > int64(hackathon.CreditBudget) {
+			return fmt.Errorf("投票积分不足，剩余%d积分，投%d票需%d积分", int64(hackathon.CreditBudget)-usedCredits, weight, weight*weight)
+		}
+	}
+	return nil
+}
+
+// SubmitBallot 提交排序选票（仅排序投票），submissionIDs 按偏好从高到低排列，覆盖该赛道下之前的选票
+func (s *VoteService) SubmitBallot(hackathonID, participantID, trackID uint64, submissionIDs []uint64) error {
+	hackathon, err := s.checkVotingOpen(hackathonID, participantID)
+	if err != nil {
+		return err
+	}
+
+	if !IsRankedScheme(hackathon.VotingScheme) {
+		return errors.New("该活动不是排序投票")
+	}
+	if len(submissionIDs) == 0 {
+		return errors.New("选票不能为空")
+	}
+	if hackathon.VoteLimit > 0 && len(submissionIDs) > hackathon.VoteLimit {
+		return fmt.Errorf("选票最多排序%d个作品", hackathon.VoteLimit)
+	}
+
+	seen := make(map[uint64]bool, len(submissionIDs))
+	for _, submissionID := range submissionIDs {
+		if seen[submissionID] {
+			return errors.New("选票中的作品不能重复")
+		}
+		seen[submissionID] = true
+		if err := s.checkVoteTarget(hackathonID, participantID, submissionID, trackID); err != nil {
+			return err
+		}
+	}
+
+	sybilWeight, err := s.getSybilWeight(hackathon, participantID)
+	if err != nil {
+		return err
+	}
+
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// 锁定报名记录，同一投票人并发提交选票时串行覆盖
+		if err := lockRegistration(tx, hackathonID, participantID); err != nil {
+			return err
+		}
+		if err := tx.Where("hackathon_id = ? AND participant_id = ? AND track_id = ?", hackathonID, participantID, trackID).
+			Delete(&models.Vote{}).Error; err != nil {
+			return err
+		}
+		for i, submissionID := range submissionIDs {
+			vote := models.Vote{
+				HackathonID:   hackathonID,
+				ParticipantID: participantID,
+				SubmissionID:  submissionID,
+				TrackID:       trackID,
+				Weight:        1,
+				Rank:          i + 1,
+				SybilWeight:   sybilWeight,
+			}
+			if err := tx.Create(&vote).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	scheduleVoteTally(hackathonID)
+	return nil
+}
+
+// getSybilWeight 获取投票人的防女巫加权系数。加权规则需查询链上数据，每个投票人在活动中只计算一次并缓存在报名记录上
+func (s *VoteService) getSybilWeight(hackathon *models.Hackathon, participantID uint64) (float64, error) {
+	var registration models.Registration
+	if err := database.DB.Select("id", "sybil_weight").
+		Where("hackathon_id = ? AND participant_id = ?", hackathon.ID, participantID).
+		First(&registration).Error; err != nil {
+		return 0, errors.New("您未报名该活动")
+	}
+	if registration.SybilWeight != nil {
+		return *registration.SybilWeight, nil
+	}
+
+	var participant models.Participant
+	if err := database.DB.Where("id = ?", participantID).First(&participant).Error; err != nil {
+		return 0, errors.New("参赛者不存在")
+	}
+	weight, _ := ComputeVoteWeight(hackathon, &participant)
+
+	// 并发首次投票时以先写入的系数为准
+	result := database.DB.Model(&models.Registration{}).
+		Where("id = ? AND sybil_weight IS NULL", registration.ID).
+		Update("sybil_weight", weight)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		if err := database.DB.Select("id", "sybil_weight").First(&registration, registration.ID).Error; err != nil {
+			return 0, err
+		}
+		if registration.SybilWeight != nil {
+			return *registration.SybilWeight, nil
+		}
+	}
+	return weight, nil
+}
+
+// getUsage 统计投票人在活动中已投票数与已消耗积分（积分按每条投票 weight² 计）
+func (s *VoteService) getUsage(tx *gorm.DB, hackathonID, participantID uint64) (int64, int64, error) {
+	var usage struct {
+		Votes   int64
+		Credits int64
 	}
+	if err := tx.Model(&models.Vote{}).
+		Select("COUNT(*) AS votes, COALESCE(SUM(weight * weight), 0) AS credits").
+		Where("hackathon_id = ? AND participant_id = ?", hackathonID, participantID).
+		Scan(&usage).Error; err != nil {
+		return 0, 0, err
+	}
+	return usage.Votes, usage.Credits, nil
+}
 
-	// 创建投票记录
-	vote := models.Vote{
-		HackathonID:   hackathonID,
-		ParticipantID: participantID,
-		SubmissionID:  submissionID,
+// GetVoteQuota 获取投票人在活动中的投票方式与剩余额度
+func (s *VoteService) GetVoteQuota(hackathonID, participantID uint64) (map[string]interface{}, error) {
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
+		return nil, errors.New("活动不存在")
 	}
 
-	return database.DB.Create(&vote).Error
+	usedVotes, usedCredits, err := s.getUsage(database.DB, hackathonID, participantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"voting_scheme": hackathon.VotingScheme,
+		"vote_limit":    hackathon.VoteLimit,
+		"credit_budget": hackathon.CreditBudget,
+		"used_votes":    usedVotes,
+		"used_credits":  usedCredits,
+	}, nil
+}
+
+// TallyVotes 按活动的投票方式计票。trackID 为 0 时统计所有赛道的投票，否则仅统计该赛道
+func (s *VoteService) TallyVotes(hackathon *models.Hackathon, trackID uint64, candidateIDs []uint64) (map[uint64]int64, error) {
+	query := database.DB.Where("hackathon_id = ?", hackathon.ID)
+	if trackID > 0 {
+		query = query.Where("track_id = ?", trackID)
+	}
+	var votes []models.Vote
+	if err := query.Find(&votes).Error; err != nil {
+		return nil, err
+	}
+	return NewTallier(hackathon.VotingScheme).Tally(candidateIDs, votes), nil
 }
 
-// CancelVote 取消投票
-func (s *VoteService) CancelVote(participantID, submissionID uint64) error {
+// CancelVote 取消投票（trackID 为投票时指定的赛道，未分赛道为 0）
+func (s *VoteService) CancelVote(participantID, submissionID, trackID uint64) error {
 	// 检查活动状态
 	var vote models.Vote
-	if err := database.DB.Where("participant_id = ? AND submission_id = ?", participantID, submissionID).First(&vote).Error; err != nil {
+	if err := database.DB.Where("participant_id = ? AND submission_id = ? AND track_id = ?", participantID, submissionID, trackID).First(&vote).Error; err != nil {
 		return errors.New("投票记录不存在")
 	}
 
@@ -91,7 +342,11 @@ func (s *VoteService) CancelVote(participantID, submissionID uint64) error {
 	}
 
 	// 删除投票记录
-	return database.DB.Where("participant_id = ? AND submission_id = ?", participantID, submissionID).Delete(&models.Vote{}).Error
+	if err := database.DB.Delete(&vote).Error; err != nil {
+		return err
+	}
+	scheduleVoteTally(vote.HackathonID)
+	return nil
 }
 
 // GetMyVotes 获取我的投票记录
@@ -106,7 +361,7 @@ func (s *VoteService) GetMyVotes(hackathonID, participantID uint64) ([]models.Vo
 	return votes, nil
 }
 
-// GetVoteCount 获取作品得票数
+// GetVoteCount 获取作品得票数（所有赛道合计）
 func (s *VoteService) GetVoteCount(submissionID uint64) (int64, error) {
 	var count int64
 	if err := database.DB.Model(&models.Vote{}).Where("submission_id = ?", submissionID).Count(&count).Error; err != nil {
@@ -115,8 +370,18 @@ func (s *VoteService) GetVoteCount(submissionID uint64) (int64, error) {
 	return count, nil
 }
 
-// GetResults 获取比赛结果
-func (s *VoteService) GetResults(hackathonID uint64) ([]map[string]interface{}, error) {
+// GetTrackVoteCount 获取作品在指定赛道的得票数
+func (s *VoteService) GetTrackVoteCount(submissionID, trackID uint64) (int64, error) {
+	var count int64
+	if err := database.DB.Model(&models.Vote{}).Where("submission_id = ? AND track_id = ?", submissionID, trackID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetResults 获取比赛结果。trackID 为 0 时返回全场排名（所有赛道得票合计、全场奖项），否则返回该赛道的排名与赛道奖项。
+// 排名与获奖读取结果引擎落库的 hackathon_results
+func (s *VoteService) GetResults(hackathonID, trackID uint64) ([]map[string]interface{}, error) {
 	// 检查活动状态
 	var hackathon models.Hackathon
 	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
@@ -127,62 +392,202 @@ func (s *VoteService) GetResults(hackathonID uint64) ([]map[string]interface{},
 		return nil, errors.New("结果尚未公布")
 	}
 
-	// 获取所有提交的作品及其得票数
-	var submissions []models.Submission
-	if err := database.DB.Preload("Team").Preload("Team.Members").Preload("Team.Members.Participant").
-		Where("hackathon_id = ? AND draft = 0", hackathonID).Find(&submissions).Error; err != nil {
-		return nil, err
+	if trackID > 0 {
+		trackService := &TrackService{}
+		if _, err := trackService.GetTrack(hackathonID, trackID); err != nil {
+			return nil, err
+		}
 	}
 
-	// 获取奖项设置
-	var awards []models.HackathonAward
-	if err := database.DB.Where("hackathon_id = ?", hackathonID).Order("`rank` ASC").Find(&awards).Error; err != nil {
+	resultService := &ResultService{}
+	records, err := resultService.GetResultRecords(hackathonID, trackID)
+	if err != nil {
 		return nil, err
 	}
 
-	// 计算每个作品的得票数并排序
-	type SubmissionWithVotes struct {
-		Submission models.Submission
-		VoteCount  int64
+	// 构建结果
+	results := make([]map[string]interface{}, 0, len(records))
+	for _, record := range records {
+		result := map[string]interface{}{
+			"rank":            record.Rank,
+			"team":            record.Team,
+			"submission":      record.Submission,
+			"vote_count":      record.VoteCount,
+			"judge_score":     record.JudgeScore,
+			"score":           record.Score,
+			"tie_break":       record.TieBreak,
+			"overridden":      record.Overridden,
+			"override_reason": record.OverrideReason,
+			"award":           nil,
+		}
+		if record.Award != nil {
+			result["award"] = *record.Award
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// GetTrackResults 获取活动各赛道的比赛结果
+func (s *VoteService) GetTrackResults(hackathonID uint64) ([]map[string]interface{}, error) {
+	trackService := &TrackService{}
+	tracks, err := trackService.GetTracks(hackathonID)
+	if err != nil {
+		return nil, err
 	}
 
-	var submissionsWithVotes []SubmissionWithVotes
-	for _, submission := range submissions {
-		voteCount, _ := s.GetVoteCount(submission.ID)
-		submissionsWithVotes = append(submissionsWithVotes, SubmissionWithVotes{
-			Submission: submission,
-			VoteCount:  voteCount,
+	trackResults := make([]map[string]interface{}, 0, len(tracks))
+	for _, track := range tracks {
+		rankings, err := s.GetResults(hackathonID, track.ID)
+		if err != nil {
+			return nil, err
+		}
+		trackResults = append(trackResults, map[string]interface{}{
+			"track":    track,
+			"rankings": rankings,
 		})
 	}
+	return trackResults, nil
+}
 
-	// 按得票数排序（降序）
-	for i := 0; i < len(submissionsWithVotes)-1; i++ {
-		for j := i + 1; j < len(submissionsWithVotes); j++ {
-			if submissionsWithVotes[i].VoteCount < submissionsWithVotes[j].VoteCount {
-				submissionsWithVotes[i], submissionsWithVotes[j] = submissionsWithVotes[j], submissionsWithVotes[i]
-			}
+// 可疑投票团伙判定阈值
+const (
+	suspiciousClusterMinSize = 3                // 团伙最少人数
+	burstRegistrationWindow  = 10 * time.Minute // 集中注册时间窗口
+	lowSybilWeight           = 0.6              // 低权重投票阈值
+)
+
+// GetSuspiciousVoteClusters 获取活动中的可疑投票团伙（Admin 查看）：
+// identical_votes - 多名投票人的投票选择完全相同；
+// burst_registration - 同一作品的投票人在短时间内集中注册；
+// low_weight_concentration - 作品的投票大多来自低权重（疑似女巫）钱包
+func (s *VoteService) GetSuspiciousVoteClusters(hackathonID uint64) ([]map[string]interface{}, error) {
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
+		return nil, errors.New("活动不存在")
+	}
+
+	var votes []models.Vote
+	if err := database.DB.Preload("Participant").Where("hackathon_id = ?", hackathonID).
+		Order("participant_id ASC, track_id ASC, submission_id ASC").Find(&votes).Error; err != nil {
+		return nil, err
+	}
+
+	participants := make(map[uint64]models.Participant)
+	participantVotes := make(map[uint64][]models.Vote)
+	submissionVotes := make(map[uint64][]models.Vote)
+	participantOrder := make([]uint64, 0)
+	submissionOrder := make([]uint64, 0)
+	for _, vote := range votes {
+		if _, ok := participants[vote.ParticipantID]; !ok {
+			participants[vote.ParticipantID] = vote.Participant
+			participantOrder = append(participantOrder, vote.ParticipantID)
 		}
+		if _, ok := submissionVotes[vote.SubmissionID]; !ok {
+			submissionOrder = append(submissionOrder, vote.SubmissionID)
+		}
+		participantVotes[vote.ParticipantID] = append(participantVotes[vote.ParticipantID], vote)
+		submissionVotes[vote.SubmissionID] = append(submissionVotes[vote.SubmissionID], vote)
 	}
 
-	// 构建结果
-	results := make([]map[string]interface{}, 0)
-	for rank, item := range submissionsWithVotes {
-		result := map[string]interface{}{
-			"rank":       rank + 1,
-			"team":       item.Submission.Team,
-			"submission": item.Submission,
-			"vote_count": item.VoteCount,
-			"award":      nil,
+	describe := func(participantIDs []uint64) []map[string]interface{} {
+		list := make([]map[string]interface{}, 0, len(participantIDs))
+		for _, id := range participantIDs {
+			participant := participants[id]
+			list = append(list, map[string]interface{}{
+				"participant_id": id,
+				"wallet_address": participant.WalletAddress,
+				"wallet_type":    participant.WalletType,
+				"registered_at":  participant.CreatedAt,
+				"sybil_weight":   voteWeight(participantVotes[id][0]),
+			})
+		}
+		return list
+	}
+
+	clusters := make([]map[string]interface{}, 0)
+
+	// 投票选择完全相同（投票已按赛道、作品排序，直接拼接为签名）
+	signatureGroups := make(map[string][]uint64)
+	signatureOrder := make([]string, 0)
+	for _, participantID := range participantOrder {
+		signature := ""
+		for _, vote := range participantVotes[participantID] {
+			signature += fmt.Sprintf("%d:%d:%d:%d;", vote.TrackID, vote.SubmissionID, vote.Weight, vote.Rank)
+		}
+		if _, ok := signatureGroups[signature]; !ok {
+			signatureOrder = append(signatureOrder, signature)
+		}
+		signatureGroups[signature] = append(signatureGroups[signature], participantID)
+	}
+	for _, signature := range signatureOrder {
+		group := signatureGroups[signature]
+		if len(group) < suspiciousClusterMinSize {
+			continue
+		}
+		submissionIDs := make([]uint64, 0)
+		for _, vote := range participantVotes[group[0]] {
+			submissionIDs = append(submissionIDs, vote.SubmissionID)
 		}
+		clusters = append(clusters, map[string]interface{}{
+			"type":           "identical_votes",
+			"reason":         fmt.Sprintf("%d名投票人的投票选择完全相同", len(group)),
+			"submission_ids": submissionIDs,
+			"participants":   describe(group),
+		})
+	}
+
+	for _, submissionID := range submissionOrder {
+		subVotes := submissionVotes[submissionID]
 
-		// 分配奖项
-		if rank < len(awards) {
-			result["award"] = awards[rank]
+		// 投票人集中注册：按注册时间排序后，查找时间窗口内人数最多的一组
+		voters := make([]uint64, 0, len(subVotes))
+		seen := make(map[uint64]bool)
+		for _, vote := range subVotes {
+			if !seen[vote.ParticipantID] {
+				seen[vote.ParticipantID] = true
+				voters = append(voters, vote.ParticipantID)
+			}
+		}
+		sort.Slice(voters, func(i, j int) bool {
+			return participants[voters[i]].CreatedAt.Before(participants[voters[j]].CreatedAt)
+		})
+		bestStart, bestEnd := 0, 0
+		for start, end := 0, 0; end < len(voters); end++ {
+			for participants[voters[end]].CreatedAt.Sub(participants[voters[start]].CreatedAt) > burstRegistrationWindow {
+				start++
+			}
+			if end-start > bestEnd-bestStart {
+				bestStart, bestEnd = start, end
+			}
+		}
+		if len(voters) > 0 && bestEnd-bestStart+1 >= suspiciousClusterMinSize {
+			group := voters[bestStart : bestEnd+1]
+			clusters = append(clusters, map[string]interface{}{
+				"type":           "burst_registration",
+				"reason":         fmt.Sprintf("%d名投票人在%d分钟内集中注册并投票给同一作品", len(group), int(burstRegistrationWindow.Minutes())),
+				"submission_ids": []uint64{submissionID},
+				"participants":   describe(group),
+			})
 		}
 
-		results = append(results, result)
+		// 低权重钱包集中投票
+		lowWeightVoters := make([]uint64, 0)
+		for _, participantID := range voters {
+			if voteWeight(participantVotes[participantID][0]) < lowSybilWeight {
+				lowWeightVoters = append(lowWeightVoters, participantID)
+			}
+		}
+		if len(lowWeightVoters) >= suspiciousClusterMinSize && len(lowWeightVoters)*2 > len(voters) {
+			clusters = append(clusters, map[string]interface{}{
+				"type":           "low_weight_concentration",
+				"reason":         fmt.Sprintf("作品的%d名投票人中有%d名为低权重钱包", len(voters), len(lowWeightVoters)),
+				"submission_ids": []uint64{submissionID},
+				"participants":   describe(lowWeightVoters),
+			})
+		}
 	}
 
-	return results, nil
+	return clusters, nil
 }
-
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"testing"
+
+	"hackathon-backend/models"
+)
+
+func TestCheckVoteBudget(t *testing.T) {
+	limited := &models.Hackathon{VotingScheme: VotingSchemeLimited, VoteLimit: 3}
+	quadratic := &models.Hackathon{VotingScheme: VotingSchemeQuadratic, CreditBudget: 10}
+	tests := []struct {
+		name        string
+		hackathon   *models.Hackathon
+		usedVotes   int64
+		usedCredits int64
+		weight      int
+		wantErr     bool
+	}{
+		{name: "limited first vote", hackathon: limited, weight: 1},
+		{name: "limited last vote", hackathon: limited, usedVotes: 2, usedCredits: 2, weight: 1},
+		{name: "limited over limit", hackathon: limited, usedVotes: 3, usedCredits: 3, weight: 1, wantErr: true},
+		{name: "limited zero limit", hackathon: &models.Hackathon{VotingScheme: VotingSchemeLimited}, weight: 1, wantErr: true},
+		{name: "quadratic single vote", hackathon: quadratic, weight: 1},
+		{name: "quadratic exact budget", hackathon: quadratic, usedVotes: 1, usedCredits: 1, weight: 3},
+		{name: "quadratic over budget", hackathon: quadratic, usedVotes: 1, usedCredits: 2, weight: 3, wantErr: true},
+		{name: "quadratic weight alone over budget", hackathon: quadratic, weight: 4, wantErr: true},
+		{name: "simple scheme unlimited", hackathon: &models.Hackathon{VotingScheme: VotingSchemeSimple}, usedVotes: 100, usedCredits: 100, weight: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkVoteBudget(tt.hackathon, tt.usedVotes, tt.usedCredits, tt.weight)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkVoteBudget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
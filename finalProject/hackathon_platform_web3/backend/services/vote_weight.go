@@ -0,0 +1,157 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"hackathon-backend/models"
+	"hackathon-backend/solana"
+)
+
+// minVoteWeight 投票权重下限，加权仅降低可疑投票的影响，不完全作废
+const minVoteWeight = 0.1
+
+// VoteWeighter 投票防女巫加权规则，根据投票人的钱包与签到信息返回 0-1 的权重系数
+type VoteWeighter interface {
+	// Name 规则名称，活动通过 vote_weighters 按名称启用
+	Name() string
+	// Weigh 计算投票人的权重系数，无法判断时返回 1
+	Weigh(hackathon *models.Hackathon, participant *models.Participant) float64
+}
+
+// voteWeighters 已注册的加权规则
+var voteWeighters = make(map[string]VoteWeighter)
+
+// RegisterVoteWeighter 注册投票加权规则
+func RegisterVoteWeighter(w VoteWeighter) {
+	voteWeighters[w.Name()] = w
+}
+
+func init() {
+	RegisterVoteWeighter(walletTypeWeighter{})
+	RegisterVoteWeighter(attendanceWeighter{})
+	RegisterVoteWeighter(walletAgeWeighter{})
+}
+
+// parseVoteWeighters 解析活动启用的加权规则名称（逗号分隔）
+func parseVoteWeighters(value string) []string {
+	names := make([]string, 0)
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// validateVoteWeighters 校验活动启用的加权规则均已注册
+func validateVoteWeighters(value string) error {
+	for _, name := range parseVoteWeighters(value) {
+		if _, ok := voteWeighters[name]; !ok {
+			return errors.New("无效的投票加权规则: " + name)
+		}
+	}
+	return nil
+}
+
+// ComputeVoteWeight 按活动启用的加权规则计算投票人的权重（各规则系数相乘），并返回各规则的系数明细
+func ComputeVoteWeight(hackathon *models.Hackathon, participant *models.Participant) (float64, map[string]float64) {
+	weight := 1.0
+	details := make(map[string]float64)
+	for _, name := range parseVoteWeighters(hackathon.VoteWeighters) {
+		w, ok := voteWeighters[name]
+		if !ok {
+			continue
+		}
+		factor := w.Weigh(hackathon, participant)
+		if factor < 0 {
+			factor = 0
+		}
+		if factor > 1 {
+			factor = 1
+		}
+		details[name] = factor
+		weight *= factor
+	}
+	if weight < minVoteWeight {
+		weight = minVoteWeight
+	}
+	return weight, details
+}
+
+// walletTypeWeighter 按钱包类型加权：Phantom（Solana）钱包可在链上核验签到与活跃度，其他钱包降低权重
+type walletTypeWeighter struct{}
+
+func (walletTypeWeighter) Name() string { return "wallet_type" }
+
+func (walletTypeWeighter) Weigh(hackathon *models.Hackathon, participant *models.Participant) float64 {
+	if participant.WalletType == "phantom" {
+		return 1
+	}
+	return 0.8
+}
+
+// attendanceWeighter 按链上签到加权：活动已通过 upload_check_ins 上链签到名单时，未在名单中的 Phantom 钱包降低权重
+type attendanceWeighter struct{}
+
+func (attendanceWeighter) Name() string { return "attendance" }
+
+func (attendanceWeighter) Weigh(hackathon *models.Hackathon, participant *models.Participant) float64 {
+	chainAddr := strings.TrimSpace(hackathon.ChainActivityAddress)
+	if participant.WalletType != "phantom" || chainAddr == "" {
+		return 1
+	}
+	programID, rpcURL, err := solana.PreparePublishConfig()
+	if err != nil {
+		return 1
+	}
+	attendees, err := solana.FetchCheckIns(rpcURL, programID, chainAddr)
+	if err != nil || len(attendees) == 0 {
+		return 1
+	}
+	for _, attendee := range attendees {
+		if attendee == participant.WalletAddress {
+			return 1
+		}
+	}
+	return 0.5
+}
+
+// walletNewAge 首笔交易在此时长内的钱包视为新钱包
+const walletNewAge = 30 * 24 * time.Hour
+
+// walletAgeWeighter 按钱包年龄与链上活跃度加权（通过 Solana RPC 查询，仅适用于 Phantom 钱包）
+type walletAgeWeighter struct{}
+
+func (walletAgeWeighter) Name() string { return "wallet_age" }
+
+func (walletAgeWeighter) Weigh(hackathon *models.Hackathon, participant *models.Participant) float64 {
+	if participant.WalletType != "phantom" {
+		return 1
+	}
+	_, rpcURL, err := solana.PreparePublishConfig()
+	if err != nil {
+		return 1
+	}
+	activity, err := solana.FetchWalletActivity(rpcURL, participant.WalletAddress, walletNewAge)
+	if err != nil {
+		return 1
+	}
+	switch {
+	case activity.Truncated:
+		// 交易多到查询上限仍未查完历史，视为老钱包
+		return 1
+	case activity.TxCount == 0 || activity.FirstTxTime == nil:
+		// 没有任何链上交易的新钱包
+		return 0.3
+	case time.Since(*activity.FirstTxTime) < walletNewAge:
+		// 30 天内创建的钱包
+		return 0.6
+	case activity.TxCount < 5:
+		// 交易很少的钱包
+		return 0.8
+	default:
+		return 1
+	}
+}
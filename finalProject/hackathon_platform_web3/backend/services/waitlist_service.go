@@ -0,0 +1,373 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"hackathon-backend/database"
+	"hackathon-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WaitlistService 报名候补：名额已满时排队，有名额空出时按顺序递补，被递补者需在确认期限内确认
+type WaitlistService struct{}
+
+const (
+	waitlistOfferWindow  = 24 * time.Hour // 递补后的确认期限（不晚于报名阶段结束时间）
+	waitlistPollInterval = time.Minute    // 检查过期递补的间隔
+)
+
+// lockHackathon 在事务中锁定活动行，使同一活动的报名、取消与递补串行执行，避免并发超额报名
+func lockHackathon(tx *gorm.DB, hackathonID uint64) (*models.Hackathon, error) {
+	var hackathon models.Hackathon
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
+		return nil, errors.New("活动不存在")
+	}
+	return &hackathon, nil
+}
+
+// occupiedSeats 已占用的名额：报名人数（不含审核未通过的）+ 递补待确认人数
+func occupiedSeats(tx *gorm.DB, hackathonID uint64) (int64, error) {
+	var registered, offered int64
+	if err := tx.Model(&models.Registration{}).Where("hackathon_id = ? AND status <> ?", hackathonID, "rejected").Count(&registered).Error; err != nil {
+		return 0, err
+	}
+	if err := tx.Model(&models.WaitlistEntry{}).Where("hackathon_id = ? AND status = ?", hackathonID, "offered").Count(&offered).Error; err != nil {
+		return 0, err
+	}
+	return registered + offered, nil
+}
+
+// promoteWaitlist 使超过确认期限的递补失效（归还其核销的邀请码），并按排队顺序将候补者递补到空出的名额（仅报名阶段）
+func promoteWaitlist(tx *gorm.DB, hackathon *models.Hackathon) error {
+	now := time.Now()
+	var expired []models.WaitlistEntry
+	if err := tx.Where("hackathon_id = ? AND status = ? AND offer_expires_at <= ?", hackathon.ID, "offered", now).
+		Find(&expired).Error; err != nil {
+		return err
+	}
+	for _, entry := range expired {
+		if err := tx.Model(&entry).Updates(map[string]interface{}{
+			"status":         "expired",
+			"invite_code_id": nil,
+		}).Error; err != nil {
+			return err
+		}
+		if err := releaseInviteCode(tx, entry.InviteCodeID); err != nil {
+			return err
+		}
+	}
+
+	if hackathon.Status != "registration" {
+		return nil
+	}
+
+	// 改为不限人数时递补全部候补者
+	slots := int64(-1)
+	if hackathon.MaxParticipants > 0 {
+		seats, err := occupiedSeats(tx, hackathon.ID)
+		if err != nil {
+			return err
+		}
+		if seats >= int64(hackathon.MaxParticipants) {
+			return nil
+		}
+		slots = int64(hackathon.MaxParticipants) - seats
+	}
+
+	expiresAt := now.Add(waitlistOfferWindow)
+	var stage models.HackathonStage
+	if err := tx.Where("hackathon_id = ? AND stage = ?", hackathon.ID, "registration").First(&stage).Error; err == nil && stage.EndTime.Before(expiresAt) {
+		expiresAt = stage.EndTime
+	}
+	if !expiresAt.After(now) {
+		return nil
+	}
+
+	var next []models.WaitlistEntry
+	query := tx.Where("hackathon_id = ? AND status = ?", hackathon.ID, "waiting").Order("position ASC, id ASC")
+	if slots > 0 {
+		query = query.Limit(int(slots))
+	}
+	if err := query.Find(&next).Error; err != nil {
+		return err
+	}
+	if len(next) == 0 {
+		return nil
+	}
+	offeredIDs := make([]uint64, 0, len(next))
+	for _, entry := range next {
+		if err := tx.Model(&entry).Updates(map[string]interface{}{
+			"status":           "offered",
+			"offered_at":       now,
+			"offer_expires_at": expiresAt,
+		}).Error; err != nil {
+			return err
+		}
+		offeredIDs = append(offeredIDs, entry.ParticipantID)
+	}
+	return notifyParticipants(tx, offeredIDs, hackathon.ID, NotifyWaitlistOffered, map[string]interface{}{
+		"HackathonName": hackathon.Name,
+		"ExpiresAt":     expiresAt.Format("2006-01-02 15:04 MST"),
+	})
+}
+
+// StartWaitlistWorker 启动候补递补的定期处理：过期未确认的递补及时失效并递补给下一位，不依赖有人访问候补名单
+func StartWaitlistWorker() {
+	go func() {
+		ticker := time.NewTicker(waitlistPollInterval)
+		defer ticker.Stop()
+		for {
+			sweepWaitlistOffers()
+			<-ticker.C
+		}
+	}()
+}
+
+// sweepWaitlistOffers 处理存在过期递补的活动
+func sweepWaitlistOffers() {
+	var hackathonIDs []uint64
+	if err := database.DB.Model(&models.WaitlistEntry{}).
+		Where("status = ? AND offer_expires_at <= ?", "offered", time.Now()).
+		Distinct().Pluck("hackathon_id", &hackathonIDs).Error; err != nil {
+		log.Printf("查询过期递补失败: %v", err)
+		return
+	}
+	for _, hackathonID := range hackathonIDs {
+		if err := syncWaitlist(hackathonID); err != nil {
+			log.Printf("活动 %d 处理候补递补失败: %v", hackathonID, err)
+		}
+	}
+}
+
+// syncWaitlist 处理过期的递补并递补空出的名额
+func syncWaitlist(hackathonID uint64) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		hackathon, err := lockHackathon(tx, hackathonID)
+		if err != nil {
+			return err
+		}
+		return promoteWaitlist(tx, hackathon)
+	})
+}
+
+// joinWaitlist 将参赛者加入候补名单末尾（之前的递补已过期时重新排队），inviteCodeID 为本次核销的邀请码
+func joinWaitlist(tx *gorm.DB, hackathonID, participantID uint64, inviteCodeID *uint64) (*models.WaitlistEntry, error) {
+	var maxPosition int
+	if err := tx.Model(&models.WaitlistEntry{}).Where("hackathon_id = ?", hackathonID).
+		Select("COALESCE(MAX(position), 0)").Scan(&maxPosition).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("hackathon_id = ? AND participant_id = ? AND status = ?", hackathonID, participantID, "expired").
+		Delete(&models.WaitlistEntry{}).Error; err != nil {
+		return nil, err
+	}
+
+	entry := models.WaitlistEntry{
+		HackathonID:   hackathonID,
+		ParticipantID: participantID,
+		Position:      maxPosition + 1,
+		Status:        "waiting",
+		InviteCodeID:  inviteCodeID,
+	}
+	if err := tx.Create(&entry).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// fillQueuePositions 计算候补中的参赛者当前排第几位
+func fillQueuePositions(tx *gorm.DB, entries []models.WaitlistEntry) error {
+	for i := range entries {
+		if entries[i].Status != "waiting" {
+			continue
+		}
+		var ahead int64
+		if err := tx.Model(&models.WaitlistEntry{}).
+			Where("hackathon_id = ? AND status = ? AND (position < ? OR (position = ? AND id < ?))",
+				entries[i].HackathonID, "waiting", entries[i].Position, entries[i].Position, entries[i].ID).
+			Count(&ahead).Error; err != nil {
+			return err
+		}
+		entries[i].QueuePosition = int(ahead) + 1
+	}
+	return nil
+}
+
+// GetMyEntry 获取参赛者在活动候补名单中的记录，不在候补名单时返回 nil
+func (s *WaitlistService) GetMyEntry(hackathonID, participantID uint64) (*models.WaitlistEntry, error) {
+	if err := syncWaitlist(hackathonID); err != nil {
+		return nil, err
+	}
+
+	var entry models.WaitlistEntry
+	err := database.DB.Where("hackathon_id = ? AND participant_id = ?", hackathonID, participantID).First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []models.WaitlistEntry{entry}
+	if err := fillQueuePositions(database.DB, entries); err != nil {
+		return nil, err
+	}
+	return &entries[0], nil
+}
+
+// ConfirmOffer 被递补的参赛者在确认期限内确认报名
+func (s *WaitlistService) ConfirmOffer(hackathonID, participantID uint64) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		hackathon, err := lockHackathon(tx, hackathonID)
+		if err != nil {
+			return err
+		}
+		if hackathon.Status != "registration" {
+			return errors.New("当前不在报名阶段")
+		}
+		if err := promoteWaitlist(tx, hackathon); err != nil {
+			return err
+		}
+
+		var entry models.WaitlistEntry
+		if err := tx.Where("hackathon_id = ? AND participant_id = ?", hackathonID, participantID).First(&entry).Error; err != nil {
+			return errors.New("您不在候补名单中")
+		}
+		switch entry.Status {
+		case "waiting":
+			return errors.New("尚未轮到您递补，请耐心等待")
+		case "expired":
+			return errors.New("递补确认已过期")
+		}
+
+		// 报名表答案在候补时已提交，确认后关联到报名记录；候补时核销的邀请码转到报名记录上
+		registration := newRegistration(hackathon, participantID)
+		registration.InviteCodeID = entry.InviteCodeID
+		if err := tx.Create(&registration).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.RegistrationAnswer{}).Where("hackathon_id = ? AND participant_id = ?", hackathonID, participantID).
+			Update("registration_id", registration.ID).Error; err != nil {
+			return err
+		}
+		if err := notifyRegistrationConfirmed(tx, hackathon, &registration); err != nil {
+			return err
+		}
+		if err := emitRegistrationWebhook(tx, hackathon, &registration); err != nil {
+			return err
+		}
+		return tx.Delete(&entry).Error
+	})
+}
+
+// LeaveWaitlist 退出候补名单（删除已提交的报名表答案并归还核销的邀请码），放弃的递补名额会递补给下一位
+func (s *WaitlistService) LeaveWaitlist(hackathonID, participantID uint64) error {
+	var answers []models.RegistrationAnswer
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		hackathon, err := lockHackathon(tx, hackathonID)
+		if err != nil {
+			return err
+		}
+
+		var entry models.WaitlistEntry
+		if err := tx.Where("hackathon_id = ? AND participant_id = ?", hackathonID, participantID).First(&entry).Error; err != nil {
+			return errors.New("您不在候补名单中")
+		}
+		if err := tx.Delete(&entry).Error; err != nil {
+			return err
+		}
+		if err := releaseInviteCode(tx, entry.InviteCodeID); err != nil {
+			return err
+		}
+		if answers, err = deleteParticipantAnswers(tx, hackathonID, participantID); err != nil {
+			return err
+		}
+		return promoteWaitlist(tx, hackathon)
+	})
+	if err != nil {
+		return err
+	}
+	deleteAnswerFiles(answers)
+	return nil
+}
+
+// checkWaitlistManager 检查用户是否可以查看或调整活动候补名单
+func (s *WaitlistService) checkWaitlistManager(hackathonID, userID uint64, userRole string) error {
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
+		return errors.New("活动不存在")
+	}
+	if userRole != "admin" && hackathon.OrganizerID != userID {
+		return errors.New("只能管理自己创建活动的候补名单")
+	}
+	return nil
+}
+
+// GetWaitlist 获取活动候补名单（递补待确认的在前，其余按排队顺序）
+func (s *WaitlistService) GetWaitlist(hackathonID, userID uint64, userRole string) ([]models.WaitlistEntry, error) {
+	if err := s.checkWaitlistManager(hackathonID, userID, userRole); err != nil {
+		return nil, err
+	}
+	if err := syncWaitlist(hackathonID); err != nil {
+		return nil, err
+	}
+
+	var entries []models.WaitlistEntry
+	if err := database.DB.Where("hackathon_id = ?", hackathonID).Preload("Participant").
+		Order("FIELD(status, 'offered', 'waiting', 'expired'), position ASC, id ASC").
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	if err := fillQueuePositions(database.DB, entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// ReorderWaitlist 调整候补顺序（仅活动创建者），entryIDs 须包含全部候补中的记录，按新的顺序排列
+func (s *WaitlistService) ReorderWaitlist(hackathonID uint64, entryIDs []uint64, userID uint64, userRole string) error {
+	if userRole == "admin" {
+		return errors.New("Admin不能调整候补名单")
+	}
+	if err := s.checkWaitlistManager(hackathonID, userID, userRole); err != nil {
+		return err
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockHackathon(tx, hackathonID); err != nil {
+			return err
+		}
+
+		var waitingIDs []uint64
+		if err := tx.Model(&models.WaitlistEntry{}).Where("hackathon_id = ? AND status = ?", hackathonID, "waiting").
+			Pluck("id", &waitingIDs).Error; err != nil {
+			return err
+		}
+		waiting := make(map[uint64]bool, len(waitingIDs))
+		for _, id := range waitingIDs {
+			waiting[id] = true
+		}
+		if len(entryIDs) != len(waitingIDs) {
+			return errors.New("候补名单已变化，请刷新后重试")
+		}
+		seen := make(map[uint64]bool, len(entryIDs))
+		for _, id := range entryIDs {
+			if !waiting[id] || seen[id] {
+				return errors.New("候补名单已变化，请刷新后重试")
+			}
+			seen[id] = true
+		}
+
+		for i, id := range entryIDs {
+			if err := tx.Model(&models.WaitlistEntry{}).Where("id = ?", id).Update("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,699 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"hackathon-backend/database"
+	"hackathon-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// WebhookService 外部系统集成：业务操作在同一事务中写入投递记录，由后台任务签名投递，失败后按指数退避重试
+type WebhookService struct{}
+
+// Webhook 事件类型
+const (
+	WebhookParticipantRegistered = "participant.registered"
+	WebhookTeamCreated           = "team.created"
+	WebhookSubmissionCreated     = "submission.created"
+	WebhookSubmissionUpdated     = "submission.updated"
+	WebhookSponsorApproved       = "sponsor.approved"
+	WebhookResultsPublished      = "results.published"
+	webhookMaxAttempts           = 8
+	webhookDeliveryLease         = 5 * time.Minute // 投递被领取后的时限，超时未完成（如进程退出）可被再次领取
+	webhookPollInterval          = 10 * time.Second
+	webhookRequestTimeout        = 10 * time.Second
+	webhookResponseLimit         = 2000 // 读取的响应内容上限（字节）
+	webhookResponseBodyLimit     = 256  // 记录到投递记录的响应内容上限（字节），只保留开头一段便于排查
+	webhookBatchSize             = 50
+)
+
+// WebhookEventType 可订阅的 Webhook 事件
+type WebhookEventType struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+var webhookEventTypes = []WebhookEventType{
+	{Type: WebhookParticipantRegistered, Name: "参赛者报名", Description: "参赛者报名成功或报名申请审核通过"},
+	{Type: WebhookTeamCreated, Name: "队伍创建", Description: "参赛者创建队伍"},
+	{Type: WebhookSubmissionCreated, Name: "作品提交", Description: "队伍首次提交作品"},
+	{Type: WebhookSubmissionUpdated, Name: "作品更新", Description: "队伍修改已提交的作品"},
+	{Type: WebhookSponsorApproved, Name: "赞助商审核通过", Description: "赞助申请或已开通赞助商的追加赞助申请审核通过，订阅了所赞助活动的 Webhook 同样会收到"},
+	{Type: WebhookResultsPublished, Name: "结果公布", Description: "活动进入公布结果阶段，附带各榜单排名"},
+}
+
+// webhookEnvelope Webhook 请求体
+type webhookEnvelope struct {
+	ID        string      `json:"id"` // 事件ID，同一事件的重试与重放保持不变
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookInput 创建或修改 Webhook 的参数
+type WebhookInput struct {
+	HackathonID *uint64  `json:"hackathon_id"` // 仅创建时有效，为空表示订阅全平台事件（仅 Admin）
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	Description string   `json:"description"`
+	Enabled     *bool    `json:"enabled"`
+}
+
+var webhookClient = &http.Client{
+	Timeout: webhookRequestTimeout,
+	// 不使用环境变量中的代理，连接前校验解析出的地址，防止回调地址指向内网（SSRF）
+	Transport: &http.Transport{
+		Proxy:               nil,
+		DialContext:         dialPublicOnly,
+		TLSHandshakeTimeout: webhookRequestTimeout,
+		MaxIdleConns:        20,
+		IdleConnTimeout:     90 * time.Second,
+	},
+	// 不跟随重定向，接收方应直接返回 2xx
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// webhookDialer 投递 Webhook 使用的底层拨号器
+var webhookDialer = &net.Dialer{Timeout: webhookRequestTimeout, KeepAlive: 30 * time.Second}
+
+// dialPublicOnly 解析主机名后只连接公网地址：任一解析结果为内网、回环、链路本地等地址时拒绝连接，
+// 并直接连接校验过的 IP，避免校验与连接之间 DNS 结果变化（DNS rebinding）
+func dialPublicOnly(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("无法解析回调地址 %s", host)
+	}
+	for _, addr := range addrs {
+		if isBlockedWebhookIP(addr.IP) {
+			return nil, fmt.Errorf("回调地址 %s 解析到内网地址 %s，已拒绝", host, addr.IP)
+		}
+	}
+	var lastErr error
+	for _, addr := range addrs {
+		conn, err := webhookDialer.DialContext(ctx, network, net.JoinHostPort(addr.IP.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// blockedWebhookNets 除私有、回环、链路本地、组播等标准判断外额外禁止的 IPv4 地址段
+var blockedWebhookNets = []*net.IPNet{
+	mustParseCIDR("0.0.0.0/8"),     // 本网络（部分系统上 0.x 地址会连到本机）
+	mustParseCIDR("100.64.0.0/10"), // 运营商级 NAT
+	mustParseCIDR("192.0.0.0/24"),  // IETF 协议分配
+	mustParseCIDR("198.18.0.0/15"), // 网络基准测试
+	mustParseCIDR("240.0.0.0/4"),   // 保留地址（含广播地址）
+}
+
+// nat64Prefixes NAT64 地址前缀，地址的最后 4 字节为内嵌的 IPv4 地址
+var nat64Prefixes = []*net.IPNet{
+	mustParseCIDR("64:ff9b::/96"),
+	mustParseCIDR("64:ff9b:1::/48"),
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return ipNet
+}
+
+// isBlockedWebhookIP 判断是否为不允许投递的地址：私有、回环、链路本地、未指定、组播及 blockedWebhookNets 中的地址。
+// IPv4 映射地址（::ffff:a.b.c.d）与 NAT64 地址按内嵌的 IPv4 地址判断
+func isBlockedWebhookIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	} else if len(ip) == net.IPv6len {
+		for _, prefix := range nat64Prefixes {
+			if prefix.Contains(ip) {
+				ip = ip[12:16]
+				break
+			}
+		}
+	}
+	if ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return true
+	}
+	for _, blocked := range blockedWebhookNets {
+		if blocked.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// emitWebhookEvent 为订阅了该事件的 Webhook 写入投递记录：全平台订阅，以及订阅了 hackathonIDs 中任一活动的订阅
+func emitWebhookEvent(tx *gorm.DB, event string, data interface{}, hackathonIDs ...uint64) error {
+	query := tx.Where("enabled = ?", true)
+	if len(hackathonIDs) > 0 {
+		query = query.Where("hackathon_id IS NULL OR hackathon_id IN ?", hackathonIDs)
+	} else {
+		query = query.Where("hackathon_id IS NULL")
+	}
+	var webhooks []models.Webhook
+	if err := query.Find(&webhooks).Error; err != nil {
+		return err
+	}
+
+	var deliveries []models.WebhookDelivery
+	var eventID string
+	var payload []byte
+	for _, webhook := range webhooks {
+		if !webhookSubscribes(&webhook, event) {
+			continue
+		}
+		// 同一事件的所有投递共用事件ID与请求体
+		if payload == nil {
+			var err error
+			if eventID, err = generateWebhookToken(16); err != nil {
+				return err
+			}
+			if payload, err = json.Marshal(webhookEnvelope{ID: eventID, Event: event, CreatedAt: time.Now(), Data: data}); err != nil {
+				return err
+			}
+		}
+		deliveries = append(deliveries, models.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       eventID,
+			Event:         event,
+			Payload:       string(payload),
+			Status:        "pending",
+			NextAttemptAt: time.Now(),
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return tx.Create(&deliveries).Error
+}
+
+// emitWebhookEventLogged 在事务外发送 Webhook 事件，写入失败只记录日志，不影响业务操作
+func emitWebhookEventLogged(event string, data interface{}, hackathonIDs ...uint64) {
+	if err := emitWebhookEvent(database.DB, event, data, hackathonIDs...); err != nil {
+		log.Printf("写入 Webhook 事件 %s 失败: %v", event, err)
+	}
+}
+
+// webhookSubscribes 判断 Webhook 是否订阅了该事件
+func webhookSubscribes(webhook *models.Webhook, event string) bool {
+	for _, subscribed := range strings.Split(webhook.Events, ",") {
+		if subscribed == event {
+			return true
+		}
+	}
+	return false
+}
+
+// generateWebhookToken 生成 n 字节的随机十六进制串（事件ID、签名密钥）
+func generateWebhookToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// signWebhookPayload 计算签名：HMAC-SHA256(secret, "<timestamp>.<body>")，接收方据此校验来源并拒绝过期请求
+func signWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// emitRegistrationWebhook 报名生效（无需审核的报名或审核通过）时发送参赛者报名事件
+func emitRegistrationWebhook(tx *gorm.DB, hackathon *models.Hackathon, registration *models.Registration) error {
+	if registration.Status != "approved" {
+		return nil
+	}
+	var participant models.Participant
+	if err := tx.Where("id = ?", registration.ParticipantID).First(&participant).Error; err != nil {
+		return err
+	}
+	return emitWebhookEvent(tx, WebhookParticipantRegistered, map[string]interface{}{
+		"hackathon_id":    hackathon.ID,
+		"hackathon_name":  hackathon.Name,
+		"registration_id": registration.ID,
+		"participant_id":  participant.ID,
+		"wallet_address":  participant.WalletAddress,
+		"nickname":        participant.Nickname,
+	}, hackathon.ID)
+}
+
+// emitTeamCreated 发送队伍创建事件，autoGrouped 表示由主办方自动组队创建
+func emitTeamCreated(tx *gorm.DB, team *models.Team, memberIDs []uint64, autoGrouped bool) error {
+	return emitWebhookEvent(tx, WebhookTeamCreated, map[string]interface{}{
+		"hackathon_id": team.HackathonID,
+		"team_id":      team.ID,
+		"name":         team.Name,
+		"leader_id":    team.LeaderID,
+		"member_ids":   memberIDs,
+		"auto_grouped": autoGrouped,
+	}, team.HackathonID)
+}
+
+// emitSubmissionSaved 作品保存后按最新内容发送事件：首次正式提交（含草稿转为正式提交）为作品提交，其余为作品更新；草稿不发送
+func emitSubmissionSaved(tx *gorm.DB, submissionID uint64, firstSubmit bool) error {
+	var submission models.Submission
+	if err := tx.Where("id = ?", submissionID).First(&submission).Error; err != nil {
+		return err
+	}
+	if submission.Draft == 1 {
+		return nil
+	}
+	event := WebhookSubmissionUpdated
+	if firstSubmit {
+		event = WebhookSubmissionCreated
+	}
+	return emitWebhookEvent(tx, event, map[string]interface{}{
+		"hackathon_id":  submission.HackathonID,
+		"submission_id": submission.ID,
+		"team_id":       submission.TeamID,
+		"name":          submission.Name,
+		"link":          submission.Link,
+		"repo_url":      submission.RepoURL,
+		"demo_url":      submission.DemoURL,
+		"video_url":     submission.VideoURL,
+		"late":          submission.Late,
+	}, submission.HackathonID)
+}
+
+// emitResultsPublished 活动公布结果后发送结果事件（含全场与各赛道排名），写入失败只记录日志
+func emitResultsPublished(hackathon *models.Hackathon) {
+	var results []models.HackathonResult
+	if err := database.DB.Where("hackathon_id = ?", hackathon.ID).Order("track_id ASC, `rank` ASC").Find(&results).Error; err != nil {
+		log.Printf("查询活动 %d 结果失败，未发送 Webhook: %v", hackathon.ID, err)
+		return
+	}
+	rankings := make([]map[string]interface{}, 0, len(results))
+	for _, result := range results {
+		rankings = append(rankings, map[string]interface{}{
+			"track_id":      result.TrackID,
+			"rank":          result.Rank,
+			"submission_id": result.SubmissionID,
+			"team_id":       result.TeamID,
+			"score":         result.Score,
+			"vote_count":    result.VoteCount,
+			"award_id":      result.AwardID,
+		})
+	}
+	emitWebhookEventLogged(WebhookResultsPublished, map[string]interface{}{
+		"hackathon_id":   hackathon.ID,
+		"hackathon_name": hackathon.Name,
+		"results":        rankings,
+	}, hackathon.ID)
+}
+
+// StartWebhookWorker 启动后台投递任务，定时投递到期的 Webhook
+func StartWebhookWorker() {
+	go func() {
+		ticker := time.NewTicker(webhookPollInterval)
+		defer ticker.Stop()
+		for {
+			processWebhookDeliveries()
+			<-ticker.C
+		}
+	}()
+}
+
+// processWebhookDeliveries 领取并投递到期的 Webhook，直到没有到期的投递
+func processWebhookDeliveries() {
+	for {
+		var deliveries []models.WebhookDelivery
+		if err := database.DB.Where("status = ? AND next_attempt_at <= ?", "pending", time.Now()).
+			Order("next_attempt_at ASC, id ASC").Limit(webhookBatchSize).Find(&deliveries).Error; err != nil {
+			log.Printf("查询 Webhook 投递队列失败: %v", err)
+			return
+		}
+		sent := 0
+		for i := range deliveries {
+			if claimWebhookDelivery(&deliveries[i]) {
+				deliverWebhook(&deliveries[i])
+				sent++
+			}
+		}
+		if len(deliveries) < webhookBatchSize || sent == 0 {
+			return
+		}
+	}
+}
+
+// claimWebhookDelivery 将投递的下次尝试时间推后以领取投递，多个实例同时运行时只有一个能领取成功
+func claimWebhookDelivery(delivery *models.WebhookDelivery) bool {
+	result := database.DB.Model(&models.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND next_attempt_at = ?", delivery.ID, "pending", delivery.NextAttemptAt).
+		Update("next_attempt_at", time.Now().Add(webhookDeliveryLease))
+	return result.Error == nil && result.RowsAffected == 1
+}
+
+// deliverWebhook 签名并投递 Webhook，记录响应结果，失败时安排重试或标记为失败
+func deliverWebhook(delivery *models.WebhookDelivery) {
+	now := time.Now()
+	attempts := delivery.Attempts + 1
+	updates := map[string]interface{}{"attempts": attempts}
+
+	var webhook models.Webhook
+	if err := database.DB.Where("id = ?", delivery.WebhookID).First(&webhook).Error; err != nil {
+		updates["status"] = "failed"
+		updates["last_error"] = "Webhook 已删除"
+	} else if !webhook.Enabled {
+		updates["status"] = "failed"
+		updates["last_error"] = "Webhook 已停用"
+	} else {
+		statusCode, responseBody, err := postWebhook(&webhook, delivery)
+		updates["response_status"] = statusCode
+		updates["response_body"] = responseBody
+		if err == nil {
+			updates["status"] = "success"
+			updates["delivered_at"] = now
+			updates["last_error"] = ""
+		} else {
+			lastError := err.Error()
+			if len(lastError) > 1000 {
+				lastError = lastError[:1000]
+			}
+			updates["last_error"] = lastError
+			if attempts >= webhookMaxAttempts {
+				updates["status"] = "failed"
+				log.Printf("Webhook 投递 %d 失败（已尝试 %d 次）: %v", delivery.ID, attempts, err)
+			} else {
+				updates["next_attempt_at"] = now.Add(notificationBackoff(attempts))
+			}
+		}
+	}
+
+	if err := database.DB.Model(&models.WebhookDelivery{}).Where("id = ?", delivery.ID).Updates(updates).Error; err != nil {
+		log.Printf("更新 Webhook 投递 %d 状态失败: %v", delivery.ID, err)
+	}
+}
+
+// postWebhook 发送签名请求，返回状态码与截断后的响应内容（仅保留开头一小段，便于排查），非 2xx 响应视为失败
+func postWebhook(webhook *models.Webhook, delivery *models.WebhookDelivery) (int, string, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Hackathon-Webhook/1.0")
+	req.Header.Set("X-Webhook-Event", delivery.Event)
+	req.Header.Set("X-Webhook-ID", delivery.EventID)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatUint(delivery.ID, 10))
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", signWebhookPayload(webhook.Secret, timestamp, body))
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseBodyLimit))
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, webhookResponseLimit))
+	responseBody := strings.ToValidUTF8(string(snippet), "")
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, responseBody, fmt.Errorf("接收方返回 HTTP %d", resp.StatusCode)
+	}
+	return resp.StatusCode, responseBody, nil
+}
+
+// GetEventTypes 获取可订阅的事件类型
+func (s *WebhookService) GetEventTypes() []WebhookEventType {
+	return webhookEventTypes
+}
+
+// normalizeWebhookInput 校验回调地址与订阅事件，返回逗号分隔的事件列表
+func normalizeWebhookInput(input *WebhookInput) (string, error) {
+	input.URL = strings.TrimSpace(input.URL)
+	parsed, err := url.Parse(input.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return "", errors.New("回调地址须为有效的 http(s) 地址")
+	}
+	// 回调地址不能指向内网；域名在每次投递连接时再按解析结果校验
+	if host := parsed.Hostname(); strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return "", errors.New("回调地址不能指向内网地址")
+	} else if ip := net.ParseIP(host); ip != nil && isBlockedWebhookIP(ip) {
+		return "", errors.New("回调地址不能指向内网地址")
+	}
+	if len(input.URL) > 500 {
+		return "", errors.New("回调地址过长")
+	}
+	if len([]rune(input.Description)) > 255 {
+		return "", errors.New("备注不能超过255个字符")
+	}
+	if len(input.Events) == 0 {
+		return "", errors.New("请至少订阅一个事件")
+	}
+	seen := make(map[string]bool)
+	events := make([]string, 0, len(input.Events))
+	for _, event := range input.Events {
+		known := false
+		for _, eventType := range webhookEventTypes {
+			if eventType.Type == event {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return "", fmt.Errorf("未知的事件类型: %s", event)
+		}
+		if !seen[event] {
+			seen[event] = true
+			events = append(events, event)
+		}
+	}
+	return strings.Join(events, ","), nil
+}
+
+// checkWebhookManager 检查用户是否可以管理该 Webhook：Admin 可管理全部，主办方只能管理自己创建活动的 Webhook
+func (s *WebhookService) checkWebhookManager(webhookID, userID uint64, userRole string) (*models.Webhook, error) {
+	var webhook models.Webhook
+	if err := database.DB.Where("id = ?", webhookID).First(&webhook).Error; err != nil {
+		return nil, errors.New("Webhook 不存在")
+	}
+	if userRole == "admin" {
+		return &webhook, nil
+	}
+	if webhook.HackathonID == nil {
+		return nil, errors.New("只能管理自己创建活动的 Webhook")
+	}
+	if _, err := checkWebhookHackathon(*webhook.HackathonID, userID); err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// checkWebhookHackathon 检查活动存在且由该主办方创建
+func checkWebhookHackathon(hackathonID, userID uint64) (*models.Hackathon, error) {
+	var hackathon models.Hackathon
+	if err := database.DB.Where("id = ? AND deleted_at IS NULL", hackathonID).First(&hackathon).Error; err != nil {
+		return nil, errors.New("活动不存在")
+	}
+	if hackathon.OrganizerID != userID {
+		return nil, errors.New("只能管理自己创建活动的 Webhook")
+	}
+	return &hackathon, nil
+}
+
+// GetWebhooks 获取 Webhook 列表：Admin 可查看全部（可按活动筛选），主办方查看自己创建活动的 Webhook
+func (s *WebhookService) GetWebhooks(hackathonID, userID uint64, userRole string, page, pageSize int) ([]models.Webhook, int64, error) {
+	query := database.DB.Model(&models.Webhook{})
+	if hackathonID > 0 {
+		query = query.Where("hackathon_id = ?", hackathonID)
+	}
+	if userRole != "admin" {
+		query = query.Where("hackathon_id IN (?)",
+			database.DB.Model(&models.Hackathon{}).Select("id").Where("organizer_id = ? AND deleted_at IS NULL", userID))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var webhooks []models.Webhook
+	offset := (page - 1) * pageSize
+	if err := query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&webhooks).Error; err != nil {
+		return nil, 0, err
+	}
+	return webhooks, total, nil
+}
+
+// CreateWebhook 创建 Webhook 并生成签名密钥（密钥只在此时与重置时返回）
+func (s *WebhookService) CreateWebhook(input WebhookInput, userID uint64, userRole string) (*models.Webhook, error) {
+	events, err := normalizeWebhookInput(&input)
+	if err != nil {
+		return nil, err
+	}
+	if input.HackathonID == nil {
+		if userRole != "admin" {
+			return nil, errors.New("请指定订阅的活动，只有Admin可以订阅全平台事件")
+		}
+	} else if userRole == "admin" {
+		var count int64
+		if err := database.DB.Model(&models.Hackathon{}).Where("id = ? AND deleted_at IS NULL", *input.HackathonID).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return nil, errors.New("活动不存在")
+		}
+	} else if _, err := checkWebhookHackathon(*input.HackathonID, userID); err != nil {
+		return nil, err
+	}
+
+	secret, err := generateWebhookToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("生成签名密钥失败: %w", err)
+	}
+	webhook := models.Webhook{
+		HackathonID: input.HackathonID,
+		CreatedBy:   userID,
+		URL:         input.URL,
+		Secret:      secret,
+		Events:      events,
+		Description: input.Description,
+		Enabled:     input.Enabled == nil || *input.Enabled,
+	}
+	if err := database.DB.Create(&webhook).Error; err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// UpdateWebhook 修改 Webhook 的回调地址、订阅事件、备注与启用状态（订阅范围不可修改）
+func (s *WebhookService) UpdateWebhook(webhookID uint64, input WebhookInput, userID uint64, userRole string) (*models.Webhook, error) {
+	webhook, err := s.checkWebhookManager(webhookID, userID, userRole)
+	if err != nil {
+		return nil, err
+	}
+	events, err := normalizeWebhookInput(&input)
+	if err != nil {
+		return nil, err
+	}
+	updates := map[string]interface{}{
+		"url":         input.URL,
+		"events":      events,
+		"description": input.Description,
+	}
+	if input.Enabled != nil {
+		updates["enabled"] = *input.Enabled
+	}
+	if err := database.DB.Model(webhook).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// DeleteWebhook 删除 Webhook，尚未投递的事件不再投递
+func (s *WebhookService) DeleteWebhook(webhookID, userID uint64, userRole string) error {
+	webhook, err := s.checkWebhookManager(webhookID, userID, userRole)
+	if err != nil {
+		return err
+	}
+	return database.DB.Delete(webhook).Error
+}
+
+// RotateSecret 重置签名密钥，之后的投递（含重试与重放）使用新密钥签名
+func (s *WebhookService) RotateSecret(webhookID, userID uint64, userRole string) (string, error) {
+	webhook, err := s.checkWebhookManager(webhookID, userID, userRole)
+	if err != nil {
+		return "", err
+	}
+	secret, err := generateWebhookToken(32)
+	if err != nil {
+		return "", fmt.Errorf("生成签名密钥失败: %w", err)
+	}
+	if err := database.DB.Model(webhook).Update("secret", secret).Error; err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+// GetDeliveries 获取 Webhook 的投递记录（含响应状态码），可按状态筛选
+func (s *WebhookService) GetDeliveries(webhookID, userID uint64, userRole, status string, page, pageSize int) ([]models.WebhookDelivery, int64, error) {
+	if _, err := s.checkWebhookManager(webhookID, userID, userRole); err != nil {
+		return nil, 0, err
+	}
+	query := database.DB.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", webhookID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var deliveries []models.WebhookDelivery
+	offset := (page - 1) * pageSize
+	if err := query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&deliveries).Error; err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
+
+// ReplayDelivery 重放投递：以相同的事件ID与内容新建一条投递记录并立即排队，原记录保留
+func (s *WebhookService) ReplayDelivery(webhookID, deliveryID, userID uint64, userRole string) (*models.WebhookDelivery, error) {
+	webhook, err := s.checkWebhookManager(webhookID, userID, userRole)
+	if err != nil {
+		return nil, err
+	}
+	if !webhook.Enabled {
+		return nil, errors.New("Webhook 已停用，请先启用")
+	}
+	var original models.WebhookDelivery
+	if err := database.DB.Where("id = ? AND webhook_id = ?", deliveryID, webhookID).First(&original).Error; err != nil {
+		return nil, errors.New("投递记录不存在")
+	}
+	if original.Status == "pending" {
+		return nil, errors.New("该投递仍在进行中")
+	}
+
+	replay := models.WebhookDelivery{
+		WebhookID:     webhookID,
+		EventID:       original.EventID,
+		Event:         original.Event,
+		Payload:       original.Payload,
+		Status:        "pending",
+		NextAttemptAt: time.Now(),
+		ReplayOf:      &original.ID,
+	}
+	if err := database.DB.Create(&replay).Error; err != nil {
+		return nil, err
+	}
+	return &replay, nil
+}
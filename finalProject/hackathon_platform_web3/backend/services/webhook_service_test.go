@@ -0,0 +1,50 @@
+package services
+
+import (
+	"net"
+	"testing"
+)
+
+func TestIsBlockedWebhookIP(t *testing.T) {
+	tests := []struct {
+		name    string
+		ip      string
+		blocked bool
+	}{
+		{"this network 0.0.0.0", "0.0.0.0", true},
+		{"this network 0.x", "0.1.2.3", true},
+		{"loopback", "127.0.0.1", true},
+		{"private 10.x", "10.1.2.3", true},
+		{"private 192.168.x", "192.168.1.1", true},
+		{"link-local metadata", "169.254.169.254", true},
+		{"carrier-grade NAT", "100.64.0.1", true},
+		{"IETF protocol assignments", "192.0.0.8", true},
+		{"benchmarking", "198.18.0.1", true},
+		{"broadcast", "255.255.255.255", true},
+		{"multicast", "224.0.0.1", true},
+		{"IPv4-mapped loopback", "::ffff:127.0.0.1", true},
+		{"IPv4-mapped private", "::ffff:10.0.0.1", true},
+		{"NAT64 loopback", "64:ff9b::7f00:1", true},
+		{"NAT64 private", "64:ff9b::a00:1", true},
+		{"local-use NAT64 private", "64:ff9b:1::a00:1", true},
+		{"IPv6 loopback", "::1", true},
+		{"IPv6 unspecified", "::", true},
+		{"IPv6 link-local", "fe80::1", true},
+		{"IPv6 unique local", "fc00::1", true},
+		{"public IPv4", "8.8.8.8", false},
+		{"IPv4-mapped public", "::ffff:8.8.8.8", false},
+		{"public IPv6", "2606:4700::1111", false},
+		{"NAT64 public", "64:ff9b::808:808", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip := net.ParseIP(tt.ip)
+			if ip == nil {
+				t.Fatalf("invalid test address %s", tt.ip)
+			}
+			if got := isBlockedWebhookIP(ip); got != tt.blocked {
+				t.Errorf("isBlockedWebhookIP(%s) = %v, want %v", tt.ip, got, tt.blocked)
+			}
+		})
+	}
+}
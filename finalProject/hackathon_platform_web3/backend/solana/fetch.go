@@ -6,11 +6,18 @@ import (
 	"context"
 	"encoding/binary"
 	"strings"
+	"time"
 
 	"github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/rpc"
 )
 
+// rpcReadTimeout 单次读取账户的 RPC 超时，walletActivityTimeout 分页查询钱包交易记录的总超时
+const (
+	rpcReadTimeout        = 10 * time.Second
+	walletActivityTimeout = 20 * time.Second
+)
+
 // ActivityAccountExists 检查链上 activity 账户是否已存在，用于在提交 start_registration 等前避免 AccountNotInitialized。
 func ActivityAccountExists(rpcURL, activityAddr string) (bool, error) {
 	addr := strings.TrimSpace(activityAddr)
@@ -22,7 +29,9 @@ func ActivityAccountExists(rpcURL, activityAddr string) (bool, error) {
 		return false, err
 	}
 	client := rpc.New(rpcURL)
-	acc, err := client.GetAccountInfo(context.Background(), pubkey)
+	ctx, cancel := context.WithTimeout(context.Background(), rpcReadTimeout)
+	defer cancel()
+	acc, err := client.GetAccountInfo(ctx, pubkey)
 	if err != nil {
 		return false, err
 	}
@@ -70,7 +79,9 @@ func FetchCheckIns(rpcURL, programID, activityAddr string) (attendees []string,
 		return nil, err
 	}
 	client := rpc.New(rpcURL)
-	acc, err := client.GetAccountInfo(context.Background(), pda)
+	ctx, cancel := context.WithTimeout(context.Background(), rpcReadTimeout)
+	defer cancel()
+	acc, err := client.GetAccountInfo(ctx, pda)
 	if err != nil {
 		return nil, err
 	}
@@ -120,7 +131,9 @@ func FetchVoteTally(rpcURL, programID, activityAddr string) (counts []CandidateV
 		return nil, err
 	}
 	client := rpc.New(rpcURL)
-	acc, err := client.GetAccountInfo(context.Background(), pda)
+	ctx, cancel := context.WithTimeout(context.Background(), rpcReadTimeout)
+	defer cancel()
+	acc, err := client.GetAccountInfo(ctx, pda)
 	if err != nil {
 		return nil, err
 	}
@@ -160,3 +173,61 @@ func FetchVoteTally(rpcURL, programID, activityAddr string) (counts []CandidateV
 	// }
 	return counts, nil
 }
+
+// walletActivityPageSize、walletActivityMaxPages 分页查询交易签名的每页数量与最多页数
+const (
+	walletActivityPageSize = 1000
+	walletActivityMaxPages = 20
+)
+
+// WalletActivity 钱包链上活跃度概况
+type WalletActivity struct {
+	TxCount     int        `json:"tx_count"`      // 已统计的交易数（查询提前结束时不是全部交易）
+	FirstTxTime *time.Time `json:"first_tx_time"` // 已统计范围内最早一笔交易时间，无交易时为 nil
+	Truncated   bool       `json:"truncated"`     // 交易过多，达到查询页数上限仍未查完全部历史，此时钱包至少与 FirstTxTime 一样老
+}
+
+// FetchWalletActivity 从 RPC 分页获取钱包的交易签名记录，用于评估钱包年龄与链上活跃度。
+// 按时间倒序翻页，直到查完全部历史、查到早于 olderThan 的交易（已足以判定为老钱包）或达到页数上限
+func FetchWalletActivity(rpcURL, walletAddr string, olderThan time.Duration) (*WalletActivity, error) {
+	pubkey, err := solana.PublicKeyFromBase58(strings.TrimSpace(walletAddr))
+	if err != nil {
+		return nil, err
+	}
+	client := rpc.New(rpcURL)
+	ctx, cancel := context.WithTimeout(context.Background(), walletActivityTimeout)
+	defer cancel()
+	threshold := time.Now().Add(-olderThan)
+	activity := &WalletActivity{}
+
+	var before solana.Signature
+	for page := 0; page < walletActivityMaxPages; page++ {
+		limit := walletActivityPageSize
+		opts := &rpc.GetSignaturesForAddressOpts{Limit: &limit}
+		if page > 0 {
+			opts.Before = before
+		}
+		signatures, err := client.GetSignaturesForAddressWithOpts(ctx, pubkey, opts)
+		if err != nil {
+			return nil, err
+		}
+		activity.TxCount += len(signatures)
+		// 返回结果按时间倒序，最后一笔为本页最早的交易
+		for i := len(signatures) - 1; i >= 0; i-- {
+			if signatures[i].BlockTime != nil {
+				t := signatures[i].BlockTime.Time()
+				activity.FirstTxTime = &t
+				break
+			}
+		}
+		if len(signatures) < walletActivityPageSize {
+			return activity, nil
+		}
+		if activity.FirstTxTime != nil && activity.FirstTxTime.Before(threshold) {
+			return activity, nil
+		}
+		before = signatures[len(signatures)-1].Signature
+	}
+	activity.Truncated = true
+	return activity, nil
+}
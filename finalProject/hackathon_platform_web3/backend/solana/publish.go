@@ -97,3 +97,9 @@ func WaitForConfirmation(rpcURL, txSignature string, timeout time.Duration) erro
 		}
 	}
 }
+
+// ValidSignature 检查是否为有效的交易签名（base58 编码的 64 字节签名）
+func ValidSignature(txSignature string) bool {
+	_, err := solana.SignatureFromBase58(strings.TrimSpace(txSignature))
+	return err == nil
+}
@@ -0,0 +1,194 @@
+// Package solana token 提供钱包 SPL 代币余额与 NFT 合集持有情况的查询，用于报名资格校验。
+
+package solana
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// ValidAddress 检查是否为有效的 Solana 地址（base58 编码的 32 字节公钥）
+func ValidAddress(addr string) bool {
+	_, err := solana.PublicKeyFromBase58(strings.TrimSpace(addr))
+	return err == nil
+}
+
+// tokenAccountAmount 解析 SPL 代币账户（Token / Token-2022 布局相同）中的数量（偏移 64 的 u64）
+func tokenAccountAmount(data []byte) (uint64, bool) {
+	if len(data) < 72 {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint64(data[64:72]), true
+}
+
+// tokenAccountMint 解析 SPL 代币账户的 mint（偏移 0 的 32 字节）
+func tokenAccountMint(data []byte) (solana.PublicKey, bool) {
+	if len(data) < 32 {
+		return solana.PublicKey{}, false
+	}
+	return solana.PublicKeyFromBytes(data[:32]), true
+}
+
+// FetchTokenBalance 查询钱包持有某 SPL 代币的余额（按 mint 精度换算后的数量，多个代币账户合计）
+func FetchTokenBalance(rpcURL, walletAddr, mintAddr string) (float64, error) {
+	owner, err := solana.PublicKeyFromBase58(strings.TrimSpace(walletAddr))
+	if err != nil {
+		return 0, err
+	}
+	mint, err := solana.PublicKeyFromBase58(strings.TrimSpace(mintAddr))
+	if err != nil {
+		return 0, err
+	}
+
+	client := rpc.New(rpcURL)
+	mintAccount, err := client.GetAccountInfo(context.Background(), mint)
+	if err != nil {
+		return 0, err
+	}
+	if mintAccount == nil || mintAccount.Value == nil {
+		return 0, errors.New("mint account not found")
+	}
+	mintData := mintAccount.Value.Data.GetBinary()
+	// Mint 布局：mint_authority(COption<Pubkey>, 36) + supply(8) + decimals(1)
+	if len(mintData) < 45 {
+		return 0, errors.New("invalid mint account")
+	}
+	decimals := int(mintData[44])
+
+	accounts, err := client.GetTokenAccountsByOwner(context.Background(), owner,
+		&rpc.GetTokenAccountsConfig{Mint: &mint},
+		&rpc.GetTokenAccountsOpts{Encoding: solana.EncodingBase64})
+	if err != nil {
+		return 0, err
+	}
+	var total uint64
+	for _, account := range accounts.Value {
+		if amount, ok := tokenAccountAmount(account.Account.Data.GetBinary()); ok {
+			total += amount
+		}
+	}
+	return float64(total) / math.Pow10(decimals), nil
+}
+
+// metadataCollection 解析 Metaplex 元数据账户中的合集字段，返回合集地址与是否已验证
+func metadataCollection(data []byte) (solana.PublicKey, bool, bool) {
+	// key(1) + update_authority(32) + mint(32)
+	offset := 65
+	readString := func() bool {
+		if offset+4 > len(data) {
+			return false
+		}
+		length := int(binary.LittleEndian.Uint32(data[offset : offset+4]))
+		offset += 4 + length
+		return offset <= len(data)
+	}
+	// name、symbol、uri
+	for i := 0; i < 3; i++ {
+		if !readString() {
+			return solana.PublicKey{}, false, false
+		}
+	}
+	// seller_fee_basis_points(2)
+	offset += 2
+	// creators: Option<Vec<Creator>>，每个 Creator 为 address(32) + verified(1) + share(1)
+	if offset >= len(data) {
+		return solana.PublicKey{}, false, false
+	}
+	if data[offset] == 1 {
+		if offset+5 > len(data) {
+			return solana.PublicKey{}, false, false
+		}
+		count := int(binary.LittleEndian.Uint32(data[offset+1 : offset+5]))
+		offset += 5 + count*34
+	} else {
+		offset++
+	}
+	// primary_sale_happened(1) + is_mutable(1)
+	offset += 2
+	// edition_nonce、token_standard: Option<u8>
+	for i := 0; i < 2; i++ {
+		if offset >= len(data) {
+			return solana.PublicKey{}, false, false
+		}
+		if data[offset] == 1 {
+			offset += 2
+		} else {
+			offset++
+		}
+	}
+	// collection: Option<Collection{verified: bool, key: Pubkey}>
+	if offset+34 > len(data) || data[offset] != 1 {
+		return solana.PublicKey{}, false, false
+	}
+	verified := data[offset+1] == 1
+	return solana.PublicKeyFromBytes(data[offset+2 : offset+34]), verified, true
+}
+
+// OwnsCollectionNFT 查询钱包是否持有某个 NFT 合集（Metaplex 已验证合集）中的 NFT，
+// 同时查询 Token 与 Token-2022 程序的代币账户。
+// 不支持压缩 NFT（cNFT）：其所有权记录在状态树中而非代币账户，需通过 DAS API 查询
+func OwnsCollectionNFT(rpcURL, walletAddr, collectionAddr string) (bool, error) {
+	owner, err := solana.PublicKeyFromBase58(strings.TrimSpace(walletAddr))
+	if err != nil {
+		return false, err
+	}
+	collection, err := solana.PublicKeyFromBase58(strings.TrimSpace(collectionAddr))
+	if err != nil {
+		return false, err
+	}
+
+	client := rpc.New(rpcURL)
+	metadataKeys := make([]solana.PublicKey, 0)
+	for _, programID := range []solana.PublicKey{solana.TokenProgramID, solana.Token2022ProgramID} {
+		programID := programID
+		accounts, err := client.GetTokenAccountsByOwner(context.Background(), owner,
+			&rpc.GetTokenAccountsConfig{ProgramId: &programID},
+			&rpc.GetTokenAccountsOpts{Encoding: solana.EncodingBase64})
+		if err != nil {
+			return false, err
+		}
+
+		// NFT 的代币账户数量为 1；Token-2022 账户的基础布局与 Token 相同，扩展数据位于其后
+		for _, account := range accounts.Value {
+			data := account.Account.Data.GetBinary()
+			amount, ok := tokenAccountAmount(data)
+			if !ok || amount != 1 {
+				continue
+			}
+			mint, _ := tokenAccountMint(data)
+			metadata, _, err := solana.FindTokenMetadataAddress(mint)
+			if err != nil {
+				continue
+			}
+			metadataKeys = append(metadataKeys, metadata)
+		}
+	}
+
+	// getMultipleAccounts 每次最多查询 100 个账户
+	for start := 0; start < len(metadataKeys); start += 100 {
+		end := start + 100
+		if end > len(metadataKeys) {
+			end = len(metadataKeys)
+		}
+		result, err := client.GetMultipleAccounts(context.Background(), metadataKeys[start:end]...)
+		if err != nil {
+			return false, err
+		}
+		for _, account := range result.Value {
+			if account == nil {
+				continue
+			}
+			key, verified, ok := metadataCollection(account.Data.GetBinary())
+			if ok && verified && key.Equals(collection) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
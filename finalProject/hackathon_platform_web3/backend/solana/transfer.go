@@ -0,0 +1,111 @@
+// Package solana transfer 校验链上转账交易，用于登记赏金奖励发放。
+
+package solana
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// lamportsPerSOL 1 SOL 对应的 lamports
+const lamportsPerSOL = 1_000_000_000
+
+// VerifyTransfer 通过 RPC 获取已确认的交易，校验交易执行成功，且收款钱包收到不少于 amount 的转账：
+// mintAddr 为空时按 SOL 余额变化校验，否则按该代币在收款钱包名下各代币账户的余额变化合计校验
+func VerifyTransfer(rpcURL, txSignature, recipientAddr, mintAddr string, amount float64) error {
+	sig, err := solana.SignatureFromBase58(strings.TrimSpace(txSignature))
+	if err != nil {
+		return errors.New("无效的交易签名")
+	}
+	recipient, err := solana.PublicKeyFromBase58(strings.TrimSpace(recipientAddr))
+	if err != nil {
+		return errors.New("收款钱包不是有效的 Solana 地址，无法校验链上转账")
+	}
+
+	maxVersion := uint64(0)
+	result, err := rpc.New(rpcURL).GetTransaction(context.Background(), sig, &rpc.GetTransactionOpts{
+		Commitment:                     rpc.CommitmentConfirmed,
+		MaxSupportedTransactionVersion: &maxVersion,
+	})
+	if err != nil {
+		if errors.Is(err, rpc.ErrNotFound) {
+			return errors.New("交易不存在或尚未确认")
+		}
+		return fmt.Errorf("查询交易失败: %w", err)
+	}
+	if result.Meta == nil || result.Transaction == nil {
+		return errors.New("交易不存在或尚未确认")
+	}
+	if result.Meta.Err != nil {
+		return fmt.Errorf("交易执行失败: %v", result.Meta.Err)
+	}
+
+	if mintAddr == "" {
+		tx, err := result.Transaction.GetTransaction()
+		if err != nil {
+			return fmt.Errorf("解析交易失败: %w", err)
+		}
+		// 账户顺序：交易中的账户，之后为地址查找表加载的可写、只读账户（与余额数组一一对应）
+		keys := append(solana.PublicKeySlice{}, tx.Message.AccountKeys...)
+		keys = append(keys, result.Meta.LoadedAddresses.Writable...)
+		keys = append(keys, result.Meta.LoadedAddresses.ReadOnly...)
+
+		received := new(big.Int)
+		for i, key := range keys {
+			if !key.Equals(recipient) || i >= len(result.Meta.PreBalances) || i >= len(result.Meta.PostBalances) {
+				continue
+			}
+			received.Add(received, new(big.Int).SetUint64(result.Meta.PostBalances[i]))
+			received.Sub(received, new(big.Int).SetUint64(result.Meta.PreBalances[i]))
+		}
+		expected := new(big.Int).SetUint64(uint64(math.Round(amount * lamportsPerSOL)))
+		if received.Cmp(expected) < 0 {
+			return fmt.Errorf("交易未向收款钱包转入 %s SOL", strconv.FormatFloat(amount, 'f', -1, 64))
+		}
+		return nil
+	}
+
+	mint, err := solana.PublicKeyFromBase58(strings.TrimSpace(mintAddr))
+	if err != nil {
+		return errors.New("代币 mint 地址无效")
+	}
+	received := new(big.Int)
+	var decimals uint8
+	found := false
+	add := func(balances []rpc.TokenBalance, sign int) {
+		for _, balance := range balances {
+			if balance.Owner == nil || !balance.Owner.Equals(recipient) || !balance.Mint.Equals(mint) || balance.UiTokenAmount == nil {
+				continue
+			}
+			value, ok := new(big.Int).SetString(balance.UiTokenAmount.Amount, 10)
+			if !ok {
+				continue
+			}
+			decimals = balance.UiTokenAmount.Decimals
+			found = true
+			if sign < 0 {
+				received.Sub(received, value)
+			} else {
+				received.Add(received, value)
+			}
+		}
+	}
+	add(result.Meta.PreTokenBalances, -1)
+	add(result.Meta.PostTokenBalances, 1)
+	if !found {
+		return errors.New("交易未向收款钱包转入奖励代币")
+	}
+	expected := new(big.Int).SetUint64(uint64(math.Round(amount * math.Pow10(int(decimals)))))
+	if received.Cmp(expected) < 0 {
+		return fmt.Errorf("交易向收款钱包转入的代币少于 %s", strconv.FormatFloat(amount, 'f', -1, 64))
+	}
+	return nil
+}
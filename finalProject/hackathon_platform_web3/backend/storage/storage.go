@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"hackathon-backend/config"
+)
+
+// ErrNotFound 文件不存在
+var ErrNotFound = errors.New("文件不存在")
+
+// Storage 文件存储后端，key 为以 / 分隔的相对路径（如 submissions/12/xxx.pdf）
+type Storage interface {
+	// Put 写入文件，已存在时覆盖
+	Put(key string, r io.Reader, size int64, contentType string) error
+	// Get 读取文件，调用方负责关闭
+	Get(key string) (io.ReadCloser, error)
+	// Delete 删除文件，文件不存在时不报错
+	Delete(key string) error
+	// SignedURL 生成限时有效的下载链接
+	SignedURL(key string, expires time.Duration) (string, error)
+}
+
+// Default 当前使用的存储后端，由 Init 根据配置初始化
+var Default Storage
+
+// Init 根据配置初始化存储后端
+func Init() error {
+	cfg := config.AppConfig.Storage
+	switch cfg.Driver {
+	case "", "local":
+		local, err := NewLocalStorage(cfg.LocalDir, cfg.PublicBaseURL, cfg.SigningSecret)
+		if err != nil {
+			return err
+		}
+		Default = local
+	case "s3":
+		s3, err := NewS3Storage(cfg.S3.Endpoint, cfg.S3.Region, cfg.S3.Bucket, cfg.S3.AccessKey, cfg.S3.SecretKey, cfg.S3.UsePathStyle)
+		if err != nil {
+			return err
+		}
+		Default = s3
+	default:
+		return fmt.Errorf("不支持的存储后端: %s", cfg.Driver)
+	}
+	return nil
+}
+
+// URLExpiry 签名下载链接有效期
+func URLExpiry() time.Duration {
+	minutes := config.AppConfig.Storage.URLExpireMinutes
+	if minutes <= 0 {
+		minutes = 60
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// NewKey 生成不可猜测的文件 key：<prefix>/<随机串><ext>
+func NewKey(prefix, ext string) string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return path.Join(prefix, hex.EncodeToString(buf)+strings.ToLower(ext))
+}
+
+// cleanKey 校验并规范化 key，拒绝绝对路径与 .. 等越界写法
+func cleanKey(key string) (string, error) {
+	key = strings.TrimPrefix(key, "/")
+	cleaned := path.Clean(key)
+	if key == "" || cleaned != key || cleaned == "." || strings.HasPrefix(cleaned, "../") || cleaned == ".." {
+		return "", errors.New("无效的文件路径")
+	}
+	return cleaned, nil
+}
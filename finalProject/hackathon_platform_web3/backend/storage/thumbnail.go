@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	_ "image/gif" // 注册 GIF 解码
+	"image/jpeg"
+	"image/png"
+)
+
+// ThumbnailSize 缩略图最长边（像素）
+const ThumbnailSize = 320
+
+// MakeThumbnail 生成图片缩略图（最长边不超过 ThumbnailSize，按区域平均缩放）。
+// 带透明通道的 PNG/GIF 输出 PNG，其余输出 JPEG；不支持的格式（如 WebP）返回错误
+func MakeThumbnail(data []byte) ([]byte, string, error) {
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	thumbWidth, thumbHeight := width, height
+	if width > ThumbnailSize || height > ThumbnailSize {
+		if width >= height {
+			thumbWidth, thumbHeight = ThumbnailSize, height*ThumbnailSize/width
+		} else {
+			thumbWidth, thumbHeight = width*ThumbnailSize/height, ThumbnailSize
+		}
+	}
+	if thumbWidth < 1 {
+		thumbWidth = 1
+	}
+	if thumbHeight < 1 {
+		thumbHeight = 1
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, thumbWidth, thumbHeight))
+	for y := 0; y < thumbHeight; y++ {
+		y0 := bounds.Min.Y + y*height/thumbHeight
+		y1 := bounds.Min.Y + (y+1)*height/thumbHeight
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < thumbWidth; x++ {
+			x0 := bounds.Min.X + x*width/thumbWidth
+			x1 := bounds.Min.X + (x+1)*width/thumbWidth
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, b, a, count uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := color.NRGBAModel.Convert(src.At(sx, sy)).(color.NRGBA)
+					r += uint64(c.R)
+					g += uint64(c.G)
+					b += uint64(c.B)
+					a += uint64(c.A)
+					count++
+				}
+			}
+			dst.SetNRGBA(x, y, color.NRGBA{R: uint8(r / count), G: uint8(g / count), B: uint8(b / count), A: uint8(a / count)})
+		}
+	}
+
+	var buf bytes.Buffer
+	if format == "png" || format == "gif" {
+		if err := png.Encode(&buf, dst); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/png", nil
+	}
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), "image/jpeg", nil
+}
@@ -0,0 +1,231 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"hackathon-backend/config"
+)
+
+const mb = 1 << 20
+
+// 上传文件类别
+const (
+	KindImage     = "image"      // 图片（作品截图、赞助商 Logo）
+	KindPitchDeck = "pitch_deck" // 路演材料
+	KindVideo     = "video"      // 演示视频
+	KindDocument  = "document"   // 其他文档
+)
+
+// UploadRule 某类文件的大小与类型限制
+type UploadRule struct {
+	MaxSize   int64
+	MIMETypes map[string]string // 允许的 MIME 类型 -> 存储时使用的扩展名
+}
+
+// uploadRules 各类文件的上传限制，MIME 类型以文件内容检测结果为准，不信任客户端声明
+var uploadRules = map[string]UploadRule{
+	KindImage: {
+		MaxSize: 10 * mb,
+		MIMETypes: map[string]string{
+			"image/png":  ".png",
+			"image/jpeg": ".jpg",
+			"image/gif":  ".gif",
+			"image/webp": ".webp",
+		},
+	},
+	KindPitchDeck: {
+		MaxSize: 50 * mb,
+		MIMETypes: map[string]string{
+			"application/pdf": ".pdf",
+			"application/vnd.openxmlformats-officedocument.presentationml.presentation": ".pptx",
+			"application/vnd.apple.keynote":                                             ".key",
+		},
+	},
+	KindVideo: {
+		MaxSize: 200 * mb,
+		MIMETypes: map[string]string{
+			"video/mp4":       ".mp4",
+			"video/webm":      ".webm",
+			"video/quicktime": ".mov",
+		},
+	},
+	KindDocument: {
+		MaxSize: 20 * mb,
+		MIMETypes: map[string]string{
+			"application/pdf": ".pdf",
+			"application/zip": ".zip",
+			"text/plain":      ".txt",
+			"application/vnd.openxmlformats-officedocument.wordprocessingml.document": ".docx",
+		},
+	},
+}
+
+// UploadedFile 已上传的文件
+type UploadedFile struct {
+	Key          string
+	ThumbnailKey string // 图片缩略图，非图片或无法生成时为空
+	FileName     string
+	ContentType  string
+	Size         int64
+}
+
+// maxUploadSize 某类文件的大小上限（不超过全局上限）
+func maxUploadSize(rule UploadRule) int64 {
+	limit := rule.MaxSize
+	if global := int64(config.AppConfig.Storage.MaxUploadMB) * mb; global > 0 && global < limit {
+		limit = global
+	}
+	return limit
+}
+
+// DetectContentType 根据文件内容检测 MIME 类型；zip 容器格式（pptx、docx、keynote）与 QuickTime 视频按扩展名细分
+func DetectContentType(head []byte, fileName string) string {
+	contentType := http.DetectContentType(head)
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+	ext := strings.ToLower(filepath.Ext(fileName))
+
+	switch contentType {
+	case "application/zip":
+		switch ext {
+		case ".pptx":
+			return "application/vnd.openxmlformats-officedocument.presentationml.presentation"
+		case ".docx":
+			return "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+		case ".key":
+			return "application/vnd.apple.keynote"
+		}
+	case "application/octet-stream":
+		if ext == ".mov" && len(head) >= 8 && string(head[4:8]) == "ftyp" {
+			return "video/quicktime"
+		}
+	}
+	return contentType
+}
+
+// Upload 校验并保存上传的文件：检查大小与内容类型，写入存储后端，图片同时生成缩略图
+func Upload(kind, prefix string, header *multipart.FileHeader) (*UploadedFile, error) {
+	if Default == nil {
+		return nil, errors.New("文件存储未初始化")
+	}
+	rule, ok := uploadRules[kind]
+	if !ok {
+		return nil, errors.New("不支持的文件类别")
+	}
+	limit := maxUploadSize(rule)
+	if header.Size <= 0 {
+		return nil, errors.New("文件为空")
+	}
+	if header.Size > limit {
+		return nil, fmt.Errorf("文件大小不能超过 %dMB", limit/mb)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, fmt.Errorf("读取上传文件失败: %w", err)
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("读取上传文件失败: %w", err)
+	}
+	head = head[:n]
+
+	contentType := DetectContentType(head, header.Filename)
+	ext, ok := rule.MIMETypes[contentType]
+	if !ok {
+		return nil, fmt.Errorf("不支持的文件类型: %s", contentType)
+	}
+
+	uploaded := &UploadedFile{
+		Key:         NewKey(prefix, ext),
+		FileName:    filepath.Base(header.Filename),
+		ContentType: contentType,
+		Size:        header.Size,
+	}
+
+	if kind != KindImage {
+		if err := Default.Put(uploaded.Key, io.MultiReader(bytes.NewReader(head), file), header.Size, contentType); err != nil {
+			return nil, fmt.Errorf("保存文件失败: %w", err)
+		}
+		return uploaded, nil
+	}
+
+	// 图片读入内存以便生成缩略图（图片大小上限较小）
+	data, err := io.ReadAll(io.MultiReader(bytes.NewReader(head), file))
+	if err != nil {
+		return nil, fmt.Errorf("读取上传文件失败: %w", err)
+	}
+	if err := Default.Put(uploaded.Key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return nil, fmt.Errorf("保存文件失败: %w", err)
+	}
+	if thumb, thumbType, err := MakeThumbnail(data); err == nil {
+		thumbKey := strings.TrimSuffix(uploaded.Key, ext) + "_thumb" + thumbExt(thumbType)
+		if err := Default.Put(thumbKey, bytes.NewReader(thumb), int64(len(thumb)), thumbType); err == nil {
+			uploaded.ThumbnailKey = thumbKey
+		}
+	}
+	return uploaded, nil
+}
+
+// UploadBytes 保存已在内存中的图片（用于历史 base64 Logo 迁移），校验规则与 Upload 相同
+func UploadBytes(kind, prefix, fileName string, data []byte) (*UploadedFile, error) {
+	if Default == nil {
+		return nil, errors.New("文件存储未初始化")
+	}
+	rule, ok := uploadRules[kind]
+	if !ok {
+		return nil, errors.New("不支持的文件类别")
+	}
+	if len(data) == 0 {
+		return nil, errors.New("文件为空")
+	}
+	if limit := maxUploadSize(rule); int64(len(data)) > limit {
+		return nil, fmt.Errorf("文件大小不能超过 %dMB", limit/mb)
+	}
+	contentType := DetectContentType(data, fileName)
+	ext, ok := rule.MIMETypes[contentType]
+	if !ok {
+		return nil, fmt.Errorf("不支持的文件类型: %s", contentType)
+	}
+
+	uploaded := &UploadedFile{
+		Key:         NewKey(prefix, ext),
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+	}
+	if err := Default.Put(uploaded.Key, bytes.NewReader(data), uploaded.Size, contentType); err != nil {
+		return nil, fmt.Errorf("保存文件失败: %w", err)
+	}
+	return uploaded, nil
+}
+
+// DeleteFiles 删除文件及缩略图，空 key 忽略（尽力而为，失败不影响业务）
+func DeleteFiles(keys ...string) {
+	if Default == nil {
+		return
+	}
+	for _, key := range keys {
+		if key != "" {
+			_ = Default.Delete(key)
+		}
+	}
+}
+
+func thumbExt(contentType string) string {
+	if contentType == "image/png" {
+		return ".png"
+	}
+	return ".jpg"
+}
@@ -0,0 +1,174 @@
+package utils
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	mdHeadingPattern     = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	mdUnorderedPattern   = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	mdOrderedPattern     = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+	mdCodeSpanPattern    = regexp.MustCompile("`([^`]+)`")
+	mdLinkPattern        = regexp.MustCompile(`!?\[([^\]]*)\]\(([^)\s]+)\)`)
+	mdBoldPattern        = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	mdItalicPattern      = regexp.MustCompile(`\*([^*]+)\*|\b_([^_]+)_\b`)
+	mdPlaceholderPattern = regexp.MustCompile("\x00(\\d+)\x00")
+)
+
+// RenderMarkdown 将 Markdown 渲染为安全的 HTML。
+// 原文中的 HTML 一律转义，仅输出标题、段落、列表、引用、代码、粗体、斜体与链接等固定标签，
+// 链接只允许 http、https、mailto 与站内相对地址，可直接插入页面而无 XSS 风险
+func RenderMarkdown(src string) string {
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+
+	var out strings.Builder
+	var paragraph []string
+	listTag := ""
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			out.WriteString("<p>" + strings.Join(paragraph, "<br>") + "</p>\n")
+			paragraph = nil
+		}
+	}
+	closeList := func() {
+		if listTag != "" {
+			out.WriteString("</" + listTag + ">\n")
+			listTag = ""
+		}
+	}
+	openList := func(tag string) {
+		if listTag != tag {
+			closeList()
+			out.WriteString("<" + tag + ">\n")
+			listTag = tag
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		// 围栏代码块
+		if strings.HasPrefix(trimmed, "```") {
+			flushParagraph()
+			closeList()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			out.WriteString("<pre><code>" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>\n")
+			continue
+		}
+
+		if trimmed == "" {
+			flushParagraph()
+			closeList()
+			continue
+		}
+
+		if m := mdHeadingPattern.FindStringSubmatch(trimmed); m != nil {
+			flushParagraph()
+			closeList()
+			out.WriteString(fmt.Sprintf("<h%d>%s</h%d>\n", len(m[1]), renderInline(m[2]), len(m[1])))
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, ">") {
+			flushParagraph()
+			closeList()
+			out.WriteString("<blockquote><p>" + renderInline(strings.TrimSpace(strings.TrimPrefix(trimmed, ">"))) + "</p></blockquote>\n")
+			continue
+		}
+
+		if m := mdUnorderedPattern.FindStringSubmatch(line); m != nil {
+			flushParagraph()
+			openList("ul")
+			out.WriteString("<li>" + renderInline(m[1]) + "</li>\n")
+			continue
+		}
+		if m := mdOrderedPattern.FindStringSubmatch(line); m != nil {
+			flushParagraph()
+			openList("ol")
+			out.WriteString("<li>" + renderInline(m[1]) + "</li>\n")
+			continue
+		}
+
+		closeList()
+		paragraph = append(paragraph, renderInline(trimmed))
+	}
+	flushParagraph()
+	closeList()
+
+	return out.String()
+}
+
+// renderInline 渲染行内元素（先转义 HTML，代码片段内不再解析其他语法）。
+// 代码片段与生成的链接先替换为 \x00N\x00 占位符，粗体、斜体处理完后再还原，避免改写链接地址中的 * 与 _
+func renderInline(text string) string {
+	var tokens []string
+	placeholder := func(s string) string {
+		tokens = append(tokens, s)
+		return fmt.Sprintf("\x00%d\x00", len(tokens)-1)
+	}
+	restore := func(s string) string {
+		return mdPlaceholderPattern.ReplaceAllStringFunc(s, func(p string) string {
+			var index int
+			fmt.Sscanf(strings.Trim(p, "\x00"), "%d", &index)
+			if index < len(tokens) {
+				return tokens[index]
+			}
+			return ""
+		})
+	}
+
+	text = mdCodeSpanPattern.ReplaceAllStringFunc(text, func(s string) string {
+		return placeholder("<code>" + html.EscapeString(s[1:len(s)-1]) + "</code>")
+	})
+
+	text = html.EscapeString(text)
+
+	text = mdLinkPattern.ReplaceAllStringFunc(text, func(s string) string {
+		m := mdLinkPattern.FindStringSubmatch(s)
+		label, href := m[1], safeLinkURL(html.UnescapeString(m[2]))
+		if href == "" {
+			return label
+		}
+		if label == "" {
+			label = m[2]
+		} else {
+			label = restore(renderEmphasis(label))
+		}
+		return placeholder(`<a href="` + html.EscapeString(href) + `" rel="nofollow noopener noreferrer" target="_blank">` + label + "</a>")
+	})
+
+	return restore(renderEmphasis(text))
+}
+
+// renderEmphasis 渲染粗体与斜体
+func renderEmphasis(text string) string {
+	text = mdBoldPattern.ReplaceAllString(text, "<strong>$1$2</strong>")
+	return mdItalicPattern.ReplaceAllString(text, "<em>$1$2</em>")
+}
+
+// safeLinkURL 校验链接地址，仅允许 http、https、mailto 与站内相对地址，其他（如 javascript:）返回空
+func safeLinkURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "mailto":
+		return raw
+	case "":
+		if strings.HasPrefix(raw, "/") && !strings.HasPrefix(raw, "//") || strings.HasPrefix(raw, "#") {
+			return raw
+		}
+	}
+	return ""
+}
@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "escapes raw html",
+			src:     `<script>alert(1)</script>`,
+			want:    []string{"&lt;script&gt;alert(1)&lt;/script&gt;"},
+			notWant: []string{"<script>"},
+		},
+		{
+			name: "heading and emphasis",
+			src:  "## Demo **fast** and *small*",
+			want: []string{"<h2>Demo <strong>fast</strong> and <em>small</em></h2>"},
+		},
+		{
+			name: "lists",
+			src:  "- one\n- two\n\n1. first",
+			want: []string{"<ul>\n<li>one</li>\n<li>two</li>\n</ul>", "<ol>\n<li>first</li>\n</ol>"},
+		},
+		{
+			name:    "code span is not parsed",
+			src:     "run `a **b** <c>`",
+			want:    []string{"<code>a **b** &lt;c&gt;</code>"},
+			notWant: []string{"<strong>"},
+		},
+		{
+			name:    "fenced code block",
+			src:     "```\n<b>x</b>\n```",
+			want:    []string{"<pre><code>&lt;b&gt;x&lt;/b&gt;</code></pre>"},
+			notWant: []string{"<b>"},
+		},
+		{
+			name:    "link url keeps underscores and asterisks",
+			src:     "see [repo](https://example.com/my_cool_repo/**x**)",
+			want:    []string{`<a href="https://example.com/my_cool_repo/**x**" rel="nofollow noopener noreferrer" target="_blank">repo</a>`},
+			notWant: []string{"<em>", "<strong>"},
+		},
+		{
+			name: "emphasis inside link label",
+			src:  "[**docs**](https://example.com/a_b_c)",
+			want: []string{`<a href="https://example.com/a_b_c" rel="nofollow noopener noreferrer" target="_blank"><strong>docs</strong></a>`},
+		},
+		{
+			name: "emphasis around link",
+			src:  "**see [x](/page_one)**",
+			want: []string{`<strong>see <a href="/page_one" rel="nofollow noopener noreferrer" target="_blank">x</a></strong>`},
+		},
+		{
+			name: "empty label shows url",
+			src:  "[](https://example.com/a_b_)",
+			want: []string{`target="_blank">https://example.com/a_b_</a>`},
+		},
+		{
+			name:    "javascript link is dropped",
+			src:     "[click](javascript:alert(1))",
+			want:    []string{"click"},
+			notWant: []string{"<a ", "javascript:"},
+		},
+		{
+			name:    "protocol relative link is dropped",
+			src:     "[x](//evil.example)",
+			notWant: []string{"<a "},
+		},
+		{
+			name:    "quotes in url are escaped",
+			src:     `[x](https://example.com/"onmouseover="alert(1))`,
+			notWant: []string{`"onmouseover="`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RenderMarkdown(tt.src)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("RenderMarkdown(%q) = %q, want substring %q", tt.src, got, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("RenderMarkdown(%q) = %q, must not contain %q", tt.src, got, notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestSafeLinkURL(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"https://example.com/a", "https://example.com/a"},
+		{"http://example.com", "http://example.com"},
+		{"mailto:team@example.com", "mailto:team@example.com"},
+		{"/hackathons/1", "/hackathons/1"},
+		{"#section", "#section"},
+		{"javascript:alert(1)", ""},
+		{"JavaScript:alert(1)", ""},
+		{"data:text/html;base64,PHNjcmlwdD4=", ""},
+		{"//evil.example", ""},
+		{"relative/path", ""},
+	}
+	for _, tt := range tests {
+		if got := safeLinkURL(tt.raw); got != tt.want {
+			t.Errorf("safeLinkURL(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}